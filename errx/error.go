@@ -2,8 +2,11 @@ package errx
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 
 	cerr "github.com/aserto-dev/errors"
+	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
 )
 
@@ -16,4 +19,128 @@ var (
 	ErrProviderVerification = cerr.NewAsertoError("E10030", codes.InvalidArgument, http.StatusBadRequest, "verification failed")
 	// Returned when an operation timed out after multiple retries.
 	ErrRetryTimeout = cerr.NewAsertoError("E10034", codes.DeadlineExceeded, http.StatusRequestTimeout, "timeout after multiple retries")
+	// Returned when an access token doesn't have all the required scopes.
+	ErrMissingScopes = cerr.NewAsertoError("E10035", codes.PermissionDenied, http.StatusForbidden, "access token is missing scopes")
+	// Returned when a provider reports that a resource doesn't exist.
+	ErrNotFound = cerr.NewAsertoError("E10036", codes.NotFound, http.StatusNotFound, "resource not found")
+	// Returned when a provider denies access to a resource.
+	ErrForbidden = cerr.NewAsertoError("E10037", codes.PermissionDenied, http.StatusForbidden, "access to resource denied")
+	// Returned when a provider doesn't answer a ping.
+	ErrProviderUnreachable = cerr.NewAsertoError("E10038", codes.Unavailable, http.StatusServiceUnavailable, "provider is unreachable")
+	// Returned when a repo can't be renamed because another repo already has the new name.
+	ErrRepoNameTaken = cerr.NewAsertoError("E10039", codes.AlreadyExists, http.StatusConflict, "repo name is already taken")
+	// Returned when a provider doesn't let secret values be read back.
+	ErrSecretWriteOnly = cerr.NewAsertoError("E10040", codes.Unimplemented, http.StatusNotImplemented, "secret values are write-only")
+	// Returned when a branch moved while a commit was being created on it, e.g. because someone else pushed to it.
+	ErrConcurrentUpdate = cerr.NewAsertoError("E10041", codes.Aborted, http.StatusConflict, "branch changed during the update")
+	// Returned when a repo has no commit yet, e.g. because it was created without AutoInit.
+	ErrEmptyRepo = cerr.NewAsertoError("E10042", codes.FailedPrecondition, http.StatusPreconditionFailed, "repository is not initialized")
 )
+
+// RetryAttemptsKey is the ErrRetryTimeout data key holding the number of attempts made.
+const RetryAttemptsKey = "attempts"
+
+// StatusCodeKey is the ErrNotFound and ErrForbidden data key holding the HTTP status returned by the provider.
+const StatusCodeKey = "status-code"
+
+// MissingScopesError holds the scopes an access token is missing.
+type MissingScopesError struct {
+	Scopes []string
+}
+
+func (e *MissingScopesError) Error() string {
+	return "missing scopes: " + strings.Join(e.Scopes, ", ")
+}
+
+// MissingScopes returns the scopes carried by an ErrMissingScopes error, or nil if err doesn't carry any.
+func MissingScopes(err error) []string {
+	var scopesErr *MissingScopesError
+	if errors.As(err, &scopesErr) {
+		return scopesErr.Scopes
+	}
+
+	return nil
+}
+
+// RetryAttempts returns the number of attempts recorded on an ErrRetryTimeout error, or 0 if err doesn't carry any.
+func RetryAttempts(err error) int {
+	asertoErr := cerr.UnwrapAsertoError(err)
+	if asertoErr == nil {
+		return 0
+	}
+
+	attempts, convErr := strconv.Atoi(asertoErr.Data()[RetryAttemptsKey])
+	if convErr != nil {
+		return 0
+	}
+
+	return attempts
+}
+
+// AsAserto returns the AsertoError err carries, and whether there is one, so that its code and data can be read
+// without importing github.com/aserto-dev/errors. When AsertoErrors are nested, like an ErrRetryTimeout around the
+// error of the last attempt, it is the outermost one, which is also the one the Is functions check.
+func AsAserto(err error) (*cerr.AsertoError, bool) {
+	asertoErr := cerr.UnwrapAsertoError(err)
+	return asertoErr, asertoErr != nil
+}
+
+// IsRepoAlreadyConnected reports whether err is an ErrRepoAlreadyConnected error.
+func IsRepoAlreadyConnected(err error) bool {
+	return ErrRepoAlreadyConnected.SameAs(err)
+}
+
+// IsGithubSecret reports whether err is an ErrGithubSecret error.
+func IsGithubSecret(err error) bool {
+	return ErrGithubSecret.SameAs(err)
+}
+
+// IsProviderVerification reports whether err is an ErrProviderVerification error.
+func IsProviderVerification(err error) bool {
+	return ErrProviderVerification.SameAs(err)
+}
+
+// IsRetryTimeout reports whether err is an ErrRetryTimeout error.
+func IsRetryTimeout(err error) bool {
+	return ErrRetryTimeout.SameAs(err)
+}
+
+// IsMissingScopes reports whether err is an ErrMissingScopes error. MissingScopes returns the scopes it lacks.
+func IsMissingScopes(err error) bool {
+	return ErrMissingScopes.SameAs(err)
+}
+
+// IsNotFound reports whether err is an ErrNotFound error.
+func IsNotFound(err error) bool {
+	return ErrNotFound.SameAs(err)
+}
+
+// IsForbidden reports whether err is an ErrForbidden error.
+func IsForbidden(err error) bool {
+	return ErrForbidden.SameAs(err)
+}
+
+// IsProviderUnreachable reports whether err is an ErrProviderUnreachable error.
+func IsProviderUnreachable(err error) bool {
+	return ErrProviderUnreachable.SameAs(err)
+}
+
+// IsRepoNameTaken reports whether err is an ErrRepoNameTaken error.
+func IsRepoNameTaken(err error) bool {
+	return ErrRepoNameTaken.SameAs(err)
+}
+
+// IsSecretWriteOnly reports whether err is an ErrSecretWriteOnly error.
+func IsSecretWriteOnly(err error) bool {
+	return ErrSecretWriteOnly.SameAs(err)
+}
+
+// IsConcurrentUpdate reports whether err is an ErrConcurrentUpdate error.
+func IsConcurrentUpdate(err error) bool {
+	return ErrConcurrentUpdate.SameAs(err)
+}
+
+// IsEmptyRepo reports whether err is an ErrEmptyRepo error.
+func IsEmptyRepo(err error) bool {
+	return ErrEmptyRepo.SameAs(err)
+}
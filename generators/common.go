@@ -17,6 +17,11 @@ type Config struct {
 	Repo   string
 	Token  string
 	User   string
+	// Data is passed to the templates as the dot value, e.g. {{ .tenantID }}.
+	Data map[string]interface{}
+	// SafeFuncs excludes the sprig functions that read the environment or resolve host names, for rendering untrusted
+	// templates.
+	SafeFuncs bool
 }
 
 func IsGitRepo(path string) error {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -14,11 +15,38 @@ import (
 	"github.com/rs/zerolog"
 )
 
+const templateSuffix = ".tmpl"
+
+// unsafeFuncs are the sprig functions dropped when Config.SafeFuncs is set: they read the environment or resolve
+// host names.
+var unsafeFuncs = []string{"env", "expandenv", "getHostByName"}
+
 type GeneratedFilesContent map[string]string
 
+// GeneratedFile is a generated file path and its content.
+type GeneratedFile struct {
+	Path    string
+	Content string
+}
+
+// GenerateResult lists the files written and skipped by Generate.
+type GenerateResult struct {
+	Written []string
+	Skipped []string
+}
+
+// PlannedFile describes what Generate would do with a single file.
+type PlannedFile struct {
+	Path    string
+	Content string
+	Skip    bool
+}
+
 type Generator interface {
 	GenerateFilesContent() (GeneratedFilesContent, error)
-	Generate(pathToTemplates string, overwrite bool) error
+	GenerateFilesOrdered() ([]GeneratedFile, error)
+	Generate(pathToTemplates string, overwrite bool) (*GenerateResult, error)
+	Plan(pathToTemplates string, overwrite bool) ([]PlannedFile, error)
 }
 
 type generatorImpl struct {
@@ -34,7 +62,10 @@ func NewGenerator(cfg *Config, log *zerolog.Logger, dfs fs.FS) (Generator, error
 	}
 
 	var files []string
-	_ = fs.WalkDir(dfs, ".", func(path string, d fs.DirEntry, err error) error {
+	err := fs.WalkDir(dfs, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
 		if d.IsDir() {
 			return nil
 		}
@@ -42,6 +73,11 @@ func NewGenerator(cfg *Config, log *zerolog.Logger, dfs fs.FS) (Generator, error
 		files = append(files, path)
 		return nil
 	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list generator files")
+	}
+	sort.Strings(files)
+
 	return &generatorImpl{
 		cfg:    cfg,
 		files:  files,
@@ -54,36 +90,45 @@ func (c *generatorImpl) GenerateFilesContent() (GeneratedFilesContent, error) {
 	result := make(GeneratedFilesContent)
 
 	for _, file := range c.files {
-		if !strings.Contains(file, ".tmpl") {
-			content, err := fs.ReadFile(c.dfs, file)
-			if err != nil {
-				return result, err
-			}
-			result[file] = string(content)
-			continue
+		content, err := c.fileContent(file)
+		if err != nil {
+			return result, err
 		}
-		content, err := c.interpolateTemplate(file)
+		result[targetPath("", file)] = content
+	}
+
+	return result, nil
+}
+
+// GenerateFilesOrdered returns the generated files sorted by their source path.
+func (c *generatorImpl) GenerateFilesOrdered() ([]GeneratedFile, error) {
+	result := make([]GeneratedFile, 0, len(c.files))
+
+	for _, file := range c.files {
+		content, err := c.fileContent(file)
 		if err != nil {
 			return result, err
 		}
-		fileName := strings.TrimSuffix(file, ".tmpl")
-		result[fileName] = content
+		result = append(result, GeneratedFile{Path: targetPath("", file), Content: content})
 	}
 
 	return result, nil
 }
 
-func (c *generatorImpl) Generate(pathToTemplates string, overwrite bool) error {
+func (c *generatorImpl) Generate(pathToTemplates string, overwrite bool) (*GenerateResult, error) {
+	result := &GenerateResult{}
+
 	for _, file := range c.files {
-		fileName := filepath.Join(pathToTemplates, strings.TrimSuffix(file, ".tmpl"))
+		fileName := targetPath(pathToTemplates, file)
 
 		// check if file exists
 		exist, err := FileExist(fileName)
 		if err != nil {
-			return err
+			return result, err
 		}
 		if exist && !overwrite {
 			c.logger.Debug().Msgf("file '%s' already exists, skipping", fileName)
+			result.Skipped = append(result.Skipped, fileName)
 			continue
 		}
 
@@ -91,39 +136,103 @@ func (c *generatorImpl) Generate(pathToTemplates string, overwrite bool) error {
 		baseDir := filepath.Dir(fileName)
 		err = os.MkdirAll(baseDir, 0755)
 		if err != nil {
-			return errors.Wrapf(err, "create directory '%s'", baseDir)
+			return result, errors.Wrapf(err, "create directory '%s'", baseDir)
 		}
 
-		var content string
-		if strings.Contains(file, ".tmpl") {
-			content, err = c.interpolateTemplate(file)
-			if err != nil {
-				return err
-			}
-		} else {
-			cnt, err := fs.ReadFile(c.dfs, file)
-			if err != nil {
-				return err
-			}
-			content = string(cnt)
+		content, err := c.fileContent(file)
+		if err != nil {
+			return result, err
 		}
 
-		err = c.writeContentToFile(fileName, content)
+		err = c.writeContentToFile(fileName, content, c.fileMode(file))
 		if err != nil {
-			return err
+			return result, err
 		}
+		result.Written = append(result.Written, fileName)
 	}
 
-	return nil
+	return result, nil
+}
+
+// Plan returns the files Generate would write or skip, with their rendered content, without writing anything.
+func (c *generatorImpl) Plan(pathToTemplates string, overwrite bool) ([]PlannedFile, error) {
+	var planned []PlannedFile
+
+	for _, file := range c.files {
+		fileName := targetPath(pathToTemplates, file)
+
+		exist, err := FileExist(fileName)
+		if err != nil {
+			return planned, err
+		}
+
+		content, err := c.fileContent(file)
+		if err != nil {
+			return planned, err
+		}
+
+		planned = append(planned, PlannedFile{
+			Path:    fileName,
+			Content: content,
+			Skip:    exist && !overwrite,
+		})
+	}
+
+	return planned, nil
+}
+
+func isTemplate(file string) bool {
+	return strings.HasSuffix(file, templateSuffix)
+}
+
+// targetPath returns the path a source file is generated to, without its template suffix.
+func targetPath(pathToTemplates, file string) string {
+	fileName := filepath.Join(pathToTemplates, file)
+	if isTemplate(file) {
+		fileName = strings.TrimSuffix(fileName, templateSuffix)
+	}
+
+	return fileName
+}
+
+// fileContent returns the content of a source file, interpolating it if it is a template.
+func (c *generatorImpl) fileContent(file string) (string, error) {
+	if isTemplate(file) {
+		return c.interpolateTemplate(file)
+	}
+
+	content, err := fs.ReadFile(c.dfs, file)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+// fileMode returns the permissions of the given source file, falling back to 0644
+// when the FS doesn't report a writable mode (e.g. embed.FS reports 0444).
+func (c *generatorImpl) fileMode(file string) fs.FileMode {
+	fi, err := fs.Stat(c.dfs, file)
+	if err != nil || fi.Mode().Perm()&0200 == 0 {
+		return 0644
+	}
+
+	return fi.Mode().Perm()
 }
 
-func (c *generatorImpl) writeContentToFile(filePath, content string) error {
-	w, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY, 0644)
+// writeContentToFile writes content to filePath with the given permissions, replacing those of an existing file.
+func (c *generatorImpl) writeContentToFile(filePath, content string, mode fs.FileMode) error {
+	w, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
 	if err != nil {
 		return errors.Wrapf(err, "open file '%s'", filePath)
 	}
 	defer w.Close()
 
+	// the mode of OpenFile only applies to the files it creates.
+	if err := w.Chmod(mode); err != nil {
+		return errors.Wrapf(err, "set mode of file '%s'", filePath)
+	}
+
 	_, err = w.WriteString(content)
 	if err != nil {
 		return err
@@ -148,17 +257,29 @@ func (c *generatorImpl) interpolateTemplate(templateName string) (string, error)
 		},
 	}
 
+	baseFuncs := sprig.TxtFuncMap()
+	if c.cfg != nil && c.cfg.SafeFuncs {
+		for _, name := range unsafeFuncs {
+			delete(baseFuncs, name)
+		}
+	}
+
 	parsedTemplate, err := template.New(filepath.Base(templateName)).
-		Funcs(sprig.TxtFuncMap()).
+		Funcs(baseFuncs).
 		Funcs(funcs).
 		ParseFS(c.dfs, templateName)
 	if err != nil {
 		return "", err
 	}
 
+	var data map[string]interface{}
+	if c.cfg != nil {
+		data = c.cfg.Data
+	}
+
 	var buf bytes.Buffer
 
-	if err := parsedTemplate.Execute(&buf, nil); err != nil {
+	if err := parsedTemplate.Execute(&buf, data); err != nil {
 		return "", err
 	}
 
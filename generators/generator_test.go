@@ -0,0 +1,177 @@
+package generators_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/aserto-dev/scc-lib/generators"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+var templates = fstest.MapFS{
+	"README.md.tmpl":      {Data: []byte("repo: {{ repo }}")},
+	"config.tmpl.json":    {Data: []byte(`{"repo": "{{ repo }}"}`)},
+	"hooks/build.sh.tmpl": {Data: []byte("#!/bin/sh\necho {{ repo }}\n"), Mode: 0755},
+}
+
+var errBroken = errors.New("broken")
+
+// brokenFS fails to read the "broken" directory.
+type brokenFS struct {
+	fstest.MapFS
+}
+
+func (b brokenFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if name == "broken" {
+		return nil, errBroken
+	}
+
+	return b.MapFS.ReadDir(name)
+}
+
+func newGenerator(t *testing.T) generators.Generator {
+	log := zerolog.Nop()
+	g, err := generators.NewGenerator(&generators.Config{Repo: "aserto-dev/policy"}, &log, templates)
+	require.NoError(t, err)
+
+	return g
+}
+
+func TestGenerateFilesContentSuffix(t *testing.T) {
+	assert := require.New(t)
+
+	content, err := newGenerator(t).GenerateFilesContent()
+
+	assert.NoError(err)
+	assert.Equal("repo: aserto-dev/policy", content["README.md"])
+	assert.Equal(`{"repo": "{{ repo }}"}`, content["config.tmpl.json"])
+}
+
+func TestGenerateFilesOrdered(t *testing.T) {
+	assert := require.New(t)
+
+	files, err := newGenerator(t).GenerateFilesOrdered()
+	assert.NoError(err)
+
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+	assert.Equal([]string{"README.md", "config.tmpl.json", "hooks/build.sh"}, paths)
+	assert.Equal("repo: aserto-dev/policy", files[0].Content)
+}
+
+func TestGenerateSuffix(t *testing.T) {
+	assert := require.New(t)
+
+	dir := t.TempDir()
+	_, err := newGenerator(t).Generate(dir, false)
+	assert.NoError(err)
+
+	readme, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	assert.NoError(err)
+	assert.Equal("repo: aserto-dev/policy", string(readme))
+
+	config, err := os.ReadFile(filepath.Join(dir, "config.tmpl.json"))
+	assert.NoError(err)
+	assert.Equal(`{"repo": "{{ repo }}"}`, string(config))
+}
+
+func TestGeneratePreservesMode(t *testing.T) {
+	assert := require.New(t)
+
+	dir := t.TempDir()
+	_, err := newGenerator(t).Generate(dir, false)
+	assert.NoError(err)
+
+	script, err := os.Stat(filepath.Join(dir, "hooks", "build.sh"))
+	assert.NoError(err)
+	assert.Equal(os.FileMode(0755), script.Mode().Perm())
+
+	readme, err := os.Stat(filepath.Join(dir, "README.md"))
+	assert.NoError(err)
+	assert.Equal(os.FileMode(0644), readme.Mode().Perm())
+
+	// overwriting restores the mode and replaces the whole content of the existing file.
+	older := []byte("#!/bin/sh\necho an older and longer script\n")
+	assert.NoError(os.WriteFile(filepath.Join(dir, "hooks", "build.sh"), older, 0600))
+	_, err = newGenerator(t).Generate(dir, true)
+	assert.NoError(err)
+
+	script, err = os.Stat(filepath.Join(dir, "hooks", "build.sh"))
+	assert.NoError(err)
+	assert.Equal(os.FileMode(0755), script.Mode().Perm())
+
+	content, err := os.ReadFile(filepath.Join(dir, "hooks", "build.sh"))
+	assert.NoError(err)
+	assert.Equal("#!/bin/sh\necho aserto-dev/policy\n", string(content))
+}
+
+func TestPlan(t *testing.T) {
+	assert := require.New(t)
+
+	dir := t.TempDir()
+	assert.NoError(os.WriteFile(filepath.Join(dir, "README.md"), []byte("existing"), 0600))
+
+	planned, err := newGenerator(t).Plan(dir, false)
+	assert.NoError(err)
+	assert.Len(planned, 3)
+
+	for _, file := range planned {
+		switch file.Path {
+		case filepath.Join(dir, "README.md"):
+			assert.True(file.Skip)
+			assert.Equal("repo: aserto-dev/policy", file.Content)
+		default:
+			assert.False(file.Skip)
+			_, err := os.Stat(file.Path)
+			assert.True(os.IsNotExist(err))
+		}
+	}
+
+	readme, err := os.ReadFile(filepath.Join(dir, "README.md"))
+	assert.NoError(err)
+	assert.Equal("existing", string(readme))
+}
+
+func TestSafeFuncs(t *testing.T) {
+	assert := require.New(t)
+
+	log := zerolog.Nop()
+	dfs := fstest.MapFS{"home.tmpl": {Data: []byte(`{{ env "HOME" }}`)}}
+
+	g, err := generators.NewGenerator(&generators.Config{SafeFuncs: true}, &log, dfs)
+	assert.NoError(err)
+	_, err = g.GenerateFilesContent()
+	assert.Error(err)
+
+	g, err = generators.NewGenerator(&generators.Config{SafeFuncs: true}, &log, fstest.MapFS{
+		"host.tmpl": {Data: []byte(`{{ getHostByName "localhost" }}`)},
+	})
+	assert.NoError(err)
+	_, err = g.GenerateFilesContent()
+	assert.Error(err)
+
+	g, err = generators.NewGenerator(&generators.Config{Repo: "policy", SafeFuncs: true}, &log, fstest.MapFS{
+		"repo.tmpl": {Data: []byte(`{{ repo | upper }}`)},
+	})
+	assert.NoError(err)
+	content, err := g.GenerateFilesContent()
+	assert.NoError(err)
+	assert.Equal("POLICY", content["repo"])
+}
+
+func TestNewGeneratorWalkError(t *testing.T) {
+	assert := require.New(t)
+
+	log := zerolog.Nop()
+	dfs := brokenFS{fstest.MapFS{"broken/file.tmpl": {Data: []byte("content")}}}
+
+	_, err := generators.NewGenerator(&generators.Config{}, &log, dfs)
+	assert.ErrorIs(err, errBroken)
+}
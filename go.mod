@@ -3,6 +3,7 @@ module github.com/aserto-dev/scc-lib
 go 1.23.4
 
 require (
+	code.gitea.io/sdk/gitea v0.19.0
 	github.com/Masterminds/sprig v2.22.0+incompatible
 	github.com/aserto-dev/errors v0.0.12
 	github.com/aserto-dev/go-grpc v0.9.2
@@ -11,6 +12,7 @@ require (
 	github.com/google/wire v0.6.0
 	github.com/hashicorp/go-retryablehttp v0.7.7
 	github.com/jpillora/backoff v1.0.0
+	github.com/microsoft/azure-devops-go-api/azuredevops/v7 v7.1.0
 	github.com/pkg/errors v0.9.1
 	github.com/rs/zerolog v1.33.0
 	github.com/shurcooL/githubv4 v0.0.0-20240727222349-48295856cce7
@@ -28,11 +30,14 @@ require (
 	github.com/Masterminds/goutils v1.1.1 // indirect
 	github.com/Masterminds/semver v1.5.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/davidmz/go-pageant v1.0.2 // indirect
+	github.com/go-fed/httpsig v1.1.0 // indirect
 	github.com/google/go-querystring v1.1.0 // indirect
 	github.com/google/subcommands v1.2.0 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/grpc-ecosystem/grpc-gateway/v2 v2.24.0 // indirect
 	github.com/hashicorp/go-cleanhttp v0.5.2 // indirect
+	github.com/hashicorp/go-version v1.6.0 // indirect
 	github.com/huandu/xstrings v1.5.0 // indirect
 	github.com/imdario/mergo v0.3.13 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
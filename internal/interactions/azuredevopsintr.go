@@ -0,0 +1,179 @@
+package interactions
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/location"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/taskagent"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/webapi"
+	"github.com/pkg/errors"
+)
+
+//go:generate mockgen -source=azuredevopsintr.go -destination=mock_azuredevopsintr.go -package=interactions --build_flags=--mod=mod
+
+// AzIntr creates the Azure DevOps clients of the organization at organizationURL, whose requests are each bounded
+// by httpTimeout.
+type AzIntr func(ctx context.Context, organizationURL, token string, httpTimeout time.Duration) (AzureDevOpsIntr, error)
+
+type AzureDevOpsIntr interface {
+	GetConnectionData(ctx context.Context) (*location.ConnectionData, error)
+	GetProjects(ctx context.Context, args core.GetProjectsArgs) (*core.GetProjectsResponseValue, error)
+	GetProject(ctx context.Context, args core.GetProjectArgs) (*core.TeamProject, error)
+	GetTeamMembers(ctx context.Context, args core.GetTeamMembersWithExtendedPropertiesArgs) (*[]webapi.TeamMember, error)
+	GetRepositories(ctx context.Context, args git.GetRepositoriesArgs) (*[]git.GitRepository, error)
+	GetRepository(ctx context.Context, args git.GetRepositoryArgs) (*git.GitRepository, error)
+	CreateRepository(ctx context.Context, args git.CreateRepositoryArgs) (*git.GitRepository, error)
+	UpdateRepository(ctx context.Context, args git.UpdateRepositoryArgs) (*git.GitRepository, error)
+	GetRefs(ctx context.Context, args git.GetRefsArgs) (*git.GetRefsResponseValue, error)
+	CreateAnnotatedTag(ctx context.Context, args git.CreateAnnotatedTagArgs) error
+	GetItem(ctx context.Context, args git.GetItemArgs) (*git.GitItem, error)
+	CreatePush(ctx context.Context, args git.CreatePushArgs) (*git.GitPush, error)
+	GetVariableGroups(ctx context.Context, args taskagent.GetVariableGroupsArgs) (*[]taskagent.VariableGroup, error)
+	AddVariableGroup(ctx context.Context, args taskagent.AddVariableGroupArgs) error
+	UpdateVariableGroup(ctx context.Context, args taskagent.UpdateVariableGroupArgs) error
+}
+
+type azureDevOpsInteraction struct {
+	Core      core.Client
+	Git       git.Client
+	Location  location.Client
+	TaskAgent taskagent.Client
+	observe   ObserveFunc
+}
+
+func NewAzureDevOpsInteraction() AzIntr {
+	return NewAzureDevOpsInteractionWithObserver(nil)
+}
+
+// NewAzureDevOpsInteractionWithObserver hands the calls of the interactions to observe.
+func NewAzureDevOpsInteractionWithObserver(observe ObserveFunc) AzIntr {
+	return func(ctx context.Context, organizationURL, token string, httpTimeout time.Duration) (AzureDevOpsIntr, error) {
+		if strings.TrimSpace(organizationURL) == "" {
+			return nil, errors.New("azure devops organization URL must not be empty")
+		}
+
+		connection := azuredevops.NewPatConnection(organizationURL, token)
+		connection.Timeout = &httpTimeout
+
+		coreClient, err := core.NewClient(ctx, connection)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create Azure DevOps core client")
+		}
+
+		gitClient, err := git.NewClient(ctx, connection)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create Azure DevOps git client")
+		}
+
+		taskAgentClient, err := taskagent.NewClient(ctx, connection)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create Azure DevOps task agent client")
+		}
+
+		return &azureDevOpsInteraction{
+			Core:      coreClient,
+			Git:       gitClient,
+			Location:  location.NewClient(ctx, connection),
+			TaskAgent: taskAgentClient,
+			observe:   observe,
+		}, nil
+	}
+}
+
+func (az *azureDevOpsInteraction) GetConnectionData(ctx context.Context) (*location.ConnectionData, error) {
+	return observeCall(az.observe, "GetConnectionData", func() (*location.ConnectionData, error) {
+		return az.Location.GetConnectionData(ctx, location.GetConnectionDataArgs{})
+	})
+}
+
+func (az *azureDevOpsInteraction) GetProjects(ctx context.Context, args core.GetProjectsArgs) (*core.GetProjectsResponseValue, error) {
+	return observeCall(az.observe, "GetProjects", func() (*core.GetProjectsResponseValue, error) {
+		return az.Core.GetProjects(ctx, args)
+	})
+}
+
+func (az *azureDevOpsInteraction) GetProject(ctx context.Context, args core.GetProjectArgs) (*core.TeamProject, error) {
+	return observeCall(az.observe, "GetProject", func() (*core.TeamProject, error) {
+		return az.Core.GetProject(ctx, args)
+	})
+}
+
+func (az *azureDevOpsInteraction) GetTeamMembers(ctx context.Context, args core.GetTeamMembersWithExtendedPropertiesArgs) (*[]webapi.TeamMember, error) {
+	return observeCall(az.observe, "GetTeamMembers", func() (*[]webapi.TeamMember, error) {
+		return az.Core.GetTeamMembersWithExtendedProperties(ctx, args)
+	})
+}
+
+func (az *azureDevOpsInteraction) GetRepositories(ctx context.Context, args git.GetRepositoriesArgs) (*[]git.GitRepository, error) {
+	return observeCall(az.observe, "GetRepositories", func() (*[]git.GitRepository, error) {
+		return az.Git.GetRepositories(ctx, args)
+	})
+}
+
+func (az *azureDevOpsInteraction) GetRepository(ctx context.Context, args git.GetRepositoryArgs) (*git.GitRepository, error) {
+	return observeCall(az.observe, "GetRepository", func() (*git.GitRepository, error) {
+		return az.Git.GetRepository(ctx, args)
+	})
+}
+
+func (az *azureDevOpsInteraction) CreateRepository(ctx context.Context, args git.CreateRepositoryArgs) (*git.GitRepository, error) {
+	return observeCall(az.observe, "CreateRepository", func() (*git.GitRepository, error) {
+		return az.Git.CreateRepository(ctx, args)
+	})
+}
+
+func (az *azureDevOpsInteraction) UpdateRepository(ctx context.Context, args git.UpdateRepositoryArgs) (*git.GitRepository, error) {
+	return observeCall(az.observe, "UpdateRepository", func() (*git.GitRepository, error) {
+		return az.Git.UpdateRepository(ctx, args)
+	})
+}
+
+func (az *azureDevOpsInteraction) GetRefs(ctx context.Context, args git.GetRefsArgs) (*git.GetRefsResponseValue, error) {
+	return observeCall(az.observe, "GetRefs", func() (*git.GetRefsResponseValue, error) {
+		return az.Git.GetRefs(ctx, args)
+	})
+}
+
+func (az *azureDevOpsInteraction) CreateAnnotatedTag(ctx context.Context, args git.CreateAnnotatedTagArgs) error {
+	return az.observe.call("CreateAnnotatedTag", func() error {
+		_, err := az.Git.CreateAnnotatedTag(ctx, args)
+		return err
+	})
+}
+
+func (az *azureDevOpsInteraction) GetItem(ctx context.Context, args git.GetItemArgs) (*git.GitItem, error) {
+	return observeCall(az.observe, "GetItem", func() (*git.GitItem, error) {
+		return az.Git.GetItem(ctx, args)
+	})
+}
+
+func (az *azureDevOpsInteraction) CreatePush(ctx context.Context, args git.CreatePushArgs) (*git.GitPush, error) {
+	return observeCall(az.observe, "CreatePush", func() (*git.GitPush, error) {
+		return az.Git.CreatePush(ctx, args)
+	})
+}
+
+func (az *azureDevOpsInteraction) GetVariableGroups(ctx context.Context, args taskagent.GetVariableGroupsArgs) (*[]taskagent.VariableGroup, error) {
+	return observeCall(az.observe, "GetVariableGroups", func() (*[]taskagent.VariableGroup, error) {
+		return az.TaskAgent.GetVariableGroups(ctx, args)
+	})
+}
+
+func (az *azureDevOpsInteraction) AddVariableGroup(ctx context.Context, args taskagent.AddVariableGroupArgs) error {
+	return az.observe.call("AddVariableGroup", func() error {
+		_, err := az.TaskAgent.AddVariableGroup(ctx, args)
+		return err
+	})
+}
+
+func (az *azureDevOpsInteraction) UpdateVariableGroup(ctx context.Context, args taskagent.UpdateVariableGroupArgs) error {
+	return az.observe.call("UpdateVariableGroup", func() error {
+		_, err := az.TaskAgent.UpdateVariableGroup(ctx, args)
+		return err
+	})
+}
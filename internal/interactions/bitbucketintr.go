@@ -0,0 +1,442 @@
+package interactions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+)
+
+//go:generate mockgen -source=bitbucketintr.go -destination=mock_bitbucketintr.go -package=interactions --build_flags=--mod=mod
+
+const bitbucketAPI = "https://api.bitbucket.org/2.0"
+
+type BbIntr func(ctx context.Context, token string) BitbucketIntr
+
+type BitbucketIntr interface {
+	CurrentUser(ctx context.Context) (*BitbucketUser, *http.Response, error)
+	ListWorkspaces(ctx context.Context, opt *BitbucketListOptions) (*BitbucketPage[*BitbucketWorkspace], error)
+	GetWorkspacePermission(ctx context.Context, workspace string) (string, error)
+	ListWorkspaceMembers(ctx context.Context, workspace string, opt *BitbucketListOptions) (*BitbucketPage[*BitbucketWorkspaceMember], error)
+	ListRepos(ctx context.Context, workspace string, opt *BitbucketListOptions) (*BitbucketPage[*BitbucketRepo], error)
+	GetRepo(ctx context.Context, workspace, slug string) (*BitbucketRepo, error)
+	CreateRepo(ctx context.Context, workspace, slug string, repo *BitbucketRepo) (*BitbucketRepo, error)
+	RenameRepo(ctx context.Context, workspace, slug, name string) (*BitbucketRepo, error)
+	ListTags(ctx context.Context, workspace, slug string, opt *BitbucketListOptions) (*BitbucketPage[*BitbucketTag], error)
+	CreateTag(ctx context.Context, workspace, slug string, tag *BitbucketTag) error
+	ListCommits(ctx context.Context, workspace, slug, revision string, opt *BitbucketListOptions) (*BitbucketPage[*BitbucketCommit], error)
+	CreateCommit(ctx context.Context, workspace, slug string, opt *BitbucketCommitOptions) (string, error)
+	GetFileContent(ctx context.Context, workspace, slug, revision, filePath string) (string, error)
+	ListVariables(ctx context.Context, workspace, slug string) ([]*BitbucketVariable, error)
+	CreateVariable(ctx context.Context, workspace, slug string, variable *BitbucketVariable) error
+	UpdateVariable(ctx context.Context, workspace, slug string, variable *BitbucketVariable) error
+}
+
+// BitbucketPage is a page of values returned by the Bitbucket API.
+type BitbucketPage[T any] struct {
+	Values  []T    `json:"values"`
+	Page    int    `json:"page"`
+	PageLen int    `json:"pagelen"`
+	Size    int    `json:"size"`
+	Next    string `json:"next"`
+}
+
+// BitbucketListOptions selects the page to read, and filters and sorts the values of paginated endpoints.
+type BitbucketListOptions struct {
+	Page    int
+	PageLen int
+	Query   string
+	Sort    string
+}
+
+type BitbucketUser struct {
+	Username    string `json:"username"`
+	Nickname    string `json:"nickname"`
+	DisplayName string `json:"display_name"`
+	UUID        string `json:"uuid"`
+}
+
+// BitbucketWorkspaceMember is a user's membership of a workspace.
+type BitbucketWorkspaceMember struct {
+	User BitbucketUser `json:"user"`
+}
+
+type BitbucketWorkspace struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+	UUID string `json:"uuid"`
+}
+
+type BitbucketLink struct {
+	Href string `json:"href"`
+}
+
+type BitbucketLinks struct {
+	HTML BitbucketLink `json:"html"`
+}
+
+type BitbucketBranch struct {
+	Name string `json:"name"`
+}
+
+type BitbucketRepo struct {
+	UUID        string           `json:"uuid,omitempty"`
+	Slug        string           `json:"slug,omitempty"`
+	Name        string           `json:"name,omitempty"`
+	FullName    string           `json:"full_name,omitempty"`
+	Description string           `json:"description,omitempty"`
+	SCM         string           `json:"scm,omitempty"`
+	IsPrivate   bool             `json:"is_private"`
+	Links       *BitbucketLinks  `json:"links,omitempty"`
+	MainBranch  *BitbucketBranch `json:"mainbranch,omitempty"`
+}
+
+type BitbucketCommit struct {
+	Hash string `json:"hash"`
+}
+
+// BitbucketTag is a repository tag. A non-empty Message makes it an annotated tag.
+type BitbucketTag struct {
+	Name    string          `json:"name"`
+	Message string          `json:"message,omitempty"`
+	Target  BitbucketCommit `json:"target"`
+}
+
+// BitbucketVariable is a repository pipelines variable. Secured variables can't be read back.
+type BitbucketVariable struct {
+	UUID    string `json:"uuid,omitempty"`
+	Key     string `json:"key"`
+	Value   string `json:"value,omitempty"`
+	Secured bool   `json:"secured"`
+}
+
+// BitbucketCommitOptions describes a commit created through the src endpoint.
+type BitbucketCommitOptions struct {
+	Branch  string
+	Message string
+	// Author is the author of the commit, formatted as "Name <email>". The token owner when empty.
+	Author string
+	Files  map[string]string
+}
+
+// BitbucketError is returned when the Bitbucket API replies with a non-2xx status.
+type BitbucketError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *BitbucketError) Error() string {
+	return fmt.Sprintf("bitbucket: %s: %s", e.Status, e.Body)
+}
+
+type bitbucketInteraction struct {
+	client  *http.Client
+	baseURL string
+	observe ObserveFunc
+}
+
+func NewBitbucketInteraction() BbIntr {
+	return NewBitbucketInteractionWithClient(nil, nil)
+}
+
+// NewBitbucketInteractionWithClient sends the requests through httpClient, with the token layered on top of it, and
+// hands the calls of the interactions to observe. A nil httpClient uses the default client.
+func NewBitbucketInteractionWithClient(httpClient *http.Client, observe ObserveFunc) BbIntr {
+	return func(ctx context.Context, token string) BitbucketIntr {
+		if httpClient != nil {
+			ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+		}
+
+		tokenSource := oauth2.StaticTokenSource(
+			&oauth2.Token{
+				AccessToken: token,
+			},
+		)
+
+		return &bitbucketInteraction{
+			client:  oauth2.NewClient(ctx, tokenSource),
+			baseURL: bitbucketAPI,
+			observe: observe,
+		}
+	}
+}
+
+func (bb *bitbucketInteraction) CurrentUser(ctx context.Context) (*BitbucketUser, *http.Response, error) {
+	user := &BitbucketUser{}
+	resp, err := bb.do(ctx, "CurrentUser", http.MethodGet, "/user", nil, nil, "", user)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return user, resp, nil
+}
+
+func (bb *bitbucketInteraction) ListWorkspaces(ctx context.Context, opt *BitbucketListOptions) (*BitbucketPage[*BitbucketWorkspace], error) {
+	page := &BitbucketPage[*BitbucketWorkspace]{}
+	_, err := bb.do(ctx, "ListWorkspaces", http.MethodGet, "/workspaces", opt.values(), nil, "", page)
+	return page, err
+}
+
+// GetWorkspacePermission returns the permission (owner, collaborator or member) the user has on a workspace.
+func (bb *bitbucketInteraction) GetWorkspacePermission(ctx context.Context, workspace string) (string, error) {
+	page := &BitbucketPage[*struct {
+		Permission string `json:"permission"`
+	}]{}
+
+	query := url.Values{"q": {fmt.Sprintf("workspace.slug=%q", workspace)}}
+	if _, err := bb.do(ctx, "GetWorkspacePermission", http.MethodGet, "/user/permissions/workspaces", query, nil, "", page); err != nil {
+		return "", err
+	}
+
+	if len(page.Values) == 0 {
+		return "", errors.Errorf("user is not a member of workspace '%s'", workspace)
+	}
+
+	return page.Values[0].Permission, nil
+}
+
+func (bb *bitbucketInteraction) ListWorkspaceMembers(ctx context.Context, workspace string, opt *BitbucketListOptions) (*BitbucketPage[*BitbucketWorkspaceMember], error) {
+	page := &BitbucketPage[*BitbucketWorkspaceMember]{}
+	_, err := bb.do(ctx, "ListWorkspaceMembers", http.MethodGet, path.Join("/workspaces", workspace, "members"), opt.values(), nil, "", page)
+	return page, err
+}
+
+func (bb *bitbucketInteraction) ListRepos(ctx context.Context, workspace string, opt *BitbucketListOptions) (*BitbucketPage[*BitbucketRepo], error) {
+	page := &BitbucketPage[*BitbucketRepo]{}
+	_, err := bb.do(ctx, "ListRepos", http.MethodGet, path.Join("/repositories", workspace), opt.values(), nil, "", page)
+	return page, err
+}
+
+func (bb *bitbucketInteraction) GetRepo(ctx context.Context, workspace, slug string) (*BitbucketRepo, error) {
+	repo := &BitbucketRepo{}
+	_, err := bb.do(ctx, "GetRepo", http.MethodGet, path.Join("/repositories", workspace, slug), nil, nil, "", repo)
+	return repo, err
+}
+
+func (bb *bitbucketInteraction) CreateRepo(ctx context.Context, workspace, slug string, repo *BitbucketRepo) (*BitbucketRepo, error) {
+	body, err := json.Marshal(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	created := &BitbucketRepo{}
+	_, err = bb.do(ctx, "CreateRepo", http.MethodPost, path.Join("/repositories", workspace, slug), nil, bytes.NewReader(body), "application/json", created)
+	return created, err
+}
+
+// RenameRepo only sends the new name, so that the other settings of the repo are left as they are.
+func (bb *bitbucketInteraction) RenameRepo(ctx context.Context, workspace, slug, name string) (*BitbucketRepo, error) {
+	body, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return nil, err
+	}
+
+	renamed := &BitbucketRepo{}
+	_, err = bb.do(ctx, "RenameRepo", http.MethodPut, path.Join("/repositories", workspace, slug), nil, bytes.NewReader(body), "application/json", renamed)
+	return renamed, err
+}
+
+func (bb *bitbucketInteraction) ListTags(ctx context.Context, workspace, slug string, opt *BitbucketListOptions) (*BitbucketPage[*BitbucketTag], error) {
+	page := &BitbucketPage[*BitbucketTag]{}
+	_, err := bb.do(ctx, "ListTags", http.MethodGet, path.Join("/repositories", workspace, slug, "refs/tags"), opt.values(), nil, "", page)
+	return page, err
+}
+
+func (bb *bitbucketInteraction) CreateTag(ctx context.Context, workspace, slug string, tag *BitbucketTag) error {
+	body, err := json.Marshal(tag)
+	if err != nil {
+		return err
+	}
+
+	_, err = bb.do(ctx, "CreateTag", http.MethodPost, path.Join("/repositories", workspace, slug, "refs/tags"), nil, bytes.NewReader(body), "application/json", nil)
+	return err
+}
+
+// ListCommits lists the commits reachable from revision, or from all the branches when revision is empty.
+func (bb *bitbucketInteraction) ListCommits(ctx context.Context, workspace, slug, revision string, opt *BitbucketListOptions) (*BitbucketPage[*BitbucketCommit], error) {
+	page := &BitbucketPage[*BitbucketCommit]{}
+	_, err := bb.do(ctx, "ListCommits", http.MethodGet, path.Join("/repositories", workspace, slug, "commits", revision), opt.values(), nil, "", page)
+	return page, err
+}
+
+// CreateCommit commits the given files on top of the branch head, or of the main branch when no branch is set,
+// and returns the hash of the new commit.
+func (bb *bitbucketInteraction) CreateCommit(ctx context.Context, workspace, slug string, opt *BitbucketCommitOptions) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+
+	fields := map[string]string{"message": opt.Message}
+	if opt.Branch != "" {
+		fields["branch"] = opt.Branch
+	}
+	if opt.Author != "" {
+		fields["author"] = opt.Author
+	}
+	for filePath, content := range opt.Files {
+		fields[filePath] = content
+	}
+
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return "", err
+		}
+	}
+
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	resp, err := bb.do(ctx, "CreateCommit", http.MethodPost, path.Join("/repositories", workspace, slug, "src"), nil, &body, form.FormDataContentType(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	// The location of the created commit ends with its hash.
+	return path.Base(resp.Header.Get("Location")), nil
+}
+
+// GetFileContent returns the raw content of a file at the given revision.
+func (bb *bitbucketInteraction) GetFileContent(ctx context.Context, workspace, slug, revision, filePath string) (string, error) {
+	var content []byte
+	_, err := bb.do(ctx, "GetFileContent", http.MethodGet, path.Join("/repositories", workspace, slug, "src", revision, filePath), nil, nil, "", &content)
+	return string(content), err
+}
+
+func (bb *bitbucketInteraction) ListVariables(ctx context.Context, workspace, slug string) ([]*BitbucketVariable, error) {
+	var variables []*BitbucketVariable
+
+	opt := &BitbucketListOptions{Page: 1, PageLen: 100}
+	for {
+		page := &BitbucketPage[*BitbucketVariable]{}
+		_, err := bb.do(ctx, "ListVariables", http.MethodGet, path.Join("/repositories", workspace, slug, "pipelines_config/variables")+"/", opt.values(), nil, "", page)
+		if err != nil {
+			return variables, err
+		}
+
+		variables = append(variables, page.Values...)
+		if page.Next == "" {
+			break
+		}
+
+		opt.Page++
+	}
+
+	return variables, nil
+}
+
+func (bb *bitbucketInteraction) CreateVariable(ctx context.Context, workspace, slug string, variable *BitbucketVariable) error {
+	body, err := json.Marshal(variable)
+	if err != nil {
+		return err
+	}
+
+	_, err = bb.do(ctx, "CreateVariable", http.MethodPost, path.Join("/repositories", workspace, slug, "pipelines_config/variables")+"/", nil, bytes.NewReader(body), "application/json", nil)
+	return err
+}
+
+func (bb *bitbucketInteraction) UpdateVariable(ctx context.Context, workspace, slug string, variable *BitbucketVariable) error {
+	body, err := json.Marshal(variable)
+	if err != nil {
+		return err
+	}
+
+	_, err = bb.do(ctx, "UpdateVariable", http.MethodPut, path.Join("/repositories", workspace, slug, "pipelines_config/variables", variable.UUID), nil, bytes.NewReader(body), "application/json", nil)
+	return err
+}
+
+// do sends a request to the Bitbucket API through send, and observes it under operation, the interaction method.
+func (bb *bitbucketInteraction) do(
+	ctx context.Context,
+	operation, method, endpoint string,
+	query url.Values,
+	body io.Reader,
+	contentType string,
+	result interface{},
+) (*http.Response, error) {
+	return observeCall(bb.observe, operation, func() (*http.Response, error) {
+		return bb.send(ctx, method, endpoint, query, body, contentType, result)
+	})
+}
+
+// send sends a request to the Bitbucket API and decodes the JSON reply into result, when not nil.
+// A *[]byte result receives the raw reply instead.
+func (bb *bitbucketInteraction) send(
+	ctx context.Context,
+	method, endpoint string,
+	query url.Values,
+	body io.Reader,
+	contentType string,
+	result interface{},
+) (*http.Response, error) {
+	reqURL := bb.baseURL + endpoint
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Bitbucket request")
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := bb.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		content, _ := io.ReadAll(resp.Body)
+		return resp, &BitbucketError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(content)}
+	}
+
+	if raw, ok := result.(*[]byte); ok {
+		if *raw, err = io.ReadAll(resp.Body); err != nil {
+			return resp, errors.Wrap(err, "failed to read Bitbucket response")
+		}
+
+		return resp, nil
+	}
+
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return resp, errors.Wrap(err, "failed to decode Bitbucket response")
+		}
+	}
+
+	return resp, nil
+}
+
+func (o *BitbucketListOptions) values() url.Values {
+	values := url.Values{}
+	if o == nil {
+		return values
+	}
+
+	if o.Page > 0 {
+		values.Set("page", strconv.Itoa(o.Page))
+	}
+	if o.PageLen > 0 {
+		values.Set("pagelen", strconv.Itoa(o.PageLen))
+	}
+	if o.Query != "" {
+		values.Set("q", o.Query)
+	}
+	if o.Sort != "" {
+		values.Set("sort", o.Sort)
+	}
+
+	return values
+}
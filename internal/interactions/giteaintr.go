@@ -0,0 +1,308 @@
+package interactions
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/pkg/errors"
+)
+
+//go:generate mockgen -source=giteaintr.go -destination=mock_giteaintr.go -package=interactions --build_flags=--mod=mod
+
+type GtIntr func(ctx context.Context, baseURL, token string) (GiteaIntr, error)
+
+type GiteaIntr interface {
+	GetMyUserInfo() (*gitea.User, *gitea.Response, error)
+	ListMyOrgs(opt gitea.ListOrgsOptions) ([]*gitea.Organization, *gitea.Response, error)
+	GetOrg(org string) (*gitea.Organization, error)
+	GetOrgPermissions(org, user string) (*gitea.OrgPermissions, error)
+	ListOrgMembership(org string, opt gitea.ListOrgMembershipOption) ([]*gitea.User, *gitea.Response, error)
+	ListUserRepos(user string, opt gitea.ListReposOptions) ([]*gitea.Repository, *gitea.Response, error)
+	ListOrgRepos(org string, opt gitea.ListOrgReposOptions) ([]*gitea.Repository, *gitea.Response, error)
+	SearchRepos(opt gitea.SearchRepoOptions) ([]*gitea.Repository, *gitea.Response, error)
+	GetRepo(owner, repo string) (*gitea.Repository, error)
+	CreateRepo(opt gitea.CreateRepoOption) (*gitea.Repository, error)
+	CreateOrgRepo(org string, opt gitea.CreateRepoOption) (*gitea.Repository, error)
+	EditRepo(owner, repo string, opt gitea.EditRepoOption) (*gitea.Repository, *gitea.Response, error)
+	ListRepoTags(owner, repo string, opt gitea.ListRepoTagsOptions) ([]*gitea.Tag, *gitea.Response, error)
+	CreateTag(owner, repo string, opt gitea.CreateTagOption) error
+	ListRepoActionSecrets(owner, repo string, opt gitea.ListRepoActionSecretOption) ([]*gitea.Secret, *gitea.Response, error)
+	CreateRepoActionSecret(owner, repo string, opt gitea.CreateSecretOption) error
+	GetContents(owner, repo, ref, filePath string) (*gitea.ContentsResponse, *gitea.Response, error)
+	CreateFile(owner, repo, filePath string, opt gitea.CreateFileOptions) (string, error)
+	ChangeFiles(owner, repo string, opt *GiteaChangeFilesOptions) (string, error)
+	ServerVersion() (string, *gitea.Response, error)
+}
+
+// GiteaChangeFilesOptions are the files ChangeFiles writes in a single commit, and the commit's details.
+type GiteaChangeFilesOptions struct {
+	Message string                `json:"message"`
+	Branch  string                `json:"branch"`
+	Author  *gitea.Identity       `json:"author,omitempty"`
+	Files   []*GiteaFileOperation `json:"files"`
+}
+
+// GiteaFileOperation creates or updates a file. The SHA of the file being updated makes Gitea refuse the commit when
+// the file changed meanwhile.
+type GiteaFileOperation struct {
+	Operation string `json:"operation"`
+	Path      string `json:"path"`
+	// Content must be base64 encoded.
+	Content string `json:"content"`
+	SHA     string `json:"sha,omitempty"`
+}
+
+// GiteaError is the error of a Gitea request that the SDK doesn't send.
+type GiteaError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *GiteaError) Error() string {
+	return fmt.Sprintf("gitea: %s: %s", e.Status, e.Body)
+}
+
+type giteaInteraction struct {
+	Client *gitea.Client
+	// ctx, httpClient, baseURL and token send the requests the SDK doesn't have.
+	ctx        context.Context
+	httpClient *http.Client
+	baseURL    string
+	token      string
+	observe    ObserveFunc
+}
+
+func NewGiteaInteraction() GtIntr {
+	return NewGiteaInteractionWithClient(nil, nil)
+}
+
+// NewGiteaInteractionWithClient sends the requests through httpClient, and hands the calls of the interactions to
+// observe. A nil httpClient uses the default client.
+func NewGiteaInteractionWithClient(httpClient *http.Client, observe ObserveFunc) GtIntr {
+	return func(ctx context.Context, baseURL, token string) (GiteaIntr, error) {
+		if strings.TrimSpace(baseURL) == "" {
+			return nil, errors.New("gitea base URL must not be empty")
+		}
+
+		// Without a version, the client doesn't ask the server for it on creation, which costs a request per
+		// call and fails on the versions it can't parse, like those of Forgejo.
+		options := []gitea.ClientOption{gitea.SetToken(token), gitea.SetContext(ctx), gitea.SetGiteaVersion("")}
+		if httpClient != nil {
+			options = append(options, gitea.SetHTTPClient(httpClient))
+		}
+
+		client, err := gitea.NewClient(baseURL, options...)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create Gitea client")
+		}
+
+		requestClient := httpClient
+		if requestClient == nil {
+			requestClient = &http.Client{}
+		}
+
+		return &giteaInteraction{
+			Client:     client,
+			ctx:        ctx,
+			httpClient: requestClient,
+			baseURL:    strings.TrimSuffix(baseURL, "/"),
+			token:      token,
+			observe:    observe,
+		}, nil
+	}
+}
+
+func (gi *giteaInteraction) GetMyUserInfo() (*gitea.User, *gitea.Response, error) {
+	return observeGitea(gi, "GetMyUserInfo", gi.Client.GetMyUserInfo)
+}
+
+func (gi *giteaInteraction) ListMyOrgs(opt gitea.ListOrgsOptions) ([]*gitea.Organization, *gitea.Response, error) {
+	return observeGitea(gi, "ListMyOrgs", func() ([]*gitea.Organization, *gitea.Response, error) {
+		return gi.Client.ListMyOrgs(opt)
+	})
+}
+
+func (gi *giteaInteraction) GetOrg(org string) (*gitea.Organization, error) {
+	organization, _, err := observeGitea(gi, "GetOrg", func() (*gitea.Organization, *gitea.Response, error) {
+		return gi.Client.GetOrg(org)
+	})
+	return organization, err
+}
+
+func (gi *giteaInteraction) GetOrgPermissions(org, user string) (*gitea.OrgPermissions, error) {
+	permissions, _, err := observeGitea(gi, "GetOrgPermissions", func() (*gitea.OrgPermissions, *gitea.Response, error) {
+		return gi.Client.GetOrgPermissions(org, user)
+	})
+	return permissions, err
+}
+
+func (gi *giteaInteraction) ListOrgMembership(org string, opt gitea.ListOrgMembershipOption) ([]*gitea.User, *gitea.Response, error) {
+	return observeGitea(gi, "ListOrgMembership", func() ([]*gitea.User, *gitea.Response, error) {
+		return gi.Client.ListOrgMembership(org, opt)
+	})
+}
+
+func (gi *giteaInteraction) ListUserRepos(user string, opt gitea.ListReposOptions) ([]*gitea.Repository, *gitea.Response, error) {
+	return observeGitea(gi, "ListUserRepos", func() ([]*gitea.Repository, *gitea.Response, error) {
+		return gi.Client.ListUserRepos(user, opt)
+	})
+}
+
+func (gi *giteaInteraction) ListOrgRepos(org string, opt gitea.ListOrgReposOptions) ([]*gitea.Repository, *gitea.Response, error) {
+	return observeGitea(gi, "ListOrgRepos", func() ([]*gitea.Repository, *gitea.Response, error) {
+		return gi.Client.ListOrgRepos(org, opt)
+	})
+}
+
+func (gi *giteaInteraction) SearchRepos(opt gitea.SearchRepoOptions) ([]*gitea.Repository, *gitea.Response, error) {
+	return observeGitea(gi, "SearchRepos", func() ([]*gitea.Repository, *gitea.Response, error) {
+		return gi.Client.SearchRepos(opt)
+	})
+}
+
+func (gi *giteaInteraction) GetRepo(owner, repo string) (*gitea.Repository, error) {
+	repository, _, err := observeGitea(gi, "GetRepo", func() (*gitea.Repository, *gitea.Response, error) {
+		return gi.Client.GetRepo(owner, repo)
+	})
+	return repository, err
+}
+
+func (gi *giteaInteraction) CreateRepo(opt gitea.CreateRepoOption) (*gitea.Repository, error) {
+	repository, _, err := observeGitea(gi, "CreateRepo", func() (*gitea.Repository, *gitea.Response, error) {
+		return gi.Client.CreateRepo(opt)
+	})
+	return repository, err
+}
+
+func (gi *giteaInteraction) CreateOrgRepo(org string, opt gitea.CreateRepoOption) (*gitea.Repository, error) {
+	repository, _, err := observeGitea(gi, "CreateOrgRepo", func() (*gitea.Repository, *gitea.Response, error) {
+		return gi.Client.CreateOrgRepo(org, opt)
+	})
+	return repository, err
+}
+
+func (gi *giteaInteraction) EditRepo(owner, repo string, opt gitea.EditRepoOption) (*gitea.Repository, *gitea.Response, error) {
+	return observeGitea(gi, "EditRepo", func() (*gitea.Repository, *gitea.Response, error) {
+		return gi.Client.EditRepo(owner, repo, opt)
+	})
+}
+
+func (gi *giteaInteraction) ListRepoTags(owner, repo string, opt gitea.ListRepoTagsOptions) ([]*gitea.Tag, *gitea.Response, error) {
+	return observeGitea(gi, "ListRepoTags", func() ([]*gitea.Tag, *gitea.Response, error) {
+		return gi.Client.ListRepoTags(owner, repo, opt)
+	})
+}
+
+func (gi *giteaInteraction) CreateTag(owner, repo string, opt gitea.CreateTagOption) error {
+	_, _, err := observeGitea(gi, "CreateTag", func() (*gitea.Tag, *gitea.Response, error) {
+		return gi.Client.CreateTag(owner, repo, opt)
+	})
+	return err
+}
+
+func (gi *giteaInteraction) ListRepoActionSecrets(owner, repo string, opt gitea.ListRepoActionSecretOption) ([]*gitea.Secret, *gitea.Response, error) {
+	return observeGitea(gi, "ListRepoActionSecrets", func() ([]*gitea.Secret, *gitea.Response, error) {
+		return gi.Client.ListRepoActionSecret(owner, repo, opt)
+	})
+}
+
+func (gi *giteaInteraction) CreateRepoActionSecret(owner, repo string, opt gitea.CreateSecretOption) error {
+	return gi.observe.call("CreateRepoActionSecret", func() error {
+		_, err := gi.Client.CreateRepoActionSecret(owner, repo, opt)
+		return err
+	})
+}
+
+func (gi *giteaInteraction) GetContents(owner, repo, ref, filePath string) (*gitea.ContentsResponse, *gitea.Response, error) {
+	return observeGitea(gi, "GetContents", func() (*gitea.ContentsResponse, *gitea.Response, error) {
+		return gi.Client.GetContents(owner, repo, ref, filePath)
+	})
+}
+
+func (gi *giteaInteraction) CreateFile(owner, repo, filePath string, opt gitea.CreateFileOptions) (string, error) {
+	file, _, err := observeGitea(gi, "CreateFile", func() (*gitea.FileResponse, *gitea.Response, error) {
+		return gi.Client.CreateFile(owner, repo, filePath, opt)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fileCommitSHA(file)
+}
+
+// ChangeFiles writes the files of opt in a single commit, through the endpoint the SDK doesn't have, and returns the
+// SHA of the commit.
+func (gi *giteaInteraction) ChangeFiles(owner, repo string, opt *GiteaChangeFilesOptions) (string, error) {
+	body, err := json.Marshal(opt)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode Gitea files")
+	}
+
+	reqURL := fmt.Sprintf("%s/api/v1/repos/%s/%s/contents", gi.baseURL, url.PathEscape(owner), url.PathEscape(repo))
+
+	req, err := http.NewRequestWithContext(gi.ctx, http.MethodPost, reqURL, bytes.NewReader(body))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create Gitea request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "token "+gi.token)
+
+	var files struct {
+		Commit *gitea.FileCommitResponse `json:"commit"`
+	}
+
+	err = gi.observe.call("ChangeFiles", func() error {
+		resp, err := gi.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+			content, _ := io.ReadAll(resp.Body)
+			return &GiteaError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(content)}
+		}
+
+		return errors.Wrap(json.NewDecoder(resp.Body).Decode(&files), "failed to decode Gitea response")
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fileCommitSHA(&gitea.FileResponse{Commit: files.Commit})
+}
+
+// fileCommitSHA returns the SHA of the commit that wrote a file.
+func fileCommitSHA(file *gitea.FileResponse) (string, error) {
+	if file == nil || file.Commit == nil {
+		return "", errors.New("gitea didn't return the created commit")
+	}
+
+	return file.Commit.SHA, nil
+}
+
+func (gi *giteaInteraction) ServerVersion() (string, *gitea.Response, error) {
+	return observeGitea(gi, "ServerVersion", gi.Client.ServerVersion)
+}
+
+// observeGitea runs f, a call of the interaction method, through the observer of gi.
+func observeGitea[T any](gi *giteaInteraction, method string, f func() (T, *gitea.Response, error)) (T, *gitea.Response, error) {
+	var result T
+	var resp *gitea.Response
+
+	err := gi.observe.call(method, func() error {
+		var err error
+		result, resp, err = f()
+		return err
+	})
+
+	return result, resp, err
+}
@@ -3,42 +3,74 @@ package interactions
 import (
 	"context"
 	"errors"
+	"net/http"
 	"time"
 
 	"github.com/aserto-dev/scc-lib/errx"
 	"github.com/google/go-github/v66/github"
+	"github.com/jpillora/backoff"
 	"golang.org/x/oauth2"
 )
 
 //go:generate mockgen -source=githubintr.go -destination=mock_githubintr.go -package=interactions --build_flags=--mod=mod
 
-type GhIntr func(ctx context.Context, token, tokenType string, rateLimitTimeout, retryCount int) GithubIntr
+type GhIntr func(ctx context.Context, token, tokenType string, rateLimitTimeout, retryCount int, httpTimeout time.Duration, userAgent string) GithubIntr
 
 type GithubIntr interface {
 	GetUsers(context.Context, string) (*github.User, *github.Response, error)
-	ListRepoSecrets(context.Context, string, string, *github.ListOptions) (*github.Secrets, error)
+	ListRepoSecrets(context.Context, string, string, *github.ListOptions) (*github.Secrets, *github.Response, error)
 	GetRepoPublicKey(context.Context, string, string) (*github.PublicKey, error)
 	CreateOrUpdateRepoSecret(context.Context, string, string, *github.EncryptedSecret) (*github.Response, error)
 	GetRepo(context.Context, string, string) (*github.Repository, error)
-	CreateRepo(context.Context, string, *github.Repository) error
+	CreateRepo(context.Context, string, *github.Repository) (*github.Repository, error)
+	EditRepo(ctx context.Context, owner, repo string, repository *github.Repository) (*github.Repository, *github.Response, error)
+	CreateHook(ctx context.Context, owner, repo string, hook *github.Hook) (*github.Hook, *github.Response, error)
+	ListHooks(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.Hook, *github.Response, error)
+	DeleteHook(ctx context.Context, owner, repo string, id int64) (*github.Response, error)
+	CreateKey(ctx context.Context, owner, repo string, key *github.Key) (*github.Key, *github.Response, error)
+	ListKeys(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.Key, *github.Response, error)
+	DeleteKey(ctx context.Context, owner, repo string, id int64) (*github.Response, error)
+	ListPullRequests(ctx context.Context, owner, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error)
 	ListRepoTags(context.Context, string, string, *github.ListOptions) ([]*github.RepositoryTag, error)
 	GetRepoRef(context.Context, string, string, string) (*github.Reference, *github.Response, error)
 	CreateRepoTag(context.Context, string, string, *github.Tag) (*github.Tag, error)
 	CreateRepoRef(context.Context, string, string, *github.Reference) error
 	ListRepositoryWorkflowRuns(context.Context, string, string, *github.ListWorkflowRunsOptions) (*github.WorkflowRuns, error)
+	GetWorkflowRunByID(ctx context.Context, owner, repo string, runID int64) (*github.WorkflowRun, error)
 	CreateWorkflowDispatchEventByFileName(context.Context, string, string, string, github.CreateWorkflowDispatchEventRequest) error
+	GetWorkflowByFileName(ctx context.Context, owner, repo, workflowFileName string) (*github.Workflow, error)
 	CreateFile(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, error)
+	GetFileContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (*github.RepositoryContent, error)
 	GetCommit(ctx context.Context, owner, repo, sha string) (*github.Commit, error)
+	CreateTree(ctx context.Context, owner, repo, baseTree string, entries []*github.TreeEntry) (*github.Tree, error)
+	CreateCommit(ctx context.Context, owner, repo string, commit *github.Commit) (*github.Commit, error)
+	UpdateRef(ctx context.Context, owner, repo string, ref *github.Reference, force bool) (*github.Reference, error)
+	ListUserRepos(ctx context.Context, opts *github.RepositoryListByAuthenticatedUserOptions) ([]*github.Repository, *github.Response, error)
+	GetOrg(ctx context.Context, org string) (*github.Organization, error)
+	GetOrgMembership(ctx context.Context, org string) (*github.Membership, error)
+	ListOrgMembers(ctx context.Context, org string, opts *github.ListMembersOptions) ([]*github.User, *github.Response, error)
+	Zen(ctx context.Context) (*github.Response, error)
 }
 
 type githubInteraction struct {
 	Client            *github.Client
 	retryLimitTimeout int
 	retryCount        int
+	observe           ObserveFunc
 }
 
 func NewGithubInteraction() GhIntr {
-	return func(ctx context.Context, token, tokenType string, retryLimitTimeout, retryCount int) GithubIntr {
+	return NewGithubInteractionWithClient(nil, nil)
+}
+
+// NewGithubInteractionWithClient sends the requests through httpClient, with the token layered on top of it, and
+// hands the calls of the interactions to observe. A nil httpClient uses the default client.
+func NewGithubInteractionWithClient(httpClient *http.Client, observe ObserveFunc) GhIntr {
+	return func(ctx context.Context, token, tokenType string, retryLimitTimeout, retryCount int, httpTimeout time.Duration, userAgent string) GithubIntr {
+		if httpClient != nil {
+			ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+		}
+
 		tokenSource := oauth2.StaticTokenSource(
 			&oauth2.Token{
 				AccessToken: token,
@@ -46,13 +78,18 @@ func NewGithubInteraction() GhIntr {
 			},
 		)
 		clientWithToken := oauth2.NewClient(ctx, tokenSource)
+		clientWithToken.Timeout = httpTimeout
 
 		githubClient := github.NewClient(clientWithToken)
+		if userAgent != "" {
+			githubClient.UserAgent = userAgent
+		}
 
 		return &githubInteraction{
 			Client:            githubClient,
 			retryLimitTimeout: retryLimitTimeout,
 			retryCount:        retryCount,
+			observe:           observe,
 		}
 	}
 }
@@ -61,7 +98,7 @@ func (gh *githubInteraction) GetCommit(ctx context.Context, owner, repo, sha str
 	var err error
 	var commit *github.Commit
 
-	err = gh.withSecondaryRateLimitRetry(func() error {
+	err = gh.withSecondaryRateLimitRetry(ctx, "GetCommit", func() error {
 		commit, _, err = gh.Client.Git.GetCommit(ctx, owner, repo, sha)
 		return err
 	})
@@ -69,12 +106,51 @@ func (gh *githubInteraction) GetCommit(ctx context.Context, owner, repo, sha str
 	return commit, err
 }
 
+// CreateTree creates a tree with entries on top of baseTree, whose entries it keeps unless they are replaced.
+func (gh *githubInteraction) CreateTree(ctx context.Context, owner, repo, baseTree string, entries []*github.TreeEntry) (*github.Tree, error) {
+	var tree *github.Tree
+	var err error
+
+	err = gh.withSecondaryRateLimitRetry(ctx, "CreateTree", func() error {
+		tree, _, err = gh.Client.Git.CreateTree(ctx, owner, repo, baseTree, entries)
+		return err
+	})
+
+	return tree, err
+}
+
+// CreateCommit creates a commit object, which no branch points to until a ref is moved to it.
+func (gh *githubInteraction) CreateCommit(ctx context.Context, owner, repo string, commit *github.Commit) (*github.Commit, error) {
+	var created *github.Commit
+	var err error
+
+	err = gh.withSecondaryRateLimitRetry(ctx, "CreateCommit", func() error {
+		created, _, err = gh.Client.Git.CreateCommit(ctx, owner, repo, commit, nil)
+		return err
+	})
+
+	return created, err
+}
+
+// UpdateRef moves ref to its object. Without force, GitHub refuses moves that aren't fast forwards.
+func (gh *githubInteraction) UpdateRef(ctx context.Context, owner, repo string, ref *github.Reference, force bool) (*github.Reference, error) {
+	var updated *github.Reference
+	var err error
+
+	err = gh.withSecondaryRateLimitRetry(ctx, "UpdateRef", func() error {
+		updated, _, err = gh.Client.Git.UpdateRef(ctx, owner, repo, ref, force)
+		return err
+	})
+
+	return updated, err
+}
+
 func (gh *githubInteraction) GetUsers(ctx context.Context, username string) (*github.User, *github.Response, error) {
 	var user *github.User
 	var resp *github.Response
 	var err error
 
-	err = gh.withSecondaryRateLimitRetry(func() error {
+	err = gh.withSecondaryRateLimitRetry(ctx, "GetUsers", func() error {
 		user, resp, err = gh.Client.Users.Get(ctx, username)
 		return err
 	})
@@ -82,22 +158,23 @@ func (gh *githubInteraction) GetUsers(ctx context.Context, username string) (*gi
 	return user, resp, err
 }
 
-func (gh *githubInteraction) ListRepoSecrets(ctx context.Context, owner, repo string, opts *github.ListOptions) (*github.Secrets, error) {
+func (gh *githubInteraction) ListRepoSecrets(ctx context.Context, owner, repo string, opts *github.ListOptions) (*github.Secrets, *github.Response, error) {
 	var secrets *github.Secrets
+	var resp *github.Response
 	var err error
 
-	err = gh.withSecondaryRateLimitRetry(func() error {
-		secrets, _, err = gh.Client.Actions.ListRepoSecrets(ctx, owner, repo, opts)
+	err = gh.withSecondaryRateLimitRetry(ctx, "ListRepoSecrets", func() error {
+		secrets, resp, err = gh.Client.Actions.ListRepoSecrets(ctx, owner, repo, opts)
 		return err
 	})
-	return secrets, err
+	return secrets, resp, err
 }
 
 func (gh *githubInteraction) GetRepoPublicKey(ctx context.Context, org, repo string) (*github.PublicKey, error) {
 	var key *github.PublicKey
 	var err error
 
-	err = gh.withSecondaryRateLimitRetry(func() error {
+	err = gh.withSecondaryRateLimitRetry(ctx, "GetRepoPublicKey", func() error {
 		key, _, err = gh.Client.Actions.GetRepoPublicKey(ctx, org, repo)
 		return err
 	})
@@ -109,7 +186,7 @@ func (gh *githubInteraction) CreateOrUpdateRepoSecret(ctx context.Context, org,
 	var response *github.Response
 	var err error
 
-	err = gh.withSecondaryRateLimitRetry(func() error {
+	err = gh.withSecondaryRateLimitRetry(ctx, "CreateOrUpdateRepoSecret", func() error {
 		response, err = gh.Client.Actions.CreateOrUpdateRepoSecret(ctx, org, repo, secret)
 		return err
 	})
@@ -120,27 +197,130 @@ func (gh *githubInteraction) GetRepo(ctx context.Context, owner, repo string) (*
 	var repoResult *github.Repository
 	var err error
 
-	err = gh.withSecondaryRateLimitRetry(func() error {
+	err = gh.withSecondaryRateLimitRetry(ctx, "GetRepo", func() error {
 		repoResult, _, err = gh.Client.Repositories.Get(ctx, owner, repo)
 		return err
 	})
 	return repoResult, err
 }
 
-func (gh *githubInteraction) CreateRepo(ctx context.Context, owner string, repo *github.Repository) error {
+func (gh *githubInteraction) CreateRepo(ctx context.Context, owner string, repo *github.Repository) (*github.Repository, error) {
+	var repoResult *github.Repository
+	var err error
+
+	err = gh.withSecondaryRateLimitRetry(ctx, "CreateRepo", func() error {
+		repoResult, _, err = gh.Client.Repositories.Create(ctx, owner, repo)
+		return err
+	})
+	return repoResult, err
+}
+
+func (gh *githubInteraction) EditRepo(ctx context.Context, owner, repo string, repository *github.Repository) (*github.Repository, *github.Response, error) {
+	var edited *github.Repository
+	var resp *github.Response
 	var err error
 
-	err = gh.withSecondaryRateLimitRetry(func() error {
-		_, _, err = gh.Client.Repositories.Create(ctx, owner, repo)
+	err = gh.withSecondaryRateLimitRetry(ctx, "EditRepo", func() error {
+		edited, resp, err = gh.Client.Repositories.Edit(ctx, owner, repo, repository)
 		return err
 	})
-	return err
+
+	return edited, resp, err
+}
+
+func (gh *githubInteraction) CreateHook(ctx context.Context, owner, repo string, hook *github.Hook) (*github.Hook, *github.Response, error) {
+	var created *github.Hook
+	var resp *github.Response
+	var err error
+
+	err = gh.withSecondaryRateLimitRetry(ctx, "CreateHook", func() error {
+		created, resp, err = gh.Client.Repositories.CreateHook(ctx, owner, repo, hook)
+		return err
+	})
+
+	return created, resp, err
+}
+
+func (gh *githubInteraction) ListHooks(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.Hook, *github.Response, error) {
+	var hooks []*github.Hook
+	var resp *github.Response
+	var err error
+
+	err = gh.withSecondaryRateLimitRetry(ctx, "ListHooks", func() error {
+		hooks, resp, err = gh.Client.Repositories.ListHooks(ctx, owner, repo, opts)
+		return err
+	})
+
+	return hooks, resp, err
+}
+
+func (gh *githubInteraction) ListPullRequests(ctx context.Context, owner, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error) {
+	var pulls []*github.PullRequest
+	var resp *github.Response
+	var err error
+
+	err = gh.withSecondaryRateLimitRetry(ctx, "ListPullRequests", func() error {
+		pulls, resp, err = gh.Client.PullRequests.List(ctx, owner, repo, opts)
+		return err
+	})
+
+	return pulls, resp, err
+}
+
+func (gh *githubInteraction) DeleteHook(ctx context.Context, owner, repo string, id int64) (*github.Response, error) {
+	var resp *github.Response
+	var err error
+
+	err = gh.withSecondaryRateLimitRetry(ctx, "DeleteHook", func() error {
+		resp, err = gh.Client.Repositories.DeleteHook(ctx, owner, repo, id)
+		return err
+	})
+
+	return resp, err
+}
+
+func (gh *githubInteraction) CreateKey(ctx context.Context, owner, repo string, key *github.Key) (*github.Key, *github.Response, error) {
+	var created *github.Key
+	var resp *github.Response
+	var err error
+
+	err = gh.withSecondaryRateLimitRetry(ctx, "CreateKey", func() error {
+		created, resp, err = gh.Client.Repositories.CreateKey(ctx, owner, repo, key)
+		return err
+	})
+
+	return created, resp, err
+}
+
+func (gh *githubInteraction) ListKeys(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.Key, *github.Response, error) {
+	var keys []*github.Key
+	var resp *github.Response
+	var err error
+
+	err = gh.withSecondaryRateLimitRetry(ctx, "ListKeys", func() error {
+		keys, resp, err = gh.Client.Repositories.ListKeys(ctx, owner, repo, opts)
+		return err
+	})
+
+	return keys, resp, err
+}
+
+func (gh *githubInteraction) DeleteKey(ctx context.Context, owner, repo string, id int64) (*github.Response, error) {
+	var resp *github.Response
+	var err error
+
+	err = gh.withSecondaryRateLimitRetry(ctx, "DeleteKey", func() error {
+		resp, err = gh.Client.Repositories.DeleteKey(ctx, owner, repo, id)
+		return err
+	})
+
+	return resp, err
 }
 
 func (gh *githubInteraction) ListRepoTags(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.RepositoryTag, error) {
 	var tags []*github.RepositoryTag
 	var err error
-	err = gh.withSecondaryRateLimitRetry(func() error {
+	err = gh.withSecondaryRateLimitRetry(ctx, "ListRepoTags", func() error {
 		tags, _, err = gh.Client.Repositories.ListTags(ctx, owner, repo, opts)
 		return err
 	})
@@ -151,7 +331,7 @@ func (gh *githubInteraction) GetRepoRef(ctx context.Context, owner, repo, ref st
 	var reference *github.Reference
 	var response *github.Response
 	var err error
-	err = gh.withSecondaryRateLimitRetry(func() error {
+	err = gh.withSecondaryRateLimitRetry(ctx, "GetRepoRef", func() error {
 		reference, response, err = gh.Client.Git.GetRef(ctx, owner, repo, ref)
 		return err
 	})
@@ -161,7 +341,7 @@ func (gh *githubInteraction) GetRepoRef(ctx context.Context, owner, repo, ref st
 func (gh *githubInteraction) CreateRepoTag(ctx context.Context, owner, repo string, tag *github.Tag) (*github.Tag, error) {
 	var tagResult *github.Tag
 	var err error
-	err = gh.withSecondaryRateLimitRetry(func() error {
+	err = gh.withSecondaryRateLimitRetry(ctx, "CreateRepoTag", func() error {
 		tagResult, _, err = gh.Client.Git.CreateTag(ctx, owner, repo, tag)
 		return err
 	})
@@ -170,7 +350,7 @@ func (gh *githubInteraction) CreateRepoTag(ctx context.Context, owner, repo stri
 
 func (gh *githubInteraction) CreateRepoRef(ctx context.Context, owner, repo string, ref *github.Reference) error {
 	var err error
-	err = gh.withSecondaryRateLimitRetry(func() error {
+	err = gh.withSecondaryRateLimitRetry(ctx, "CreateRepoRef", func() error {
 		_, _, err = gh.Client.Git.CreateRef(ctx, owner, repo, ref)
 		return err
 	})
@@ -180,61 +360,174 @@ func (gh *githubInteraction) CreateRepoRef(ctx context.Context, owner, repo stri
 func (gh *githubInteraction) ListRepositoryWorkflowRuns(ctx context.Context, owner, repo string, opts *github.ListWorkflowRunsOptions) (*github.WorkflowRuns, error) {
 	var runs *github.WorkflowRuns
 	var err error
-	err = gh.withSecondaryRateLimitRetry(func() error {
+	err = gh.withSecondaryRateLimitRetry(ctx, "ListRepositoryWorkflowRuns", func() error {
 		runs, _, err = gh.Client.Actions.ListRepositoryWorkflowRuns(ctx, owner, repo, opts)
 		return err
 	})
 	return runs, err
 }
 
+func (gh *githubInteraction) GetWorkflowRunByID(ctx context.Context, owner, repo string, runID int64) (*github.WorkflowRun, error) {
+	var run *github.WorkflowRun
+	var err error
+	err = gh.withSecondaryRateLimitRetry(ctx, "GetWorkflowRunByID", func() error {
+		run, _, err = gh.Client.Actions.GetWorkflowRunByID(ctx, owner, repo, runID)
+		return err
+	})
+	return run, err
+}
+
 func (gh *githubInteraction) CreateWorkflowDispatchEventByFileName(ctx context.Context, owner, repo, fileNameWorkflow string, event github.CreateWorkflowDispatchEventRequest) error {
 	var err error
-	err = gh.withSecondaryRateLimitRetry(func() error {
+	err = gh.withSecondaryRateLimitRetry(ctx, "CreateWorkflowDispatchEventByFileName", func() error {
 		_, err = gh.Client.Actions.CreateWorkflowDispatchEventByFileName(ctx, owner, repo, fileNameWorkflow, event)
 		return err
 	})
 	return err
 }
 
+func (gh *githubInteraction) GetWorkflowByFileName(ctx context.Context, owner, repo, workflowFileName string) (*github.Workflow, error) {
+	var workflow *github.Workflow
+	var err error
+
+	err = gh.withSecondaryRateLimitRetry(ctx, "GetWorkflowByFileName", func() error {
+		workflow, _, err = gh.Client.Actions.GetWorkflowByFileName(ctx, owner, repo, workflowFileName)
+		return err
+	})
+
+	return workflow, err
+}
+
 func (gh *githubInteraction) CreateFile(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, error) {
 	var contentResponse *github.RepositoryContentResponse
 	var err error
-	err = gh.withSecondaryRateLimitRetry(func() error {
+	err = gh.withSecondaryRateLimitRetry(ctx, "CreateFile", func() error {
 		contentResponse, _, err = gh.Client.Repositories.CreateFile(ctx, owner, repo, path, opts)
 		return err
 	})
 	return contentResponse, err
 }
 
-func (gh *githubInteraction) withSecondaryRateLimitRetry(f func() error) (err error) {
-	timeout := time.Duration(gh.retryLimitTimeout) * time.Second
-	tryCount := 0
-retryLoop:
-	for t := time.After(timeout); ; {
-		tryCount++
-		err = f()
+// GetFileContents returns the content of a file, or nil if path is a directory.
+func (gh *githubInteraction) GetFileContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (*github.RepositoryContent, error) {
+	var fileContent *github.RepositoryContent
+	var err error
+	err = gh.withSecondaryRateLimitRetry(ctx, "GetFileContents", func() error {
+		fileContent, _, _, err = gh.Client.Repositories.GetContents(ctx, owner, repo, path, opts)
+		return err
+	})
+	return fileContent, err
+}
+
+func (gh *githubInteraction) ListUserRepos(ctx context.Context, opts *github.RepositoryListByAuthenticatedUserOptions) ([]*github.Repository, *github.Response, error) {
+	var repos []*github.Repository
+	var response *github.Response
+	var err error
+	err = gh.withSecondaryRateLimitRetry(ctx, "ListUserRepos", func() error {
+		repos, response, err = gh.Client.Repositories.ListByAuthenticatedUser(ctx, opts)
+		return err
+	})
+	return repos, response, err
+}
+
+func (gh *githubInteraction) GetOrg(ctx context.Context, org string) (*github.Organization, error) {
+	var organization *github.Organization
+	var err error
+	err = gh.withSecondaryRateLimitRetry(ctx, "GetOrg", func() error {
+		organization, _, err = gh.Client.Organizations.Get(ctx, org)
+		return err
+	})
+	return organization, err
+}
+
+func (gh *githubInteraction) GetOrgMembership(ctx context.Context, org string) (*github.Membership, error) {
+	var membership *github.Membership
+	var err error
+	err = gh.withSecondaryRateLimitRetry(ctx, "GetOrgMembership", func() error {
+		membership, _, err = gh.Client.Organizations.GetOrgMembership(ctx, "", org)
+		return err
+	})
+	return membership, err
+}
+
+func (gh *githubInteraction) ListOrgMembers(ctx context.Context, org string, opts *github.ListMembersOptions) ([]*github.User, *github.Response, error) {
+	var members []*github.User
+	var resp *github.Response
+	var err error
+
+	err = gh.withSecondaryRateLimitRetry(ctx, "ListOrgMembers", func() error {
+		members, resp, err = gh.Client.Organizations.ListMembers(ctx, org, opts)
+		return err
+	})
+
+	return members, resp, err
+}
+
+func (gh *githubInteraction) Zen(ctx context.Context) (*github.Response, error) {
+	return observeCall(gh.observe, "Zen", func() (*github.Response, error) {
+		_, resp, err := gh.Client.Meta.Zen(ctx)
+		return resp, err
+	})
+}
+
+// withSecondaryRateLimitRetry retries f while GitHub reports a rate limit. It waits for the Retry-After of secondary
+// rate limits, backing off exponentially from a minute when GitHub doesn't send one, and for the reset of primary
+// rate limits. The retry limit timeout bounds the total wait: a wait that would end past it fails right away with the
+// last error. It returns ctx.Err() as soon as ctx is done, including while waiting. Each attempt is observed under
+// method.
+func (gh *githubInteraction) withSecondaryRateLimitRetry(ctx context.Context, method string, f func() error) error {
+	deadline := time.Now().Add(time.Duration(gh.retryLimitTimeout) * time.Second)
+
+	// GitHub asks to wait at least a minute, then exponentially longer, when a secondary rate limit has no Retry-After.
+	b := &backoff.Backoff{
+		Min:    time.Minute,
+		Max:    5 * time.Minute,
+		Factor: 2,
+		Jitter: true,
+	}
+
+	for tryCount := 1; ; tryCount++ {
+		err := gh.observe.call(method, f)
 		if err == nil {
 			return nil
 		}
 
-		var ghErr *github.AbuseRateLimitError
-		if errors.As(err, &ghErr) {
-			time.Sleep(*ghErr.RetryAfter)
-		} else {
+		wait, isRateLimit := githubRateLimitWait(err, b)
+		if !isRateLimit {
 			return err
 		}
 
 		if tryCount >= gh.retryCount {
-			return errx.ErrRetryTimeout.Msg("reached retry limit")
+			return errx.ErrRetryTimeout.Err(err).Int(errx.RetryAttemptsKey, tryCount).Msg("reached retry limit")
+		}
+
+		if time.Now().Add(wait).After(deadline) {
+			return errx.ErrRetryTimeout.Err(err).Int(errx.RetryAttemptsKey, tryCount)
 		}
 
 		select {
-		case <-t:
-			break retryLoop
-		default:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
 		}
+	}
+}
+
+// githubRateLimitWait returns how long to wait before retrying after err, and false when err isn't a rate limit.
+// Secondary rate limits without a Retry-After wait for the next duration of b.
+func githubRateLimitWait(err error, b *backoff.Backoff) (time.Duration, bool) {
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		if abuseErr.RetryAfter != nil {
+			return *abuseErr.RetryAfter, true
+		}
+		return b.Duration(), true
+	}
 
+	var rateErr *github.RateLimitError
+	if errors.As(err, &rateErr) {
+		return max(time.Until(rateErr.Rate.Reset.Time), 0), true
 	}
 
-	return errx.ErrRetryTimeout.Err(err)
+	return 0, false
 }
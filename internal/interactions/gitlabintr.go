@@ -1,45 +1,131 @@
 package interactions
 
 import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aserto-dev/scc-lib/errx"
+	"github.com/jpillora/backoff"
 	"github.com/pkg/errors"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
 )
 
 //go:generate mockgen -source=gitlabintr.go -destination=mock_gitlabintr.go -package=interactions --build_flags=--mod=mod
 
-type GlIntr func(token string) (GitlabIntr, error)
+// GlIntr creates a Gitlab client. OAuth ("oauth" or "bearer") tokens are sent as bearer tokens;
+// any other type, including an empty one, is treated as a personal access token.
+// Requests rejected with a 429, and reads rejected with one of retryStatusCodes (502, 503 and 504 when empty), are
+// retried up to retryCount times within retryLimitTimeout seconds, and each request is bounded by httpTimeout.
+// A non-empty userAgent replaces the client's default User-Agent. The clients created by the same GlIntr remember the
+// user that owns each token for userCacheTTL, a TTL that isn't positive remembers nothing.
+type GlIntr func(token, tokenType string, retryLimitTimeout, retryCount int, retryStatusCodes []int, httpTimeout time.Duration, userAgent string, userCacheTTL time.Duration) (GitlabIntr, error)
 
 type GitlabIntr interface {
 	// GetClient(token string) (GitlabIntr, error)
 	CurrentUser() (*gitlab.User, *gitlab.Response, error)
+	// CachedCurrentUser returns the user that owns the token, without fetching it when it was fetched within the
+	// user cache TTL.
+	CachedCurrentUser() (*gitlab.User, error)
+	GetCurrentAccessToken() (*gitlab.PersonalAccessToken, *gitlab.Response, error)
 	ListUserProjects(uid interface{}, opt *gitlab.ListProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error)
 	ListGroupProjects(gid interface{}, opt *gitlab.ListGroupProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error)
 	ListGroups(opt *gitlab.ListGroupsOptions) ([]*gitlab.Group, *gitlab.Response, error)
+	ListProjects(opt *gitlab.ListProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error)
 	GetProject(pid interface{}) (*gitlab.Project, *gitlab.Response, error)
-	GetNamespace(id interface{}) (*gitlab.Namespace, error)
-	CreateProject(opt *gitlab.CreateProjectOptions) (*gitlab.Project, error)
+	GetNamespace(id interface{}) (*gitlab.Namespace, *gitlab.Response, error)
+	CreateProject(opt *gitlab.CreateProjectOptions) (*gitlab.Project, *gitlab.Response, error)
+	EditProject(pid interface{}, opt *gitlab.EditProjectOptions) (*gitlab.Project, *gitlab.Response, error)
+	AddProjectHook(pid interface{}, opt *gitlab.AddProjectHookOptions) (*gitlab.ProjectHook, *gitlab.Response, error)
+	ListProjectHooks(pid interface{}, opt *gitlab.ListProjectHooksOptions) ([]*gitlab.ProjectHook, *gitlab.Response, error)
+	DeleteProjectHook(pid interface{}, hook int) (*gitlab.Response, error)
+	AddDeployKey(pid interface{}, opt *gitlab.AddDeployKeyOptions) (*gitlab.ProjectDeployKey, *gitlab.Response, error)
+	ListProjectDeployKeys(pid interface{}, opt *gitlab.ListProjectDeployKeysOptions) ([]*gitlab.ProjectDeployKey, *gitlab.Response, error)
+	DeleteDeployKey(pid interface{}, deployKey int) (*gitlab.Response, error)
+	ListProjectMergeRequests(pid interface{}, opt *gitlab.ListProjectMergeRequestsOptions) ([]*gitlab.MergeRequest, *gitlab.Response, error)
+	CreateMergeRequest(pid interface{}, opt *gitlab.CreateMergeRequestOptions) (*gitlab.MergeRequest, *gitlab.Response, error)
 	ProtectRepositoryTags(pid interface{}, opt *gitlab.ProtectRepositoryTagsOptions) error
 	CreateTag(pid interface{}, opt *gitlab.CreateTagOptions) error
-	GetProjectVariable(pid interface{}, key string) (*gitlab.ProjectVariable, *gitlab.Response, error)
-	UpdateProjectVariable(pid interface{}, key string, opt *gitlab.UpdateProjectVariableOptions) error
-	CreateProjectVariable(pid interface{}, opt *gitlab.CreateProjectVariableOptions) error
-	GetProjectFile(pid interface{}, fileName string, opt *gitlab.GetFileOptions) error
+	ListProjectPipelines(pid interface{}, opt *gitlab.ListProjectPipelinesOptions) ([]*gitlab.PipelineInfo, *gitlab.Response, error)
+	CreatePipeline(pid interface{}, opt *gitlab.CreatePipelineOptions) (*gitlab.Pipeline, *gitlab.Response, error)
+	ListTags(pid interface{}, opt *gitlab.ListTagsOptions) ([]*gitlab.Tag, *gitlab.Response, error)
+	GetBranch(pid interface{}, branch string) (*gitlab.Branch, *gitlab.Response, error)
+	GetVersion() (*gitlab.Response, error)
+	GetProjectVariable(pid interface{}, key, environmentScope string) (*gitlab.ProjectVariable, *gitlab.Response, error)
+	UpdateProjectVariable(pid interface{}, key, environmentScope string, opt *gitlab.UpdateProjectVariableOptions) (*gitlab.Response, error)
+	CreateProjectVariable(pid interface{}, opt *gitlab.CreateProjectVariableOptions) (*gitlab.Response, error)
+	GetProjectFile(pid interface{}, fileName string, opt *gitlab.GetFileOptions) (*gitlab.File, *gitlab.Response, error)
 	CreateCommit(pid interface{}, opt *gitlab.CreateCommitOptions) (string, error)
+	GetGroup(gid interface{}) (*gitlab.Group, error)
+	GetInheritedGroupMember(gid interface{}, user int) (*gitlab.GroupMember, error)
+	ListGroupMembers(gid interface{}, opt *gitlab.ListGroupMembersOptions) ([]*gitlab.GroupMember, *gitlab.Response, error)
 }
 
+// defaultGitlabRetryStatusCodes are the 5xx statuses retried when none are configured.
+var defaultGitlabRetryStatusCodes = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
 type gitlabInteraction struct {
-	Client *gitlab.Client
+	Client            *gitlab.Client
+	retryLimitTimeout int
+	retryCount        int
+	retryStatusCodes  []int
+	token             string
+	users             *userCache[*gitlab.User]
+	userCacheTTL      time.Duration
+	observe           ObserveFunc
 }
 
 func NewGitlabInteraction() GlIntr {
-	return func(token string) (GitlabIntr, error) {
-		client, err := gitlab.NewClient(token)
+	return NewGitlabInteractionWithClient(nil, nil)
+}
+
+// NewGitlabInteractionWithClient sends the requests through httpClient, and hands the calls of the interactions to
+// observe. A nil httpClient uses the default client.
+func NewGitlabInteractionWithClient(httpClient *http.Client, observe ObserveFunc) GlIntr {
+	users := &userCache[*gitlab.User]{}
+
+	return func(token, tokenType string, retryLimitTimeout, retryCount int, retryStatusCodes []int, httpTimeout time.Duration, userAgent string, userCacheTTL time.Duration) (GitlabIntr, error) {
+		// The client's own retries are disabled, withRateLimitRetry handles them.
+		options := []gitlab.ClientOptionFunc{
+			gitlab.WithHTTPClient(httpClientWithTimeout(httpClient, httpTimeout)),
+			gitlab.WithoutRetries(),
+		}
+
+		client, err := newGitlabClient(token, tokenType, options...)
 
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create Gitlab client")
 		}
 
-		return &gitlabInteraction{Client: client}, nil
+		if userAgent != "" {
+			client.UserAgent = userAgent
+		}
+
+		if len(retryStatusCodes) == 0 {
+			retryStatusCodes = defaultGitlabRetryStatusCodes
+		}
+
+		return &gitlabInteraction{
+			Client:            client,
+			retryLimitTimeout: retryLimitTimeout,
+			retryCount:        retryCount,
+			retryStatusCodes:  retryStatusCodes,
+			token:             token,
+			users:             users,
+			userCacheTTL:      userCacheTTL,
+			observe:           observe,
+		}, nil
+	}
+}
+
+func newGitlabClient(token, tokenType string, options ...gitlab.ClientOptionFunc) (*gitlab.Client, error) {
+	switch strings.ToLower(tokenType) {
+	case "oauth", "bearer":
+		return gitlab.NewOAuthClient(token, options...)
+	default:
+		return gitlab.NewClient(token, options...)
 	}
 }
 
@@ -53,69 +139,325 @@ func (gi *gitlabInteraction) GetClient(token string) (GitlabIntr, error) {
 	return &gitlabInteraction{Client: client}, nil
 }
 
+// CurrentUser fetches the user that owns the token, and remembers it for CachedCurrentUser.
 func (gi *gitlabInteraction) CurrentUser() (*gitlab.User, *gitlab.Response, error) {
-	return gi.Client.Users.CurrentUser()
+	user, response, err := retryGitlab(gi, "CurrentUser", func() (*gitlab.User, *gitlab.Response, error) {
+		return gi.Client.Users.CurrentUser()
+	})
+	if err == nil && user != nil && gi.users != nil {
+		gi.users.put(gi.token, user, gi.userCacheTTL)
+	}
+
+	return user, response, err
+}
+
+func (gi *gitlabInteraction) CachedCurrentUser() (*gitlab.User, error) {
+	if gi.users != nil {
+		if user, ok := gi.users.get(gi.token); ok {
+			return user, nil
+		}
+	}
+
+	user, _, err := gi.CurrentUser()
+
+	return user, err
+}
+
+// GetCurrentAccessToken returns the access token the client authenticates with. It works for personal, project and
+// group access tokens, not for OAuth tokens.
+func (gi *gitlabInteraction) GetCurrentAccessToken() (*gitlab.PersonalAccessToken, *gitlab.Response, error) {
+	return retryGitlab(gi, "GetCurrentAccessToken", func() (*gitlab.PersonalAccessToken, *gitlab.Response, error) {
+		return gi.Client.PersonalAccessTokens.GetSinglePersonalAccessToken()
+	})
 }
 
 func (gi *gitlabInteraction) ListUserProjects(uid interface{}, opt *gitlab.ListProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error) {
-	return gi.Client.Projects.ListUserProjects(uid, opt)
+	return retryGitlab(gi, "ListUserProjects", func() ([]*gitlab.Project, *gitlab.Response, error) {
+		return gi.Client.Projects.ListUserProjects(uid, opt)
+	})
+}
+
+func (gi *gitlabInteraction) ListProjects(opt *gitlab.ListProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error) {
+	return retryGitlab(gi, "ListProjects", func() ([]*gitlab.Project, *gitlab.Response, error) {
+		return gi.Client.Projects.ListProjects(opt)
+	})
 }
 
 func (gi *gitlabInteraction) ListGroupProjects(gid interface{}, opt *gitlab.ListGroupProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error) {
-	return gi.Client.Groups.ListGroupProjects(gid, opt)
+	return retryGitlab(gi, "ListGroupProjects", func() ([]*gitlab.Project, *gitlab.Response, error) {
+		return gi.Client.Groups.ListGroupProjects(gid, opt)
+	})
 }
 
 func (gi *gitlabInteraction) ListGroups(opt *gitlab.ListGroupsOptions) ([]*gitlab.Group, *gitlab.Response, error) {
-	return gi.Client.Groups.ListGroups(opt)
+	return retryGitlab(gi, "ListGroups", func() ([]*gitlab.Group, *gitlab.Response, error) {
+		return gi.Client.Groups.ListGroups(opt)
+	})
 }
 
 func (gi *gitlabInteraction) GetProject(pid interface{}) (*gitlab.Project, *gitlab.Response, error) {
-	return gi.Client.Projects.GetProject(pid, nil)
+	return retryGitlab(gi, "GetProject", func() (*gitlab.Project, *gitlab.Response, error) {
+		return gi.Client.Projects.GetProject(pid, nil)
+	})
+}
+
+func (gi *gitlabInteraction) GetNamespace(id interface{}) (*gitlab.Namespace, *gitlab.Response, error) {
+	return retryGitlab(gi, "GetNamespace", func() (*gitlab.Namespace, *gitlab.Response, error) {
+		return gi.Client.Namespaces.GetNamespace(id)
+	})
+}
+
+func (gi *gitlabInteraction) CreateProject(opt *gitlab.CreateProjectOptions) (*gitlab.Project, *gitlab.Response, error) {
+	return retryGitlab(gi, "CreateProject", func() (*gitlab.Project, *gitlab.Response, error) {
+		return gi.Client.Projects.CreateProject(opt)
+	})
+}
+
+func (gi *gitlabInteraction) EditProject(pid interface{}, opt *gitlab.EditProjectOptions) (*gitlab.Project, *gitlab.Response, error) {
+	return retryGitlab(gi, "EditProject", func() (*gitlab.Project, *gitlab.Response, error) {
+		return gi.Client.Projects.EditProject(pid, opt)
+	})
+}
+
+func (gi *gitlabInteraction) AddProjectHook(pid interface{}, opt *gitlab.AddProjectHookOptions) (*gitlab.ProjectHook, *gitlab.Response, error) {
+	return retryGitlab(gi, "AddProjectHook", func() (*gitlab.ProjectHook, *gitlab.Response, error) {
+		return gi.Client.Projects.AddProjectHook(pid, opt)
+	})
+}
+
+func (gi *gitlabInteraction) ListProjectHooks(pid interface{}, opt *gitlab.ListProjectHooksOptions) ([]*gitlab.ProjectHook, *gitlab.Response, error) {
+	return retryGitlab(gi, "ListProjectHooks", func() ([]*gitlab.ProjectHook, *gitlab.Response, error) {
+		return gi.Client.Projects.ListProjectHooks(pid, opt)
+	})
 }
 
-func (gi *gitlabInteraction) GetNamespace(id interface{}) (*gitlab.Namespace, error) {
-	namespace, _, err := gi.Client.Namespaces.GetNamespace(id)
-	return namespace, err
+func (gi *gitlabInteraction) ListProjectMergeRequests(pid interface{}, opt *gitlab.ListProjectMergeRequestsOptions) ([]*gitlab.MergeRequest, *gitlab.Response, error) {
+	return retryGitlab(gi, "ListProjectMergeRequests", func() ([]*gitlab.MergeRequest, *gitlab.Response, error) {
+		return gi.Client.MergeRequests.ListProjectMergeRequests(pid, opt)
+	})
 }
 
-func (gi *gitlabInteraction) CreateProject(opt *gitlab.CreateProjectOptions) (*gitlab.Project, error) {
-	proj, _, err := gi.Client.Projects.CreateProject(opt)
-	return proj, err
+func (gi *gitlabInteraction) CreateMergeRequest(pid interface{}, opt *gitlab.CreateMergeRequestOptions) (*gitlab.MergeRequest, *gitlab.Response, error) {
+	return retryGitlab(gi, "CreateMergeRequest", func() (*gitlab.MergeRequest, *gitlab.Response, error) {
+		return gi.Client.MergeRequests.CreateMergeRequest(pid, opt)
+	})
+}
+
+func (gi *gitlabInteraction) DeleteProjectHook(pid interface{}, hook int) (*gitlab.Response, error) {
+	_, resp, err := retryGitlab(gi, "DeleteProjectHook", func() (struct{}, *gitlab.Response, error) {
+		resp, err := gi.Client.Projects.DeleteProjectHook(pid, hook)
+		return struct{}{}, resp, err
+	})
+	return resp, err
+}
+
+func (gi *gitlabInteraction) AddDeployKey(pid interface{}, opt *gitlab.AddDeployKeyOptions) (*gitlab.ProjectDeployKey, *gitlab.Response, error) {
+	return retryGitlab(gi, "AddDeployKey", func() (*gitlab.ProjectDeployKey, *gitlab.Response, error) {
+		return gi.Client.DeployKeys.AddDeployKey(pid, opt)
+	})
+}
+
+func (gi *gitlabInteraction) ListProjectDeployKeys(pid interface{}, opt *gitlab.ListProjectDeployKeysOptions) ([]*gitlab.ProjectDeployKey, *gitlab.Response, error) {
+	return retryGitlab(gi, "ListProjectDeployKeys", func() ([]*gitlab.ProjectDeployKey, *gitlab.Response, error) {
+		return gi.Client.DeployKeys.ListProjectDeployKeys(pid, opt)
+	})
+}
+
+func (gi *gitlabInteraction) DeleteDeployKey(pid interface{}, deployKey int) (*gitlab.Response, error) {
+	_, resp, err := retryGitlab(gi, "DeleteDeployKey", func() (struct{}, *gitlab.Response, error) {
+		resp, err := gi.Client.DeployKeys.DeleteDeployKey(pid, deployKey)
+		return struct{}{}, resp, err
+	})
+	return resp, err
 }
 
 func (gi *gitlabInteraction) ProtectRepositoryTags(pid interface{}, opt *gitlab.ProtectRepositoryTagsOptions) error {
-	_, _, err := gi.Client.ProtectedTags.ProtectRepositoryTags(pid, opt)
+	_, _, err := retryGitlab(gi, "ProtectRepositoryTags", func() (*gitlab.ProtectedTag, *gitlab.Response, error) {
+		return gi.Client.ProtectedTags.ProtectRepositoryTags(pid, opt)
+	})
 	return err
 }
 
 func (gi *gitlabInteraction) CreateTag(pid interface{}, opt *gitlab.CreateTagOptions) error {
-	_, _, err := gi.Client.Tags.CreateTag(pid, opt)
+	_, _, err := retryGitlab(gi, "CreateTag", func() (*gitlab.Tag, *gitlab.Response, error) {
+		return gi.Client.Tags.CreateTag(pid, opt)
+	})
 	return err
 }
 
-func (gi *gitlabInteraction) GetProjectVariable(pid interface{}, key string) (*gitlab.ProjectVariable, *gitlab.Response, error) {
-	return gi.Client.ProjectVariables.GetVariable(pid, key, nil)
+func (gi *gitlabInteraction) ListProjectPipelines(pid interface{}, opt *gitlab.ListProjectPipelinesOptions) ([]*gitlab.PipelineInfo, *gitlab.Response, error) {
+	return retryGitlab(gi, "ListProjectPipelines", func() ([]*gitlab.PipelineInfo, *gitlab.Response, error) {
+		return gi.Client.Pipelines.ListProjectPipelines(pid, opt)
+	})
 }
 
-func (gi *gitlabInteraction) UpdateProjectVariable(pid interface{}, key string, opt *gitlab.UpdateProjectVariableOptions) error {
-	_, _, err := gi.Client.ProjectVariables.UpdateVariable(pid, key, opt)
-	return err
+func (gi *gitlabInteraction) CreatePipeline(pid interface{}, opt *gitlab.CreatePipelineOptions) (*gitlab.Pipeline, *gitlab.Response, error) {
+	return retryGitlab(gi, "CreatePipeline", func() (*gitlab.Pipeline, *gitlab.Response, error) {
+		return gi.Client.Pipelines.CreatePipeline(pid, opt)
+	})
 }
 
-func (gi *gitlabInteraction) CreateProjectVariable(pid interface{}, opt *gitlab.CreateProjectVariableOptions) error {
-	_, _, err := gi.Client.ProjectVariables.CreateVariable(pid, opt)
-	return err
+func (gi *gitlabInteraction) ListTags(pid interface{}, opt *gitlab.ListTagsOptions) ([]*gitlab.Tag, *gitlab.Response, error) {
+	return retryGitlab(gi, "ListTags", func() ([]*gitlab.Tag, *gitlab.Response, error) {
+		return gi.Client.Tags.ListTags(pid, opt)
+	})
 }
 
-func (gi *gitlabInteraction) GetProjectFile(pid interface{}, fileName string, opt *gitlab.GetFileOptions) error {
-	_, _, err := gi.Client.RepositoryFiles.GetFile(pid, fileName, opt)
-	return err
+func (gi *gitlabInteraction) GetProjectVariable(pid interface{}, key, environmentScope string) (*gitlab.ProjectVariable, *gitlab.Response, error) {
+	opt := &gitlab.GetProjectVariableOptions{Filter: &gitlab.VariableFilter{EnvironmentScope: environmentScope}}
+	return retryGitlab(gi, "GetProjectVariable", func() (*gitlab.ProjectVariable, *gitlab.Response, error) {
+		return gi.Client.ProjectVariables.GetVariable(pid, key, opt)
+	})
+}
+
+func (gi *gitlabInteraction) UpdateProjectVariable(pid interface{}, key, environmentScope string, opt *gitlab.UpdateProjectVariableOptions) (*gitlab.Response, error) {
+	opt.Filter = &gitlab.VariableFilter{EnvironmentScope: environmentScope}
+	_, resp, err := retryGitlab(gi, "UpdateProjectVariable", func() (*gitlab.ProjectVariable, *gitlab.Response, error) {
+		return gi.Client.ProjectVariables.UpdateVariable(pid, key, opt)
+	})
+	return resp, err
+}
+
+func (gi *gitlabInteraction) CreateProjectVariable(pid interface{}, opt *gitlab.CreateProjectVariableOptions) (*gitlab.Response, error) {
+	_, resp, err := retryGitlab(gi, "CreateProjectVariable", func() (*gitlab.ProjectVariable, *gitlab.Response, error) {
+		return gi.Client.ProjectVariables.CreateVariable(pid, opt)
+	})
+	return resp, err
+}
+
+func (gi *gitlabInteraction) GetProjectFile(pid interface{}, fileName string, opt *gitlab.GetFileOptions) (*gitlab.File, *gitlab.Response, error) {
+	return retryGitlab(gi, "GetProjectFile", func() (*gitlab.File, *gitlab.Response, error) {
+		return gi.Client.RepositoryFiles.GetFile(pid, fileName, opt)
+	})
 }
 
 func (gi *gitlabInteraction) CreateCommit(pid interface{}, opt *gitlab.CreateCommitOptions) (string, error) {
-	commit, _, err := gi.Client.Commits.CreateCommit(pid, opt)
+	commit, _, err := retryGitlab(gi, "CreateCommit", func() (*gitlab.Commit, *gitlab.Response, error) {
+		return gi.Client.Commits.CreateCommit(pid, opt)
+	})
 	if err != nil {
 		return "", err
 	}
 	return commit.ID, err
 }
+
+func (gi *gitlabInteraction) GetGroup(gid interface{}) (*gitlab.Group, error) {
+	group, _, err := retryGitlab(gi, "GetGroup", func() (*gitlab.Group, *gitlab.Response, error) {
+		return gi.Client.Groups.GetGroup(gid, nil)
+	})
+	return group, err
+}
+
+func (gi *gitlabInteraction) GetInheritedGroupMember(gid interface{}, user int) (*gitlab.GroupMember, error) {
+	member, _, err := retryGitlab(gi, "GetInheritedGroupMember", func() (*gitlab.GroupMember, *gitlab.Response, error) {
+		return gi.Client.GroupMembers.GetInheritedGroupMember(gid, user)
+	})
+	return member, err
+}
+
+func (gi *gitlabInteraction) GetBranch(pid interface{}, branch string) (*gitlab.Branch, *gitlab.Response, error) {
+	return retryGitlab(gi, "GetBranch", func() (*gitlab.Branch, *gitlab.Response, error) {
+		return gi.Client.Branches.GetBranch(pid, branch)
+	})
+}
+
+func (gi *gitlabInteraction) GetVersion() (*gitlab.Response, error) {
+	return observeCall(gi.observe, "GetVersion", func() (*gitlab.Response, error) {
+		_, resp, err := gi.Client.Version.GetVersion()
+		return resp, err
+	})
+}
+
+func (gi *gitlabInteraction) ListGroupMembers(gid interface{}, opt *gitlab.ListGroupMembersOptions) ([]*gitlab.GroupMember, *gitlab.Response, error) {
+	return retryGitlab(gi, "ListGroupMembers", func() ([]*gitlab.GroupMember, *gitlab.Response, error) {
+		return gi.Client.Groups.ListGroupMembers(gid, opt)
+	})
+}
+
+// retryGitlab runs f through withRateLimitRetry under method and returns the result of its last attempt.
+func retryGitlab[T any](gi *gitlabInteraction, method string, f func() (T, *gitlab.Response, error)) (T, *gitlab.Response, error) {
+	var result T
+	var resp *gitlab.Response
+
+	err := gi.withRateLimitRetry(method, func() (*gitlab.Response, error) {
+		var err error
+		result, resp, err = f()
+		return resp, err
+	})
+
+	return result, resp, err
+}
+
+// withRateLimitRetry retries f while Gitlab replies with a 429, or one of the retried 5xx statuses to a GET or HEAD.
+// It waits for the Retry-After or RateLimit-Reset of the response when there is one, and backs off exponentially otherwise.
+// Each attempt is observed under method.
+func (gi *gitlabInteraction) withRateLimitRetry(method string, f func() (*gitlab.Response, error)) error {
+	b := &backoff.Backoff{
+		Min:    time.Second,
+		Max:    time.Minute,
+		Factor: 2,
+		Jitter: true,
+	}
+
+	timeout := time.After(time.Duration(gi.retryLimitTimeout) * time.Second)
+
+	for tryCount := 1; ; tryCount++ {
+		var resp *gitlab.Response
+		err := gi.observe.call(method, func() error {
+			var err error
+			resp, err = f()
+			return err
+		})
+		if err == nil || !gi.isRetryable(resp) {
+			return err
+		}
+
+		if tryCount >= gi.retryCount {
+			return errx.ErrRetryTimeout.Err(err).Int(errx.RetryAttemptsKey, tryCount).Msg("reached retry limit")
+		}
+
+		wait := gitlabRetryAfter(resp)
+		if wait == 0 {
+			wait = b.Duration()
+		}
+
+		select {
+		case <-timeout:
+			return errx.ErrRetryTimeout.Err(err).Int(errx.RetryAttemptsKey, tryCount)
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (gi *gitlabInteraction) isRetryable(resp *gitlab.Response) bool {
+	if resp == nil || resp.Response == nil {
+		return false
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	// Gitlab may have applied a mutating request before failing with a 5xx, and replaying it could e.g. create a
+	// project or a commit twice. Only reads are retried on those.
+	if resp.Request == nil || (resp.Request.Method != http.MethodGet && resp.Request.Method != http.MethodHead) {
+		return false
+	}
+
+	return slices.Contains(gi.retryStatusCodes, resp.StatusCode)
+}
+
+// gitlabRetryAfter returns how long Gitlab asked to wait before retrying, or 0 if it didn't say.
+func gitlabRetryAfter(resp *gitlab.Response) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if reset, err := strconv.ParseInt(resp.Header.Get("RateLimit-Reset"), 10, 64); err == nil {
+		if wait := time.Until(time.Unix(reset, 0)); wait > 0 {
+			return wait
+		}
+	}
+
+	return 0
+}
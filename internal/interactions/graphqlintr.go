@@ -2,15 +2,21 @@ package interactions
 
 import (
 	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/aserto-dev/scc-lib/errx"
 	"github.com/hashicorp/go-retryablehttp"
+	"github.com/jpillora/backoff"
 	"github.com/shurcooL/githubv4"
 	"golang.org/x/oauth2"
 )
 
 //go:generate mockgen -source=graphqlintr.go -destination=mock_graphqlintr.go -package=interactions --build_flags=--mod=mod
-type GqlIntr func(ctx context.Context, token, tokenType string, retryLimitTimeout, retryCount int) GraphqlIntr
+type GqlIntr func(ctx context.Context, token, tokenType string, retryLimitTimeout, retryCount int, httpTimeout time.Duration, userAgent string) GraphqlIntr
 
 type GraphqlIntr interface {
 	Query(context.Context, interface{}, map[string]interface{}) error
@@ -18,11 +24,21 @@ type GraphqlIntr interface {
 }
 
 type graphqlInteraction struct {
-	Client *githubv4.Client
+	Client            *githubv4.Client
+	transport         *retryAfterTransport
+	retryLimitTimeout int
+	retryCount        int
+	observe           ObserveFunc
 }
 
 func NewGraphqlInteraction() GqlIntr {
-	return func(ctx context.Context, token, tokenType string, retryLimitTimeout, retryCount int) GraphqlIntr {
+	return NewGraphqlInteractionWithClient(nil, nil)
+}
+
+// NewGraphqlInteractionWithClient sends the requests through httpClient, with retries and the token layered on top of
+// it, and hands the queries and mutations to observe. A nil httpClient uses the default client.
+func NewGraphqlInteractionWithClient(httpClient *http.Client, observe ObserveFunc) GqlIntr {
+	return func(ctx context.Context, token, tokenType string, retryLimitTimeout, retryCount int, httpTimeout time.Duration, userAgent string) GraphqlIntr {
 		src := oauth2.StaticTokenSource(
 			&oauth2.Token{
 				AccessToken: token,
@@ -36,21 +52,112 @@ func NewGraphqlInteraction() GqlIntr {
 		retryClient.RetryWaitMax = time.Second * time.Duration(retryLimitTimeout)
 		retryClient.RetryMax = retryCount
 
-		httpClient := oauth2.NewClient(
+		// A nil httpClient uses http.DefaultTransport, like the REST client, rather than a new pool for every call.
+		retryClient.HTTPClient = httpClientWithTimeout(httpClient, httpTimeout)
+
+		oauthClient := oauth2.NewClient(
 			context.WithValue(ctx, oauth2.HTTPClient, retryClient.StandardClient()),
 			src,
 		)
 
-		client := githubv4.NewClient(httpClient)
+		transport := &retryAfterTransport{base: withUserAgent(oauthClient.Transport, userAgent)}
+		oauthClient.Transport = transport
+
+		client := githubv4.NewClient(oauthClient)
 
-		return &graphqlInteraction{Client: client}
+		return &graphqlInteraction{
+			Client:            client,
+			transport:         transport,
+			retryLimitTimeout: retryLimitTimeout,
+			retryCount:        retryCount,
+			observe:           observe,
+		}
 	}
 }
 
 func (g *graphqlInteraction) Query(ctx context.Context, query interface{}, vars map[string]interface{}) error {
-	return g.Client.Query(ctx, query, vars)
+	return g.withSecondaryRateLimitRetry(ctx, "Query", func() error {
+		return g.Client.Query(ctx, query, vars)
+	})
 }
 
 func (g *graphqlInteraction) Mutate(ctx context.Context, m interface{}, input githubv4.Input, variables map[string]interface{}) error {
-	return g.Client.Mutate(ctx, m, input, variables)
+	return g.withSecondaryRateLimitRetry(ctx, "Mutate", func() error {
+		return g.Client.Mutate(ctx, m, input, variables)
+	})
+}
+
+// withSecondaryRateLimitRetry retries f while GitHub reports a secondary rate limit, either as a 403
+// or as a 200 with an errors array. It waits for the Retry-After of the last response when there is one,
+// and backs off exponentially otherwise. Each attempt is observed under method.
+func (g *graphqlInteraction) withSecondaryRateLimitRetry(ctx context.Context, method string, f func() error) error {
+	b := &backoff.Backoff{
+		Min:    time.Second,
+		Max:    time.Minute,
+		Factor: 2,
+		Jitter: true,
+	}
+
+	timeout := time.After(time.Duration(g.retryLimitTimeout) * time.Second)
+
+	for tryCount := 1; ; tryCount++ {
+		err := g.observe.call(method, f)
+		if err == nil || !isSecondaryRateLimit(err) {
+			return err
+		}
+
+		if tryCount >= g.retryCount {
+			return errx.ErrRetryTimeout.Err(err).Msg("reached retry limit")
+		}
+
+		wait := g.transport.lastRetryAfter()
+		if wait == 0 {
+			wait = b.Duration()
+		}
+
+		select {
+		case <-timeout:
+			return errx.ErrRetryTimeout.Err(err)
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func isSecondaryRateLimit(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "secondary rate limit") || strings.Contains(msg, "abuse detection")
+}
+
+// retryAfterTransport remembers the Retry-After header of the last response, which the GraphQL client doesn't expose.
+type retryAfterTransport struct {
+	base http.RoundTripper
+
+	mu         sync.Mutex
+	retryAfter time.Duration
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+
+	var retryAfter time.Duration
+	if resp != nil {
+		if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+	}
+
+	t.mu.Lock()
+	t.retryAfter = retryAfter
+	t.mu.Unlock()
+
+	return resp, err
+}
+
+func (t *retryAfterTransport) lastRetryAfter() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.retryAfter
 }
@@ -0,0 +1,46 @@
+package interactions
+
+import (
+	"net/http"
+	"time"
+)
+
+// httpClientWithTimeout returns a copy of base, or a new client when base is nil, bounded by timeout.
+// The copy keeps the caller's client untouched.
+func httpClientWithTimeout(base *http.Client, timeout time.Duration) *http.Client {
+	client := &http.Client{}
+	if base != nil {
+		copied := *base
+		client = &copied
+	}
+
+	client.Timeout = timeout
+
+	return client
+}
+
+// withUserAgent returns base, or http.DefaultTransport when base is nil, setting userAgent on every request.
+// An empty userAgent returns base unchanged.
+func withUserAgent(base http.RoundTripper, userAgent string) http.RoundTripper {
+	if userAgent == "" {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &userAgentTransport{base: base, userAgent: userAgent}
+}
+
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the caller's request.
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.userAgent)
+
+	return t.base.RoundTrip(req)
+}
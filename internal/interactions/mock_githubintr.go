@@ -41,6 +41,21 @@ func (m *MockGithubIntr) EXPECT() *MockGithubIntrMockRecorder {
 	return m.recorder
 }
 
+// CreateCommit mocks base method.
+func (m *MockGithubIntr) CreateCommit(ctx context.Context, owner, repo string, commit *github.Commit) (*github.Commit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCommit", ctx, owner, repo, commit)
+	ret0, _ := ret[0].(*github.Commit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCommit indicates an expected call of CreateCommit.
+func (mr *MockGithubIntrMockRecorder) CreateCommit(ctx, owner, repo, commit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCommit", reflect.TypeOf((*MockGithubIntr)(nil).CreateCommit), ctx, owner, repo, commit)
+}
+
 // CreateFile mocks base method.
 func (m *MockGithubIntr) CreateFile(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, error) {
 	m.ctrl.T.Helper()
@@ -56,6 +71,38 @@ func (mr *MockGithubIntrMockRecorder) CreateFile(ctx, owner, repo, path, opts an
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFile", reflect.TypeOf((*MockGithubIntr)(nil).CreateFile), ctx, owner, repo, path, opts)
 }
 
+// CreateHook mocks base method.
+func (m *MockGithubIntr) CreateHook(ctx context.Context, owner, repo string, hook *github.Hook) (*github.Hook, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHook", ctx, owner, repo, hook)
+	ret0, _ := ret[0].(*github.Hook)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateHook indicates an expected call of CreateHook.
+func (mr *MockGithubIntrMockRecorder) CreateHook(ctx, owner, repo, hook any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHook", reflect.TypeOf((*MockGithubIntr)(nil).CreateHook), ctx, owner, repo, hook)
+}
+
+// CreateKey mocks base method.
+func (m *MockGithubIntr) CreateKey(ctx context.Context, owner, repo string, key *github.Key) (*github.Key, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateKey", ctx, owner, repo, key)
+	ret0, _ := ret[0].(*github.Key)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateKey indicates an expected call of CreateKey.
+func (mr *MockGithubIntrMockRecorder) CreateKey(ctx, owner, repo, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateKey", reflect.TypeOf((*MockGithubIntr)(nil).CreateKey), ctx, owner, repo, key)
+}
+
 // CreateOrUpdateRepoSecret mocks base method.
 func (m *MockGithubIntr) CreateOrUpdateRepoSecret(arg0 context.Context, arg1, arg2 string, arg3 *github.EncryptedSecret) (*github.Response, error) {
 	m.ctrl.T.Helper()
@@ -72,11 +119,12 @@ func (mr *MockGithubIntrMockRecorder) CreateOrUpdateRepoSecret(arg0, arg1, arg2,
 }
 
 // CreateRepo mocks base method.
-func (m *MockGithubIntr) CreateRepo(arg0 context.Context, arg1 string, arg2 *github.Repository) error {
+func (m *MockGithubIntr) CreateRepo(arg0 context.Context, arg1 string, arg2 *github.Repository) (*github.Repository, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateRepo", arg0, arg1, arg2)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*github.Repository)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CreateRepo indicates an expected call of CreateRepo.
@@ -114,6 +162,21 @@ func (mr *MockGithubIntrMockRecorder) CreateRepoTag(arg0, arg1, arg2, arg3 any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRepoTag", reflect.TypeOf((*MockGithubIntr)(nil).CreateRepoTag), arg0, arg1, arg2, arg3)
 }
 
+// CreateTree mocks base method.
+func (m *MockGithubIntr) CreateTree(ctx context.Context, owner, repo, baseTree string, entries []*github.TreeEntry) (*github.Tree, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTree", ctx, owner, repo, baseTree, entries)
+	ret0, _ := ret[0].(*github.Tree)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTree indicates an expected call of CreateTree.
+func (mr *MockGithubIntrMockRecorder) CreateTree(ctx, owner, repo, baseTree, entries any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTree", reflect.TypeOf((*MockGithubIntr)(nil).CreateTree), ctx, owner, repo, baseTree, entries)
+}
+
 // CreateWorkflowDispatchEventByFileName mocks base method.
 func (m *MockGithubIntr) CreateWorkflowDispatchEventByFileName(arg0 context.Context, arg1, arg2, arg3 string, arg4 github.CreateWorkflowDispatchEventRequest) error {
 	m.ctrl.T.Helper()
@@ -128,6 +191,52 @@ func (mr *MockGithubIntrMockRecorder) CreateWorkflowDispatchEventByFileName(arg0
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkflowDispatchEventByFileName", reflect.TypeOf((*MockGithubIntr)(nil).CreateWorkflowDispatchEventByFileName), arg0, arg1, arg2, arg3, arg4)
 }
 
+// DeleteHook mocks base method.
+func (m *MockGithubIntr) DeleteHook(ctx context.Context, owner, repo string, id int64) (*github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHook", ctx, owner, repo, id)
+	ret0, _ := ret[0].(*github.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteHook indicates an expected call of DeleteHook.
+func (mr *MockGithubIntrMockRecorder) DeleteHook(ctx, owner, repo, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHook", reflect.TypeOf((*MockGithubIntr)(nil).DeleteHook), ctx, owner, repo, id)
+}
+
+// DeleteKey mocks base method.
+func (m *MockGithubIntr) DeleteKey(ctx context.Context, owner, repo string, id int64) (*github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteKey", ctx, owner, repo, id)
+	ret0, _ := ret[0].(*github.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteKey indicates an expected call of DeleteKey.
+func (mr *MockGithubIntrMockRecorder) DeleteKey(ctx, owner, repo, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteKey", reflect.TypeOf((*MockGithubIntr)(nil).DeleteKey), ctx, owner, repo, id)
+}
+
+// EditRepo mocks base method.
+func (m *MockGithubIntr) EditRepo(ctx context.Context, owner, repo string, repository *github.Repository) (*github.Repository, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditRepo", ctx, owner, repo, repository)
+	ret0, _ := ret[0].(*github.Repository)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// EditRepo indicates an expected call of EditRepo.
+func (mr *MockGithubIntrMockRecorder) EditRepo(ctx, owner, repo, repository any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditRepo", reflect.TypeOf((*MockGithubIntr)(nil).EditRepo), ctx, owner, repo, repository)
+}
+
 // GetCommit mocks base method.
 func (m *MockGithubIntr) GetCommit(ctx context.Context, owner, repo, sha string) (*github.Commit, error) {
 	m.ctrl.T.Helper()
@@ -143,6 +252,51 @@ func (mr *MockGithubIntrMockRecorder) GetCommit(ctx, owner, repo, sha any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommit", reflect.TypeOf((*MockGithubIntr)(nil).GetCommit), ctx, owner, repo, sha)
 }
 
+// GetFileContents mocks base method.
+func (m *MockGithubIntr) GetFileContents(ctx context.Context, owner, repo, path string, opts *github.RepositoryContentGetOptions) (*github.RepositoryContent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileContents", ctx, owner, repo, path, opts)
+	ret0, _ := ret[0].(*github.RepositoryContent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileContents indicates an expected call of GetFileContents.
+func (mr *MockGithubIntrMockRecorder) GetFileContents(ctx, owner, repo, path, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileContents", reflect.TypeOf((*MockGithubIntr)(nil).GetFileContents), ctx, owner, repo, path, opts)
+}
+
+// GetOrg mocks base method.
+func (m *MockGithubIntr) GetOrg(ctx context.Context, org string) (*github.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrg", ctx, org)
+	ret0, _ := ret[0].(*github.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrg indicates an expected call of GetOrg.
+func (mr *MockGithubIntrMockRecorder) GetOrg(ctx, org any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrg", reflect.TypeOf((*MockGithubIntr)(nil).GetOrg), ctx, org)
+}
+
+// GetOrgMembership mocks base method.
+func (m *MockGithubIntr) GetOrgMembership(ctx context.Context, org string) (*github.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrgMembership", ctx, org)
+	ret0, _ := ret[0].(*github.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrgMembership indicates an expected call of GetOrgMembership.
+func (mr *MockGithubIntrMockRecorder) GetOrgMembership(ctx, org any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrgMembership", reflect.TypeOf((*MockGithubIntr)(nil).GetOrgMembership), ctx, org)
+}
+
 // GetRepo mocks base method.
 func (m *MockGithubIntr) GetRepo(arg0 context.Context, arg1, arg2 string) (*github.Repository, error) {
 	m.ctrl.T.Helper()
@@ -205,13 +359,108 @@ func (mr *MockGithubIntrMockRecorder) GetUsers(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockGithubIntr)(nil).GetUsers), arg0, arg1)
 }
 
+// GetWorkflowByFileName mocks base method.
+func (m *MockGithubIntr) GetWorkflowByFileName(ctx context.Context, owner, repo, workflowFileName string) (*github.Workflow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkflowByFileName", ctx, owner, repo, workflowFileName)
+	ret0, _ := ret[0].(*github.Workflow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkflowByFileName indicates an expected call of GetWorkflowByFileName.
+func (mr *MockGithubIntrMockRecorder) GetWorkflowByFileName(ctx, owner, repo, workflowFileName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkflowByFileName", reflect.TypeOf((*MockGithubIntr)(nil).GetWorkflowByFileName), ctx, owner, repo, workflowFileName)
+}
+
+// GetWorkflowRunByID mocks base method.
+func (m *MockGithubIntr) GetWorkflowRunByID(ctx context.Context, owner, repo string, runID int64) (*github.WorkflowRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkflowRunByID", ctx, owner, repo, runID)
+	ret0, _ := ret[0].(*github.WorkflowRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkflowRunByID indicates an expected call of GetWorkflowRunByID.
+func (mr *MockGithubIntrMockRecorder) GetWorkflowRunByID(ctx, owner, repo, runID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkflowRunByID", reflect.TypeOf((*MockGithubIntr)(nil).GetWorkflowRunByID), ctx, owner, repo, runID)
+}
+
+// ListHooks mocks base method.
+func (m *MockGithubIntr) ListHooks(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.Hook, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListHooks", ctx, owner, repo, opts)
+	ret0, _ := ret[0].([]*github.Hook)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListHooks indicates an expected call of ListHooks.
+func (mr *MockGithubIntrMockRecorder) ListHooks(ctx, owner, repo, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListHooks", reflect.TypeOf((*MockGithubIntr)(nil).ListHooks), ctx, owner, repo, opts)
+}
+
+// ListKeys mocks base method.
+func (m *MockGithubIntr) ListKeys(ctx context.Context, owner, repo string, opts *github.ListOptions) ([]*github.Key, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListKeys", ctx, owner, repo, opts)
+	ret0, _ := ret[0].([]*github.Key)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListKeys indicates an expected call of ListKeys.
+func (mr *MockGithubIntrMockRecorder) ListKeys(ctx, owner, repo, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListKeys", reflect.TypeOf((*MockGithubIntr)(nil).ListKeys), ctx, owner, repo, opts)
+}
+
+// ListOrgMembers mocks base method.
+func (m *MockGithubIntr) ListOrgMembers(ctx context.Context, org string, opts *github.ListMembersOptions) ([]*github.User, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrgMembers", ctx, org, opts)
+	ret0, _ := ret[0].([]*github.User)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListOrgMembers indicates an expected call of ListOrgMembers.
+func (mr *MockGithubIntrMockRecorder) ListOrgMembers(ctx, org, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrgMembers", reflect.TypeOf((*MockGithubIntr)(nil).ListOrgMembers), ctx, org, opts)
+}
+
+// ListPullRequests mocks base method.
+func (m *MockGithubIntr) ListPullRequests(ctx context.Context, owner, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPullRequests", ctx, owner, repo, opts)
+	ret0, _ := ret[0].([]*github.PullRequest)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListPullRequests indicates an expected call of ListPullRequests.
+func (mr *MockGithubIntrMockRecorder) ListPullRequests(ctx, owner, repo, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPullRequests", reflect.TypeOf((*MockGithubIntr)(nil).ListPullRequests), ctx, owner, repo, opts)
+}
+
 // ListRepoSecrets mocks base method.
-func (m *MockGithubIntr) ListRepoSecrets(arg0 context.Context, arg1, arg2 string, arg3 *github.ListOptions) (*github.Secrets, error) {
+func (m *MockGithubIntr) ListRepoSecrets(arg0 context.Context, arg1, arg2 string, arg3 *github.ListOptions) (*github.Secrets, *github.Response, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ListRepoSecrets", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(*github.Secrets)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // ListRepoSecrets indicates an expected call of ListRepoSecrets.
@@ -249,3 +498,49 @@ func (mr *MockGithubIntrMockRecorder) ListRepositoryWorkflowRuns(arg0, arg1, arg
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRepositoryWorkflowRuns", reflect.TypeOf((*MockGithubIntr)(nil).ListRepositoryWorkflowRuns), arg0, arg1, arg2, arg3)
 }
+
+// ListUserRepos mocks base method.
+func (m *MockGithubIntr) ListUserRepos(ctx context.Context, opts *github.RepositoryListByAuthenticatedUserOptions) ([]*github.Repository, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserRepos", ctx, opts)
+	ret0, _ := ret[0].([]*github.Repository)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListUserRepos indicates an expected call of ListUserRepos.
+func (mr *MockGithubIntrMockRecorder) ListUserRepos(ctx, opts any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserRepos", reflect.TypeOf((*MockGithubIntr)(nil).ListUserRepos), ctx, opts)
+}
+
+// UpdateRef mocks base method.
+func (m *MockGithubIntr) UpdateRef(ctx context.Context, owner, repo string, ref *github.Reference, force bool) (*github.Reference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRef", ctx, owner, repo, ref, force)
+	ret0, _ := ret[0].(*github.Reference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRef indicates an expected call of UpdateRef.
+func (mr *MockGithubIntrMockRecorder) UpdateRef(ctx, owner, repo, ref, force any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRef", reflect.TypeOf((*MockGithubIntr)(nil).UpdateRef), ctx, owner, repo, ref, force)
+}
+
+// Zen mocks base method.
+func (m *MockGithubIntr) Zen(ctx context.Context) (*github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Zen", ctx)
+	ret0, _ := ret[0].(*github.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Zen indicates an expected call of Zen.
+func (mr *MockGithubIntrMockRecorder) Zen(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Zen", reflect.TypeOf((*MockGithubIntr)(nil).Zen), ctx)
+}
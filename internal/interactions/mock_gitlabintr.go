@@ -40,6 +40,53 @@ func (m *MockGitlabIntr) EXPECT() *MockGitlabIntrMockRecorder {
 	return m.recorder
 }
 
+// AddDeployKey mocks base method.
+func (m *MockGitlabIntr) AddDeployKey(pid any, opt *gitlab.AddDeployKeyOptions) (*gitlab.ProjectDeployKey, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddDeployKey", pid, opt)
+	ret0, _ := ret[0].(*gitlab.ProjectDeployKey)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AddDeployKey indicates an expected call of AddDeployKey.
+func (mr *MockGitlabIntrMockRecorder) AddDeployKey(pid, opt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDeployKey", reflect.TypeOf((*MockGitlabIntr)(nil).AddDeployKey), pid, opt)
+}
+
+// AddProjectHook mocks base method.
+func (m *MockGitlabIntr) AddProjectHook(pid any, opt *gitlab.AddProjectHookOptions) (*gitlab.ProjectHook, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddProjectHook", pid, opt)
+	ret0, _ := ret[0].(*gitlab.ProjectHook)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AddProjectHook indicates an expected call of AddProjectHook.
+func (mr *MockGitlabIntrMockRecorder) AddProjectHook(pid, opt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProjectHook", reflect.TypeOf((*MockGitlabIntr)(nil).AddProjectHook), pid, opt)
+}
+
+// CachedCurrentUser mocks base method.
+func (m *MockGitlabIntr) CachedCurrentUser() (*gitlab.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CachedCurrentUser")
+	ret0, _ := ret[0].(*gitlab.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CachedCurrentUser indicates an expected call of CachedCurrentUser.
+func (mr *MockGitlabIntrMockRecorder) CachedCurrentUser() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CachedCurrentUser", reflect.TypeOf((*MockGitlabIntr)(nil).CachedCurrentUser))
+}
+
 // CreateCommit mocks base method.
 func (m *MockGitlabIntr) CreateCommit(pid any, opt *gitlab.CreateCommitOptions) (string, error) {
 	m.ctrl.T.Helper()
@@ -55,13 +102,46 @@ func (mr *MockGitlabIntrMockRecorder) CreateCommit(pid, opt any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCommit", reflect.TypeOf((*MockGitlabIntr)(nil).CreateCommit), pid, opt)
 }
 
+// CreateMergeRequest mocks base method.
+func (m *MockGitlabIntr) CreateMergeRequest(pid any, opt *gitlab.CreateMergeRequestOptions) (*gitlab.MergeRequest, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMergeRequest", pid, opt)
+	ret0, _ := ret[0].(*gitlab.MergeRequest)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateMergeRequest indicates an expected call of CreateMergeRequest.
+func (mr *MockGitlabIntrMockRecorder) CreateMergeRequest(pid, opt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMergeRequest", reflect.TypeOf((*MockGitlabIntr)(nil).CreateMergeRequest), pid, opt)
+}
+
+// CreatePipeline mocks base method.
+func (m *MockGitlabIntr) CreatePipeline(pid any, opt *gitlab.CreatePipelineOptions) (*gitlab.Pipeline, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePipeline", pid, opt)
+	ret0, _ := ret[0].(*gitlab.Pipeline)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreatePipeline indicates an expected call of CreatePipeline.
+func (mr *MockGitlabIntrMockRecorder) CreatePipeline(pid, opt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePipeline", reflect.TypeOf((*MockGitlabIntr)(nil).CreatePipeline), pid, opt)
+}
+
 // CreateProject mocks base method.
-func (m *MockGitlabIntr) CreateProject(opt *gitlab.CreateProjectOptions) (*gitlab.Project, error) {
+func (m *MockGitlabIntr) CreateProject(opt *gitlab.CreateProjectOptions) (*gitlab.Project, *gitlab.Response, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateProject", opt)
 	ret0, _ := ret[0].(*gitlab.Project)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // CreateProject indicates an expected call of CreateProject.
@@ -71,11 +151,12 @@ func (mr *MockGitlabIntrMockRecorder) CreateProject(opt any) *gomock.Call {
 }
 
 // CreateProjectVariable mocks base method.
-func (m *MockGitlabIntr) CreateProjectVariable(pid any, opt *gitlab.CreateProjectVariableOptions) error {
+func (m *MockGitlabIntr) CreateProjectVariable(pid any, opt *gitlab.CreateProjectVariableOptions) (*gitlab.Response, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateProjectVariable", pid, opt)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*gitlab.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CreateProjectVariable indicates an expected call of CreateProjectVariable.
@@ -114,13 +195,122 @@ func (mr *MockGitlabIntrMockRecorder) CurrentUser() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CurrentUser", reflect.TypeOf((*MockGitlabIntr)(nil).CurrentUser))
 }
 
+// DeleteDeployKey mocks base method.
+func (m *MockGitlabIntr) DeleteDeployKey(pid any, deployKey int) (*gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDeployKey", pid, deployKey)
+	ret0, _ := ret[0].(*gitlab.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteDeployKey indicates an expected call of DeleteDeployKey.
+func (mr *MockGitlabIntrMockRecorder) DeleteDeployKey(pid, deployKey any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeployKey", reflect.TypeOf((*MockGitlabIntr)(nil).DeleteDeployKey), pid, deployKey)
+}
+
+// DeleteProjectHook mocks base method.
+func (m *MockGitlabIntr) DeleteProjectHook(pid any, hook int) (*gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProjectHook", pid, hook)
+	ret0, _ := ret[0].(*gitlab.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteProjectHook indicates an expected call of DeleteProjectHook.
+func (mr *MockGitlabIntrMockRecorder) DeleteProjectHook(pid, hook any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProjectHook", reflect.TypeOf((*MockGitlabIntr)(nil).DeleteProjectHook), pid, hook)
+}
+
+// EditProject mocks base method.
+func (m *MockGitlabIntr) EditProject(pid any, opt *gitlab.EditProjectOptions) (*gitlab.Project, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditProject", pid, opt)
+	ret0, _ := ret[0].(*gitlab.Project)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// EditProject indicates an expected call of EditProject.
+func (mr *MockGitlabIntrMockRecorder) EditProject(pid, opt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditProject", reflect.TypeOf((*MockGitlabIntr)(nil).EditProject), pid, opt)
+}
+
+// GetBranch mocks base method.
+func (m *MockGitlabIntr) GetBranch(pid any, branch string) (*gitlab.Branch, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBranch", pid, branch)
+	ret0, _ := ret[0].(*gitlab.Branch)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetBranch indicates an expected call of GetBranch.
+func (mr *MockGitlabIntrMockRecorder) GetBranch(pid, branch any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBranch", reflect.TypeOf((*MockGitlabIntr)(nil).GetBranch), pid, branch)
+}
+
+// GetCurrentAccessToken mocks base method.
+func (m *MockGitlabIntr) GetCurrentAccessToken() (*gitlab.PersonalAccessToken, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCurrentAccessToken")
+	ret0, _ := ret[0].(*gitlab.PersonalAccessToken)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetCurrentAccessToken indicates an expected call of GetCurrentAccessToken.
+func (mr *MockGitlabIntrMockRecorder) GetCurrentAccessToken() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentAccessToken", reflect.TypeOf((*MockGitlabIntr)(nil).GetCurrentAccessToken))
+}
+
+// GetGroup mocks base method.
+func (m *MockGitlabIntr) GetGroup(gid any) (*gitlab.Group, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroup", gid)
+	ret0, _ := ret[0].(*gitlab.Group)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroup indicates an expected call of GetGroup.
+func (mr *MockGitlabIntrMockRecorder) GetGroup(gid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroup", reflect.TypeOf((*MockGitlabIntr)(nil).GetGroup), gid)
+}
+
+// GetInheritedGroupMember mocks base method.
+func (m *MockGitlabIntr) GetInheritedGroupMember(gid any, user int) (*gitlab.GroupMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInheritedGroupMember", gid, user)
+	ret0, _ := ret[0].(*gitlab.GroupMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetInheritedGroupMember indicates an expected call of GetInheritedGroupMember.
+func (mr *MockGitlabIntrMockRecorder) GetInheritedGroupMember(gid, user any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInheritedGroupMember", reflect.TypeOf((*MockGitlabIntr)(nil).GetInheritedGroupMember), gid, user)
+}
+
 // GetNamespace mocks base method.
-func (m *MockGitlabIntr) GetNamespace(id any) (*gitlab.Namespace, error) {
+func (m *MockGitlabIntr) GetNamespace(id any) (*gitlab.Namespace, *gitlab.Response, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetNamespace", id)
 	ret0, _ := ret[0].(*gitlab.Namespace)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetNamespace indicates an expected call of GetNamespace.
@@ -146,11 +336,13 @@ func (mr *MockGitlabIntrMockRecorder) GetProject(pid any) *gomock.Call {
 }
 
 // GetProjectFile mocks base method.
-func (m *MockGitlabIntr) GetProjectFile(pid any, fileName string, opt *gitlab.GetFileOptions) error {
+func (m *MockGitlabIntr) GetProjectFile(pid any, fileName string, opt *gitlab.GetFileOptions) (*gitlab.File, *gitlab.Response, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetProjectFile", pid, fileName, opt)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*gitlab.File)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // GetProjectFile indicates an expected call of GetProjectFile.
@@ -160,9 +352,9 @@ func (mr *MockGitlabIntrMockRecorder) GetProjectFile(pid, fileName, opt any) *go
 }
 
 // GetProjectVariable mocks base method.
-func (m *MockGitlabIntr) GetProjectVariable(pid any, key string) (*gitlab.ProjectVariable, *gitlab.Response, error) {
+func (m *MockGitlabIntr) GetProjectVariable(pid any, key, environmentScope string) (*gitlab.ProjectVariable, *gitlab.Response, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetProjectVariable", pid, key)
+	ret := m.ctrl.Call(m, "GetProjectVariable", pid, key, environmentScope)
 	ret0, _ := ret[0].(*gitlab.ProjectVariable)
 	ret1, _ := ret[1].(*gitlab.Response)
 	ret2, _ := ret[2].(error)
@@ -170,9 +362,40 @@ func (m *MockGitlabIntr) GetProjectVariable(pid any, key string) (*gitlab.Projec
 }
 
 // GetProjectVariable indicates an expected call of GetProjectVariable.
-func (mr *MockGitlabIntrMockRecorder) GetProjectVariable(pid, key any) *gomock.Call {
+func (mr *MockGitlabIntrMockRecorder) GetProjectVariable(pid, key, environmentScope any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProjectVariable", reflect.TypeOf((*MockGitlabIntr)(nil).GetProjectVariable), pid, key, environmentScope)
+}
+
+// GetVersion mocks base method.
+func (m *MockGitlabIntr) GetVersion() (*gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersion")
+	ret0, _ := ret[0].(*gitlab.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersion indicates an expected call of GetVersion.
+func (mr *MockGitlabIntrMockRecorder) GetVersion() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProjectVariable", reflect.TypeOf((*MockGitlabIntr)(nil).GetProjectVariable), pid, key)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersion", reflect.TypeOf((*MockGitlabIntr)(nil).GetVersion))
+}
+
+// ListGroupMembers mocks base method.
+func (m *MockGitlabIntr) ListGroupMembers(gid any, opt *gitlab.ListGroupMembersOptions) ([]*gitlab.GroupMember, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListGroupMembers", gid, opt)
+	ret0, _ := ret[0].([]*gitlab.GroupMember)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListGroupMembers indicates an expected call of ListGroupMembers.
+func (mr *MockGitlabIntrMockRecorder) ListGroupMembers(gid, opt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGroupMembers", reflect.TypeOf((*MockGitlabIntr)(nil).ListGroupMembers), gid, opt)
 }
 
 // ListGroupProjects mocks base method.
@@ -207,6 +430,102 @@ func (mr *MockGitlabIntrMockRecorder) ListGroups(opt any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListGroups", reflect.TypeOf((*MockGitlabIntr)(nil).ListGroups), opt)
 }
 
+// ListProjectDeployKeys mocks base method.
+func (m *MockGitlabIntr) ListProjectDeployKeys(pid any, opt *gitlab.ListProjectDeployKeysOptions) ([]*gitlab.ProjectDeployKey, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProjectDeployKeys", pid, opt)
+	ret0, _ := ret[0].([]*gitlab.ProjectDeployKey)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListProjectDeployKeys indicates an expected call of ListProjectDeployKeys.
+func (mr *MockGitlabIntrMockRecorder) ListProjectDeployKeys(pid, opt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProjectDeployKeys", reflect.TypeOf((*MockGitlabIntr)(nil).ListProjectDeployKeys), pid, opt)
+}
+
+// ListProjectHooks mocks base method.
+func (m *MockGitlabIntr) ListProjectHooks(pid any, opt *gitlab.ListProjectHooksOptions) ([]*gitlab.ProjectHook, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProjectHooks", pid, opt)
+	ret0, _ := ret[0].([]*gitlab.ProjectHook)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListProjectHooks indicates an expected call of ListProjectHooks.
+func (mr *MockGitlabIntrMockRecorder) ListProjectHooks(pid, opt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProjectHooks", reflect.TypeOf((*MockGitlabIntr)(nil).ListProjectHooks), pid, opt)
+}
+
+// ListProjectMergeRequests mocks base method.
+func (m *MockGitlabIntr) ListProjectMergeRequests(pid any, opt *gitlab.ListProjectMergeRequestsOptions) ([]*gitlab.MergeRequest, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProjectMergeRequests", pid, opt)
+	ret0, _ := ret[0].([]*gitlab.MergeRequest)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListProjectMergeRequests indicates an expected call of ListProjectMergeRequests.
+func (mr *MockGitlabIntrMockRecorder) ListProjectMergeRequests(pid, opt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProjectMergeRequests", reflect.TypeOf((*MockGitlabIntr)(nil).ListProjectMergeRequests), pid, opt)
+}
+
+// ListProjectPipelines mocks base method.
+func (m *MockGitlabIntr) ListProjectPipelines(pid any, opt *gitlab.ListProjectPipelinesOptions) ([]*gitlab.PipelineInfo, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProjectPipelines", pid, opt)
+	ret0, _ := ret[0].([]*gitlab.PipelineInfo)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListProjectPipelines indicates an expected call of ListProjectPipelines.
+func (mr *MockGitlabIntrMockRecorder) ListProjectPipelines(pid, opt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProjectPipelines", reflect.TypeOf((*MockGitlabIntr)(nil).ListProjectPipelines), pid, opt)
+}
+
+// ListProjects mocks base method.
+func (m *MockGitlabIntr) ListProjects(opt *gitlab.ListProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProjects", opt)
+	ret0, _ := ret[0].([]*gitlab.Project)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListProjects indicates an expected call of ListProjects.
+func (mr *MockGitlabIntrMockRecorder) ListProjects(opt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProjects", reflect.TypeOf((*MockGitlabIntr)(nil).ListProjects), opt)
+}
+
+// ListTags mocks base method.
+func (m *MockGitlabIntr) ListTags(pid any, opt *gitlab.ListTagsOptions) ([]*gitlab.Tag, *gitlab.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTags", pid, opt)
+	ret0, _ := ret[0].([]*gitlab.Tag)
+	ret1, _ := ret[1].(*gitlab.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListTags indicates an expected call of ListTags.
+func (mr *MockGitlabIntrMockRecorder) ListTags(pid, opt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTags", reflect.TypeOf((*MockGitlabIntr)(nil).ListTags), pid, opt)
+}
+
 // ListUserProjects mocks base method.
 func (m *MockGitlabIntr) ListUserProjects(uid any, opt *gitlab.ListProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error) {
 	m.ctrl.T.Helper()
@@ -238,15 +557,16 @@ func (mr *MockGitlabIntrMockRecorder) ProtectRepositoryTags(pid, opt any) *gomoc
 }
 
 // UpdateProjectVariable mocks base method.
-func (m *MockGitlabIntr) UpdateProjectVariable(pid any, key string, opt *gitlab.UpdateProjectVariableOptions) error {
+func (m *MockGitlabIntr) UpdateProjectVariable(pid any, key, environmentScope string, opt *gitlab.UpdateProjectVariableOptions) (*gitlab.Response, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateProjectVariable", pid, key, opt)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "UpdateProjectVariable", pid, key, environmentScope, opt)
+	ret0, _ := ret[0].(*gitlab.Response)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // UpdateProjectVariable indicates an expected call of UpdateProjectVariable.
-func (mr *MockGitlabIntrMockRecorder) UpdateProjectVariable(pid, key, opt any) *gomock.Call {
+func (mr *MockGitlabIntrMockRecorder) UpdateProjectVariable(pid, key, environmentScope, opt any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProjectVariable", reflect.TypeOf((*MockGitlabIntr)(nil).UpdateProjectVariable), pid, key, opt)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProjectVariable", reflect.TypeOf((*MockGitlabIntr)(nil).UpdateProjectVariable), pid, key, environmentScope, opt)
 }
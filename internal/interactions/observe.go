@@ -0,0 +1,33 @@
+package interactions
+
+import "time"
+
+// ObserveFunc is told about each call an interaction makes to the provider, named after the interaction method, with
+// its duration and error. Retried calls are told about once per attempt. A nil ObserveFunc observes nothing.
+type ObserveFunc func(method string, duration time.Duration, err error)
+
+// call runs f and hands its duration and error to fn under method.
+func (fn ObserveFunc) call(method string, f func() error) error {
+	if fn == nil {
+		return f()
+	}
+
+	start := time.Now()
+	err := f()
+	fn(method, time.Since(start), err)
+
+	return err
+}
+
+// observeCall runs f through fn.call and returns its result.
+func observeCall[T any](fn ObserveFunc, method string, f func() (T, error)) (T, error) {
+	var result T
+
+	err := fn.call(method, func() error {
+		var err error
+		result, err = f()
+		return err
+	})
+
+	return result, err
+}
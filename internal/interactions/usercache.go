@@ -0,0 +1,64 @@
+package interactions
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// userCache remembers the user that owns each token for a while, so that the calls of a flow don't fetch it again.
+// The tokens are kept as their hash. The zero value is ready to use.
+type userCache[U any] struct {
+	mu    sync.Mutex
+	users map[[sha256.Size]byte]cachedUser[U]
+}
+
+type cachedUser[U any] struct {
+	user    U
+	expires time.Time
+}
+
+// get returns the user of token when it was put less than its TTL ago.
+func (c *userCache[U]) get(token string) (U, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := sha256.Sum256([]byte(token))
+
+	cached, ok := c.users[key]
+	if !ok {
+		var zero U
+		return zero, false
+	}
+	if time.Now().After(cached.expires) {
+		delete(c.users, key)
+
+		var zero U
+		return zero, false
+	}
+
+	return cached.user, true
+}
+
+// put remembers the user of token for ttl. A ttl that isn't positive remembers nothing.
+func (c *userCache[U]) put(token string, user U, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.users == nil {
+		c.users = map[[sha256.Size]byte]cachedUser[U]{}
+	}
+
+	now := time.Now()
+	for key, cached := range c.users {
+		if now.After(cached.expires) {
+			delete(c.users, key)
+		}
+	}
+
+	c.users[sha256.Sum256([]byte(token))] = cachedUser[U]{user: user, expires: now.Add(ttl)}
+}
@@ -0,0 +1,19 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// SetSleep replaces the sleep between attempts and disables jitter. The returned func restores them.
+func SetSleep(f func(time.Duration)) func() {
+	oldSleep, oldJitter := sleep, jitter
+	sleep = func(_ context.Context, d time.Duration) {
+		f(d)
+	}
+	jitter = false
+
+	return func() {
+		sleep, jitter = oldSleep, oldJitter
+	}
+}
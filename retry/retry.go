@@ -1,31 +1,64 @@
 package retry
 
 import (
+	"context"
 	"time"
 
 	"github.com/aserto-dev/scc-lib/errx"
 	"github.com/jpillora/backoff"
 )
 
+// sleep and jitter are overridden by tests to run retries without waiting and with predictable delays.
+var (
+	sleep  = sleepContext
+	jitter = true
+)
+
 // Retry retries to run the given function until it returns no error.
 // Returns an error after `timeout`.
 // It uses an exponential backoff for retries, with a min of 10ms, max of 5 seconds and a factor of 1.5.
 // Uses jitter to randomize sleep durations, to avoid contention. See more here:  github.com/jpillora/backoff
 // If the duration is set to 0, it run the given function once.
-func Retry(timeout time.Duration, f func(int) error) (err error) {
+// When it gives up, the returned ErrRetryTimeout records the number of attempts made, see errx.RetryAttempts.
+func Retry(timeout time.Duration, f func(int) error) error {
+	return RetryContext(context.Background(), timeout, f)
+}
+
+// RetryContext is Retry with ctx as a hard cap: it stops retrying, including while sleeping, as soon as ctx is done,
+// even before timeout. Giving ctx a deadline spanning several calls bounds the total time spent retrying, e.g. over
+// a whole onboarding flow. The ErrRetryTimeout it then returns wraps ctx.Err().
+func RetryContext(ctx context.Context, timeout time.Duration, f func(int) error) error {
+	return RetryIfContext(ctx, timeout, nil, f)
+}
+
+// RetryIf is Retry that only retries the errors isRetryable accepts. Any other error is returned as is, right away,
+// rather than retried until timeout. A nil isRetryable retries all the errors.
+func RetryIf(timeout time.Duration, isRetryable func(error) bool, f func(int) error) error {
+	return RetryIfContext(context.Background(), timeout, isRetryable, f)
+}
+
+// RetryIfContext is RetryIf with ctx as a hard cap, like RetryContext.
+func RetryIfContext(ctx context.Context, timeout time.Duration, isRetryable func(error) bool, f func(int) error) (err error) {
 	b := &backoff.Backoff{
 		Min:    10 * time.Millisecond,
 		Max:    5 * time.Second,
 		Factor: 1.5,
-		Jitter: true,
+		Jitter: jitter,
+	}
+
+	if ctx.Err() != nil {
+		return errx.ErrRetryTimeout.Err(ctx.Err()).Int(errx.RetryAttemptsKey, 0)
 	}
 
 	attempt := 1
 
 	if timeout == 0 {
 		err = f(attempt)
+		if err != nil && isRetryable != nil && !isRetryable(err) {
+			return err
+		}
 		if err != nil {
-			return errx.ErrRetryTimeout.Err(err)
+			return errx.ErrRetryTimeout.Err(err).Int(errx.RetryAttemptsKey, attempt)
 		}
 		return nil
 	}
@@ -35,6 +68,8 @@ retryLoop:
 		select {
 		case <-t:
 			break retryLoop
+		case <-ctx.Done():
+			return errx.ErrRetryTimeout.Err(err).Err(ctx.Err()).Int(errx.RetryAttemptsKey, attempt-1)
 		default:
 		}
 
@@ -42,10 +77,24 @@ retryLoop:
 		if err == nil {
 			return nil
 		}
+		if isRetryable != nil && !isRetryable(err) {
+			return err
+		}
 
 		attempt++
-		time.Sleep(b.Duration())
+		sleep(ctx, b.Duration())
 	}
 
-	return errx.ErrRetryTimeout.Err(err)
+	return errx.ErrRetryTimeout.Err(err).Int(errx.RetryAttemptsKey, attempt-1)
+}
+
+// sleepContext waits for d, or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
 }
@@ -1,10 +1,12 @@
 package retry_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
 
+	"github.com/aserto-dev/scc-lib/errx"
 	"github.com/aserto-dev/scc-lib/retry"
 	"github.com/stretchr/testify/require"
 )
@@ -40,6 +42,7 @@ func TestRetryOnce(t *testing.T) {
 
 	assert.Error(err)
 	assert.Equal(iteration, 1)
+	assert.Equal(1, errx.RetryAttempts(err))
 }
 
 func TestRetryOnceNoErr(t *testing.T) {
@@ -55,3 +58,115 @@ func TestRetryOnceNoErr(t *testing.T) {
 	assert.NoError(err)
 	assert.Equal(iteration, 1)
 }
+
+func TestRetryTimeoutAttempts(t *testing.T) {
+	assert := require.New(t)
+
+	var iteration int
+	err := retry.Retry(100*time.Millisecond, func(i int) error {
+		iteration = i
+
+		return errNope
+	})
+
+	assert.Error(err)
+	assert.Equal(iteration, errx.RetryAttempts(err))
+}
+
+func TestRetryIfStopsOnPermanentError(t *testing.T) {
+	assert := require.New(t)
+
+	errPermanent := errors.New("permanent")
+	var iteration int
+	err := retry.RetryIf(5*time.Second, func(err error) bool { return !errors.Is(err, errPermanent) }, func(i int) error {
+		iteration = i
+		if i < 3 {
+			return errNope
+		}
+
+		return errPermanent
+	})
+
+	assert.ErrorIs(err, errPermanent)
+	assert.False(errx.ErrRetryTimeout.SameAs(err))
+	assert.Equal(3, iteration)
+}
+
+func TestRetryIfOnce(t *testing.T) {
+	assert := require.New(t)
+
+	err := retry.RetryIf(0, func(error) bool { return false }, func(i int) error {
+		return errNope
+	})
+
+	assert.ErrorIs(err, errNope)
+	assert.False(errx.ErrRetryTimeout.SameAs(err))
+}
+
+func TestRetryBackoffDelays(t *testing.T) {
+	assert := require.New(t)
+
+	var delays []time.Duration
+	restore := retry.SetSleep(func(d time.Duration) {
+		delays = append(delays, d)
+	})
+	defer restore()
+
+	start := time.Now()
+	err := retry.Retry(time.Minute, func(i int) error {
+		if i == 3 {
+			return nil
+		}
+
+		return errNope
+	})
+
+	assert.NoError(err)
+	assert.Equal([]time.Duration{10 * time.Millisecond, 15 * time.Millisecond}, delays)
+	assert.Less(time.Since(start), time.Second)
+}
+
+func TestRetryContextSharedDeadline(t *testing.T) {
+	assert := require.New(t)
+
+	// A flow of steps, each allowed to retry for a minute, shares a budget of 200ms.
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	var steps []int
+	var err error
+	for step := 1; step <= 3 && err == nil; step++ {
+		steps = append(steps, step)
+		err = retry.RetryContext(ctx, time.Minute, func(i int) error {
+			if step == 1 {
+				return nil
+			}
+
+			return errNope
+		})
+	}
+
+	assert.Error(err)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.Equal([]int{1, 2}, steps)
+	assert.Less(time.Since(start), time.Second)
+}
+
+func TestRetryContextDone(t *testing.T) {
+	assert := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	err := retry.RetryContext(ctx, time.Minute, func(i int) error {
+		called = true
+
+		return nil
+	})
+
+	assert.ErrorIs(err, context.Canceled)
+	assert.False(called)
+	assert.Equal(0, errx.RetryAttempts(err))
+}
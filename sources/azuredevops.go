@@ -0,0 +1,965 @@
+package sources
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aserto-dev/go-grpc/aserto/api/v1"
+	scc "github.com/aserto-dev/go-grpc/aserto/tenant/scc/v1"
+	"github.com/aserto-dev/scc-lib/errx"
+	"github.com/aserto-dev/scc-lib/internal/interactions"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/taskagent"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	headsRefPrefix = "refs/heads/"
+	// emptyObjectID is the old object id of a ref that doesn't exist yet.
+	emptyObjectID = "0000000000000000000000000000000000000000"
+)
+
+var (
+	_             Source = &azureDevOpsSource{}
+	azureDevOpsCI        = "/_build"
+
+	// azureDevOpsScopes are the personal access token scopes the source needs.
+	azureDevOpsScopes = []string{
+		"Code (Read & write)",
+		"Project and Team (Read, write & manage)",
+		"Variable Groups (Read, create & manage)",
+	}
+)
+
+// azureDevOpsSource deals with source management on the Azure DevOps organization at Config.BaseURL.
+// Projects are the orgs of Azure DevOps, and each repo keeps its secrets in a variable group named after it.
+type azureDevOpsSource struct {
+	logger           *zerolog.Logger
+	cfg              *Config
+	interactionsFunc interactions.AzIntr
+}
+
+func (a *azureDevOpsSource) client(ctx context.Context, accessToken *AccessToken) (interactions.AzureDevOpsIntr, error) {
+	client, err := a.interactionsFunc(ctx, a.cfg.BaseURL, accessToken.Token, a.cfg.httpTimeout())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Azure DevOps client")
+	}
+
+	return client, nil
+}
+
+// Ping checks that the Azure DevOps organization answers.
+func (a *azureDevOpsSource) Ping(ctx context.Context) (err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "Ping", "", "")(&err)
+
+	client, err := a.client(ctx, &AccessToken{})
+	if err != nil {
+		return pingError("azure-devops", azureDevOpsStatusCode(err), err)
+	}
+
+	_, err = client.GetConnectionData(ctx)
+	if err == nil {
+		return nil
+	}
+
+	return pingError("azure-devops", azureDevOpsStatusCode(err), err)
+}
+
+// Close is a no-op, the source creates its clients for every call.
+func (a *azureDevOpsSource) Close() error {
+	return nil
+}
+
+// ValidateConnection checks that the personal access token is valid.
+// Azure DevOps doesn't report the scopes of a token, so the required scopes are listed in the error instead.
+func (a *azureDevOpsSource) ValidateConnection(ctx context.Context, accessToken *AccessToken, requiredScopes []string) (err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "ValidateConnection", "", "")(&err)
+
+	client, err := a.client(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	if _, err := client.GetConnectionData(ctx); err != nil {
+		return azureDevOpsVerificationError(err)
+	}
+
+	return nil
+}
+
+func azureDevOpsVerificationError(err error) error {
+	return errx.ErrProviderVerification.
+		Err(err).
+		Interface("required-scopes", azureDevOpsScopes).
+		Msgf("failed to connect to Azure DevOps, the personal access token needs the scopes: %s", strings.Join(azureDevOpsScopes, ", "))
+}
+
+// Preflight checks that the token is valid. Azure DevOps doesn't report token scopes nor project permissions,
+// so the other checks are always skipped.
+func (a *azureDevOpsSource) Preflight(ctx context.Context, accessToken *AccessToken, owner string, requiredScopes []string) (_ *PreflightResult, err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "Preflight", owner, "")(&err)
+
+	client, err := a.client(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PreflightResult{}
+
+	_, err = client.GetConnectionData(ctx)
+	if err != nil {
+		result.Connection = checkFromError(azureDevOpsVerificationError(err))
+		return result, nil
+	}
+
+	result.Connection = checkFromError(nil)
+
+	return result, nil
+}
+
+func (a *azureDevOpsSource) Profile(ctx context.Context, accessToken *AccessToken) (_ string, _ []*scc.Repo, err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "Profile", "", "")(&err)
+
+	username, repos, _, err := a.profilePage(ctx, accessToken, &api.PaginationRequest{Size: -1})
+	return username, repos, err
+}
+
+// ProfilePage returns the display name of the user that owns the token, and a page of the repos
+// of the organization. A page size of -1 reads all the pages.
+func (a *azureDevOpsSource) ProfilePage(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest) (_ string, _ []*scc.Repo, _ *api.PaginationResponse, err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "ProfilePage", "", "")(&err)
+
+	return a.profilePage(ctx, accessToken, page)
+}
+
+// profilePage reads the profile page for Profile and ProfilePage, which each track the operation themselves.
+func (a *azureDevOpsSource) profilePage(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest) (_ string, _ []*scc.Repo, _ *api.PaginationResponse, err error) {
+	if err := validatePage(page); err != nil {
+		return "", nil, nil, err
+	}
+
+	client, err := a.client(ctx, accessToken)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	connectionData, err := client.GetConnectionData(ctx)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	username := ""
+	if connectionData.AuthenticatedUser != nil {
+		username = ptr.Deref(connectionData.AuthenticatedUser.ProviderDisplayName, "")
+	}
+
+	repos, response, err := a.listRepos(ctx, client, nil, page, "")
+
+	return username, repos, response, err
+}
+
+func (a *azureDevOpsSource) ListOrgs(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest, opts *ListOrgsOptions) (_ []*api.SccOrg, _ *api.PaginationResponse, err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "ListOrgs", "", "")(&err)
+
+	if err := validatePage(page); err != nil {
+		return nil, nil, err
+	}
+
+	client, err := a.client(ctx, accessToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	args := core.GetProjectsArgs{}
+	if page.Size != -1 {
+		args.Top = ptr.To(int(page.Size))
+	}
+	if strings.TrimSpace(page.Token) != "" {
+		token, err := strconv.Atoi(page.Token)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "page token must be int")
+		}
+		args.ContinuationToken = &token
+	}
+
+	var orgs []*api.SccOrg
+
+	for {
+		projects, err := client.GetProjects(ctx, args)
+		if err != nil {
+			return orgs, nil, err
+		}
+
+		for _, project := range projects.Value {
+			name := ptr.Deref(project.Name, "")
+			orgs = append(orgs, &api.SccOrg{
+				Name: name,
+				Id:   name,
+			})
+		}
+
+		if page.Size != -1 {
+			return orgs, &api.PaginationResponse{
+				NextToken:  projects.ContinuationToken,
+				ResultSize: safeInt32(len(orgs)),
+			}, nil
+		}
+		if projects.ContinuationToken == "" {
+			break
+		}
+
+		token, err := strconv.Atoi(projects.ContinuationToken)
+		if err != nil {
+			return orgs, nil, errors.Wrap(err, "continuation token must be int")
+		}
+		args.ContinuationToken = &token
+	}
+
+	response := &api.PaginationResponse{
+		NextToken:  "",
+		ResultSize: safeInt32(len(orgs)),
+		TotalSize:  safeInt32(len(orgs)),
+	}
+	return orgs, response, nil
+}
+
+// ListOrgMembers lists the members of the default team of the project, which is the closest Azure DevOps has to
+// the members of an organization. The page token is the number of members to skip.
+func (a *azureDevOpsSource) ListOrgMembers(ctx context.Context, accessToken *AccessToken, org string, page *api.PaginationRequest) (_ []*SccUser, _ *api.PaginationResponse, err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "ListOrgMembers", org, "")(&err)
+
+	if err := validatePage(page); err != nil {
+		return nil, nil, err
+	}
+
+	client, err := a.client(ctx, accessToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	project, err := client.GetProject(ctx, core.GetProjectArgs{ProjectId: &org})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to get project '%s'", org)
+	}
+	if project.DefaultTeam == nil || project.DefaultTeam.Name == nil {
+		return nil, nil, errors.Errorf("project '%s' has no default team", org)
+	}
+
+	top := int(page.Size)
+	if page.Size == -1 {
+		top = 100
+	}
+
+	skip := 0
+	if strings.TrimSpace(page.Token) != "" {
+		skip, err = strconv.Atoi(page.Token)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "page token must be int")
+		}
+	}
+
+	var members []*SccUser
+
+	for {
+		teamMembers, err := client.GetTeamMembers(ctx, core.GetTeamMembersWithExtendedPropertiesArgs{
+			ProjectId: &org,
+			TeamId:    project.DefaultTeam.Name,
+			Top:       &top,
+			Skip:      &skip,
+		})
+		if err != nil {
+			return members, nil, errors.Wrapf(err, "failed to list members of project '%s'", org)
+		}
+
+		count := 0
+		if teamMembers != nil {
+			count = len(*teamMembers)
+			for _, member := range *teamMembers {
+				if member.Identity == nil {
+					continue
+				}
+				members = append(members, &SccUser{
+					Login: ptr.Deref(member.Identity.UniqueName, ""),
+					Name:  ptr.Deref(member.Identity.DisplayName, ""),
+				})
+			}
+		}
+
+		// Azure DevOps doesn't report the total number of members, a full page means there might be more.
+		skip += count
+		hasNextPage := count == top
+
+		if page.Size != -1 {
+			resp := &api.PaginationResponse{
+				ResultSize: safeInt32(len(members)),
+			}
+			if hasNextPage {
+				resp.NextToken = strconv.Itoa(skip)
+			}
+			return members, resp, nil
+		}
+		if !hasNextPage {
+			break
+		}
+	}
+
+	response := &api.PaginationResponse{
+		NextToken:  "",
+		ResultSize: safeInt32(len(members)),
+		TotalSize:  safeInt32(len(members)),
+	}
+	return members, response, nil
+}
+
+// ListRepos lists the git repositories of a project. Azure DevOps returns all of them at once,
+// so the page token is the offset of the page.
+func (a *azureDevOpsSource) ListRepos(
+	ctx context.Context,
+	accessToken *AccessToken,
+	owner string,
+	page *api.PaginationRequest,
+	opts *ListReposOptions,
+) (_ []*scc.Repo, _ *api.PaginationResponse, err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "ListRepos", owner, "")(&err)
+
+	if err := validatePage(page); err != nil {
+		return nil, nil, err
+	}
+
+	client, err := a.client(ctx, accessToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	filter := ""
+	if opts != nil {
+		filter = strings.TrimSpace(opts.Filter)
+	}
+
+	return a.listRepos(ctx, client, &owner, page, filter)
+}
+
+func (a *azureDevOpsSource) listRepos(
+	ctx context.Context,
+	client interactions.AzureDevOpsIntr,
+	project *string,
+	page *api.PaginationRequest,
+	filter string,
+) ([]*scc.Repo, *api.PaginationResponse, error) {
+	offset := 0
+	if strings.TrimSpace(page.Token) != "" {
+		var err error
+		offset, err = strconv.Atoi(page.Token)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "page token must be int")
+		}
+	}
+
+	azRepos, err := client.GetRepositories(ctx, git.GetRepositoriesArgs{Project: project})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	all := []*scc.Repo{}
+	if azRepos != nil {
+		for i := range *azRepos {
+			repo := &(*azRepos)[i]
+			if filter != "" && !strings.Contains(ptr.Deref(repo.Name, ""), filter) {
+				continue
+			}
+			all = append(all, a.sccRepo(repo))
+		}
+	}
+
+	if page.Size == -1 {
+		return all, &api.PaginationResponse{
+			NextToken:  "",
+			ResultSize: safeInt32(len(all)),
+			TotalSize:  safeInt32(len(all)),
+		}, nil
+	}
+
+	start := min(offset, len(all))
+	end := min(start+int(page.Size), len(all))
+	nextToken := ""
+	if end < len(all) {
+		nextToken = strconv.Itoa(end)
+	}
+
+	return all[start:end], &api.PaginationResponse{
+		NextToken:  nextToken,
+		ResultSize: safeInt32(end - start),
+		TotalSize:  safeInt32(len(all)),
+	}, nil
+}
+
+func (a *azureDevOpsSource) getRepo(ctx context.Context, client interactions.AzureDevOpsIntr, owner, repo string) (*git.GitRepository, error) {
+	azRepo, err := client.GetRepository(ctx, git.GetRepositoryArgs{Project: &owner, RepositoryId: &repo})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get repo: %s/%s", owner, repo)
+	}
+
+	return azRepo, nil
+}
+
+func (a *azureDevOpsSource) GetRepo(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ *scc.Repo, err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "GetRepo", owner, repo)(&err)
+
+	client, err := a.client(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	azRepo, err := a.getRepo(ctx, client, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.sccRepo(azRepo), nil
+}
+
+func (a *azureDevOpsSource) RenameRepo(ctx context.Context, accessToken *AccessToken, owner, repo, newName string) (err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "RenameRepo", owner, repo)(&err)
+
+	client, err := a.client(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	azRepo, err := client.GetRepository(ctx, git.GetRepositoryArgs{Project: &owner, RepositoryId: &repo})
+	if err != nil {
+		return errors.Wrapf(renameRepoError(err, azureDevOpsStatusCode(err), owner, newName), "failed to get repo: %s/%s", owner, repo)
+	}
+
+	// Renames are addressed by the ID of the repo, its name can't be used.
+	_, err = client.UpdateRepository(ctx, git.UpdateRepositoryArgs{
+		NewRepositoryInfo: &git.GitRepository{Name: &newName},
+		Project:           &owner,
+		RepositoryId:      azRepo.Id,
+	})
+	if err != nil {
+		return errors.Wrapf(renameRepoError(err, azureDevOpsStatusCode(err), owner, newName), "failed to rename repo %s/%s", owner, repo)
+	}
+
+	return nil
+}
+
+func (a *azureDevOpsSource) GetRepoID(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ string, err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "GetRepoID", owner, repo)(&err)
+
+	client, err := a.client(ctx, accessToken)
+	if err != nil {
+		return "", err
+	}
+
+	azRepo, err := a.getRepo(ctx, client, owner, repo)
+	if err != nil {
+		return "", err
+	}
+
+	if azRepo.Id == nil {
+		return "", errors.Errorf("repo %s/%s has no id", owner, repo)
+	}
+
+	return azRepo.Id.String(), nil
+}
+
+// CreateRepo creates a git repository in the project. Visibility and descriptions are set per project
+// on Azure DevOps, so Private and Description are ignored. AutoInit pushes a README on the main branch.
+func (a *azureDevOpsSource) CreateRepo(ctx context.Context, accessToken *AccessToken, owner, name string, opts *CreateRepoOptions) (_ *scc.Repo, err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "CreateRepo", owner, name)(&err)
+
+	client, err := a.client(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	azRepo, err := client.CreateRepository(ctx, git.CreateRepositoryArgs{
+		Project:               &owner,
+		GitRepositoryToCreate: &git.GitRepositoryCreateOptions{Name: &name},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if opts != nil && opts.AutoInit != nil && *opts.AutoInit {
+		_, err = a.push(ctx, client, owner, name, "main", emptyObjectID, "Initial commit", nil, map[string]string{"README.md": "# " + name + "\n"})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to initialize repo: %s/%s", owner, name)
+		}
+	}
+
+	return a.sccRepo(azRepo), nil
+}
+
+// InitialTag creates an annotated tag on the commit, or on the head of the default branch when commitSha is empty.
+// Azure DevOps doesn't report the pipeline run the tag triggers, so the returned ID is always 0.
+func (a *azureDevOpsSource) InitialTag(ctx context.Context, accessToken *AccessToken, fullName, workflowFileName, commitSha, tagMessage string) (_ int64, err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "InitialTag", "", fullName)(&err)
+
+	client, err := a.client(ctx, accessToken)
+	if err != nil {
+		return 0, err
+	}
+
+	if strings.Count(fullName, "/") != 1 {
+		return 0, errors.Errorf("invalid full azure devops repo name '%s', should be in the form project/repo", fullName)
+	}
+
+	owner, name, _ := strings.Cut(fullName, "/")
+
+	tags, err := client.GetRefs(ctx, git.GetRefsArgs{
+		Project:      &owner,
+		RepositoryId: &name,
+		Filter:       ptr.To("tags/"),
+		Top:          ptr.To(1),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(tags.Value) > 0 {
+		return 0, nil
+	}
+
+	if commitSha == "" {
+		azRepo, err := a.getRepo(ctx, client, owner, name)
+		if err != nil {
+			return 0, err
+		}
+		if azRepo.DefaultBranch == nil {
+			return 0, errors.Wrapf(ErrEmptyRepo, "%s", fullName)
+		}
+
+		commitSha, err = a.branchHead(ctx, client, owner, name, strings.TrimPrefix(*azRepo.DefaultBranch, headsRefPrefix))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	tag := a.cfg.initialTag()
+
+	return 0, client.CreateAnnotatedTag(ctx, git.CreateAnnotatedTagArgs{
+		Project:      &owner,
+		RepositoryId: &name,
+		TagObject: &git.GitAnnotatedTag{
+			Name:         &tag,
+			Message:      ptr.To(tagMessageOrName(tag, tagMessage)),
+			TaggedObject: &git.GitObject{ObjectId: &commitSha},
+		},
+	})
+}
+
+// branchHead returns the commit the branch points to, or emptyObjectID if the branch doesn't exist.
+func (a *azureDevOpsSource) branchHead(ctx context.Context, client interactions.AzureDevOpsIntr, owner, repo, branch string) (string, error) {
+	refs, err := client.GetRefs(ctx, git.GetRefsArgs{
+		Project:      &owner,
+		RepositoryId: &repo,
+		Filter:       ptr.To("heads/" + branch),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, ref := range refs.Value {
+		if ptr.Deref(ref.Name, "") == headsRefPrefix+branch {
+			return ptr.Deref(ref.ObjectId, emptyObjectID), nil
+		}
+	}
+
+	return emptyObjectID, nil
+}
+
+// variableGroup returns the variable group holding the secrets of the repo, or nil if it doesn't exist yet.
+func (a *azureDevOpsSource) variableGroup(ctx context.Context, client interactions.AzureDevOpsIntr, owner, repo string) (*taskagent.VariableGroup, error) {
+	groups, err := client.GetVariableGroups(ctx, taskagent.GetVariableGroupsArgs{Project: &owner, GroupName: &repo})
+	if err != nil {
+		return nil, err
+	}
+
+	if groups == nil || len(*groups) == 0 {
+		return nil, nil
+	}
+
+	return &(*groups)[0], nil
+}
+
+func hasVariable(group *taskagent.VariableGroup, name string) bool {
+	if group == nil || group.Variables == nil {
+		return false
+	}
+
+	_, ok := (*group.Variables)[name]
+	return ok
+}
+
+func (a *azureDevOpsSource) HasSecret(ctx context.Context, token *AccessToken, owner, repo, secretName string, opts *SecretOptions) (_ bool, err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "HasSecret", owner, repo)(&err)
+
+	client, err := a.client(ctx, token)
+	if err != nil {
+		return false, err
+	}
+
+	group, err := a.variableGroup(ctx, client, owner, repo)
+	if err != nil {
+		return false, err
+	}
+
+	return hasVariable(group, secretName), nil
+}
+
+// GetSecret returns errx.ErrSecretWriteOnly: Azure DevOps doesn't return the values of the secret variables set by
+// AddSecretToRepo.
+func (a *azureDevOpsSource) GetSecret(ctx context.Context, token *AccessToken, owner, repo, secretName string) (_ string, _ bool, err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "GetSecret", owner, repo)(&err)
+
+	return "", false, errx.ErrSecretWriteOnly.Msg("Azure DevOps secret variables can't be read back")
+}
+
+func (a *azureDevOpsSource) AddSecretToRepo(ctx context.Context, token *AccessToken, orgName, repoName, secretName, value string, overrideSecret bool, opts *SecretOptions) (err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "AddSecretToRepo", orgName, repoName)(&err)
+
+	client, err := a.client(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	group, err := a.variableGroup(ctx, client, orgName, repoName)
+	if err != nil {
+		return err
+	}
+
+	if !overrideSecret && hasVariable(group, secretName) {
+		return errx.ErrRepoAlreadyConnected.Msg("you're trying to link to an existing repository that already has a secret. Please consider overwriting the Aserto push secret.").Str("repo", orgName+"/"+repoName)
+	}
+
+	// Secret values aren't returned, and are kept as is when sent back without a value.
+	variables := map[string]interface{}{}
+	if group != nil && group.Variables != nil {
+		for name, variable := range *group.Variables {
+			variables[name] = variable
+		}
+	}
+	variables[secretName] = taskagent.VariableValue{Value: &value, IsSecret: ptr.To(true)}
+
+	parameters := &taskagent.VariableGroupParameters{
+		Name:      &repoName,
+		Type:      ptr.To("Vsts"),
+		Variables: &variables,
+		VariableGroupProjectReferences: &[]taskagent.VariableGroupProjectReference{{
+			Name:             &repoName,
+			ProjectReference: &taskagent.ProjectReference{Name: &orgName},
+		}},
+	}
+
+	if group != nil {
+		return client.UpdateVariableGroup(ctx, taskagent.UpdateVariableGroupArgs{GroupId: group.Id, VariableGroupParameters: parameters})
+	}
+
+	return client.AddVariableGroup(ctx, taskagent.AddVariableGroupArgs{VariableGroupParameters: parameters})
+}
+
+// CreateCommitOnBranch pushes a commit on top of the branch head. The push fails if the head moves
+// in the meantime, and commits asking for ConflictRebase are refused.
+func (a *azureDevOpsSource) CreateCommitOnBranch(ctx context.Context, accessToken *AccessToken, commit *Commit) (_ string, err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "CreateCommitOnBranch", commit.Owner, commit.Repo)(&err)
+
+	if err = checkConflictStrategy("azure devops", commit); err != nil {
+		return "", err
+	}
+
+	client, err := a.client(ctx, accessToken)
+	if err != nil {
+		return "", err
+	}
+
+	head, err := a.branchHead(ctx, client, commit.Owner, commit.Repo, commit.Branch)
+	if err != nil {
+		return "", err
+	}
+
+	return a.push(ctx, client, commit.Owner, commit.Repo, commit.Branch, head, commit.Message, commit.Author, commit.Content)
+}
+
+func (a *azureDevOpsSource) CreateFile(ctx context.Context, accessToken *AccessToken, owner, repo, path, content, branch, message string) (err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "CreateFile", owner, repo)(&err)
+
+	client, err := a.client(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	head, err := a.branchHead(ctx, client, owner, repo, branch)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.push(ctx, client, owner, repo, branch, head, message, nil, map[string]string{path: content})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create file: %s", path)
+	}
+
+	return nil
+}
+
+func (a *azureDevOpsSource) push(
+	ctx context.Context,
+	client interactions.AzureDevOpsIntr,
+	owner, repo, branch, head, message string,
+	author *CommitAuthor,
+	content map[string]string,
+) (string, error) {
+	changes := []interface{}{}
+
+	for filePath, fileContent := range content {
+		changeType := git.VersionControlChangeTypeValues.Add
+		if head != emptyObjectID {
+			_, err := client.GetItem(ctx, git.GetItemArgs{
+				Project:      &owner,
+				RepositoryId: &repo,
+				Path:         &filePath,
+				VersionDescriptor: &git.GitVersionDescriptor{
+					Version:     &branch,
+					VersionType: &git.GitVersionTypeValues.Branch,
+				},
+			})
+			switch {
+			case err == nil:
+				changeType = git.VersionControlChangeTypeValues.Edit
+			case !isAzureDevOpsNotFound(err):
+				return "", err
+			}
+		}
+
+		changes = append(changes, git.GitChange{
+			ChangeType: &changeType,
+			Item:       git.GitItem{Path: ptr.To(filePath)},
+			NewContent: &git.ItemContent{
+				Content:     ptr.To(fileContent),
+				ContentType: &git.ItemContentTypeValues.RawText,
+			},
+		})
+	}
+
+	commitRef := git.GitCommitRef{
+		Comment: &message,
+		Changes: &changes,
+	}
+	if author != nil {
+		commitRef.Author = &git.GitUserDate{Name: &author.Name, Email: &author.Email}
+	}
+
+	pushed, err := client.CreatePush(ctx, git.CreatePushArgs{
+		Project:      &owner,
+		RepositoryId: &repo,
+		Push: &git.GitPush{
+			RefUpdates: &[]git.GitRefUpdate{{
+				Name:        ptr.To(headsRefPrefix + branch),
+				OldObjectId: &head,
+			}},
+			Commits: &[]git.GitCommitRef{commitRef},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if pushed.Commits == nil || len(*pushed.Commits) == 0 {
+		return "", errors.New("azure devops didn't return the pushed commit")
+	}
+
+	return ptr.Deref((*pushed.Commits)[0].CommitId, ""), nil
+}
+
+// GetFileContent returns the content of a file at the given ref, or on the default branch when ref is empty.
+// The bool is false when the file doesn't exist.
+func (a *azureDevOpsSource) GetFileContent(ctx context.Context, accessToken *AccessToken, owner, repo, path, ref string) (_ string, _ bool, err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "GetFileContent", owner, repo)(&err)
+
+	client, err := a.client(ctx, accessToken)
+	if err != nil {
+		return "", false, err
+	}
+
+	args := git.GetItemArgs{
+		Project:        &owner,
+		RepositoryId:   &repo,
+		Path:           &path,
+		IncludeContent: ptr.To(true),
+	}
+	if ref != "" {
+		args.VersionDescriptor = &git.GitVersionDescriptor{
+			Version:     &ref,
+			VersionType: &git.GitVersionTypeValues.Branch,
+		}
+	}
+
+	item, err := client.GetItem(ctx, args)
+	if err != nil {
+		if isAzureDevOpsNotFound(err) {
+			return "", false, nil
+		}
+		return "", false, errors.Wrapf(err, "failed to get file: %s", path)
+	}
+
+	if ptr.Deref(item.IsFolder, false) {
+		return "", false, errors.Errorf("'%s' is not a file", path)
+	}
+
+	return ptr.Deref(item.Content, ""), true, nil
+}
+
+func (a *azureDevOpsSource) GetDefaultBranch(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ string, err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "GetDefaultBranch", owner, repo)(&err)
+
+	client, err := a.client(ctx, accessToken)
+	if err != nil {
+		return "", err
+	}
+
+	azRepo, err := a.getRepo(ctx, client, owner, repo)
+	if err != nil {
+		return "", err
+	}
+
+	if azRepo.DefaultBranch == nil {
+		return "", errors.Wrapf(ErrEmptyRepo, "%s/%s", owner, repo)
+	}
+
+	return strings.TrimPrefix(*azRepo.DefaultBranch, headsRefPrefix), nil
+}
+
+// IsRepoEmpty returns true if the repo has no commits yet. Azure DevOps repos get a default branch with their first push.
+func (a *azureDevOpsSource) IsRepoEmpty(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ bool, err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "IsRepoEmpty", owner, repo)(&err)
+
+	client, err := a.client(ctx, accessToken)
+	if err != nil {
+		return false, err
+	}
+
+	azRepo, err := a.getRepo(ctx, client, owner, repo)
+	if err != nil {
+		return false, err
+	}
+
+	return azRepo.DefaultBranch == nil, nil
+}
+
+// ListTags lists the tags of a repo, in name order. The page token is the Azure DevOps continuation token.
+func (a *azureDevOpsSource) ListTags(ctx context.Context, accessToken *AccessToken, owner, repo string, page *api.PaginationRequest) (_ []string, _ *api.PaginationResponse, err error) {
+	defer trackOperation(a.logger, a.cfg.Observer, "azure-devops", "ListTags", owner, repo)(&err)
+
+	if err := validatePage(page); err != nil {
+		return nil, nil, err
+	}
+
+	client, err := a.client(ctx, accessToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	args := git.GetRefsArgs{
+		Project:      &owner,
+		RepositoryId: &repo,
+		Filter:       ptr.To("tags/"),
+	}
+	if page.Size != -1 {
+		args.Top = ptr.To(int(page.Size))
+	}
+	if strings.TrimSpace(page.Token) != "" {
+		args.ContinuationToken = &page.Token
+	}
+
+	tags := []string{}
+
+	for {
+		refs, err := client.GetRefs(ctx, args)
+		if err != nil {
+			return tags, nil, err
+		}
+
+		for _, ref := range refs.Value {
+			tags = append(tags, strings.TrimPrefix(ptr.Deref(ref.Name, ""), "refs/tags/"))
+		}
+
+		if page.Size != -1 {
+			return tags, &api.PaginationResponse{
+				NextToken:  refs.ContinuationToken,
+				ResultSize: safeInt32(len(tags)),
+			}, nil
+		}
+		if refs.ContinuationToken == "" {
+			break
+		}
+
+		args.ContinuationToken = &refs.ContinuationToken
+	}
+
+	response := &api.PaginationResponse{
+		NextToken:  "",
+		ResultSize: safeInt32(len(tags)),
+		TotalSize:  safeInt32(len(tags)),
+	}
+	return tags, response, nil
+}
+
+func (a *azureDevOpsSource) sccRepo(repo *git.GitRepository) *scc.Repo {
+	url := ptr.Deref(repo.WebUrl, "")
+
+	// Pipelines belong to the project, so the default CI URL is the project's.
+	ciBase := url
+	if projectURL, _, ok := strings.Cut(url, "/_git/"); ok && a.cfg.CIPathSuffix == "" {
+		ciBase = projectURL
+	}
+
+	org := ""
+	if repo.Project != nil {
+		org = ptr.Deref(repo.Project.Name, "")
+	}
+
+	return &scc.Repo{
+		Name:  ptr.Deref(repo.Name, ""),
+		Org:   org,
+		Url:   url,
+		CiUrl: a.cfg.ciURL(ciBase, azureDevOpsCI),
+	}
+}
+
+// isAzureDevOpsNotFound reports whether err is a 404 reply, which the SDK returns either as a value or a pointer.
+func isAzureDevOpsNotFound(err error) bool {
+	return azureDevOpsStatusCode(err) == http.StatusNotFound
+}
+
+// azureDevOpsStatusCode returns the HTTP status carried by an Azure DevOps error, or 0 if it doesn't carry any.
+func azureDevOpsStatusCode(err error) int {
+	var wrapped azuredevops.WrappedError
+	if errors.As(err, &wrapped) {
+		return ptr.Deref(wrapped.StatusCode, 0)
+	}
+
+	var wrappedPtr *azuredevops.WrappedError
+	if errors.As(err, &wrappedPtr) {
+		return ptr.Deref(wrappedPtr.StatusCode, 0)
+	}
+
+	return 0
+}
+
+func validatePage(page *api.PaginationRequest) error {
+	if page == nil {
+		return errors.New("page must not be empty")
+	}
+	if page.Size < -1 || page.Size > 100 {
+		return errors.New("page size must be >= -1 and <= 100")
+	}
+
+	return nil
+}
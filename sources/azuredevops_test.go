@@ -0,0 +1,288 @@
+package sources_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aserto-dev/go-grpc/aserto/api/v1"
+	"github.com/aserto-dev/scc-lib/errx"
+	"github.com/aserto-dev/scc-lib/internal/interactions"
+	"github.com/aserto-dev/scc-lib/sources"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/core"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/git"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/location"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/v7/taskagent"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"k8s.io/utils/ptr"
+)
+
+const (
+	azureDevOpsURL     = "https://dev.azure.com/aserto"
+	azureDevOpsProject = "policies"
+)
+
+func setupAzureDevOps(t *testing.T) (*interactions.MockAzureDevOpsIntr, sources.Source) {
+	ctrl := gomock.NewController(t)
+	mockAzureDevOps := interactions.NewMockAzureDevOpsIntr(ctrl)
+
+	p := sources.NewTestAzureDevOps(ctrl, &zerolog.Logger{}, &sources.Config{BaseURL: azureDevOpsURL}, func(ctx context.Context, organizationURL, token string, httpTimeout time.Duration) (interactions.AzureDevOpsIntr, error) {
+		return mockAzureDevOps, nil
+	})
+
+	return mockAzureDevOps, p
+}
+
+func azureDevOpsRepo(name string) git.GitRepository {
+	return git.GitRepository{
+		Name:          ptr.To(name),
+		WebUrl:        ptr.To("https://dev.azure.com/aserto/policies/_git/" + name),
+		DefaultBranch: ptr.To("refs/heads/" + defaultBranch),
+		Project:       &core.TeamProjectReference{Name: ptr.To(azureDevOpsProject)},
+	}
+}
+
+func TestAzureDevOpsPingUnauthorized(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockAzureDevOps, p := setupAzureDevOps(t)
+
+	// Expect
+	mockAzureDevOps.EXPECT().GetConnectionData(gomock.Any()).
+		Return(nil, &azuredevops.WrappedError{StatusCode: ptr.To(http.StatusUnauthorized)})
+
+	// Act
+	err := p.Ping(context.Background())
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestAzureDevOpsUsesHTTPTimeout(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockAzureDevOps := interactions.NewMockAzureDevOpsIntr(ctrl)
+	var timeout time.Duration
+	p := sources.NewTestAzureDevOps(ctrl, &zerolog.Logger{}, &sources.Config{BaseURL: azureDevOpsURL, HTTPTimeoutSeconds: 5}, func(ctx context.Context, organizationURL, token string, httpTimeout time.Duration) (interactions.AzureDevOpsIntr, error) {
+		timeout = httpTimeout
+		return mockAzureDevOps, nil
+	})
+
+	// Expect
+	mockAzureDevOps.EXPECT().GetConnectionData(gomock.Any()).Return(&location.ConnectionData{}, nil)
+
+	// Act
+	err := p.Ping(context.Background())
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(5*time.Second, timeout)
+}
+
+func TestAzureDevOpsPingUnreachable(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockAzureDevOps, p := setupAzureDevOps(t)
+
+	// Expect
+	mockAzureDevOps.EXPECT().GetConnectionData(gomock.Any()).Return(nil, errors.New("dial tcp: i/o timeout"))
+
+	// Act
+	err := p.Ping(context.Background())
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrProviderUnreachable.SameAs(err))
+}
+
+func TestAzureDevOpsValidateConnectionFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockAzureDevOps, p := setupAzureDevOps(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockAzureDevOps.EXPECT().GetConnectionData(gomock.Any()).Return(nil, errors.New("401 Unauthorized"))
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrProviderVerification.SameAs(err))
+}
+
+func TestAzureDevOpsListReposPage(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockAzureDevOps, p := setupAzureDevOps(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockAzureDevOps.EXPECT().GetRepositories(gomock.Any(), git.GetRepositoriesArgs{Project: ptr.To(azureDevOpsProject)}).
+		Return(&[]git.GitRepository{azureDevOpsRepo("other"), azureDevOpsRepo("policy"), azureDevOpsRepo("policy-2"), azureDevOpsRepo("policy-3")}, nil)
+
+	// Act
+	repos, page, err := p.ListRepos(context.Background(), token, azureDevOpsProject, &api.PaginationRequest{Size: 1, Token: "1"}, &sources.ListReposOptions{Filter: "pol"})
+
+	// Assert
+	assert.NoError(err)
+	assert.Len(repos, 1)
+	assert.Equal("policy-2", repos[0].Name)
+	assert.Equal(azureDevOpsProject, repos[0].Org)
+	assert.Equal("https://dev.azure.com/aserto/policies/_build", repos[0].CiUrl)
+	assert.Equal("2", page.NextToken)
+	assert.Equal(int32(3), page.TotalSize)
+}
+
+func TestAzureDevOpsAddSecretToRepoSecretExistsOverrideFalse(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockAzureDevOps, p := setupAzureDevOps(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockAzureDevOps.EXPECT().GetVariableGroups(gomock.Any(), gomock.Any()).Return(&[]taskagent.VariableGroup{{
+		Id:        ptr.To(1),
+		Variables: &map[string]interface{}{"ASERTO_PUSH_KEY": map[string]interface{}{"isSecret": true}},
+	}}, nil)
+
+	// Act
+	err := p.AddSecretToRepo(context.Background(), token, azureDevOpsProject, policyRepo, "ASERTO_PUSH_KEY", "value", false, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrRepoAlreadyConnected.SameAs(err))
+}
+
+func TestAzureDevOpsAddSecretToRepoCreatesGroup(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockAzureDevOps, p := setupAzureDevOps(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockAzureDevOps.EXPECT().GetVariableGroups(gomock.Any(), gomock.Any()).Return(&[]taskagent.VariableGroup{}, nil)
+	mockAzureDevOps.EXPECT().AddVariableGroup(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, args taskagent.AddVariableGroupArgs) error {
+			assert.Equal(policyRepo, *args.VariableGroupParameters.Name)
+			variable := (*args.VariableGroupParameters.Variables)["ASERTO_PUSH_KEY"].(taskagent.VariableValue)
+			assert.Equal("value", *variable.Value)
+			assert.True(*variable.IsSecret)
+
+			return nil
+		})
+
+	// Act
+	err := p.AddSecretToRepo(context.Background(), token, azureDevOpsProject, policyRepo, "ASERTO_PUSH_KEY", "value", false, nil)
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestAzureDevOpsCreateCommitOnBranch(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockAzureDevOps, p := setupAzureDevOps(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockAzureDevOps.EXPECT().GetRefs(gomock.Any(), gomock.Any()).
+		Return(&git.GetRefsResponseValue{Value: []git.GitRef{{Name: ptr.To("refs/heads/main"), ObjectId: ptr.To("head")}}}, nil)
+	mockAzureDevOps.EXPECT().GetItem(gomock.Any(), gomock.Any()).
+		Return(nil, &azuredevops.WrappedError{StatusCode: ptr.To(http.StatusNotFound)})
+	mockAzureDevOps.EXPECT().CreatePush(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, args git.CreatePushArgs) (*git.GitPush, error) {
+			assert.Equal("head", *(*args.Push.RefUpdates)[0].OldObjectId)
+			change := (*(*args.Push.Commits)[0].Changes)[0].(git.GitChange)
+			assert.Equal(git.VersionControlChangeTypeValues.Add, *change.ChangeType)
+
+			return &git.GitPush{Commits: &[]git.GitCommitRef{{CommitId: ptr.To("new")}}}, nil
+		})
+
+	// Act
+	sha, err := p.CreateCommitOnBranch(context.Background(), token, &sources.Commit{
+		Branch:  defaultBranch,
+		Message: "update",
+		Owner:   azureDevOpsProject,
+		Repo:    policyRepo,
+		Content: map[string]string{file: fileContent},
+	})
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("new", sha)
+}
+
+func TestAzureDevOpsCreateCommitOnBranchFailsToReadFile(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockAzureDevOps, p := setupAzureDevOps(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockAzureDevOps.EXPECT().GetRefs(gomock.Any(), gomock.Any()).
+		Return(&git.GetRefsResponseValue{Value: []git.GitRef{{Name: ptr.To("refs/heads/main"), ObjectId: ptr.To("head")}}}, nil)
+	mockAzureDevOps.EXPECT().GetItem(gomock.Any(), gomock.Any()).
+		Return(nil, &azuredevops.WrappedError{StatusCode: ptr.To(http.StatusInternalServerError)})
+
+	// Act
+	_, err := p.CreateCommitOnBranch(context.Background(), token, &sources.Commit{
+		Branch:  defaultBranch,
+		Message: "update",
+		Owner:   azureDevOpsProject,
+		Repo:    policyRepo,
+		Content: map[string]string{file: fileContent},
+	})
+
+	// Assert
+	assert.Error(err)
+}
+
+func TestAzureDevOpsIsRepoEmpty(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockAzureDevOps, p := setupAzureDevOps(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockAzureDevOps.EXPECT().GetRepository(gomock.Any(), gomock.Any()).Return(&git.GitRepository{Name: ptr.To(policyRepo)}, nil)
+
+	// Act
+	empty, err := p.IsRepoEmpty(context.Background(), token, azureDevOpsProject, policyRepo)
+
+	// Assert
+	assert.NoError(err)
+	assert.True(empty)
+}
+
+func TestAzureDevOpsGetFileContent(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockAzureDevOps, p := setupAzureDevOps(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockAzureDevOps.EXPECT().GetItem(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, args git.GetItemArgs) (*git.GitItem, error) {
+			assert.Equal(file, *args.Path)
+			assert.True(*args.IncludeContent)
+			assert.Equal(defaultBranch, *args.VersionDescriptor.Version)
+
+			return &git.GitItem{Content: ptr.To(fileContent)}, nil
+		})
+
+	// Act
+	content, exists, err := p.GetFileContent(context.Background(), token, azureDevOpsProject, policyRepo, file, defaultBranch)
+
+	// Assert
+	assert.NoError(err)
+	assert.True(exists)
+	assert.Equal(fileContent, content)
+}
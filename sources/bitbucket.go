@@ -0,0 +1,666 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aserto-dev/go-grpc/aserto/api/v1"
+	scc "github.com/aserto-dev/go-grpc/aserto/tenant/scc/v1"
+	"github.com/aserto-dev/scc-lib/errx"
+	"github.com/aserto-dev/scc-lib/internal/interactions"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+var (
+	_           Source = &bitbucketSource{}
+	bitbucketCI        = "/pipelines"
+)
+
+// bitbucketSource deals with source management on bitbucket.org.
+// Workspaces are the orgs of Bitbucket, and repository pipelines variables hold the secrets.
+type bitbucketSource struct {
+	logger           *zerolog.Logger
+	cfg              *Config
+	interactionsFunc interactions.BbIntr
+	// clients are the HTTP clients the source sends its requests through.
+	clients *httpClients
+}
+
+// Ping checks that the Bitbucket API answers. Without credentials, it replies to /user with a 401.
+func (b *bitbucketSource) Ping(ctx context.Context) (err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "Ping", "", "")(&err)
+
+	client := b.interactionsFunc(ctx, "")
+
+	_, response, err := client.CurrentUser(ctx)
+
+	statusCode := 0
+	var bitbucketErr *interactions.BitbucketError
+	if response != nil {
+		statusCode = response.StatusCode
+	} else if errors.As(err, &bitbucketErr) {
+		statusCode = bitbucketErr.StatusCode
+	}
+
+	return pingError("bitbucket", statusCode, err)
+}
+
+// Close closes the idle connections of the HTTP clients the source sends its requests through.
+func (b *bitbucketSource) Close() error {
+	b.clients.closeIdleConnections()
+	return nil
+}
+
+func (b *bitbucketSource) ValidateConnection(ctx context.Context, accessToken *AccessToken, requiredScopes []string) (err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "ValidateConnection", "", "")(&err)
+
+	client := b.interactionsFunc(ctx, accessToken.Token)
+
+	_, response, err := client.CurrentUser(ctx)
+	if err != nil {
+		return bitbucketVerificationError(err)
+	}
+
+	return b.validateScopes(response, requiredScopes)
+}
+
+func (b *bitbucketSource) validateScopes(response *http.Response, requiredScopes []string) error {
+	if len(requiredScopes) == 0 {
+		return nil
+	}
+
+	scopeSlice := strings.Split(response.Header.Get(oauthScopesHeader), ",")
+	missing, err := missingScopes(scopeSlice, requiredScopes)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return errx.ErrMissingScopes.
+			Err(&errx.MissingScopesError{Scopes: missing}).
+			Interface("provided-scopes", scopeSlice).
+			Interface("required-scopes", requiredScopes).
+			Msg("bitbucket access token is missing scopes")
+	}
+
+	return nil
+}
+
+// Preflight checks that the token is valid, has the required scopes, and can create repos in the workspace.
+// Bitbucket doesn't expose whether a workspace allows public repos, so the visibility check is always skipped.
+func (b *bitbucketSource) Preflight(ctx context.Context, accessToken *AccessToken, owner string, requiredScopes []string) (_ *PreflightResult, err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "Preflight", owner, "")(&err)
+
+	client := b.interactionsFunc(ctx, accessToken.Token)
+	result := &PreflightResult{}
+
+	user, response, err := client.CurrentUser(ctx)
+	if err != nil {
+		result.Connection = checkFromError(bitbucketVerificationError(err))
+		return result, nil
+	}
+
+	result.Connection = checkFromError(nil)
+	result.Scopes = checkFromError(b.validateScopes(response, requiredScopes))
+
+	// Users can always create repos in their personal workspace.
+	if user.Username == owner {
+		result.CreateRepo = checkFromError(nil)
+		return result, nil
+	}
+
+	permission, err := client.GetWorkspacePermission(ctx, owner)
+	if err != nil {
+		result.CreateRepo = checkFromError(errors.Wrapf(err, "failed to read permission on workspace '%s'", owner))
+		return result, nil
+	}
+
+	result.CreateRepo = checkFromError(nil)
+	if permission == "collaborator" {
+		result.CreateRepo = checkFromError(errx.ErrProviderVerification.Str("workspace", owner).Msg("workspace collaborators are not allowed to create repositories"))
+	}
+
+	return result, nil
+}
+
+func bitbucketVerificationError(err error) error {
+	var bbErr *interactions.BitbucketError
+	if errors.As(err, &bbErr) {
+		return errx.ErrProviderVerification.
+			Str("status", bbErr.Status).
+			Int("status-code", bbErr.StatusCode).
+			Str("bitbucket-response", bbErr.Body).
+			Msg("unexpected reply from Bitbucket")
+	}
+
+	return errors.Wrap(err, "failed to connect to Bitbucket")
+}
+
+func (b *bitbucketSource) Profile(ctx context.Context, accessToken *AccessToken) (_ string, _ []*scc.Repo, err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "Profile", "", "")(&err)
+
+	username, repos, _, err := b.profilePage(ctx, accessToken, &api.PaginationRequest{Size: -1})
+	return username, repos, err
+}
+
+// ProfilePage returns the username of the user that owns the token, and a page of the repos of its personal workspace.
+// A page size of -1 reads all the pages.
+func (b *bitbucketSource) ProfilePage(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest) (_ string, _ []*scc.Repo, _ *api.PaginationResponse, err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "ProfilePage", "", "")(&err)
+
+	return b.profilePage(ctx, accessToken, page)
+}
+
+// profilePage reads the profile page for Profile and ProfilePage, which each track the operation themselves.
+func (b *bitbucketSource) profilePage(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest) (_ string, _ []*scc.Repo, _ *api.PaginationResponse, err error) {
+	opt, err := bitbucketListOptions(page)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	client := b.interactionsFunc(ctx, accessToken.Token)
+
+	user, _, err := client.CurrentUser(ctx)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	repos, response, err := b.listRepos(ctx, client, user.Username, page.Size, opt)
+	return user.Username, repos, response, err
+}
+
+func (b *bitbucketSource) ListOrgs(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest, opts *ListOrgsOptions) (_ []*api.SccOrg, _ *api.PaginationResponse, err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "ListOrgs", "", "")(&err)
+
+	opt, err := bitbucketListOptions(page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client := b.interactionsFunc(ctx, accessToken.Token)
+
+	var orgs []*api.SccOrg
+	response, err := listBitbucketPages(ctx, page.Size, opt,
+		func() (*interactions.BitbucketPage[*interactions.BitbucketWorkspace], error) {
+			return client.ListWorkspaces(ctx, opt)
+		},
+		func(workspace *interactions.BitbucketWorkspace) {
+			orgs = append(orgs, &api.SccOrg{
+				Name: workspace.Name,
+				Id:   workspace.Slug,
+			})
+		})
+
+	return orgs, response, err
+}
+
+func (b *bitbucketSource) ListOrgMembers(ctx context.Context, accessToken *AccessToken, org string, page *api.PaginationRequest) (_ []*SccUser, _ *api.PaginationResponse, err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "ListOrgMembers", org, "")(&err)
+
+	opt, err := bitbucketListOptions(page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client := b.interactionsFunc(ctx, accessToken.Token)
+
+	var members []*SccUser
+	response, err := listBitbucketPages(ctx, page.Size, opt,
+		func() (*interactions.BitbucketPage[*interactions.BitbucketWorkspaceMember], error) {
+			return client.ListWorkspaceMembers(ctx, org, opt)
+		},
+		func(member *interactions.BitbucketWorkspaceMember) {
+			login := member.User.Username
+			if login == "" {
+				login = member.User.Nickname
+			}
+			members = append(members, &SccUser{
+				Login: login,
+				Name:  member.User.DisplayName,
+			})
+		})
+
+	return members, response, err
+}
+
+func (b *bitbucketSource) ListRepos(
+	ctx context.Context,
+	accessToken *AccessToken,
+	owner string,
+	page *api.PaginationRequest,
+	opts *ListReposOptions,
+) (_ []*scc.Repo, _ *api.PaginationResponse, err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "ListRepos", owner, "")(&err)
+
+	opt, err := bitbucketListOptions(page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if opts != nil && strings.TrimSpace(opts.Filter) != "" {
+		opt.Query = fmt.Sprintf("name ~ %q", strings.TrimSpace(opts.Filter))
+	}
+
+	client := b.interactionsFunc(ctx, accessToken.Token)
+
+	return b.listRepos(ctx, client, owner, page.Size, opt)
+}
+
+func (b *bitbucketSource) listRepos(
+	ctx context.Context,
+	client interactions.BitbucketIntr,
+	workspace string,
+	pageSize int32,
+	opt *interactions.BitbucketListOptions,
+) ([]*scc.Repo, *api.PaginationResponse, error) {
+	repos := []*scc.Repo{}
+	response, err := listBitbucketPages(ctx, pageSize, opt,
+		func() (*interactions.BitbucketPage[*interactions.BitbucketRepo], error) {
+			return client.ListRepos(ctx, workspace, opt)
+		},
+		func(repo *interactions.BitbucketRepo) {
+			repos = append(repos, b.sccRepo(workspace, repo))
+		})
+
+	return repos, response, err
+}
+
+func (b *bitbucketSource) GetRepo(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ *scc.Repo, err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "GetRepo", owner, repo)(&err)
+
+	client := b.interactionsFunc(ctx, accessToken.Token)
+
+	bbRepo, err := client.GetRepo(ctx, owner, repo)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get repo: %s/%s", owner, repo)
+	}
+
+	return b.sccRepo(owner, bbRepo), nil
+}
+
+func (b *bitbucketSource) RenameRepo(ctx context.Context, accessToken *AccessToken, owner, repo, newName string) (err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "RenameRepo", owner, repo)(&err)
+
+	client := b.interactionsFunc(ctx, accessToken.Token)
+
+	if _, err := client.RenameRepo(ctx, owner, repo, newName); err != nil {
+		statusCode := 0
+		var bbErr *interactions.BitbucketError
+		if errors.As(err, &bbErr) {
+			statusCode = bbErr.StatusCode
+		}
+		return errors.Wrapf(renameRepoError(err, statusCode, owner, newName), "failed to rename repo %s/%s", owner, repo)
+	}
+
+	return nil
+}
+
+func (b *bitbucketSource) GetRepoID(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ string, err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "GetRepoID", owner, repo)(&err)
+
+	client := b.interactionsFunc(ctx, accessToken.Token)
+
+	bbRepo, err := client.GetRepo(ctx, owner, repo)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get repo: %s/%s", owner, repo)
+	}
+
+	return bbRepo.UUID, nil
+}
+
+// CreateRepo creates a git repo in the workspace. Bitbucket can't initialize repos on creation,
+// so AutoInit commits a README on the main branch.
+func (b *bitbucketSource) CreateRepo(ctx context.Context, accessToken *AccessToken, owner, name string, opts *CreateRepoOptions) (_ *scc.Repo, err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "CreateRepo", owner, name)(&err)
+
+	client := b.interactionsFunc(ctx, accessToken.Token)
+
+	bbRepo := &interactions.BitbucketRepo{
+		Name: name,
+		SCM:  "git",
+	}
+
+	autoInit := false
+	if opts != nil {
+		bbRepo.IsPrivate = opts.Private
+		bbRepo.Description = opts.Description
+		autoInit = opts.AutoInit != nil && *opts.AutoInit
+	}
+
+	created, err := client.CreateRepo(ctx, owner, name, bbRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	if autoInit {
+		_, err = client.CreateCommit(ctx, owner, created.Slug, &interactions.BitbucketCommitOptions{
+			Message: "Initial commit",
+			Files:   map[string]string{"README.md": "# " + name + "\n"},
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to initialize repo: %s/%s", owner, created.Slug)
+		}
+	}
+
+	return b.sccRepo(owner, created), nil
+}
+
+// InitialTag creates a tag on the commit, or on the last commit when commitSha is empty. Bitbucket doesn't
+// report the pipeline the tag triggers, so the returned ID is always 0.
+func (b *bitbucketSource) InitialTag(ctx context.Context, accessToken *AccessToken, fullName, workflowFileName, commitSha, tagMessage string) (_ int64, err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "InitialTag", "", fullName)(&err)
+
+	client := b.interactionsFunc(ctx, accessToken.Token)
+
+	if strings.Count(fullName, "/") != 1 {
+		return 0, errors.Errorf("invalid full bitbucket repo name '%s', should be in the form workspace/repo", fullName)
+	}
+
+	owner, name, _ := strings.Cut(fullName, "/")
+
+	tags, err := client.ListTags(ctx, owner, name, &interactions.BitbucketListOptions{PageLen: 1})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(tags.Values) > 0 {
+		return 0, nil
+	}
+
+	if commitSha == "" {
+		commits, err := client.ListCommits(ctx, owner, name, "", &interactions.BitbucketListOptions{PageLen: 1})
+		if err != nil {
+			return 0, err
+		}
+		if len(commits.Values) == 0 {
+			return 0, errors.Wrapf(ErrEmptyRepo, "%s", fullName)
+		}
+		commitSha = commits.Values[0].Hash
+	}
+
+	return 0, client.CreateTag(ctx, owner, name, &interactions.BitbucketTag{
+		Name:    b.cfg.initialTag(),
+		Message: tagMessage,
+		Target:  interactions.BitbucketCommit{Hash: commitSha},
+	})
+}
+
+func (b *bitbucketSource) hasSecret(ctx context.Context, client interactions.BitbucketIntr, owner, repo, secretName string) (*interactions.BitbucketVariable, error) {
+	variables, err := client.ListVariables(ctx, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, variable := range variables {
+		if variable.Key == secretName {
+			return variable, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (b *bitbucketSource) HasSecret(ctx context.Context, token *AccessToken, owner, repo, secretName string, opts *SecretOptions) (_ bool, err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "HasSecret", owner, repo)(&err)
+
+	client := b.interactionsFunc(ctx, token.Token)
+
+	variable, err := b.hasSecret(ctx, client, owner, repo, secretName)
+	if err != nil {
+		return false, err
+	}
+
+	return variable != nil, nil
+}
+
+// GetSecret returns errx.ErrSecretWriteOnly: the repository variables set by AddSecretToRepo are secured, and
+// Bitbucket doesn't return their values.
+func (b *bitbucketSource) GetSecret(ctx context.Context, token *AccessToken, owner, repo, secretName string) (_ string, _ bool, err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "GetSecret", owner, repo)(&err)
+
+	return "", false, errx.ErrSecretWriteOnly.Msg("Bitbucket secured variables can't be read back")
+}
+
+func (b *bitbucketSource) AddSecretToRepo(ctx context.Context, token *AccessToken, orgName, repoName, secretName, value string, overrideSecret bool, opts *SecretOptions) (err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "AddSecretToRepo", orgName, repoName)(&err)
+
+	client := b.interactionsFunc(ctx, token.Token)
+
+	existing, err := b.hasSecret(ctx, client, orgName, repoName, secretName)
+	if err != nil {
+		return err
+	}
+
+	if !overrideSecret && existing != nil {
+		return errx.ErrRepoAlreadyConnected.Msg("you're trying to link to an existing repository that already has a secret. Please consider overwriting the Aserto push secret.").Str("repo", orgName+"/"+repoName)
+	}
+
+	variable := &interactions.BitbucketVariable{
+		Key:     secretName,
+		Value:   value,
+		Secured: true,
+	}
+
+	if existing != nil {
+		variable.UUID = existing.UUID
+		return client.UpdateVariable(ctx, orgName, repoName, variable)
+	}
+
+	return client.CreateVariable(ctx, orgName, repoName, variable)
+}
+
+// CreateCommitOnBranch commits the content on top of the branch head. Bitbucket applies the commit
+// to the current head, and commits asking for ConflictRebase are refused.
+func (b *bitbucketSource) CreateCommitOnBranch(ctx context.Context, accessToken *AccessToken, commit *Commit) (_ string, err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "CreateCommitOnBranch", commit.Owner, commit.Repo)(&err)
+
+	if err = checkConflictStrategy("bitbucket", commit); err != nil {
+		return "", err
+	}
+
+	client := b.interactionsFunc(ctx, accessToken.Token)
+
+	opt := &interactions.BitbucketCommitOptions{
+		Branch:  commit.Branch,
+		Message: commit.Message,
+		Files:   commit.Content,
+	}
+	if commit.Author != nil {
+		opt.Author = fmt.Sprintf("%s <%s>", commit.Author.Name, commit.Author.Email)
+	}
+
+	return client.CreateCommit(ctx, commit.Owner, commit.Repo, opt)
+}
+
+func (b *bitbucketSource) CreateFile(ctx context.Context, accessToken *AccessToken, owner, repo, path, content, branch, message string) (err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "CreateFile", owner, repo)(&err)
+
+	client := b.interactionsFunc(ctx, accessToken.Token)
+
+	_, err = client.CreateCommit(ctx, owner, repo, &interactions.BitbucketCommitOptions{
+		Branch:  branch,
+		Message: message,
+		Files:   map[string]string{path: content},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create file: %s", path)
+	}
+
+	return nil
+}
+
+// GetFileContent returns the content of a file at the given ref, or on the default branch when ref is empty.
+// The bool is false when the file doesn't exist.
+func (b *bitbucketSource) GetFileContent(ctx context.Context, accessToken *AccessToken, owner, repo, path, ref string) (_ string, _ bool, err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "GetFileContent", owner, repo)(&err)
+
+	client := b.interactionsFunc(ctx, accessToken.Token)
+
+	if ref == "" {
+		var err error
+		ref, err = b.GetDefaultBranch(ctx, accessToken, owner, repo)
+		if err != nil {
+			return "", false, err
+		}
+	}
+
+	content, err := client.GetFileContent(ctx, owner, repo, ref, path)
+	if err != nil {
+		var bbErr *interactions.BitbucketError
+		if errors.As(err, &bbErr) && bbErr.StatusCode == http.StatusNotFound {
+			return "", false, nil
+		}
+		return "", false, errors.Wrapf(err, "failed to get file: %s", path)
+	}
+
+	return content, true, nil
+}
+
+func (b *bitbucketSource) GetDefaultBranch(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ string, err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "GetDefaultBranch", owner, repo)(&err)
+
+	client := b.interactionsFunc(ctx, accessToken.Token)
+
+	bbRepo, err := client.GetRepo(ctx, owner, repo)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get repo: %s/%s", owner, repo)
+	}
+
+	if bbRepo.MainBranch == nil {
+		return "", errors.Wrapf(ErrEmptyRepo, "%s/%s", owner, repo)
+	}
+
+	return bbRepo.MainBranch.Name, nil
+}
+
+// IsRepoEmpty returns true if the repo has no commits yet.
+func (b *bitbucketSource) IsRepoEmpty(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ bool, err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "IsRepoEmpty", owner, repo)(&err)
+
+	client := b.interactionsFunc(ctx, accessToken.Token)
+
+	commits, err := client.ListCommits(ctx, owner, repo, "", &interactions.BitbucketListOptions{PageLen: 1})
+	if err != nil {
+		return false, err
+	}
+
+	return len(commits.Values) == 0, nil
+}
+
+// ListTags lists the tags of a repo, most recent first.
+func (b *bitbucketSource) ListTags(ctx context.Context, accessToken *AccessToken, owner, repo string, page *api.PaginationRequest) (_ []string, _ *api.PaginationResponse, err error) {
+	defer trackOperation(b.logger, b.cfg.Observer, "bitbucket", "ListTags", owner, repo)(&err)
+
+	opt, err := bitbucketListOptions(page)
+	if err != nil {
+		return nil, nil, err
+	}
+	opt.Sort = "-target.date"
+
+	client := b.interactionsFunc(ctx, accessToken.Token)
+
+	tags := []string{}
+	response, err := listBitbucketPages(ctx, page.Size, opt,
+		func() (*interactions.BitbucketPage[*interactions.BitbucketTag], error) {
+			return client.ListTags(ctx, owner, repo, opt)
+		},
+		func(tag *interactions.BitbucketTag) {
+			tags = append(tags, tag.Name)
+		})
+
+	return tags, response, err
+}
+
+func (b *bitbucketSource) sccRepo(workspace string, repo *interactions.BitbucketRepo) *scc.Repo {
+	var url string
+	if repo.Links != nil {
+		url = repo.Links.HTML.Href
+	}
+
+	return &scc.Repo{
+		Name:  repo.Slug,
+		Org:   workspace,
+		Url:   url,
+		CiUrl: b.cfg.ciURL(url, bitbucketCI),
+	}
+}
+
+// bitbucketListOptions validates the page request and returns the options to read it.
+func bitbucketListOptions(page *api.PaginationRequest) (*interactions.BitbucketListOptions, error) {
+	if page == nil {
+		return nil, errors.New("page must not be empty")
+	}
+	if page.Size < -1 || page.Size > 100 {
+		return nil, errors.New("page size must be >= -1 and <= 100")
+	}
+
+	opt := &interactions.BitbucketListOptions{Page: 1, PageLen: int(page.Size)}
+	if page.Size == -1 {
+		opt.PageLen = 100
+	}
+
+	if strings.TrimSpace(page.Token) != "" {
+		pageToRead, err := strconv.Atoi(page.Token)
+		if err != nil {
+			return nil, errors.Wrap(err, "page token must be int")
+		}
+		opt.Page = pageToRead
+	}
+
+	return opt, nil
+}
+
+// listBitbucketPages reads the page selected by opt, or all the pages when pageSize is -1, and passes each value to add.
+func listBitbucketPages[T any](
+	ctx context.Context,
+	pageSize int32,
+	opt *interactions.BitbucketListOptions,
+	list func() (*interactions.BitbucketPage[T], error),
+	add func(T),
+) (*api.PaginationResponse, error) {
+	count := 0
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		page, err := list()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, value := range page.Values {
+			add(value)
+		}
+		count += len(page.Values)
+
+		if pageSize != -1 {
+			nextToken := ""
+			if page.Next != "" {
+				nextToken = strconv.Itoa(opt.Page + 1)
+			}
+
+			return &api.PaginationResponse{
+				NextToken:  nextToken,
+				ResultSize: safeInt32(count),
+				TotalSize:  safeInt32(page.Size),
+			}, nil
+		}
+
+		if page.Next == "" {
+			break
+		}
+
+		opt.Page++
+	}
+
+	return &api.PaginationResponse{
+		NextToken:  "",
+		ResultSize: safeInt32(count),
+		TotalSize:  safeInt32(count),
+	}, nil
+}
@@ -0,0 +1,363 @@
+package sources_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aserto-dev/go-grpc/aserto/api/v1"
+	"github.com/aserto-dev/scc-lib/errx"
+	"github.com/aserto-dev/scc-lib/internal/interactions"
+	"github.com/aserto-dev/scc-lib/sources"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	bitbucketUsername  = "aserto"
+	bitbucketWorkspace = "aserto-dev"
+	bitbucketRepoURL   = "https://bitbucket.org/aserto-dev/policy"
+)
+
+func setupBitbucket(t *testing.T) (*interactions.MockBitbucketIntr, sources.Source) {
+	ctrl := gomock.NewController(t)
+	mockBitbucket := interactions.NewMockBitbucketIntr(ctrl)
+
+	p := sources.NewTestBitbucket(ctrl, &zerolog.Logger{}, &sources.Config{}, func(ctx context.Context, token string) interactions.BitbucketIntr {
+		return mockBitbucket
+	})
+
+	return mockBitbucket, p
+}
+
+func bitbucketRepo() *interactions.BitbucketRepo {
+	return &interactions.BitbucketRepo{
+		Slug:       policyRepo,
+		Name:       policyRepo,
+		Links:      &interactions.BitbucketLinks{HTML: interactions.BitbucketLink{Href: bitbucketRepoURL}},
+		MainBranch: &interactions.BitbucketBranch{Name: defaultBranch},
+	}
+}
+
+func TestBitbucketPing(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockBitbucket, p := setupBitbucket(t)
+
+	// Expect
+	mockBitbucket.EXPECT().CurrentUser(gomock.Any()).
+		Return(nil, &http.Response{StatusCode: http.StatusUnauthorized}, &interactions.BitbucketError{StatusCode: http.StatusUnauthorized, Status: "401 Unauthorized"})
+
+	// Act
+	err := p.Ping(context.Background())
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestBitbucketValidateConnectionErrorResponse(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockBitbucket, p := setupBitbucket(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockBitbucket.EXPECT().CurrentUser(gomock.Any()).Return(nil, nil, &interactions.BitbucketError{StatusCode: http.StatusUnauthorized, Status: "401 Unauthorized"})
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrProviderVerification.SameAs(err))
+}
+
+func TestBitbucketValidateConnectionMissingScopes(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockBitbucket, p := setupBitbucket(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	resp.Header.Set("X-OAuth-Scopes", "repository:write, account")
+
+	// Expect
+	mockBitbucket.EXPECT().CurrentUser(gomock.Any()).Return(&interactions.BitbucketUser{Username: bitbucketUsername}, resp, nil)
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{"repository:write", "pipeline:variable"})
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrMissingScopes.SameAs(err))
+	assert.Equal([]string{"pipeline:variable"}, errx.MissingScopes(err))
+}
+
+func TestBitbucketListOrgs(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockBitbucket, p := setupBitbucket(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	gomock.InOrder(
+		mockBitbucket.EXPECT().ListWorkspaces(gomock.Any(), gomock.Any()).Return(&interactions.BitbucketPage[*interactions.BitbucketWorkspace]{
+			Values: []*interactions.BitbucketWorkspace{{Slug: bitbucketWorkspace, Name: "Aserto"}},
+			Next:   "https://api.bitbucket.org/2.0/workspaces?page=2",
+		}, nil),
+		mockBitbucket.EXPECT().ListWorkspaces(gomock.Any(), gomock.Any()).Return(&interactions.BitbucketPage[*interactions.BitbucketWorkspace]{
+			Values: []*interactions.BitbucketWorkspace{{Slug: bitbucketUsername, Name: "Personal"}},
+		}, nil),
+	)
+
+	// Act
+	orgs, page, err := p.ListOrgs(context.Background(), token, &api.PaginationRequest{Size: -1}, nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.Len(orgs, 2)
+	assert.Equal(bitbucketWorkspace, orgs[0].Id)
+	assert.Equal("Aserto", orgs[0].Name)
+	assert.Equal(int32(2), page.ResultSize)
+	assert.Equal("", page.NextToken)
+}
+
+func TestBitbucketListReposPage(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockBitbucket, p := setupBitbucket(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockBitbucket.EXPECT().ListRepos(gomock.Any(), bitbucketWorkspace, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, opt *interactions.BitbucketListOptions) (*interactions.BitbucketPage[*interactions.BitbucketRepo], error) {
+			assert.Equal(2, opt.Page)
+			assert.Equal(10, opt.PageLen)
+			assert.Equal(`name ~ "pol"`, opt.Query)
+
+			return &interactions.BitbucketPage[*interactions.BitbucketRepo]{
+				Values: []*interactions.BitbucketRepo{bitbucketRepo()},
+				Size:   11,
+				Next:   "https://api.bitbucket.org/2.0/repositories/aserto-dev?page=3",
+			}, nil
+		})
+
+	// Act
+	repos, page, err := p.ListRepos(context.Background(), token, bitbucketWorkspace, &api.PaginationRequest{Size: 10, Token: "2"}, &sources.ListReposOptions{Filter: "pol"})
+
+	// Assert
+	assert.NoError(err)
+	assert.Len(repos, 1)
+	assert.Equal(policyRepo, repos[0].Name)
+	assert.Equal(bitbucketWorkspace, repos[0].Org)
+	assert.Equal(bitbucketRepoURL+"/pipelines", repos[0].CiUrl)
+	assert.Equal("3", page.NextToken)
+	assert.Equal(int32(11), page.TotalSize)
+}
+
+func TestBitbucketAddSecretToRepoSecretExistsOverrideFalse(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockBitbucket, p := setupBitbucket(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockBitbucket.EXPECT().ListVariables(gomock.Any(), bitbucketWorkspace, policyRepo).Return([]*interactions.BitbucketVariable{{UUID: "{1}", Key: "ASERTO_PUSH_KEY"}}, nil)
+
+	// Act
+	err := p.AddSecretToRepo(context.Background(), token, bitbucketWorkspace, policyRepo, "ASERTO_PUSH_KEY", "value", false, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrRepoAlreadyConnected.SameAs(err))
+}
+
+func TestBitbucketAddSecretToRepoSecretExistsOverrideTrue(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockBitbucket, p := setupBitbucket(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockBitbucket.EXPECT().ListVariables(gomock.Any(), bitbucketWorkspace, policyRepo).Return([]*interactions.BitbucketVariable{{UUID: "{1}", Key: "ASERTO_PUSH_KEY"}}, nil)
+	mockBitbucket.EXPECT().UpdateVariable(gomock.Any(), bitbucketWorkspace, policyRepo, &interactions.BitbucketVariable{
+		UUID:    "{1}",
+		Key:     "ASERTO_PUSH_KEY",
+		Value:   "value",
+		Secured: true,
+	}).Return(nil)
+
+	// Act
+	err := p.AddSecretToRepo(context.Background(), token, bitbucketWorkspace, policyRepo, "ASERTO_PUSH_KEY", "value", true, nil)
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestBitbucketAddSecretToRepoCreate(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockBitbucket, p := setupBitbucket(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockBitbucket.EXPECT().ListVariables(gomock.Any(), bitbucketWorkspace, policyRepo).Return(nil, nil)
+	mockBitbucket.EXPECT().CreateVariable(gomock.Any(), bitbucketWorkspace, policyRepo, &interactions.BitbucketVariable{
+		Key:     "ASERTO_PUSH_KEY",
+		Value:   "value",
+		Secured: true,
+	}).Return(nil)
+
+	// Act
+	err := p.AddSecretToRepo(context.Background(), token, bitbucketWorkspace, policyRepo, "ASERTO_PUSH_KEY", "value", false, nil)
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestBitbucketCreateRepoAutoInit(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockBitbucket, p := setupBitbucket(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	autoInit := true
+
+	// Expect
+	mockBitbucket.EXPECT().CreateRepo(gomock.Any(), bitbucketWorkspace, policyRepo, &interactions.BitbucketRepo{
+		Name:      policyRepo,
+		SCM:       "git",
+		IsPrivate: true,
+	}).Return(bitbucketRepo(), nil)
+	mockBitbucket.EXPECT().CreateCommit(gomock.Any(), bitbucketWorkspace, policyRepo, gomock.Any()).Return("abc", nil)
+
+	// Act
+	repo, err := p.CreateRepo(context.Background(), token, bitbucketWorkspace, policyRepo, &sources.CreateRepoOptions{Private: true, AutoInit: &autoInit})
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(policyRepo, repo.Name)
+	assert.Equal(bitbucketRepoURL, repo.Url)
+}
+
+func TestBitbucketInitialTag(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockBitbucket, p := setupBitbucket(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockBitbucket.EXPECT().ListTags(gomock.Any(), bitbucketWorkspace, policyRepo, gomock.Any()).Return(&interactions.BitbucketPage[*interactions.BitbucketTag]{}, nil)
+	mockBitbucket.EXPECT().ListCommits(gomock.Any(), bitbucketWorkspace, policyRepo, "", gomock.Any()).Return(&interactions.BitbucketPage[*interactions.BitbucketCommit]{
+		Values: []*interactions.BitbucketCommit{{Hash: "abc"}},
+	}, nil)
+	mockBitbucket.EXPECT().CreateTag(gomock.Any(), bitbucketWorkspace, policyRepo, &interactions.BitbucketTag{
+		Name:   *sources.DefaultTag(),
+		Target: interactions.BitbucketCommit{Hash: "abc"},
+	}).Return(nil)
+
+	// Act
+	_, err := p.InitialTag(context.Background(), token, bitbucketWorkspace+"/"+policyRepo, "", "", "")
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestBitbucketGetDefaultBranchEmptyRepo(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockBitbucket, p := setupBitbucket(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockBitbucket.EXPECT().GetRepo(gomock.Any(), bitbucketWorkspace, policyRepo).Return(&interactions.BitbucketRepo{Slug: policyRepo}, nil)
+
+	// Act
+	_, err := p.GetDefaultBranch(context.Background(), token, bitbucketWorkspace, policyRepo)
+
+	// Assert
+	assert.ErrorIs(err, sources.ErrEmptyRepo)
+}
+
+func TestBitbucketGetRepoObservesError(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockBitbucket := interactions.NewMockBitbucketIntr(ctrl)
+	observer := &recordingObserver{}
+	p := sources.NewTestBitbucket(ctrl, &zerolog.Logger{}, &sources.Config{Observer: observer}, func(ctx context.Context, token string) interactions.BitbucketIntr {
+		return mockBitbucket
+	})
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockBitbucket.EXPECT().GetRepo(gomock.Any(), bitbucketWorkspace, policyRepo).Return(nil, &interactions.BitbucketError{StatusCode: http.StatusInternalServerError, Status: "500 Internal Server Error"})
+
+	// Act
+	_, err := p.GetRepo(context.Background(), token, bitbucketWorkspace, policyRepo)
+
+	// Assert
+	assert.Error(err)
+	assert.Len(observer.calls, 1)
+	assert.Equal(observedCall{provider: "bitbucket", operation: "GetRepo", err: err}, observer.calls[0])
+}
+
+func TestBitbucketProfileObservedOnce(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockBitbucket := interactions.NewMockBitbucketIntr(ctrl)
+	observer := &recordingObserver{}
+	p := sources.NewTestBitbucket(ctrl, &zerolog.Logger{}, &sources.Config{Observer: observer}, func(ctx context.Context, token string) interactions.BitbucketIntr {
+		return mockBitbucket
+	})
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockBitbucket.EXPECT().CurrentUser(gomock.Any()).Return(nil, nil, &interactions.BitbucketError{StatusCode: http.StatusUnauthorized, Status: "401 Unauthorized"})
+
+	// Act
+	_, _, err := p.Profile(context.Background(), token)
+
+	// Assert
+	assert.Error(err)
+	assert.Equal([]observedCall{{provider: "bitbucket", operation: "Profile", err: err}}, observer.calls)
+}
+
+func TestBitbucketGetRepoID(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockBitbucket, p := setupBitbucket(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	repo := bitbucketRepo()
+	repo.UUID = "{5f3c2b1a-0000-4000-8000-000000000000}"
+
+	// Expect
+	mockBitbucket.EXPECT().GetRepo(gomock.Any(), bitbucketWorkspace, policyRepo).Return(repo, nil)
+
+	// Act
+	id, err := p.GetRepoID(context.Background(), token, bitbucketWorkspace, policyRepo)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("{5f3c2b1a-0000-4000-8000-000000000000}", id)
+}
+
+func TestBitbucketGetFileContentMissing(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockBitbucket, p := setupBitbucket(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockBitbucket.EXPECT().GetRepo(gomock.Any(), bitbucketWorkspace, policyRepo).Return(bitbucketRepo(), nil)
+	mockBitbucket.EXPECT().GetFileContent(gomock.Any(), bitbucketWorkspace, policyRepo, defaultBranch, file).
+		Return("", &interactions.BitbucketError{StatusCode: http.StatusNotFound, Status: "404 Not Found"})
+
+	// Act
+	content, exists, err := p.GetFileContent(context.Background(), token, bitbucketWorkspace, policyRepo, file, "")
+
+	// Assert
+	assert.NoError(err)
+	assert.False(exists)
+	assert.Empty(content)
+}
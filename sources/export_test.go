@@ -1,5 +1,15 @@
 package sources
 
+import "net/http"
+
 func DefaultTag() *string {
 	return &defaultTag
 }
+
+func SafeInt32(n int) int32 {
+	return safeInt32(n)
+}
+
+func OwnHTTPClient(base *http.Client) *http.Client {
+	return ownHTTPClient(base)
+}
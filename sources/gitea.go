@@ -0,0 +1,829 @@
+package sources
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/aserto-dev/go-grpc/aserto/api/v1"
+	scc "github.com/aserto-dev/go-grpc/aserto/tenant/scc/v1"
+	"github.com/aserto-dev/scc-lib/errx"
+	"github.com/aserto-dev/scc-lib/internal/interactions"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const totalCountHeader = "X-Total-Count"
+
+var (
+	_       Source = &giteaSource{}
+	giteaCI        = "/actions"
+)
+
+// giteaSource deals with source management on self-hosted Gitea and Forgejo instances, at Config.BaseURL.
+type giteaSource struct {
+	logger           *zerolog.Logger
+	cfg              *Config
+	interactionsFunc interactions.GtIntr
+	// clients are the HTTP clients the source sends its requests through.
+	clients *httpClients
+}
+
+func (g *giteaSource) client(ctx context.Context, accessToken *AccessToken) (interactions.GiteaIntr, error) {
+	client, err := g.interactionsFunc(ctx, g.cfg.BaseURL, accessToken.Token)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Gitea client")
+	}
+
+	return client, nil
+}
+
+// Ping checks that the Gitea server answers.
+func (g *giteaSource) Ping(ctx context.Context) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "Ping", "", "")(&err)
+
+	client, err := g.client(ctx, &AccessToken{})
+	if err != nil {
+		return pingError("gitea", 0, err)
+	}
+
+	_, response, err := client.ServerVersion()
+
+	statusCode := 0
+	if response != nil && response.Response != nil {
+		statusCode = response.StatusCode
+	}
+
+	return pingError("gitea", statusCode, err)
+}
+
+// Close closes the idle connections of the HTTP clients the source sends its requests through.
+func (g *giteaSource) Close() error {
+	g.clients.closeIdleConnections()
+	return nil
+}
+
+// ValidateConnection checks that the token is valid. Gitea doesn't report the scopes of a token, so they aren't checked.
+func (g *giteaSource) ValidateConnection(ctx context.Context, accessToken *AccessToken, requiredScopes []string) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "ValidateConnection", "", "")(&err)
+
+	client, err := g.client(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	_, response, err := client.GetMyUserInfo()
+	if err != nil {
+		return errors.Wrap(err, "failed to connect to Gitea")
+	}
+
+	if response == nil || response.Response == nil {
+		return errx.ErrProviderVerification.Msg("no reply from Gitea")
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return errx.ErrProviderVerification.
+			Str("status", response.Status).
+			Int("status-code", response.StatusCode).
+			Msg("unexpected reply from Gitea")
+	}
+
+	return nil
+}
+
+// Preflight checks that the token is valid and can create repos for the owner.
+// Gitea doesn't report the scopes of a token nor restrict public repos, so those checks are always skipped.
+func (g *giteaSource) Preflight(ctx context.Context, accessToken *AccessToken, owner string, requiredScopes []string) (_ *PreflightResult, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "Preflight", owner, "")(&err)
+
+	client, err := g.client(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PreflightResult{}
+
+	user, response, err := client.GetMyUserInfo()
+	if err != nil {
+		result.Connection = checkFromError(errors.Wrap(err, "failed to connect to Gitea"))
+		return result, nil
+	}
+
+	if response == nil || response.Response == nil {
+		result.Connection = checkFromError(errx.ErrProviderVerification.Msg("no reply from Gitea"))
+		return result, nil
+	}
+
+	if response.StatusCode != http.StatusOK {
+		result.Connection = checkFromError(errx.ErrProviderVerification.
+			Str("status", response.Status).
+			Int("status-code", response.StatusCode).
+			Msg("unexpected reply from Gitea"))
+		return result, nil
+	}
+
+	result.Connection = checkFromError(nil)
+
+	if owner == user.UserName {
+		result.CreateRepo = checkFromError(nil)
+		return result, nil
+	}
+
+	permissions, err := client.GetOrgPermissions(owner, user.UserName)
+	if err != nil {
+		result.CreateRepo = checkFromError(errors.Wrapf(err, "failed to get permissions on org '%s'", owner))
+		return result, nil
+	}
+
+	result.CreateRepo = checkFromError(nil)
+	if !permissions.CanCreateRepository {
+		result.CreateRepo = checkFromError(errx.ErrProviderVerification.Str("org", owner).Msg("user is not allowed to create repositories in org"))
+	}
+
+	return result, nil
+}
+
+func (g *giteaSource) Profile(ctx context.Context, accessToken *AccessToken) (_ string, _ []*scc.Repo, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "Profile", "", "")(&err)
+
+	username, repos, _, err := g.profilePage(ctx, accessToken, &api.PaginationRequest{Size: -1})
+	return username, repos, err
+}
+
+// ProfilePage returns the username of the user that owns the token, and a page of the repos it owns.
+// A page size of -1 reads all the pages.
+func (g *giteaSource) ProfilePage(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest) (_ string, _ []*scc.Repo, _ *api.PaginationResponse, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "ProfilePage", "", "")(&err)
+
+	return g.profilePage(ctx, accessToken, page)
+}
+
+// profilePage reads the profile page for Profile and ProfilePage, which each track the operation themselves.
+func (g *giteaSource) profilePage(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest) (_ string, _ []*scc.Repo, _ *api.PaginationResponse, err error) {
+	listOpt, err := giteaListOptions(page)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	client, err := g.client(ctx, accessToken)
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	user, _, err := client.GetMyUserInfo()
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	repos, response, err := g.listPagedRepos(ctx, user.UserName, page.Size, &listOpt, func() ([]*gitea.Repository, *gitea.Response, error) {
+		return client.ListUserRepos(user.UserName, gitea.ListReposOptions{ListOptions: listOpt})
+	})
+
+	return user.UserName, repos, response, err
+}
+
+func (g *giteaSource) ListOrgs(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest, opts *ListOrgsOptions) (_ []*api.SccOrg, _ *api.PaginationResponse, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "ListOrgs", "", "")(&err)
+
+	listOpt, err := giteaListOptions(page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := g.client(ctx, accessToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var orgs []*api.SccOrg
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return orgs, nil, err
+		}
+
+		giteaOrgs, resp, err := client.ListMyOrgs(gitea.ListOrgsOptions{ListOptions: listOpt})
+		if err != nil {
+			return orgs, nil, err
+		}
+
+		for _, org := range giteaOrgs {
+			name := org.FullName
+			if name == "" {
+				name = org.UserName
+			}
+			orgs = append(orgs, &api.SccOrg{
+				Name: name,
+				Id:   org.UserName,
+			})
+		}
+
+		if page.Size != -1 {
+			return orgs, giteaPageResponse(resp, len(orgs)), nil
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+
+		listOpt.Page = resp.NextPage
+	}
+
+	response := &api.PaginationResponse{
+		NextToken:  "",
+		ResultSize: safeInt32(len(orgs)),
+		TotalSize:  safeInt32(len(orgs)),
+	}
+	return orgs, response, nil
+}
+
+func (g *giteaSource) ListOrgMembers(ctx context.Context, accessToken *AccessToken, org string, page *api.PaginationRequest) (_ []*SccUser, _ *api.PaginationResponse, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "ListOrgMembers", org, "")(&err)
+
+	listOpt, err := giteaListOptions(page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := g.client(ctx, accessToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var members []*SccUser
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return members, nil, err
+		}
+
+		users, resp, err := client.ListOrgMembership(org, gitea.ListOrgMembershipOption{ListOptions: listOpt})
+		if err != nil {
+			return members, nil, errors.Wrapf(err, "failed to list members of org '%s'", org)
+		}
+
+		for _, user := range users {
+			members = append(members, &SccUser{
+				Login: user.UserName,
+				Name:  user.FullName,
+				Email: user.Email,
+			})
+		}
+
+		if page.Size != -1 {
+			return members, giteaPageResponse(resp, len(members)), nil
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+
+		listOpt.Page = resp.NextPage
+	}
+
+	response := &api.PaginationResponse{
+		NextToken:  "",
+		ResultSize: safeInt32(len(members)),
+		TotalSize:  safeInt32(len(members)),
+	}
+	return members, response, nil
+}
+
+func (g *giteaSource) ListRepos(
+	ctx context.Context,
+	accessToken *AccessToken,
+	owner string,
+	page *api.PaginationRequest,
+	opts *ListReposOptions,
+) (_ []*scc.Repo, _ *api.PaginationResponse, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "ListRepos", owner, "")(&err)
+
+	listOpt, err := giteaListOptions(page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := g.client(ctx, accessToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, _, err := client.GetMyUserInfo()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if opts != nil && strings.TrimSpace(opts.Filter) != "" {
+		ownerID := user.ID
+		if owner != user.UserName {
+			org, err := client.GetOrg(owner)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "failed to get org '%s'", owner)
+			}
+			ownerID = org.ID
+		}
+
+		searchOpt := gitea.SearchRepoOptions{Keyword: strings.TrimSpace(opts.Filter), OwnerID: ownerID}
+		return g.listPagedRepos(ctx, owner, page.Size, &listOpt, func() ([]*gitea.Repository, *gitea.Response, error) {
+			searchOpt.ListOptions = listOpt
+			return client.SearchRepos(searchOpt)
+		})
+	}
+
+	if owner == user.UserName {
+		return g.listPagedRepos(ctx, owner, page.Size, &listOpt, func() ([]*gitea.Repository, *gitea.Response, error) {
+			return client.ListUserRepos(owner, gitea.ListReposOptions{ListOptions: listOpt})
+		})
+	}
+
+	return g.listPagedRepos(ctx, owner, page.Size, &listOpt, func() ([]*gitea.Repository, *gitea.Response, error) {
+		return client.ListOrgRepos(owner, gitea.ListOrgReposOptions{ListOptions: listOpt})
+	})
+}
+
+func (g *giteaSource) listPagedRepos(
+	ctx context.Context,
+	owner string,
+	pageSize int32,
+	opt *gitea.ListOptions,
+	lrFunc func() ([]*gitea.Repository, *gitea.Response, error),
+) ([]*scc.Repo, *api.PaginationResponse, error) {
+	repos := []*scc.Repo{}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return repos, nil, err
+		}
+
+		giteaRepos, resp, err := lrFunc()
+		if err != nil {
+			return repos, nil, err
+		}
+
+		for _, repo := range giteaRepos {
+			repos = append(repos, g.sccRepo(owner, repo))
+		}
+
+		if pageSize != -1 {
+			return repos, giteaPageResponse(resp, len(repos)), nil
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	response := &api.PaginationResponse{
+		NextToken:  "",
+		ResultSize: safeInt32(len(repos)),
+		TotalSize:  safeInt32(len(repos)),
+	}
+	return repos, response, nil
+}
+
+func (g *giteaSource) GetRepo(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ *scc.Repo, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "GetRepo", owner, repo)(&err)
+
+	client, err := g.client(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	giteaRepo, err := client.GetRepo(owner, repo)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get repo: %s/%s", owner, repo)
+	}
+
+	return g.sccRepo(owner, giteaRepo), nil
+}
+
+func (g *giteaSource) RenameRepo(ctx context.Context, accessToken *AccessToken, owner, repo, newName string) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "RenameRepo", owner, repo)(&err)
+
+	client, err := g.client(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	_, resp, err := client.EditRepo(owner, repo, gitea.EditRepoOption{Name: &newName})
+	if err != nil {
+		statusCode := 0
+		if resp != nil && resp.Response != nil {
+			statusCode = resp.StatusCode
+		}
+		return errors.Wrapf(renameRepoError(err, statusCode, owner, newName), "failed to rename repo %s/%s", owner, repo)
+	}
+
+	return nil
+}
+
+func (g *giteaSource) GetRepoID(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "GetRepoID", owner, repo)(&err)
+
+	client, err := g.client(ctx, accessToken)
+	if err != nil {
+		return "", err
+	}
+
+	giteaRepo, err := client.GetRepo(owner, repo)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get repo: %s/%s", owner, repo)
+	}
+
+	return strconv.FormatInt(giteaRepo.ID, 10), nil
+}
+
+func (g *giteaSource) CreateRepo(ctx context.Context, accessToken *AccessToken, owner, name string, opts *CreateRepoOptions) (_ *scc.Repo, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "CreateRepo", owner, name)(&err)
+
+	client, err := g.client(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, _, err := client.GetMyUserInfo()
+	if err != nil {
+		return nil, err
+	}
+
+	opt := gitea.CreateRepoOption{Name: name}
+	if opts != nil {
+		opt.Private = opts.Private
+		opt.Description = opts.Description
+		opt.AutoInit = opts.AutoInit != nil && *opts.AutoInit
+	}
+
+	var giteaRepo *gitea.Repository
+	if owner == user.UserName {
+		giteaRepo, err = client.CreateRepo(opt)
+	} else {
+		giteaRepo, err = client.CreateOrgRepo(owner, opt)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return g.sccRepo(owner, giteaRepo), nil
+}
+
+// InitialTag creates a tag on the commit, or on the default branch when commitSha is empty. Gitea doesn't
+// report the workflow run the tag triggers, so the returned ID is always 0.
+func (g *giteaSource) InitialTag(ctx context.Context, accessToken *AccessToken, fullName, workflowFileName, commitSha, tagMessage string) (_ int64, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "InitialTag", "", fullName)(&err)
+
+	client, err := g.client(ctx, accessToken)
+	if err != nil {
+		return 0, err
+	}
+
+	if strings.Count(fullName, "/") != 1 {
+		return 0, errors.Errorf("invalid full gitea repo name '%s', should be in the form owner/repo", fullName)
+	}
+
+	owner, name, _ := strings.Cut(fullName, "/")
+
+	tags, _, err := client.ListRepoTags(owner, name, gitea.ListRepoTagsOptions{ListOptions: gitea.ListOptions{Page: 1, PageSize: 1}})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(tags) > 0 {
+		return 0, nil
+	}
+
+	if commitSha == "" {
+		giteaRepo, err := client.GetRepo(owner, name)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to get repo: %s", fullName)
+		}
+		commitSha = giteaRepo.DefaultBranch
+	}
+
+	tag := g.cfg.initialTag()
+
+	return 0, client.CreateTag(owner, name, gitea.CreateTagOption{
+		TagName: tag,
+		Message: tagMessageOrName(tag, tagMessage),
+		Target:  commitSha,
+	})
+}
+
+// hasSecret returns true if the repo has an actions secret with the given name. Gitea stores secret names in upper case.
+func (g *giteaSource) hasSecret(client interactions.GiteaIntr, owner, repo, secretName string) (bool, error) {
+	opt := gitea.ListRepoActionSecretOption{ListOptions: gitea.ListOptions{Page: 1, PageSize: 50}}
+
+	for {
+		secrets, resp, err := client.ListRepoActionSecrets(owner, repo, opt)
+		if err != nil {
+			return false, err
+		}
+
+		for _, secret := range secrets {
+			if strings.EqualFold(secret.Name, secretName) {
+				return true, nil
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return false, nil
+		}
+
+		opt.Page = resp.NextPage
+	}
+}
+
+func (g *giteaSource) HasSecret(ctx context.Context, token *AccessToken, owner, repo, secretName string, opts *SecretOptions) (_ bool, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "HasSecret", owner, repo)(&err)
+
+	client, err := g.client(ctx, token)
+	if err != nil {
+		return false, err
+	}
+
+	return g.hasSecret(client, owner, repo, secretName)
+}
+
+// GetSecret returns errx.ErrSecretWriteOnly: Gitea Actions secrets can't be read back.
+func (g *giteaSource) GetSecret(ctx context.Context, token *AccessToken, owner, repo, secretName string) (_ string, _ bool, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "GetSecret", owner, repo)(&err)
+
+	return "", false, errx.ErrSecretWriteOnly.Msg("Gitea Actions secrets can't be read back")
+}
+
+func (g *giteaSource) AddSecretToRepo(ctx context.Context, token *AccessToken, orgName, repoName, secretName, value string, overrideSecret bool, opts *SecretOptions) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "AddSecretToRepo", orgName, repoName)(&err)
+
+	client, err := g.client(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	hasSecret, err := g.hasSecret(client, orgName, repoName, secretName)
+	if err != nil {
+		return err
+	}
+
+	if !overrideSecret && hasSecret {
+		return errx.ErrRepoAlreadyConnected.Msg("you're trying to link to an existing repository that already has a secret. Please consider overwriting the Aserto push secret.").Str("repo", orgName+"/"+repoName)
+	}
+
+	// Gitea creates the secret, or updates it when it already exists.
+	return client.CreateRepoActionSecret(orgName, repoName, gitea.CreateSecretOption{
+		Name: secretName,
+		Data: value,
+	})
+}
+
+// CreateCommitOnBranch writes the files of the commit to its branch in a single commit. Gitea refuses the commit when
+// one of the files it updates changed since it was read, which is returned as errx.ErrConcurrentUpdate.
+func (g *giteaSource) CreateCommitOnBranch(ctx context.Context, accessToken *AccessToken, commit *Commit) (_ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "CreateCommitOnBranch", commit.Owner, commit.Repo)(&err)
+
+	if err = checkConflictStrategy("gitea", commit); err != nil {
+		return "", err
+	}
+
+	client, err := g.client(ctx, accessToken)
+	if err != nil {
+		return "", err
+	}
+
+	paths := make([]string, 0, len(commit.Content))
+	for filePath := range commit.Content {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+
+	opt := &interactions.GiteaChangeFilesOptions{
+		Message: commit.Message,
+		Branch:  commit.Branch,
+	}
+	if commit.Author != nil {
+		opt.Author = &gitea.Identity{Name: commit.Author.Name, Email: commit.Author.Email}
+	}
+
+	for _, filePath := range paths {
+		file := &interactions.GiteaFileOperation{
+			Operation: "create",
+			Path:      filePath,
+			Content:   base64.StdEncoding.EncodeToString([]byte(commit.Content[filePath])),
+		}
+
+		existing, resp, err := client.GetContents(commit.Owner, commit.Repo, commit.Branch, filePath)
+		switch {
+		case err == nil && existing != nil:
+			file.Operation = "update"
+			file.SHA = existing.SHA
+		case err != nil && !isGiteaNotFound(resp):
+			return "", errors.Wrapf(err, "failed to get file: %s", filePath)
+		}
+
+		opt.Files = append(opt.Files, file)
+	}
+
+	sha, err := client.ChangeFiles(commit.Owner, commit.Repo, opt)
+	if err != nil {
+		var giteaErr *interactions.GiteaError
+		if errors.As(err, &giteaErr) && giteaErr.StatusCode == http.StatusConflict {
+			return "", errx.ErrConcurrentUpdate.Err(errors.Wrap(err, "failed to create commit")).
+				Str("branch", commit.Branch).
+				Msgf("branch [%s] of %s/%s changed, retry the commit", commit.Branch, commit.Owner, commit.Repo)
+		}
+
+		return "", errors.Wrap(err, "failed to create commit")
+	}
+
+	return sha, nil
+}
+
+func (g *giteaSource) CreateFile(ctx context.Context, accessToken *AccessToken, owner, repo, path, content, branch, message string) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "CreateFile", owner, repo)(&err)
+
+	client, err := g.client(ctx, accessToken)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.CreateFile(owner, repo, path, gitea.CreateFileOptions{
+		FileOptions: gitea.FileOptions{
+			Message:    message,
+			BranchName: branch,
+		},
+		Content: base64.StdEncoding.EncodeToString([]byte(content)),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create file: %s", path)
+	}
+
+	return nil
+}
+
+// GetFileContent returns the content of a file at the given ref, or on the default branch when ref is empty.
+// The bool is false when the file doesn't exist.
+func (g *giteaSource) GetFileContent(ctx context.Context, accessToken *AccessToken, owner, repo, path, ref string) (_ string, _ bool, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "GetFileContent", owner, repo)(&err)
+
+	client, err := g.client(ctx, accessToken)
+	if err != nil {
+		return "", false, err
+	}
+
+	file, resp, err := client.GetContents(owner, repo, ref, path)
+	if err != nil {
+		if isGiteaNotFound(resp) {
+			return "", false, nil
+		}
+		return "", false, errors.Wrapf(err, "failed to get file: %s", path)
+	}
+
+	if file.Type != "file" || file.Content == nil {
+		return "", false, errors.Errorf("'%s' is not a file", path)
+	}
+
+	content, err := base64.StdEncoding.DecodeString(*file.Content)
+	if err != nil {
+		return "", false, errors.Wrapf(err, "failed to decode file: %s", path)
+	}
+
+	return string(content), true, nil
+}
+
+func (g *giteaSource) GetDefaultBranch(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "GetDefaultBranch", owner, repo)(&err)
+
+	client, err := g.client(ctx, accessToken)
+	if err != nil {
+		return "", err
+	}
+
+	giteaRepo, err := client.GetRepo(owner, repo)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get repo: %s/%s", owner, repo)
+	}
+
+	return giteaRepo.DefaultBranch, nil
+}
+
+// IsRepoEmpty returns true if the repo has no commits yet.
+func (g *giteaSource) IsRepoEmpty(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ bool, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "IsRepoEmpty", owner, repo)(&err)
+
+	client, err := g.client(ctx, accessToken)
+	if err != nil {
+		return false, err
+	}
+
+	giteaRepo, err := client.GetRepo(owner, repo)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get repo: %s/%s", owner, repo)
+	}
+
+	return giteaRepo.Empty, nil
+}
+
+// ListTags lists the tags of a repo, most recent first.
+func (g *giteaSource) ListTags(ctx context.Context, accessToken *AccessToken, owner, repo string, page *api.PaginationRequest) (_ []string, _ *api.PaginationResponse, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitea", "ListTags", owner, repo)(&err)
+
+	listOpt, err := giteaListOptions(page)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := g.client(ctx, accessToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tags := []string{}
+
+	for {
+		giteaTags, resp, err := client.ListRepoTags(owner, repo, gitea.ListRepoTagsOptions{ListOptions: listOpt})
+		if err != nil {
+			return tags, nil, err
+		}
+
+		for _, tag := range giteaTags {
+			tags = append(tags, tag.Name)
+		}
+
+		if page.Size != -1 {
+			return tags, giteaPageResponse(resp, len(tags)), nil
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+
+		listOpt.Page = resp.NextPage
+	}
+
+	response := &api.PaginationResponse{
+		NextToken:  "",
+		ResultSize: safeInt32(len(tags)),
+		TotalSize:  safeInt32(len(tags)),
+	}
+	return tags, response, nil
+}
+
+func (g *giteaSource) sccRepo(owner string, repo *gitea.Repository) *scc.Repo {
+	return &scc.Repo{
+		Name:  repo.Name,
+		Org:   owner,
+		Url:   repo.HTMLURL,
+		CiUrl: g.cfg.ciURL(repo.HTMLURL, giteaCI),
+	}
+}
+
+// giteaListOptions validates the page request and returns the options to read it.
+// isGiteaNotFound tells whether resp is the reply to a request for something that doesn't exist.
+func isGiteaNotFound(resp *gitea.Response) bool {
+	return resp != nil && resp.Response != nil && resp.StatusCode == http.StatusNotFound
+}
+
+func giteaListOptions(page *api.PaginationRequest) (gitea.ListOptions, error) {
+	if page == nil {
+		return gitea.ListOptions{}, errors.New("page must not be empty")
+	}
+	if page.Size < -1 || page.Size > 100 {
+		return gitea.ListOptions{}, errors.New("page size must be >= -1 and <= 100")
+	}
+
+	opt := gitea.ListOptions{Page: 1, PageSize: int(page.Size)}
+	if page.Size == -1 {
+		opt.PageSize = 50
+	}
+
+	if strings.TrimSpace(page.Token) != "" {
+		pageToRead, err := strconv.Atoi(page.Token)
+		if err != nil {
+			return gitea.ListOptions{}, errors.Wrap(err, "page token must be int")
+		}
+		opt.Page = pageToRead
+	}
+
+	return opt, nil
+}
+
+// giteaPageResponse builds the pagination response of a single page, using the total count reported by Gitea.
+func giteaPageResponse(resp *gitea.Response, resultSize int) *api.PaginationResponse {
+	nextToken := ""
+	if resp.NextPage != 0 {
+		nextToken = strconv.Itoa(resp.NextPage)
+	}
+
+	totalSize := resultSize
+	if resp.Response != nil {
+		if total, err := strconv.Atoi(resp.Header.Get(totalCountHeader)); err == nil {
+			totalSize = total
+		}
+	}
+
+	return &api.PaginationResponse{
+		NextToken:  nextToken,
+		ResultSize: safeInt32(resultSize),
+		TotalSize:  safeInt32(totalSize),
+	}
+}
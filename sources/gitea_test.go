@@ -0,0 +1,554 @@
+package sources_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/aserto-dev/go-grpc/aserto/api/v1"
+	"github.com/aserto-dev/scc-lib/errx"
+	"github.com/aserto-dev/scc-lib/internal/interactions"
+	"github.com/aserto-dev/scc-lib/sources"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	giteaBaseURL  = "https://gitea.example.com"
+	giteaUsername = "aserto"
+	giteaOrg      = "aserto-dev"
+	giteaRepoURL  = "https://gitea.example.com/aserto-dev/policy"
+)
+
+func setupGitea(t *testing.T) (*interactions.MockGiteaIntr, sources.Source) {
+	ctrl := gomock.NewController(t)
+	mockGitea := interactions.NewMockGiteaIntr(ctrl)
+
+	p := sources.NewTestGitea(ctrl, &zerolog.Logger{}, &sources.Config{BaseURL: giteaBaseURL}, func(ctx context.Context, baseURL, token string) (interactions.GiteaIntr, error) {
+		require.Equal(t, giteaBaseURL, baseURL)
+		return mockGitea, nil
+	})
+
+	return mockGitea, p
+}
+
+func giteaResponse(nextPage int) *gitea.Response {
+	return &gitea.Response{Response: &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Header: http.Header{}}, NextPage: nextPage}
+}
+
+func TestGiteaConstructorWithoutBaseURL(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	p := sources.NewGitea(&zerolog.Logger{}, &sources.Config{})
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "gitea base URL must not be empty")
+}
+
+func TestGiteaValidateConnectionObservesCalls(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal("/api/v1/user", req.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": 1, "login": "aserto"}`))
+	}))
+	defer server.Close()
+	observer := &recordingObserver{}
+	p := sources.NewGitea(&zerolog.Logger{}, &sources.Config{BaseURL: server.URL, Observer: observer})
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal([]observedCall{
+		{provider: "gitea", operation: "api GetMyUserInfo"},
+		{provider: "gitea", operation: "ValidateConnection"},
+	}, observer.calls)
+}
+
+func TestGiteaPing(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockGitea, p := setupGitea(t)
+
+	// Expect
+	mockGitea.EXPECT().ServerVersion().Return("1.22.0", giteaResponse(0), nil)
+
+	// Act
+	err := p.Ping(context.Background())
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestGiteaPingWithUnknownVersion(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		assert.Equal("/api/v1/version", req.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": "forgejo-dev"}`))
+	}))
+	defer server.Close()
+	p := sources.NewGitea(&zerolog.Logger{}, &sources.Config{BaseURL: server.URL})
+
+	// Act
+	err := p.Ping(context.Background())
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(1, calls)
+}
+
+func TestGiteaPingTrustsTLSCACert(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": "1.22.0"}`))
+	}))
+	defer server.Close()
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	p := sources.NewGitea(&zerolog.Logger{}, &sources.Config{BaseURL: server.URL, TLSCACert: caCert})
+
+	// Act
+	err := p.Ping(context.Background())
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestGiteaValidateConnectionErrorResponse(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockGitea, p := setupGitea(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &gitea.Response{Response: &http.Response{StatusCode: http.StatusUnauthorized, Status: "401 Unauthorized"}}
+
+	// Expect
+	mockGitea.EXPECT().GetMyUserInfo().Return(&gitea.User{}, resp, nil)
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrProviderVerification.SameAs(err))
+}
+
+func TestGiteaPreflightOrgPermissions(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockGitea, p := setupGitea(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockGitea.EXPECT().GetMyUserInfo().Return(&gitea.User{UserName: giteaUsername}, giteaResponse(0), nil)
+	mockGitea.EXPECT().GetOrgPermissions(giteaOrg, giteaUsername).Return(&gitea.OrgPermissions{CanCreateRepository: false}, nil)
+
+	// Act
+	result, err := p.Preflight(context.Background(), token, giteaOrg, nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(sources.PreflightPassed, result.Connection.Status)
+	assert.Equal(sources.PreflightFailed, result.CreateRepo.Status)
+	assert.False(result.Passed())
+}
+
+func TestGiteaListReposFilter(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockGitea, p := setupGitea(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockGitea.EXPECT().GetMyUserInfo().Return(&gitea.User{ID: 1, UserName: giteaUsername}, giteaResponse(0), nil)
+	mockGitea.EXPECT().GetOrg(giteaOrg).Return(&gitea.Organization{ID: 2, UserName: giteaOrg}, nil)
+	mockGitea.EXPECT().SearchRepos(gomock.Any()).
+		DoAndReturn(func(opt gitea.SearchRepoOptions) ([]*gitea.Repository, *gitea.Response, error) {
+			assert.Equal("pol", opt.Keyword)
+			assert.Equal(int64(2), opt.OwnerID)
+			assert.Equal(10, opt.PageSize)
+
+			return []*gitea.Repository{{Name: policyRepo, HTMLURL: giteaRepoURL}}, giteaResponse(2), nil
+		})
+
+	// Act
+	repos, page, err := p.ListRepos(context.Background(), token, giteaOrg, &api.PaginationRequest{Size: 10}, &sources.ListReposOptions{Filter: "pol"})
+
+	// Assert
+	assert.NoError(err)
+	assert.Len(repos, 1)
+	assert.Equal(giteaOrg, repos[0].Org)
+	assert.Equal(giteaRepoURL+"/actions", repos[0].CiUrl)
+	assert.Equal("2", page.NextToken)
+}
+
+func TestGiteaListOrgMembers(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockGitea, p := setupGitea(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockGitea.EXPECT().ListOrgMembership(giteaOrg, gomock.Any()).
+		Return([]*gitea.User{{UserName: giteaUsername, FullName: "Aserto", Email: "dev@aserto.com"}}, giteaResponse(0), nil)
+
+	// Act
+	members, page, err := p.ListOrgMembers(context.Background(), token, giteaOrg, &api.PaginationRequest{Size: 10})
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal([]*sources.SccUser{{Login: giteaUsername, Name: "Aserto", Email: "dev@aserto.com"}}, members)
+	assert.Empty(page.NextToken)
+}
+
+func TestGiteaCreateRepoInOrg(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockGitea, p := setupGitea(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockGitea.EXPECT().GetMyUserInfo().Return(&gitea.User{UserName: giteaUsername}, giteaResponse(0), nil)
+	mockGitea.EXPECT().CreateOrgRepo(giteaOrg, gitea.CreateRepoOption{Name: policyRepo, Private: true}).
+		Return(&gitea.Repository{Name: policyRepo, HTMLURL: giteaRepoURL}, nil)
+
+	// Act
+	repo, err := p.CreateRepo(context.Background(), token, giteaOrg, policyRepo, &sources.CreateRepoOptions{Private: true})
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(giteaRepoURL, repo.Url)
+}
+
+func TestGiteaAddSecretToRepoSecretExistsOverrideFalse(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockGitea, p := setupGitea(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockGitea.EXPECT().ListRepoActionSecrets(giteaOrg, policyRepo, gomock.Any()).
+		Return([]*gitea.Secret{{Name: "ASERTO_PUSH_KEY"}}, giteaResponse(0), nil)
+
+	// Act
+	err := p.AddSecretToRepo(context.Background(), token, giteaOrg, policyRepo, "aserto_push_key", "value", false, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrRepoAlreadyConnected.SameAs(err))
+}
+
+func TestGiteaAddSecretToRepo(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockGitea, p := setupGitea(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockGitea.EXPECT().ListRepoActionSecrets(giteaOrg, policyRepo, gomock.Any()).Return(nil, giteaResponse(0), nil)
+	mockGitea.EXPECT().CreateRepoActionSecret(giteaOrg, policyRepo, gitea.CreateSecretOption{Name: "ASERTO_PUSH_KEY", Data: "value"}).Return(nil)
+
+	// Act
+	err := p.AddSecretToRepo(context.Background(), token, giteaOrg, policyRepo, "ASERTO_PUSH_KEY", "value", false, nil)
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestGiteaInitialTag(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockGitea, p := setupGitea(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockGitea.EXPECT().ListRepoTags(giteaOrg, policyRepo, gomock.Any()).Return(nil, giteaResponse(0), nil)
+	mockGitea.EXPECT().GetRepo(giteaOrg, policyRepo).Return(&gitea.Repository{Name: policyRepo, DefaultBranch: defaultBranch}, nil)
+	mockGitea.EXPECT().CreateTag(giteaOrg, policyRepo, gitea.CreateTagOption{
+		TagName: *sources.DefaultTag(),
+		Message: *sources.DefaultTag(),
+		Target:  defaultBranch,
+	}).Return(nil)
+
+	// Act
+	_, err := p.InitialTag(context.Background(), token, giteaOrg+"/"+policyRepo, "", "", "")
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestGiteaGetDefaultBranchLogsOperation(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockGitea := interactions.NewMockGiteaIntr(ctrl)
+	var logs bytes.Buffer
+	logger := zerolog.New(&logs).Level(zerolog.DebugLevel)
+	p := sources.NewTestGitea(ctrl, &logger, &sources.Config{BaseURL: giteaBaseURL}, func(ctx context.Context, baseURL, token string) (interactions.GiteaIntr, error) {
+		return mockGitea, nil
+	})
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockGitea.EXPECT().GetRepo(giteaOrg, policyRepo).Return(&gitea.Repository{Name: policyRepo, DefaultBranch: defaultBranch}, nil)
+
+	// Act
+	_, err := p.GetDefaultBranch(context.Background(), token, giteaOrg, policyRepo)
+
+	// Assert
+	assert.NoError(err)
+	assert.Contains(logs.String(), `"provider":"gitea","operation":"GetDefaultBranch","owner":"`+giteaOrg+`","repo":"`+policyRepo+`","message":"operation started"`)
+	assert.Contains(logs.String(), `"duration_ms":`)
+	assert.NotContains(logs.String(), "sometokenvalue")
+}
+
+func TestGiteaCreateCommitOnBranch(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockGitea, p := setupGitea(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockGitea.EXPECT().GetContents(giteaOrg, policyRepo, defaultBranch, file).Return(&gitea.ContentsResponse{SHA: "old"}, giteaResponse(0), nil)
+	mockGitea.EXPECT().ChangeFiles(giteaOrg, policyRepo, gomock.Any()).
+		DoAndReturn(func(_, _ string, opt *interactions.GiteaChangeFilesOptions) (string, error) {
+			assert.Equal(defaultBranch, opt.Branch)
+			assert.Equal([]*interactions.GiteaFileOperation{
+				{Operation: "update", Path: file, Content: base64.StdEncoding.EncodeToString([]byte(fileContent)), SHA: "old"},
+			}, opt.Files)
+
+			return "new", nil
+		})
+
+	// Act
+	sha, err := p.CreateCommitOnBranch(context.Background(), token, &sources.Commit{
+		Branch:  defaultBranch,
+		Message: "update",
+		Owner:   giteaOrg,
+		Repo:    policyRepo,
+		Content: map[string]string{file: fileContent},
+	})
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("new", sha)
+}
+
+func TestGiteaCreateCommitOnBranchRefusesRebase(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	_, p := setupGitea(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	_, err := p.CreateCommitOnBranch(context.Background(), token, &sources.Commit{
+		Branch:           defaultBranch,
+		Message:          "update",
+		Owner:            giteaOrg,
+		Repo:             policyRepo,
+		Content:          map[string]string{file: fileContent},
+		ConflictStrategy: sources.ConflictRebase,
+	})
+
+	// Assert
+	assert.Error(err)
+	assert.Equal("gitea doesn't support the rebase conflict strategy", err.Error())
+}
+
+func TestGiteaCreateCommitOnBranchCommitsAllFilesAtOnce(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockGitea, p := setupGitea(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	notFound := &gitea.Response{Response: &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found"}}
+
+	// Expect
+	mockGitea.EXPECT().GetContents(giteaOrg, policyRepo, defaultBranch, "a.rego").Return(nil, notFound, errors.New("not found"))
+	mockGitea.EXPECT().GetContents(giteaOrg, policyRepo, defaultBranch, "b.rego").Return(&gitea.ContentsResponse{SHA: "old"}, giteaResponse(0), nil)
+	mockGitea.EXPECT().ChangeFiles(giteaOrg, policyRepo, gomock.Any()).
+		DoAndReturn(func(_, _ string, opt *interactions.GiteaChangeFilesOptions) (string, error) {
+			assert.Len(opt.Files, 2)
+			assert.Equal("create", opt.Files[0].Operation)
+			assert.Equal("a.rego", opt.Files[0].Path)
+			assert.Equal("update", opt.Files[1].Operation)
+			assert.Equal("b.rego", opt.Files[1].Path)
+
+			return "sha", nil
+		})
+
+	// Act
+	sha, err := p.CreateCommitOnBranch(context.Background(), token, &sources.Commit{
+		Branch:  defaultBranch,
+		Message: "create",
+		Owner:   giteaOrg,
+		Repo:    policyRepo,
+		Content: map[string]string{"b.rego": fileContent, "a.rego": fileContent},
+	})
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("sha", sha)
+}
+
+func TestGiteaCreateCommitOnBranchPostsFiles(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if req.Method == http.MethodGet {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "not found"}`))
+			return
+		}
+
+		assert.Equal(http.MethodPost, req.Method)
+		assert.Equal("/api/v1/repos/"+giteaOrg+"/"+policyRepo+"/contents", req.URL.Path)
+		assert.Equal("token sometokenvalue", req.Header.Get("Authorization"))
+
+		var opt interactions.GiteaChangeFilesOptions
+		assert.NoError(json.NewDecoder(req.Body).Decode(&opt))
+		assert.Equal(defaultBranch, opt.Branch)
+		assert.Equal([]*interactions.GiteaFileOperation{
+			{Operation: "create", Path: file, Content: base64.StdEncoding.EncodeToString([]byte(fileContent))},
+		}, opt.Files)
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"commit": {"sha": "newsha"}}`))
+	}))
+	defer server.Close()
+	p := sources.NewGitea(&zerolog.Logger{}, &sources.Config{BaseURL: server.URL})
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	sha, err := p.CreateCommitOnBranch(context.Background(), token, &sources.Commit{
+		Branch:  defaultBranch,
+		Message: "update",
+		Owner:   giteaOrg,
+		Repo:    policyRepo,
+		Content: map[string]string{file: fileContent},
+	})
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("newsha", sha)
+}
+
+func TestGiteaCreateCommitOnBranchFailsToReadFile(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockGitea, p := setupGitea(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	unauthorized := &gitea.Response{Response: &http.Response{StatusCode: http.StatusUnauthorized, Status: "401 Unauthorized"}}
+
+	// Expect
+	mockGitea.EXPECT().GetContents(giteaOrg, policyRepo, defaultBranch, file).Return(nil, unauthorized, errors.New("401 Unauthorized"))
+
+	// Act
+	_, err := p.CreateCommitOnBranch(context.Background(), token, &sources.Commit{
+		Branch:  defaultBranch,
+		Message: "update",
+		Owner:   giteaOrg,
+		Repo:    policyRepo,
+		Content: map[string]string{file: fileContent},
+	})
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "failed to get file: "+file)
+}
+
+func TestGiteaCreateCommitOnBranchConflict(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockGitea, p := setupGitea(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockGitea.EXPECT().GetContents(giteaOrg, policyRepo, defaultBranch, file).Return(&gitea.ContentsResponse{SHA: "old"}, giteaResponse(0), nil)
+	mockGitea.EXPECT().ChangeFiles(giteaOrg, policyRepo, gomock.Any()).
+		Return("", &interactions.GiteaError{StatusCode: http.StatusConflict, Status: "409 Conflict"})
+
+	// Act
+	_, err := p.CreateCommitOnBranch(context.Background(), token, &sources.Commit{
+		Branch:  defaultBranch,
+		Message: "update",
+		Owner:   giteaOrg,
+		Repo:    policyRepo,
+		Content: map[string]string{file: fileContent},
+	})
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrConcurrentUpdate.SameAs(err))
+}
+
+func TestGiteaCreateCommitOnBranchWithAuthor(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockGitea, p := setupGitea(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	notFound := &gitea.Response{Response: &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found"}}
+	mockGitea.EXPECT().GetContents(giteaOrg, policyRepo, defaultBranch, file).Return(nil, notFound, errors.New("404 Not Found"))
+	mockGitea.EXPECT().ChangeFiles(giteaOrg, policyRepo, gomock.Any()).
+		DoAndReturn(func(_, _ string, opt *interactions.GiteaChangeFilesOptions) (string, error) {
+			assert.Equal(&gitea.Identity{Name: "Aserto Bot", Email: "bot@aserto.com"}, opt.Author)
+
+			return "new", nil
+		})
+
+	// Act
+	_, err := p.CreateCommitOnBranch(context.Background(), token, &sources.Commit{
+		Branch:  defaultBranch,
+		Message: "update",
+		Owner:   giteaOrg,
+		Repo:    policyRepo,
+		Content: map[string]string{file: fileContent},
+		Author:  &sources.CommitAuthor{Name: "Aserto Bot", Email: "bot@aserto.com"},
+	})
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestGiteaGetFileContent(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	mockGitea, p := setupGitea(t)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	encoded := base64.StdEncoding.EncodeToString([]byte(fileContent))
+
+	// Expect
+	mockGitea.EXPECT().GetContents(giteaOrg, policyRepo, defaultBranch, file).
+		Return(&gitea.ContentsResponse{Type: "file", Content: &encoded}, giteaResponse(0), nil)
+
+	// Act
+	content, exists, err := p.GetFileContent(context.Background(), token, giteaOrg, policyRepo, file, defaultBranch)
+
+	// Assert
+	assert.NoError(err)
+	assert.True(exists)
+	assert.Equal(fileContent, content)
+}
@@ -5,7 +5,8 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
-	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,16 +21,39 @@ import (
 	"github.com/shurcooL/githubv4"
 	"github.com/shurcooL/graphql"
 	"golang.org/x/crypto/nacl/box"
-	"golang.org/x/oauth2"
 	"k8s.io/utils/ptr"
 )
 
-var (
-	_        Source = &githubSource{}
-	githubCI        = "/actions"
+const (
+	// fineGrainedTokenPrefix identifies GitHub fine-grained personal access tokens.
+	fineGrainedTokenPrefix = "github_pat_"
+	oauthScopesHeader      = "X-OAuth-Scopes"
+	acceptedPermsHeader    = "X-Accepted-GitHub-Permissions"
+
+	// headMismatchMessage is returned by GitHub when the expectedHeadOid of a commit is stale.
+	headMismatchMessage = "Expected branch to point to"
+	// staleDataType is the GraphQL error type of the same mismatch, which some clients report instead.
+	staleDataType     = "STALE_DATA"
+	maxRebaseAttempts = 3
+)
 
-	ErrEmptyRepo      = errors.New("repository is not initialized")
-	ErrCommitNotFound = errors.New("commit not found")
+var (
+	_        Source            = &githubSource{}
+	_        WorkflowRunWaiter = &githubSource{}
+	_        BuildTrigger      = &githubSource{}
+	_        WebhookSource     = &githubSource{}
+	_        DeployKeySource   = &githubSource{}
+	_        BranchSource      = &githubSource{}
+	_        CloneURLSource    = &githubSource{}
+	_        PullRequestSource = &githubSource{}
+	_        OrgSource         = &githubSource{}
+	_        BulkSecretSource  = &githubSource{}
+	githubCI                   = "/actions"
+
+	// ErrEmptyRepo is errx.ErrEmptyRepo, which InitialTag and CreateCommitOnBranch wrap for repos without commits.
+	ErrEmptyRepo              = errx.ErrEmptyRepo
+	ErrCommitNotFound         = errors.New("commit not found")
+	ErrWorkflowRunNotComplete = errors.New("workflow run is not completed")
 )
 
 // githubSource deals with source management on github.com.
@@ -38,10 +62,36 @@ type githubSource struct {
 	cfg              *Config
 	interactionsFunc interactions.GhIntr
 	graphqlFunc      interactions.GqlIntr
+	// clients are the HTTP clients the source sends its requests through.
+	clients *httpClients
+}
+
+// Ping checks that the GitHub API answers.
+func (g *githubSource) Ping(ctx context.Context) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "Ping", "", "")(&err)
+
+	githubClient := g.interactionsFunc(ctx, "", "", g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	response, err := githubClient.Zen(ctx)
+
+	statusCode := 0
+	if response != nil && response.Response != nil {
+		statusCode = response.StatusCode
+	}
+
+	return pingError("github", statusCode, err)
+}
+
+// Close closes the idle connections of the HTTP clients the source sends its requests through.
+func (g *githubSource) Close() error {
+	g.clients.closeIdleConnections()
+	return nil
 }
 
-func (g *githubSource) ValidateConnection(ctx context.Context, accessToken *AccessToken, requiredScopes []string) error {
-	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount)
+func (g *githubSource) ValidateConnection(ctx context.Context, accessToken *AccessToken, requiredScopes []string) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "ValidateConnection", "", "")(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
 
 	_, response, err := githubClient.GetUsers(ctx, "")
 
@@ -49,34 +99,56 @@ func (g *githubSource) ValidateConnection(ctx context.Context, accessToken *Acce
 		return errors.Wrap(err, "failed to connect to Github")
 	}
 
+	if response == nil || response.Response == nil {
+		return errx.ErrProviderVerification.
+			FromReader("github-response", responseBody(nil)).
+			Msg("no reply from GitHub")
+	}
+
 	if response.StatusCode != http.StatusOK {
 		return errx.ErrProviderVerification.
 			Str("status", response.Status).
 			Int("status-code", response.StatusCode).
-			FromReader("github-response", response.Body).
+			FromReader("github-response", responseBody(response.Response)).
 			Msg("unexpected reply from GitHub")
 	}
 
+	return g.validateScopes(ctx, githubClient, accessToken, response, requiredScopes)
+}
+
+func (g *githubSource) validateScopes(
+	ctx context.Context,
+	githubClient interactions.GithubIntr,
+	accessToken *AccessToken,
+	response *github.Response,
+	requiredScopes []string,
+) error {
 	if len(requiredScopes) == 0 {
 		return nil
 	}
 
-	foundScopes := map[string]bool{}
-	scopeSlice := strings.Split(response.Header.Get("X-OAuth-Scopes"), ",")
-	for _, es := range scopeSlice {
-		for _, rs := range requiredScopes {
-			r, err := regexp.Compile(rs)
-			if err != nil {
-				return errx.ErrProviderVerification.Err(err).Msgf("failed to compile regexp: %s", err.Error())
-			}
-			if r.MatchString(strings.TrimSpace(es)) {
-				foundScopes[rs] = true
-				break
-			}
-		}
+	if isFineGrainedToken(accessToken.Token) {
+		return g.validateFineGrainedPermissions(ctx, githubClient, requiredScopes)
 	}
-	if len(foundScopes) != len(requiredScopes) {
-		return errx.ErrProviderVerification.
+
+	return checkGithubScopes(accessToken, response, requiredScopes)
+}
+
+// checkGithubScopes returns ErrMissingScopes when the X-OAuth-Scopes of response lack some of the required scopes.
+// Fine-grained tokens, which have permissions instead, and responses without headers aren't checked.
+func checkGithubScopes(accessToken *AccessToken, response *github.Response, requiredScopes []string) error {
+	if len(requiredScopes) == 0 || response == nil || response.Response == nil || isFineGrainedToken(accessToken.Token) {
+		return nil
+	}
+
+	scopeSlice := strings.Split(response.Header.Get(oauthScopesHeader), ",")
+	missing, err := missingScopes(githubImpliedScopes(scopeSlice), requiredScopes)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return errx.ErrMissingScopes.
+			Err(&errx.MissingScopesError{Scopes: missing}).
 			Interface("provided-scopes", scopeSlice).
 			Interface("required-scopes", requiredScopes).
 			Msg("github access token is missing scopes")
@@ -85,15 +157,239 @@ func (g *githubSource) ValidateConnection(ctx context.Context, accessToken *Acce
 	return nil
 }
 
+// githubImpliedScopes adds to scopes the scopes they include, which GitHub doesn't list: e.g. a token with
+// admin:org only reports admin:org, but can do what read:org allows.
+func githubImpliedScopes(scopes []string) []string {
+	implied := map[string][]string{
+		"admin:org":       {"write:org", "read:org"},
+		"write:org":       {"read:org"},
+		"admin:repo_hook": {"write:repo_hook", "read:repo_hook"},
+		"write:repo_hook": {"read:repo_hook"},
+	}
+
+	all := []string{}
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		all = append(all, scope)
+		all = append(all, implied[scope]...)
+	}
+
+	return all
+}
+
+// Preflight checks that the token is valid, has the required scopes, and can create public repos for the owner.
+func (g *githubSource) Preflight(ctx context.Context, accessToken *AccessToken, owner string, requiredScopes []string) (_ *PreflightResult, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "Preflight", owner, "")(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+	result := &PreflightResult{}
+
+	user, response, err := githubClient.GetUsers(ctx, "")
+	if err != nil {
+		result.Connection = checkFromError(errors.Wrap(err, "failed to connect to Github"))
+		return result, nil
+	}
+
+	if response == nil || response.Response == nil {
+		result.Connection = checkFromError(errx.ErrProviderVerification.Msg("no reply from GitHub"))
+		return result, nil
+	}
+
+	if response.StatusCode != http.StatusOK {
+		result.Connection = checkFromError(errx.ErrProviderVerification.
+			Str("status", response.Status).
+			Int("status-code", response.StatusCode).
+			Msg("unexpected reply from GitHub"))
+		return result, nil
+	}
+
+	result.Connection = checkFromError(nil)
+	result.Scopes = checkFromError(g.validateScopes(ctx, githubClient, accessToken, response, requiredScopes))
+
+	// Users can always create repos in their own account.
+	if user.GetLogin() == owner {
+		result.CreateRepo = checkFromError(nil)
+		result.Visibility = checkFromError(nil)
+		return result, nil
+	}
+
+	membership, err := githubClient.GetOrgMembership(ctx, owner)
+	if err != nil {
+		result.CreateRepo = checkFromError(errors.Wrapf(err, "failed to read membership of org '%s'", owner))
+		return result, nil
+	}
+
+	org, err := githubClient.GetOrg(ctx, owner)
+	if err != nil {
+		result.CreateRepo = checkFromError(errors.Wrapf(err, "failed to read org '%s'", owner))
+		return result, nil
+	}
+
+	isAdmin := membership.GetRole() == "admin"
+
+	result.CreateRepo = checkFromError(nil)
+	if !isAdmin && !org.GetMembersCanCreateRepos() {
+		result.CreateRepo = checkFromError(errx.ErrProviderVerification.Str("org", owner).Msg("org members are not allowed to create repositories"))
+	}
+
+	result.Visibility = checkFromError(nil)
+	if !isAdmin && !org.GetMembersCanCreatePublicRepos() {
+		result.Visibility = checkFromError(errx.ErrProviderVerification.Str("org", owner).Msg("org members are not allowed to create public repositories"))
+	}
+
+	return result, nil
+}
+
+// isFineGrainedToken reports whether the token is a fine-grained PAT. Those tokens carry
+// permissions instead of OAuth scopes, so GitHub doesn't send the X-OAuth-Scopes header for them.
+func isFineGrainedToken(token string) bool {
+	return strings.HasPrefix(token, fineGrainedTokenPrefix)
+}
+
+// fineGrainedProbe reads a repo with a request GitHub forbids to fine-grained tokens without one permission.
+type fineGrainedProbe struct {
+	permission string
+	read       func(ctx context.Context, githubClient interactions.GithubIntr, owner, repo string) error
+}
+
+// fineGrainedProbes maps the classic scopes to the probes of the repo permissions that replace them for
+// fine-grained tokens. The other scopes, like workflow, have no read to probe and are reported as missing since the
+// token can't be shown to have them.
+var fineGrainedProbes = map[string][]fineGrainedProbe{
+	"repo": {
+		{permission: "contents", read: func(ctx context.Context, githubClient interactions.GithubIntr, owner, repo string) error {
+			_, err := githubClient.GetFileContents(ctx, owner, repo, "", nil)
+			return err
+		}},
+		{permission: "secrets", read: func(ctx context.Context, githubClient interactions.GithubIntr, owner, repo string) error {
+			_, err := githubClient.GetRepoPublicKey(ctx, owner, repo)
+			return err
+		}},
+	},
+}
+
+// fineGrainedProbeRepos is the number of repos the permissions of a fine-grained token are probed on, until each
+// probe gets a conclusive answer.
+const fineGrainedProbeRepos = 10
+
+// validateFineGrainedPermissions probes the permissions that replace the required scopes on the repos the token can
+// see, since fine-grained tokens can't be checked against a list of scopes. The permissions GitHub forbids are
+// returned as the missing scopes of an ErrMissingScopes, along with the scopes and permissions that can't be
+// verified: the scopes without a probe, and the permissions no repo answered for, e.g. when the token sees no repo.
+func (g *githubSource) validateFineGrainedPermissions(ctx context.Context, githubClient interactions.GithubIntr, requiredScopes []string) error {
+	probes := []fineGrainedProbe{}
+	unverified := []string{}
+	for _, scope := range requiredScopes {
+		scopeProbes, ok := fineGrainedProbes[scope]
+		if !ok {
+			unverified = append(unverified, scope)
+			continue
+		}
+		probes = append(probes, scopeProbes...)
+	}
+
+	missing := []string{}
+	accepted := []string{}
+
+	if len(probes) > 0 {
+		repos, _, err := githubClient.ListUserRepos(ctx, &github.RepositoryListByAuthenticatedUserOptions{
+			ListOptions: github.ListOptions{PerPage: fineGrainedProbeRepos},
+		})
+		if err != nil {
+			return errors.Wrap(err, "failed to verify github token permissions")
+		}
+
+		for _, repo := range repos {
+			if len(probes) == 0 {
+				break
+			}
+
+			inconclusive := []fineGrainedProbe{}
+			for _, probe := range probes {
+				err := probe.read(ctx, githubClient, repo.GetOwner().GetLogin(), repo.GetName())
+
+				var githubErr *github.ErrorResponse
+				switch {
+				case err == nil:
+				case errors.As(err, &githubErr) && githubErr.Response != nil && githubErr.Response.StatusCode == http.StatusForbidden:
+					missing = append(missing, probe.permission)
+					accepted = append(accepted, githubErr.Response.Header.Get(acceptedPermsHeader))
+				case errors.As(err, &githubErr) && githubErr.Response != nil && githubErr.Response.StatusCode == http.StatusNotFound:
+					// A 404, e.g. for the contents of an empty repo, says nothing about the permission, try the next repo.
+					inconclusive = append(inconclusive, probe)
+				default:
+					return errors.Wrapf(err, "failed to verify the %s permission of the github token", probe.permission)
+				}
+			}
+			probes = inconclusive
+		}
+
+		for _, probe := range probes {
+			unverified = append(unverified, probe.permission)
+		}
+	}
+
+	if len(missing) > 0 || len(unverified) > 0 {
+		return errx.ErrMissingScopes.
+			Err(&errx.MissingScopesError{Scopes: append(missing, unverified...)}).
+			Interface("accepted-permissions", accepted).
+			Interface("unverified-scopes", unverified).
+			Msg("github access token is missing permissions, or they can't be verified")
+	}
+
+	return nil
+}
+
 // Profile returns the username of the user that owns the token, and its associated repos.
-func (g *githubSource) Profile(ctx context.Context, accessToken *AccessToken) (string, []*scc.Repo, error) {
-	client := g.graphqlFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount)
+func (g *githubSource) Profile(ctx context.Context, accessToken *AccessToken) (_ string, _ []*scc.Repo, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "Profile", "", "")(&err)
+
+	username, repos, _, err := g.profilePage(ctx, accessToken, &api.PaginationRequest{Size: -1})
+	return username, repos, err
+}
+
+// ProfilePage returns the username of the user that owns the token, and a page of its associated repos.
+// A page size of -1 reads all the pages.
+func (g *githubSource) ProfilePage(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest) (_ string, _ []*scc.Repo, _ *api.PaginationResponse, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "ProfilePage", "", "")(&err)
+
+	return g.profilePage(ctx, accessToken, page)
+}
+
+// profilePage reads the profile page for Profile and ProfilePage, which each track the operation themselves.
+func (g *githubSource) profilePage(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest) (_ string, _ []*scc.Repo, _ *api.PaginationResponse, err error) {
+	if page == nil {
+		return "", nil, nil, errors.New("page must not be empty")
+	}
+	page = g.cfg.pageRequest(page)
+	if page.Size < -1 || page.Size > 100 {
+		return "", nil, nil, errors.New("page size must be >= -1 and <= 100")
+	}
+
+	client := g.graphqlFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
 
 	repos := []*scc.Repo{}
 	username := ""
-	var cursor graphql.String
+
+	vars := map[string]interface{}{
+		"first": graphql.Int(page.Size),
+	}
+
+	if page.Token != "" {
+		vars["after"] = graphql.String(page.Token)
+	} else {
+		vars["after"] = (*graphql.String)(nil)
+	}
+
+	if page.Size == -1 {
+		vars["first"] = graphql.Int(100)
+	}
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return "", nil, nil, err
+		}
+
 		var query struct {
 			Viewer struct {
 				Login        graphql.String
@@ -109,22 +405,14 @@ func (g *githubSource) Profile(ctx context.Context, accessToken *AccessToken) (s
 						HasNextPage graphql.Boolean
 						EndCursor   graphql.String
 					}
+					TotalCount graphql.Int
 				} `graphql:"repositories(first: $first after: $after ownerAffiliations:[OWNER])"`
 			}
 		}
-		vars := map[string]interface{}{
-			"first": graphql.Int(100),
-		}
-
-		if cursor != "" {
-			vars["after"] = cursor
-		} else {
-			vars["after"] = (*graphql.String)(nil)
-		}
 
 		err := client.Query(ctx, &query, vars)
 		if err != nil {
-			return "", nil, errors.Wrap(err, "error running query against github graphql server")
+			return "", nil, nil, errors.Wrap(err, "error running query against github graphql server")
 		}
 
 		username = string(query.Viewer.Login)
@@ -133,29 +421,78 @@ func (g *githubSource) Profile(ctx context.Context, accessToken *AccessToken) (s
 				Name:  string(r.Name),
 				Org:   string(r.Owner.Login),
 				Url:   string(r.URL),
-				CiUrl: string(r.URL) + githubCI,
+				CiUrl: g.cfg.ciURL(string(r.URL), githubCI),
 			})
 		}
 
+		if page.Size != -1 {
+			resp := &api.PaginationResponse{
+				NextToken:  string(query.Viewer.Repositories.PageInfo.EndCursor),
+				ResultSize: safeInt32(len(repos)),
+				TotalSize:  int32(query.Viewer.Repositories.TotalCount),
+			}
+			return username, repos, resp, nil
+		}
+
 		if !query.Viewer.Repositories.PageInfo.HasNextPage {
 			break
 		}
 
-		cursor = query.Viewer.Repositories.PageInfo.EndCursor
+		vars["after"] = query.Viewer.Repositories.PageInfo.EndCursor
+	}
+
+	resp := &api.PaginationResponse{
+		NextToken:  "",
+		ResultSize: safeInt32(len(repos)),
+		TotalSize:  safeInt32(len(repos)),
 	}
 
-	return username, repos, nil
+	return username, repos, resp, nil
 }
 
-func (g *githubSource) HasSecret(ctx context.Context, accessToken *AccessToken, owner, repo, secretName string) (bool, error) {
-	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount)
+func (g *githubSource) HasSecret(ctx context.Context, accessToken *AccessToken, owner, repo, secretName string, opts *SecretOptions) (_ bool, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "HasSecret", owner, repo)(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
 
 	return g.hasSecret(ctx, githubClient, owner, repo, secretName)
 }
 
-func (g *githubSource) AddSecretToRepo(ctx context.Context, accessToken *AccessToken, orgName, repoName, secretName, value string, overrideSecret bool) error {
-	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount)
+// GetSecret returns errx.ErrSecretWriteOnly: GitHub Actions secrets can't be read back.
+func (g *githubSource) GetSecret(ctx context.Context, accessToken *AccessToken, owner, repo, secretName string) (_ string, _ bool, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "GetSecret", owner, repo)(&err)
+
+	return "", false, errx.ErrSecretWriteOnly.Msg("GitHub Actions secrets can't be read back")
+}
+
+func (g *githubSource) AddSecretToRepo(ctx context.Context, accessToken *AccessToken, orgName, repoName, secretName, value string, overrideSecret bool, opts *SecretOptions) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "AddSecretToRepo", orgName, repoName)(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	return g.addSecretToRepo(ctx, githubClient, orgName, repoName, secretName, value, overrideSecret)
+}
+
+// AddSecretToRepos adds the secret to each of the repos of owner through a single client. Repo secrets are encrypted
+// with the public key of their own repo, so there is no key to share between the repos and each one is fetched.
+func (g *githubSource) AddSecretToRepos(ctx context.Context, accessToken *AccessToken, owner string, repos []string, secretName, value string, overrideSecret bool) (_ map[string]error, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "AddSecretToRepos", owner, "")(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	results := make(map[string]error, len(repos))
+	for _, repo := range repos {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return results, ctxErr
+		}
+
+		results[repo] = g.addSecretToRepo(ctx, githubClient, owner, repo, secretName, value, overrideSecret)
+	}
+
+	return results, nil
+}
 
+func (g *githubSource) addSecretToRepo(ctx context.Context, githubClient interactions.GithubIntr, orgName, repoName, secretName, value string, overrideSecret bool) error {
 	if orgName == "" {
 		return errors.New("No org name was provided")
 	}
@@ -166,7 +503,8 @@ func (g *githubSource) AddSecretToRepo(ctx context.Context, accessToken *AccessT
 
 	var pk *github.PublicKey
 	var err error
-	err = retry.Retry(time.Duration(g.cfg.CreateRepoTimeoutSeconds)*time.Second, func(i int) error {
+	// A repo that was just created can answer 404 for a moment, so all the errors are retried.
+	err = retry.RetryContext(ctx, time.Duration(g.cfg.CreateRepoTimeoutSeconds)*time.Second, func(i int) error {
 		pk, err = githubClient.GetRepoPublicKey(ctx, orgName, repoName)
 		return err
 	})
@@ -191,7 +529,7 @@ func (g *githubSource) AddSecretToRepo(ctx context.Context, accessToken *AccessT
 	}
 
 	var response *github.Response
-	err = retry.Retry(time.Duration(g.cfg.CreateRepoTimeoutSeconds)*time.Second, func(i int) error {
+	err = retry.RetryIfContext(ctx, time.Duration(g.cfg.CreateRepoTimeoutSeconds)*time.Second, isRetryable, func(i int) error {
 		response, err = githubClient.CreateOrUpdateRepoSecret(ctx, orgName, repoName, &github.EncryptedSecret{
 			Name:           secretName,
 			EncryptedValue: encryptedString,
@@ -202,18 +540,26 @@ func (g *githubSource) AddSecretToRepo(ctx context.Context, accessToken *AccessT
 	})
 
 	if err != nil {
-		return errx.ErrGithubSecret.Err(err).Str("repo", orgName+"/"+repoName).Str("secret-name", secretName).FromReader("github-response", response.Body)
+		var httpResponse *http.Response
+		if response != nil {
+			httpResponse = response.Response
+		}
+
+		return errx.ErrGithubSecret.Err(err).Str("repo", orgName+"/"+repoName).Str("secret-name", secretName).FromReader("github-response", responseBody(httpResponse))
 	}
 
 	return nil
 }
 
 // ListOrgs lists all orgs the user is a part of.
-func (g *githubSource) ListOrgs(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest) ([]*api.SccOrg, *api.PaginationResponse, error) {
+func (g *githubSource) ListOrgs(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest, opts *ListOrgsOptions) (_ []*api.SccOrg, _ *api.PaginationResponse, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "ListOrgs", "", "")(&err)
+
 	if page == nil {
 		return nil, nil, errors.New("page must not be empty")
 	}
-	client := g.graphqlFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount)
+	page = g.cfg.pageRequest(page)
+	client := g.graphqlFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
 
 	var result []*api.SccOrg
 
@@ -253,7 +599,8 @@ func (g *githubSource) ListOrgs(ctx context.Context, accessToken *AccessToken, p
 	for {
 		err := client.Query(ctx, &query, vars)
 		if err != nil {
-			return nil, nil, errors.Wrap(err, "error running query against github graphql server")
+			// The orgs of the pages read so far are kept, for callers that can make do with a partial list.
+			return result, nil, errors.Wrap(err, "error running query against github graphql server")
 		}
 
 		for _, o := range query.Viewer.Organizations.Nodes {
@@ -267,7 +614,7 @@ func (g *githubSource) ListOrgs(ctx context.Context, accessToken *AccessToken, p
 
 		resp := &api.PaginationResponse{
 			NextToken:  string(query.Viewer.Organizations.PageInfo.EndCursor),
-			ResultSize: int32(len(result)), // nolint: gosec
+			ResultSize: safeInt32(len(result)),
 			TotalSize:  int32(query.Viewer.Organizations.TotalCount),
 		}
 
@@ -283,24 +630,147 @@ func (g *githubSource) ListOrgs(ctx context.Context, accessToken *AccessToken, p
 
 	resp := &api.PaginationResponse{
 		NextToken:  "",
-		ResultSize: int32(len(result)), // nolint: gosec
-		TotalSize:  int32(len(result)), // nolint: gosec
+		ResultSize: safeInt32(len(result)),
+		TotalSize:  safeInt32(len(result)),
 	}
 
 	return result, resp, nil
 }
 
+func (g *githubSource) ListOrgMembers(ctx context.Context, accessToken *AccessToken, org string, page *api.PaginationRequest) (_ []*SccUser, _ *api.PaginationResponse, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "ListOrgMembers", org, "")(&err)
+
+	if page == nil {
+		return nil, nil, errors.New("page must not be empty")
+	}
+	page = g.cfg.pageRequest(page)
+	if page.Size < -1 || page.Size > 100 {
+		return nil, nil, errors.New("page size must be >= -1 and <= 100")
+	}
+
+	pageToRead := 1
+	if strings.TrimSpace(page.Token) != "" {
+		var err error
+		pageToRead, err = strconv.Atoi(page.Token)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "page token must be int")
+		}
+	}
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	opts := &github.ListMembersOptions{ListOptions: github.ListOptions{Page: pageToRead, PerPage: int(page.Size)}}
+	if page.Size == -1 {
+		opts.PerPage = 100
+	}
+
+	var members []*SccUser
+
+	for {
+		users, response, err := githubClient.ListOrgMembers(ctx, org, opts)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to list members of org '%s'", org)
+		}
+
+		for _, user := range users {
+			members = append(members, &SccUser{
+				Login: user.GetLogin(),
+				Name:  user.GetName(),
+				Email: user.GetEmail(),
+			})
+		}
+
+		nextPage := 0
+		if response != nil {
+			nextPage = response.NextPage
+		}
+
+		if page.Size != -1 {
+			resp := &api.PaginationResponse{
+				ResultSize: safeInt32(len(members)),
+			}
+			if nextPage != 0 {
+				resp.NextToken = strconv.Itoa(nextPage)
+			}
+			return members, resp, nil
+		}
+
+		if nextPage == 0 {
+			break
+		}
+		opts.Page = nextPage
+	}
+
+	resp := &api.PaginationResponse{
+		NextToken:  "",
+		ResultSize: safeInt32(len(members)),
+		TotalSize:  safeInt32(len(members)),
+	}
+
+	return members, resp, nil
+}
+
+func (g *githubSource) GetOrg(ctx context.Context, accessToken *AccessToken, org string) (_ *SccOrgDetail, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "GetOrg", org, "")(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	organization, err := githubClient.GetOrg(ctx, org)
+	if err != nil {
+		return nil, errors.Wrapf(githubNotFoundError(err), "failed to get org %s", org)
+	}
+
+	// Orgs without a display name are shown by their login.
+	name := organization.GetName()
+	if name == "" {
+		name = organization.GetLogin()
+	}
+
+	return &SccOrgDetail{
+		ID:           organization.GetLogin(),
+		Name:         name,
+		Description:  organization.GetDescription(),
+		AvatarURL:    organization.GetAvatarURL(),
+		URL:          organization.GetHTMLURL(),
+		BillingEmail: organization.GetBillingEmail(),
+	}, nil
+}
+
 // ListRepos lists all repos for an owner.
-func (g *githubSource) ListRepos(ctx context.Context, accessToken *AccessToken, owner string, page *api.PaginationRequest) ([]*scc.Repo, *api.PaginationResponse, error) {
+func (g *githubSource) ListRepos(
+	ctx context.Context,
+	accessToken *AccessToken,
+	owner string,
+	page *api.PaginationRequest,
+	opts *ListReposOptions,
+) (_ []*scc.Repo, _ *api.PaginationResponse, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "ListRepos", owner, "")(&err)
+
 	if page == nil {
 		return nil, nil, errors.New("page must not be empty")
 	}
+	page = g.cfg.pageRequest(page)
 	if page.Size < -1 || page.Size > 100 {
 		return nil, nil, errors.New("page size must be >= -1 and <= 100")
 	}
 	result := []*scc.Repo{}
 
-	client := g.graphqlFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount)
+	client := g.graphqlFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	if opts != nil && opts.Consistent {
+		if strings.TrimSpace(opts.Filter) != "" {
+			return nil, nil, errors.New("filter isn't supported by consistent listings")
+		}
+
+		return g.listOwnerRepos(ctx, client, owner, page)
+	}
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	user, _, err := githubClient.GetUsers(ctx, "")
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get the authenticated user")
+	}
 
 	var query struct {
 		Search struct {
@@ -320,12 +790,21 @@ func (g *githubSource) ListRepos(ctx context.Context, accessToken *AccessToken,
 					} `graphql:"... on Repository"`
 				}
 			}
-		} `graphql:"search(query:$org type:REPOSITORY first:$first after:$after)"`
+		} `graphql:"search(query:$query type:REPOSITORY first:$first after:$after)"`
+	}
+
+	// org: only matches organizations, the personal repos of the authenticated user need user:.
+	searchQuery := "org:" + owner
+	if strings.EqualFold(user.GetLogin(), owner) {
+		searchQuery = "user:" + owner
+	}
+	if opts != nil && strings.TrimSpace(opts.Filter) != "" {
+		searchQuery += " " + strings.TrimSpace(opts.Filter) + " in:name"
 	}
 
 	vars := map[string]interface{}{
 		"first": graphql.Int(page.Size),
-		"org":   "org:" + graphql.String(owner),
+		"query": graphql.String(searchQuery),
 	}
 
 	if page.Token != "" {
@@ -343,7 +822,8 @@ func (g *githubSource) ListRepos(ctx context.Context, accessToken *AccessToken,
 		err := client.Query(ctx, &query, vars)
 
 		if err != nil {
-			return nil, nil, errors.Wrap(err, "error running query against github graphql server")
+			// The repos of the pages read so far are kept, for callers that can make do with a partial list.
+			return result, nil, errors.Wrap(err, "error running query against github graphql server")
 		}
 
 		for _, r := range query.Search.Edges {
@@ -351,13 +831,13 @@ func (g *githubSource) ListRepos(ctx context.Context, accessToken *AccessToken,
 				Name:  string(r.Node.Repository.Name),
 				Org:   string(r.Node.Repository.Owner.Login),
 				Url:   string(r.Node.Repository.URL),
-				CiUrl: string(r.Node.Repository.URL) + githubCI,
+				CiUrl: g.cfg.ciURL(string(r.Node.Repository.URL), githubCI),
 			})
 		}
 
 		resp := &api.PaginationResponse{
 			NextToken:  string(query.Search.PageInfo.EndCursor),
-			ResultSize: int32(len(result)), // nolint: gosec
+			ResultSize: safeInt32(len(result)),
 			TotalSize:  int32(query.Search.RepositoryCount),
 		}
 
@@ -373,87 +853,298 @@ func (g *githubSource) ListRepos(ctx context.Context, accessToken *AccessToken,
 
 	resp := &api.PaginationResponse{
 		NextToken:  "",
-		ResultSize: int32(len(result)), // nolint: gosec
-		TotalSize:  int32(len(result)), // nolint: gosec
+		ResultSize: safeInt32(len(result)),
+		TotalSize:  safeInt32(len(result)),
 	}
 
 	return result, resp, nil
 }
 
-func (g *githubSource) GetRepo(ctx context.Context, accessToken *AccessToken, owner, repo string) (*scc.Repo, error) {
-	result := &scc.Repo{}
-
-	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount)
-
-	gitRepo, err := githubClient.GetRepo(ctx, owner, repo)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to get repo")
-	}
-
-	result.Name = *gitRepo.Name
-	result.Org = *gitRepo.Owner.Login
-	result.Url = *gitRepo.HTMLURL
-	result.CiUrl = *gitRepo.HTMLURL + githubCI
-
-	return result, err
-}
-
-func (g *githubSource) CreateRepo(ctx context.Context, accessToken *AccessToken, owner, name string) error {
-	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount)
+// listOwnerRepos lists the repos of a user or an org through the repositories connection of the owner,
+// which unlike search sees new repos right away and isn't capped. A page size of -1 reads all the pages.
+func (g *githubSource) listOwnerRepos(
+	ctx context.Context,
+	client interactions.GraphqlIntr,
+	owner string,
+	page *api.PaginationRequest,
+) ([]*scc.Repo, *api.PaginationResponse, error) {
+	result := []*scc.Repo{}
 
-	user, _, err := githubClient.GetUsers(ctx, "")
-	if err != nil {
-		return errors.Wrap(err, "failed to read user from github")
+	vars := map[string]interface{}{
+		"login": graphql.String(owner),
+		"first": graphql.Int(page.Size),
 	}
 
-	if *user.Login == owner {
-		owner = ""
+	if page.Token != "" {
+		vars["after"] = graphql.String(page.Token)
+	} else {
+		vars["after"] = (*graphql.String)(nil)
 	}
 
-	err = githubClient.CreateRepo(ctx, owner, &github.Repository{
-		Name:     &name,
-		AutoInit: ptr.To(true),
-	})
-	if err != nil {
-		return errors.Wrap(err, "failed to create repo")
+	if page.Size == -1 {
+		vars["first"] = graphql.Int(100)
 	}
 
-	return nil
-}
-
-// InitialTag creates a tag for a repo, if no other tags are defined for it.
-func (g *githubSource) InitialTag(ctx context.Context, accessToken *AccessToken, fullName, workflowFileName, commitSha string) error {
-	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount)
-	repoPieces := strings.Split(fullName, "/")
-	if len(repoPieces) != 2 {
-		return errors.Errorf("invalid full github repo name '%s', should be in the form owner/repo", fullName)
-	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, nil, err
+		}
 
-	owner := repoPieces[0]
-	name := repoPieces[1]
+		var query struct {
+			RepositoryOwner struct {
+				Login        graphql.String
+				Repositories struct {
+					Nodes []struct {
+						Name  graphql.String
+						Owner struct {
+							Login graphql.String
+						}
+						URL graphql.String
+					}
+					PageInfo struct {
+						HasNextPage graphql.Boolean
+						EndCursor   graphql.String
+					}
+					TotalCount graphql.Int
+				} `graphql:"repositories(first: $first after: $after ownerAffiliations:[OWNER])"`
+			} `graphql:"repositoryOwner(login: $login)"`
+		}
+
+		err := client.Query(ctx, &query, vars)
+		if err != nil {
+			return result, nil, errors.Wrap(err, "error running query against github graphql server")
+		}
+
+		if query.RepositoryOwner.Login == "" {
+			return nil, nil, errx.ErrNotFound.Int(errx.StatusCodeKey, http.StatusNotFound).Msgf("github owner '%s' not found", owner)
+		}
+
+		for _, r := range query.RepositoryOwner.Repositories.Nodes {
+			result = append(result, &scc.Repo{
+				Name:  string(r.Name),
+				Org:   string(r.Owner.Login),
+				Url:   string(r.URL),
+				CiUrl: g.cfg.ciURL(string(r.URL), githubCI),
+			})
+		}
+
+		if page.Size != -1 {
+			resp := &api.PaginationResponse{
+				NextToken:  string(query.RepositoryOwner.Repositories.PageInfo.EndCursor),
+				ResultSize: safeInt32(len(result)),
+				TotalSize:  int32(query.RepositoryOwner.Repositories.TotalCount),
+			}
+			return result, resp, nil
+		}
+
+		if !query.RepositoryOwner.Repositories.PageInfo.HasNextPage {
+			break
+		}
+
+		vars["after"] = query.RepositoryOwner.Repositories.PageInfo.EndCursor
+	}
+
+	resp := &api.PaginationResponse{
+		NextToken:  "",
+		ResultSize: safeInt32(len(result)),
+		TotalSize:  safeInt32(len(result)),
+	}
+
+	return result, resp, nil
+}
+
+func (g *githubSource) GetRepo(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ *scc.Repo, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "GetRepo", owner, repo)(&err)
+
+	result := &scc.Repo{}
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	gitRepo, err := githubClient.GetRepo(ctx, owner, repo)
+	if err != nil {
+		return nil, errors.Wrap(githubNotFoundError(err), "failed to get repo")
+	}
+
+	if gitRepo.GetHTMLURL() == "" {
+		return nil, errors.Errorf("github returned repo '%s/%s' without a URL", owner, repo)
+	}
+
+	// Partial replies may omit the name or the owner, the requested ones are the next best thing.
+	result.Name = gitRepo.GetName()
+	if result.Name == "" {
+		result.Name = repo
+	}
+	result.Org = gitRepo.GetOwner().GetLogin()
+	if result.Org == "" {
+		result.Org = owner
+	}
+	result.Url = gitRepo.GetHTMLURL()
+	result.CiUrl = g.cfg.ciURL(result.Url, githubCI)
+
+	return result, nil
+}
+
+func (g *githubSource) RenameRepo(ctx context.Context, accessToken *AccessToken, owner, repo, newName string) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "RenameRepo", owner, repo)(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	_, response, err := githubClient.EditRepo(ctx, owner, repo, &github.Repository{Name: &newName})
+	if err != nil {
+		statusCode := 0
+		if response != nil && response.Response != nil {
+			statusCode = response.StatusCode
+		}
+		return errors.Wrapf(renameRepoError(err, statusCode, owner, newName), "failed to rename repo %s/%s", owner, repo)
+	}
+
+	return nil
+}
+
+func (g *githubSource) GetRepoID(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "GetRepoID", owner, repo)(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	gitRepo, err := githubClient.GetRepo(ctx, owner, repo)
+	if err != nil {
+		return "", errors.Wrap(githubNotFoundError(err), "failed to get repo")
+	}
+
+	return gitRepo.GetNodeID(), nil
+}
+
+func (g *githubSource) GetCloneURLs(ctx context.Context, accessToken *AccessToken, owner, repo string) (_, _ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "GetCloneURLs", owner, repo)(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	gitRepo, err := githubClient.GetRepo(ctx, owner, repo)
+	if err != nil {
+		return "", "", errors.Wrap(githubNotFoundError(err), "failed to get repo")
+	}
+
+	if gitRepo.GetCloneURL() == "" && gitRepo.GetSSHURL() == "" {
+		return "", "", errors.Errorf("github returned repo '%s/%s' without clone URLs", owner, repo)
+	}
+
+	return gitRepo.GetCloneURL(), gitRepo.GetSSHURL(), nil
+}
+
+func (g *githubSource) CreateRepo(ctx context.Context, accessToken *AccessToken, owner, name string, opts *CreateRepoOptions) (_ *scc.Repo, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "CreateRepo", owner, name)(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	user, response, err := githubClient.GetUsers(ctx, "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read user from github")
+	}
+
+	// Creating a repo in an org needs more than creating one in the user account.
+	requiredScopes := []string{"repo"}
+	if *user.Login != owner {
+		requiredScopes = RequiredScopes("github", OperationCreateRepo)
+	}
+	if err := checkGithubScopes(accessToken, response, requiredScopes); err != nil {
+		return nil, err
+	}
+
+	if *user.Login == owner {
+		owner = ""
+	}
+
+	repo := &github.Repository{
+		Name:     &name,
+		AutoInit: ptr.To(true),
+	}
+
+	if opts != nil {
+		repo.Private = ptr.To(opts.Private)
+		if opts.Description != "" {
+			repo.Description = ptr.To(opts.Description)
+		}
+		if opts.AutoInit != nil {
+			repo.AutoInit = opts.AutoInit
+		}
+	}
+
+	created, err := githubClient.CreateRepo(ctx, owner, repo)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create repo")
+	}
+
+	return &scc.Repo{
+		Name:  created.GetName(),
+		Org:   created.GetOwner().GetLogin(),
+		Url:   created.GetHTMLURL(),
+		CiUrl: g.cfg.ciURL(created.GetHTMLURL(), githubCI),
+	}, nil
+}
+
+// InitialTag creates a tag for a repo, if no other tags are defined for it. When a workflow file is given, it
+// returns the ID of the workflow run the tag triggered, or dispatched if none was triggered. The ID is 0 when no
+// run was found. Without a commitSha, it returns ErrEmptyRepo for repos with no commits, e.g. created without AutoInit.
+func (g *githubSource) InitialTag(ctx context.Context, accessToken *AccessToken, fullName, workflowFileName, commitSha, tagMessage string) (_ int64, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "InitialTag", "", fullName)(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+	repoPieces := strings.Split(fullName, "/")
+	if len(repoPieces) != 2 {
+		return 0, errors.Errorf("invalid full github repo name '%s', should be in the form owner/repo", fullName)
+	}
+
+	owner := repoPieces[0]
+	name := repoPieces[1]
 
-	client := g.graphqlFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount)
+	client := g.graphqlFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
 
 	repo, err := githubClient.GetRepo(ctx, owner, name)
 	if err != nil {
-		return errors.Wrap(err, "failed to get repo")
+		return 0, errors.Wrap(err, "failed to get repo")
 	}
 
 	if commitSha == "" {
 		tags, err := githubClient.ListRepoTags(ctx, owner, name, &github.ListOptions{})
 		if err != nil {
-			return errors.Wrapf(err, "failed to list tags for repo '%s/%s'", owner, name)
+			return 0, errors.Wrapf(err, "failed to list tags for repo '%s/%s'", owner, name)
 		}
 
 		if len(tags) > 0 {
-			return nil
+			return 0, nil
 		}
 
-		ref, response, err := githubClient.GetRepoRef(ctx, owner, name, "heads/"+*repo.DefaultBranch)
+		ref, response, err := githubClient.GetRepoRef(ctx, owner, name, "heads/"+repo.GetDefaultBranch())
 		if err != nil {
-			return errors.Wrapf(err, "repo seems to be empty; response code from github [%d]", response.StatusCode)
+			// Repos created without AutoInit have no commit to tag until something is pushed to them.
+			if response != nil && (response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusConflict) {
+				return 0, errors.Wrapf(ErrEmptyRepo, "%s/%s", owner, name)
+			}
+			return 0, errors.Wrapf(err, "failed to get default branch of repo '%s/%s'", owner, name)
 		}
 		commitSha = *ref.Object.SHA
+	} else if err := commitExists(ctx, githubClient, owner, name, commitSha); err != nil {
+		// createRef fails with an opaque error when the commit isn't on GitHub, e.g. not pushed to an empty repo yet.
+		return 0, err
+	}
+
+	tagName := g.cfg.initialTag()
+
+	// An annotated tag is a tag object, which the ref points to instead of the commit.
+	target := commitSha
+	if tagMessage != "" {
+		tag, err := githubClient.CreateRepoTag(ctx, owner, name, &github.Tag{
+			Tag:     &tagName,
+			Message: &tagMessage,
+			Object: &github.GitObject{
+				Type: ptr.To("commit"),
+				SHA:  &commitSha,
+			},
+		})
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to create tag object")
+		}
+		target = tag.GetSHA()
 	}
 
 	var mutation struct {
@@ -466,63 +1157,352 @@ func (g *githubSource) InitialTag(ctx context.Context, accessToken *AccessToken,
 
 	input := githubv4.CreateRefInput{
 		RepositoryID: githubv4.ID(repo.NodeID),
-		Name:         githubv4.String("refs/tags/" + defaultTag),
-		Oid:          githubv4.GitObjectID(commitSha),
+		Name:         githubv4.String("refs/tags/" + tagName),
+		Oid:          githubv4.GitObjectID(target),
 	}
 
 	err = client.Mutate(ctx, &mutation, input, nil)
 	if err != nil {
-		return errors.Wrap(err, "failed to create commit")
+		return 0, errors.Wrap(err, "failed to create commit")
 	}
 
 	if workflowFileName != "" {
 		g.logger.Warn().Msgf("trigger manual dispatch for [%s] if a workflow run doesn't exist", workflowFileName)
 		return g.forceRerunWorkflow(ctx, githubClient, owner, name, workflowFileName)
 	}
+	return 0, nil
+}
+
+func (g *githubSource) HasWorkflow(ctx context.Context, accessToken *AccessToken, owner, repo, fileName string) (_ bool, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "HasWorkflow", owner, repo)(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	return hasWorkflow(ctx, githubClient, owner, repo, fileName)
+}
+
+func hasWorkflow(ctx context.Context, githubClient interactions.GithubIntr, owner, repo, fileName string) (bool, error) {
+	_, err := githubClient.GetWorkflowByFileName(ctx, owner, repo, fileName)
+	if err != nil {
+		if isGithubNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to get workflow '%s'", fileName)
+	}
+
+	return true, nil
+}
+
+func (g *githubSource) forceRerunWorkflow(ctx context.Context, githubClient interactions.GithubIntr, owner, name, workflowFileName string) (int64, error) {
+	runID, err := g.latestWorkflowRun(ctx, githubClient, owner, name, &github.ListWorkflowRunsOptions{})
+	if err == nil {
+		return runID, nil
+	}
+
+	if err := g.dispatchWorkflow(ctx, githubClient, owner, name, g.cfg.initialTag(), workflowFileName); err != nil {
+		return 0, err
+	}
+
+	// The dispatch API doesn't return the run it creates, so look it up.
+	runID, err = g.latestWorkflowRun(ctx, githubClient, owner, name, &github.ListWorkflowRunsOptions{Event: "workflow_dispatch"})
+	if err != nil {
+		g.logger.Debug().Err(err).Msgf("failed to find the run dispatched for [%s]", workflowFileName)
+		return 0, nil
+	}
+
+	return runID, nil
+}
+
+func (g *githubSource) TriggerBuild(ctx context.Context, accessToken *AccessToken, owner, repo, ref, workflowFileName string) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "TriggerBuild", owner, repo)(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	return g.dispatchWorkflow(ctx, githubClient, owner, repo, ref, workflowFileName)
+}
+
+func (g *githubSource) CreateWebhook(ctx context.Context, accessToken *AccessToken, owner, repo string, hook *Webhook) (_ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "CreateWebhook", owner, repo)(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	contentType := hook.ContentType
+	if contentType == "" {
+		contentType = "json"
+	}
+
+	events := hook.Events
+	if len(events) == 0 {
+		events = []string{"push"}
+	}
+
+	config := &github.HookConfig{
+		URL:         &hook.URL,
+		ContentType: &contentType,
+		InsecureSSL: ptr.To("0"),
+	}
+	if hook.Secret != "" {
+		config.Secret = &hook.Secret
+	}
+
+	created, _, err := githubClient.CreateHook(ctx, owner, repo, &github.Hook{
+		Name:   ptr.To("web"),
+		Config: config,
+		Events: events,
+		Active: ptr.To(true),
+	})
+	if err != nil {
+		return "", errors.Wrap(githubNotFoundError(err), "failed to create webhook")
+	}
+
+	return strconv.FormatInt(created.GetID(), 10), nil
+}
+
+func (g *githubSource) DeleteWebhook(ctx context.Context, accessToken *AccessToken, owner, repo, hookID string) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "DeleteWebhook", owner, repo)(&err)
+
+	id, err := strconv.ParseInt(hookID, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid webhook ID %s", hookID)
+	}
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	if _, err := githubClient.DeleteHook(ctx, owner, repo, id); err != nil {
+		return errors.Wrap(githubNotFoundError(err), "failed to delete webhook")
+	}
+
 	return nil
 }
 
-func (g *githubSource) forceRerunWorkflow(ctx context.Context, githubClient interactions.GithubIntr, owner, name, workflowFileName string) error {
-	err := retry.Retry(time.Second*time.Duration(g.cfg.WaitTagTimeoutSeconds), func(i int) error {
-		runs, err := githubClient.ListRepositoryWorkflowRuns(ctx, owner, name, &github.ListWorkflowRunsOptions{})
+func (g *githubSource) ListWebhooks(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ []*Webhook, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "ListWebhooks", owner, repo)(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	webhooks := []*Webhook{}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		hooks, response, err := githubClient.ListHooks(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, errors.Wrap(githubNotFoundError(err), "failed to list webhooks")
+		}
+
+		for _, hook := range hooks {
+			webhooks = append(webhooks, &Webhook{
+				ID:          strconv.FormatInt(hook.GetID(), 10),
+				URL:         hook.GetConfig().GetURL(),
+				Events:      hook.Events,
+				ContentType: hook.GetConfig().GetContentType(),
+			})
+		}
+
+		if response == nil || response.NextPage == 0 {
+			return webhooks, nil
+		}
+		opts.Page = response.NextPage
+	}
+}
+
+func (g *githubSource) ListPullRequests(ctx context.Context, accessToken *AccessToken, owner, repo, state string) (_ []*PullRequest, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "ListPullRequests", owner, repo)(&err)
+
+	state, err = pullRequestState(state)
+	if err != nil {
+		return nil, err
+	}
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	pullRequests := []*PullRequest{}
+	opts := &github.PullRequestListOptions{State: state, ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		pulls, response, err := githubClient.ListPullRequests(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, errors.Wrap(githubNotFoundError(err), "failed to list pull requests")
+		}
+
+		for _, pull := range pulls {
+			pullRequests = append(pullRequests, &PullRequest{
+				Number: pull.GetNumber(),
+				Title:  pull.GetTitle(),
+				URL:    pull.GetHTMLURL(),
+				Head:   pull.GetHead().GetRef(),
+				Base:   pull.GetBase().GetRef(),
+				State:  pull.GetState(),
+			})
+		}
+
+		if response == nil || response.NextPage == 0 {
+			return pullRequests, nil
+		}
+		opts.Page = response.NextPage
+	}
+}
+
+func (g *githubSource) AddDeployKey(ctx context.Context, accessToken *AccessToken, owner, repo, title, publicKey string, readOnly bool) (_ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "AddDeployKey", owner, repo)(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	created, _, err := githubClient.CreateKey(ctx, owner, repo, &github.Key{
+		Title:    &title,
+		Key:      &publicKey,
+		ReadOnly: &readOnly,
+	})
+	if err != nil {
+		return "", errors.Wrap(githubNotFoundError(err), "failed to add deploy key")
+	}
+
+	return strconv.FormatInt(created.GetID(), 10), nil
+}
+
+func (g *githubSource) HasDeployKey(ctx context.Context, accessToken *AccessToken, owner, repo, title string) (_ bool, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "HasDeployKey", owner, repo)(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		keys, response, err := githubClient.ListKeys(ctx, owner, repo, opts)
+		if err != nil {
+			return false, errors.Wrap(githubNotFoundError(err), "failed to list deploy keys")
+		}
+
+		for _, key := range keys {
+			if key.GetTitle() == title {
+				return true, nil
+			}
+		}
+
+		if response == nil || response.NextPage == 0 {
+			return false, nil
+		}
+		opts.Page = response.NextPage
+	}
+}
+
+func (g *githubSource) DeleteDeployKey(ctx context.Context, accessToken *AccessToken, owner, repo, keyID string) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "DeleteDeployKey", owner, repo)(&err)
+
+	id, err := strconv.ParseInt(keyID, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid deploy key ID %s", keyID)
+	}
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	if _, err := githubClient.DeleteKey(ctx, owner, repo, id); err != nil {
+		return errors.Wrap(githubNotFoundError(err), "failed to delete deploy key")
+	}
+
+	return nil
+}
+
+func (g *githubSource) GetBranchHead(ctx context.Context, accessToken *AccessToken, owner, repo, branch string) (_ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "GetBranchHead", owner, repo)(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	ref, _, err := githubClient.GetRepoRef(ctx, owner, repo, "heads/"+branch)
+	if err != nil {
+		return "", errors.Wrapf(githubNotFoundError(err), "failed to get branch %s", branch)
+	}
+
+	return ref.GetObject().GetSHA(), nil
+}
+
+// dispatchWorkflow runs the workflow workflowFileName on ref through a workflow_dispatch event.
+func (g *githubSource) dispatchWorkflow(ctx context.Context, githubClient interactions.GithubIntr, owner, name, ref, workflowFileName string) error {
+	event := github.CreateWorkflowDispatchEventRequest{
+		Ref: ref,
+	}
+	g.logger.Debug().Msgf("triggering workflow dispatch event for [%s]", workflowFileName)
+	err := githubClient.CreateWorkflowDispatchEventByFileName(ctx, owner, name, workflowFileName, event)
+	if err != nil {
+		// A missing workflow file fails the dispatch with an opaque error.
+		if found, checkErr := hasWorkflow(ctx, githubClient, owner, name, workflowFileName); checkErr == nil && !found {
+			return errx.ErrNotFound.Err(err).Msgf("workflow file not found in repo: %s", workflowFileName)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// latestWorkflowRun waits for the repo to have a workflow run and returns the ID of the most recent one.
+func (g *githubSource) latestWorkflowRun(
+	ctx context.Context,
+	githubClient interactions.GithubIntr,
+	owner, name string,
+	opts *github.ListWorkflowRunsOptions,
+) (int64, error) {
+	var runID int64
+	err := retry.RetryIfContext(ctx, time.Second*time.Duration(g.cfg.WaitTagTimeoutSeconds), isRetryable, func(i int) error {
+		runs, err := githubClient.ListRepositoryWorkflowRuns(ctx, owner, name, opts)
 		if err != nil {
 			return err
 		}
 		if runs == nil || len(runs.WorkflowRuns) == 0 {
 			return errors.New("No workflows were triggered")
 		}
+		runID = runs.WorkflowRuns[0].GetID()
 		return nil
 	})
 
-	if err != nil {
-		event := github.CreateWorkflowDispatchEventRequest{
-			Ref: defaultTag,
-		}
-		g.logger.Debug().Msgf("triggering workflow dispatch event for [%s]", workflowFileName)
-		err = githubClient.CreateWorkflowDispatchEventByFileName(ctx, owner, name, workflowFileName, event)
+	return runID, err
+}
+
+// WaitForWorkflowRun polls a workflow run until it completes and returns its conclusion. It gives up after
+// WaitTagTimeoutSeconds with an ErrRetryTimeout, and right away when GitHub answers with a 4xx status other than 429.
+func (g *githubSource) WaitForWorkflowRun(ctx context.Context, accessToken *AccessToken, owner, repo string, runID int64) (_ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "WaitForWorkflowRun", owner, repo)(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	var conclusion string
+	err = retry.RetryIfContext(ctx, time.Duration(g.cfg.WaitTagTimeoutSeconds)*time.Second, isRetryable, func(i int) error {
+		run, err := githubClient.GetWorkflowRunByID(ctx, owner, repo, runID)
 		if err != nil {
 			return err
 		}
+
+		if run.GetStatus() != "completed" {
+			return errors.Wrapf(ErrWorkflowRunNotComplete, "run %d is %s", runID, run.GetStatus())
+		}
+
+		conclusion = run.GetConclusion()
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
 
-	return nil
+	return conclusion, nil
 }
 
-func (g *githubSource) CreateCommitOnBranch(ctx context.Context, accessToken *AccessToken, commit *Commit) (string, error) {
-	src := oauth2.StaticTokenSource(
-		&oauth2.Token{
-			AccessToken: accessToken.Token,
-			TokenType:   accessToken.Type,
-		},
-	)
-	httpClient := oauth2.NewClient(ctx, src)
-	client := githubv4.NewClient(httpClient)
+func (g *githubSource) CreateCommitOnBranch(ctx context.Context, accessToken *AccessToken, commit *Commit) (_ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "CreateCommitOnBranch", commit.Owner, commit.Repo)(&err)
 
-	var filePath, content string
-	for path, cont := range commit.Content {
-		filePath = path
-		content = cont
-		break
+	if len(commit.Content) == 0 {
+		return "", errors.New("commit must contain at least one file")
+	}
+
+	if g.cfg.GithubRESTCommits {
+		return g.createCommitWithContents(ctx, accessToken, commit)
+	}
+
+	client := g.graphqlFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	paths := make([]string, 0, len(commit.Content))
+	for path := range commit.Content {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var filePath string
+	if len(paths) > 0 {
+		filePath = paths[0]
 	}
 
 	var query struct {
@@ -544,7 +1524,7 @@ func (g *githubSource) CreateCommitOnBranch(ctx context.Context, accessToken *Ac
 		"owner":         githubv4.String(commit.Owner),
 		"repo":          githubv4.String(commit.Repo),
 		"qualifiedName": githubv4.String(commit.Branch),
-		"expression":    githubv4.String(fmt.Sprintf("HEAD:%s", filePath)),
+		"expression":    githubv4.String(fmt.Sprintf("%s:%s", commit.Branch, filePath)),
 	}
 
 	var mutation struct {
@@ -555,36 +1535,71 @@ func (g *githubSource) CreateCommitOnBranch(ctx context.Context, accessToken *Ac
 		} `graphql:"createCommitOnBranch(input: $input)"`
 	}
 
-	err := retry.Retry(time.Second*time.Duration(g.cfg.CreateRepoTimeoutSeconds), func(i int) error {
-		err := client.Query(ctx, &query, variables)
-		if err != nil {
-			return errors.Wrap(err, "failed to query latest commit")
+	if commit.StartBranch != "" {
+		if err := g.ensureBranch(ctx, accessToken, commit); err != nil {
+			return "", err
 		}
+	}
 
-		ref := query.Repository.Ref.Target.Oid
-		if ref == "" {
-			return errors.Wrapf(ErrEmptyRepo, "%s/%s", commit.Owner, commit.Repo)
-		}
+	// conflict is set when the branch moved under the commit for good, which retrying doesn't fix.
+	var conflict error
 
-		configContent := query.Repository.Object.Blob.Text
+	err = retry.RetryIfContext(ctx, time.Second*time.Duration(g.cfg.CreateRepoTimeoutSeconds), isRetryable, func(i int) error {
+		for rebase := 0; ; rebase++ {
+			err := client.Query(ctx, &query, variables)
+			if err != nil {
+				return errors.Wrap(err, "failed to query latest commit")
+			}
 
-		mutationVariables := createCommitOnBranchInput(ref, commit)
+			ref := query.Repository.Ref.Target.Oid
+			if ref == "" {
+				return errors.Wrapf(ErrEmptyRepo, "%s/%s", commit.Owner, commit.Repo)
+			}
 
-		if configContent != "" && configContent == githubv4.String(content) {
-			return nil
-		}
+			configContent := query.Repository.Object.Blob.Text
 
-		err = client.Mutate(ctx, &mutation, mutationVariables, nil)
-		if err != nil {
-			return errors.Wrap(err, "failed to create commit")
-		}
+			mutationVariables := createCommitOnBranchInput(ref, commit)
 
-		return nil
+			if configContent != "" && configContent == githubv4.String(commit.Content[filePath]) {
+				// The first file is unchanged, the commit is a no-op only if all the other files are unchanged too.
+				unchanged, err := filesUnchanged(ctx, client, commit, paths[1:])
+				if err != nil {
+					return err
+				}
+				if unchanged {
+					return nil
+				}
+			}
+
+			err = client.Mutate(ctx, &mutation, mutationVariables, nil)
+			if err == nil {
+				return nil
+			}
+
+			if !isHeadMismatch(err) {
+				return errors.Wrap(err, "failed to create commit")
+			}
+			if commit.ConflictStrategy != ConflictRebase || rebase >= maxRebaseAttempts {
+				conflict = errx.ErrConcurrentUpdate.Err(errors.Wrap(err, "failed to create commit")).
+					Str("branch", commit.Branch).
+					Msgf("branch [%s] of %s/%s changed, retry the commit", commit.Branch, commit.Owner, commit.Repo)
+				return nil
+			}
+
+			g.logger.Debug().Msgf("branch [%s] of %s/%s moved, rebasing commit onto the new head", commit.Branch, commit.Owner, commit.Repo)
+		}
 	})
 
+	if errors.Is(err, ErrEmptyRepo) {
+		// Waiting didn't give the repo a first commit, report the empty repo rather than the retry timeout.
+		return "", errors.Wrapf(ErrEmptyRepo, "%s/%s", commit.Owner, commit.Repo)
+	}
 	if err != nil {
 		return "", err
 	}
+	if conflict != nil {
+		return "", conflict
+	}
 
 	if mutation.CreateCommitOnBranch.Commit.OID == "" {
 		return "", nil
@@ -593,6 +1608,211 @@ func (g *githubSource) CreateCommitOnBranch(ctx context.Context, accessToken *Ac
 	return g.waitForCommit(ctx, accessToken, commit.Owner, commit.Repo, mutation.CreateCommitOnBranch.Commit.OID)
 }
 
+// createCommitWithContents commits the files through the REST git data API: it creates a tree with the files on top
+// of the head of the branch and a single commit of it, then fast forwards the branch to that commit, so that either
+// all the files are committed or none. Nothing is committed when the files already have the commit content.
+func (g *githubSource) createCommitWithContents(ctx context.Context, accessToken *AccessToken, commit *Commit) (string, error) {
+	if commit.StartBranch != "" {
+		if err := g.ensureBranch(ctx, accessToken, commit); err != nil {
+			return "", err
+		}
+	}
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	paths := make([]string, 0, len(commit.Content))
+	for path := range commit.Content {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for rebase := 0; ; rebase++ {
+		sha, err := commitTree(ctx, githubClient, commit, paths)
+		if err == nil {
+			if sha == "" {
+				return "", nil
+			}
+
+			return g.waitForCommit(ctx, accessToken, commit.Owner, commit.Repo, sha)
+		}
+		if commit.ConflictStrategy != ConflictRebase || rebase >= maxRebaseAttempts || !errx.ErrConcurrentUpdate.SameAs(err) {
+			return "", err
+		}
+
+		g.logger.Debug().Msgf("branch [%s] of %s/%s moved, rebasing commit onto the new head", commit.Branch, commit.Owner, commit.Repo)
+	}
+}
+
+// commitTree commits the files at paths onto the current head of the branch of the commit, and returns the SHA of
+// the commit it created, or an empty SHA when the files already have the commit content.
+func commitTree(ctx context.Context, githubClient interactions.GithubIntr, commit *Commit, paths []string) (string, error) {
+	ref, _, err := githubClient.GetRepoRef(ctx, commit.Owner, commit.Repo, "heads/"+commit.Branch)
+	if err != nil {
+		// GitHub answers 409 for the refs of repos without commits.
+		var errResp *github.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusConflict {
+			return "", errors.Wrapf(ErrEmptyRepo, "%s/%s", commit.Owner, commit.Repo)
+		}
+
+		return "", errors.Wrapf(githubNotFoundError(err), "failed to get branch %s", commit.Branch)
+	}
+
+	head := ref.GetObject().GetSHA()
+
+	parent, err := githubClient.GetCommit(ctx, commit.Owner, commit.Repo, head)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get commit %s", head)
+	}
+
+	entries := make([]*github.TreeEntry, 0, len(paths))
+	for _, path := range paths {
+		entries = append(entries, &github.TreeEntry{
+			Path:    ptr.To(path),
+			Mode:    ptr.To("100644"),
+			Type:    ptr.To("blob"),
+			Content: ptr.To(commit.Content[path]),
+		})
+	}
+
+	baseTree := parent.GetTree().GetSHA()
+
+	tree, err := githubClient.CreateTree(ctx, commit.Owner, commit.Repo, baseTree, entries)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create tree")
+	}
+	if tree.GetSHA() == baseTree {
+		return "", nil
+	}
+
+	newCommit := &github.Commit{
+		Message: ptr.To(commit.Message),
+		Tree:    &github.Tree{SHA: ptr.To(tree.GetSHA())},
+		Parents: []*github.Commit{{SHA: ptr.To(head)}},
+	}
+	if commit.Author != nil {
+		newCommit.Author = &github.CommitAuthor{Name: ptr.To(commit.Author.Name), Email: ptr.To(commit.Author.Email)}
+	}
+
+	created, err := githubClient.CreateCommit(ctx, commit.Owner, commit.Repo, newCommit)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create commit")
+	}
+	if created.GetSHA() == "" {
+		return "", errors.New("github returned no commit")
+	}
+
+	_, err = githubClient.UpdateRef(ctx, commit.Owner, commit.Repo, &github.Reference{
+		Ref:    ptr.To("refs/heads/" + commit.Branch),
+		Object: &github.GitObject{SHA: ptr.To(created.GetSHA())},
+	}, false)
+	if err != nil {
+		return "", updateRefError(err, commit)
+	}
+
+	return created.GetSHA(), nil
+}
+
+// updateRefError returns ErrConcurrentUpdate when GitHub refused to move the branch because it moved meanwhile,
+// which makes the move no longer a fast forward.
+func updateRefError(err error, commit *Commit) error {
+	var errResp *github.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusUnprocessableEntity {
+		return errx.ErrConcurrentUpdate.Err(errors.Wrapf(err, "failed to update branch %s", commit.Branch)).
+			Str("branch", commit.Branch).
+			Msgf("branch [%s] of %s/%s changed, retry the commit", commit.Branch, commit.Owner, commit.Repo)
+	}
+
+	return errors.Wrapf(err, "failed to update branch %s", commit.Branch)
+}
+
+// ensureBranch creates the branch of the commit from its StartBranch when it doesn't exist yet.
+func (g *githubSource) ensureBranch(ctx context.Context, accessToken *AccessToken, commit *Commit) error {
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	_, _, err := githubClient.GetRepoRef(ctx, commit.Owner, commit.Repo, "heads/"+commit.Branch)
+	if err == nil {
+		return nil
+	}
+	if !isGithubNotFound(err) {
+		return errors.Wrapf(err, "failed to get branch %s", commit.Branch)
+	}
+
+	start, _, err := githubClient.GetRepoRef(ctx, commit.Owner, commit.Repo, "heads/"+commit.StartBranch)
+	if err != nil {
+		return errors.Wrapf(githubNotFoundError(err), "failed to get start branch %s", commit.StartBranch)
+	}
+
+	err = githubClient.CreateRepoRef(ctx, commit.Owner, commit.Repo, &github.Reference{
+		Ref:    ptr.To("refs/heads/" + commit.Branch),
+		Object: &github.GitObject{SHA: ptr.To(start.GetObject().GetSHA())},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create branch %s from %s", commit.Branch, commit.StartBranch)
+	}
+
+	return nil
+}
+
+func (g *githubSource) CreateFile(ctx context.Context, accessToken *AccessToken, owner, repo, path, content, branch, message string) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "CreateFile", owner, repo)(&err)
+
+	client := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	opts := &github.RepositoryContentFileOptions{
+		Message: &message,
+		Content: []byte(content),
+	}
+	if branch != "" {
+		opts.Branch = &branch
+	}
+
+	_, err = client.CreateFile(ctx, owner, repo, path, opts)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create file: %s", path)
+	}
+
+	return nil
+}
+
+// filesUnchanged reports whether all the given files already have the commit content on the commit branch.
+func filesUnchanged(ctx context.Context, client interactions.GraphqlIntr, commit *Commit, paths []string) (bool, error) {
+	for _, path := range paths {
+		var query struct {
+			Repository struct {
+				Object struct {
+					Blob struct {
+						Text githubv4.String
+					} `graphql:"... on Blob"`
+				} `graphql:"object(expression: $expression)"`
+			} `graphql:"repository(owner: $owner, name: $repo)"`
+		}
+
+		variables := map[string]interface{}{
+			"owner":      githubv4.String(commit.Owner),
+			"repo":       githubv4.String(commit.Repo),
+			"expression": githubv4.String(fmt.Sprintf("%s:%s", commit.Branch, path)),
+		}
+
+		err := client.Query(ctx, &query, variables)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to query content of '%s'", path)
+		}
+
+		content := query.Repository.Object.Blob.Text
+		if content == "" || content != githubv4.String(commit.Content[path]) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// isHeadMismatch reports whether a createCommitOnBranch mutation was rejected because
+// the branch no longer points to the expected head.
+func isHeadMismatch(err error) bool {
+	return strings.Contains(err.Error(), headMismatchMessage) || strings.Contains(err.Error(), staleDataType)
+}
+
 func createCommitOnBranchInput(ref githubv4.String, commit *Commit) githubv4.CreateCommitOnBranchInput {
 	branch := githubv4.String(commit.Branch)
 	repoNameWithOwner := githubv4.String(fmt.Sprintf("%s/%s", commit.Owner, commit.Repo))
@@ -628,40 +1848,173 @@ func createCommitOnBranchInput(ref githubv4.String, commit *Commit) githubv4.Cre
 }
 
 func (g *githubSource) hasSecret(ctx context.Context, githubClient interactions.GithubIntr, owner, repo, secretName string) (bool, error) {
-	var existingSecrets *github.Secrets
-	err := retry.Retry(time.Duration(g.cfg.CreateRepoTimeoutSeconds)*time.Second, func(i int) error {
-		var err error
-		existingSecrets, err = githubClient.ListRepoSecrets(ctx, owner, repo, &github.ListOptions{})
-		return err
-	})
-	if err != nil {
-		return false, errors.Wrap(err, "failed to list repo secrets")
+	opts := &github.ListOptions{Page: 1, PerPage: 100}
+
+	for {
+		var existingSecrets *github.Secrets
+		var response *github.Response
+		err := retry.RetryIfContext(ctx, time.Duration(g.cfg.CreateRepoTimeoutSeconds)*time.Second, isRetryable, func(i int) error {
+			var err error
+			existingSecrets, response, err = githubClient.ListRepoSecrets(ctx, owner, repo, opts)
+			return err
+		})
+		if err != nil {
+			return false, errors.Wrap(githubNotFoundError(err), "failed to list repo secrets")
+		}
+		if existingSecrets == nil {
+			return false, nil
+		}
+
+		for _, secret := range existingSecrets.Secrets {
+			if secret.Name == secretName {
+				return true, nil
+			}
+		}
+
+		if response == nil || response.NextPage == 0 {
+			return false, nil
+		}
+		opts.Page = response.NextPage
 	}
+}
 
-	for _, secret := range existingSecrets.Secrets {
-		if secret.Name == secretName {
-			return true, nil
+// GetFileContent returns the content of a file at the given ref, or on the default branch when ref is empty.
+// The bool is false when the file doesn't exist.
+func (g *githubSource) GetFileContent(ctx context.Context, accessToken *AccessToken, owner, repo, path, ref string) (_ string, _ bool, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "GetFileContent", owner, repo)(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	fileContent, err := githubClient.GetFileContents(ctx, owner, repo, path, &github.RepositoryContentGetOptions{Ref: ref})
+	if err != nil {
+		if isGithubNotFound(err) {
+			return "", false, nil
 		}
+		return "", false, errors.Wrapf(err, "failed to get file: %s", path)
 	}
 
-	return false, nil
+	if fileContent == nil {
+		return "", false, errors.Errorf("'%s' is not a file", path)
+	}
+
+	content, err := fileContent.GetContent()
+	if err != nil {
+		return "", false, errors.Wrapf(err, "failed to decode file: %s", path)
+	}
+
+	return content, true, nil
 }
 
-func (g *githubSource) GetDefaultBranch(ctx context.Context, accessToken *AccessToken, owner, repo string) (string, error) {
-	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount)
+func (g *githubSource) GetDefaultBranch(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "GetDefaultBranch", owner, repo)(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
 
 	gitRepo, err := githubClient.GetRepo(ctx, owner, repo)
 	if err != nil {
-		return "", errors.Wrap(err, "failed to get repo")
+		return "", errors.Wrap(githubNotFoundError(err), "failed to get repo")
 	}
 
 	return *gitRepo.DefaultBranch, nil
 }
 
+// ListTags lists the tags of a repo. The page token is the number of the page to read.
+// GitHub doesn't support ordering tags, so they are returned in the order the API provides them.
+func (g *githubSource) ListTags(ctx context.Context, accessToken *AccessToken, owner, repo string, page *api.PaginationRequest) (_ []string, _ *api.PaginationResponse, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "ListTags", owner, repo)(&err)
+
+	if page == nil {
+		return nil, nil, errors.New("page must not be empty")
+	}
+	page = g.cfg.pageRequest(page)
+	if page.Size < -1 || page.Size > 100 {
+		return nil, nil, errors.New("page size must be >= -1 and <= 100")
+	}
+
+	pageToRead := 1
+	if strings.TrimSpace(page.Token) != "" {
+		var err error
+		pageToRead, err = strconv.Atoi(page.Token)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "page token must be int")
+		}
+	}
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	opts := &github.ListOptions{Page: pageToRead, PerPage: int(page.Size)}
+	if page.Size == -1 {
+		opts.PerPage = 100
+	}
+
+	result := []string{}
+
+	for {
+		tags, err := githubClient.ListRepoTags(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to list tags for repo '%s/%s'", owner, repo)
+		}
+
+		for _, tag := range tags {
+			result = append(result, tag.GetName())
+		}
+
+		// GitHub doesn't report the total number of tags, a full page means there might be more.
+		hasNextPage := len(tags) == opts.PerPage
+
+		if page.Size != -1 {
+			resp := &api.PaginationResponse{
+				ResultSize: safeInt32(len(result)),
+			}
+			if hasNextPage {
+				resp.NextToken = strconv.Itoa(opts.Page + 1)
+			}
+			return result, resp, nil
+		}
+
+		if !hasNextPage {
+			break
+		}
+		opts.Page++
+	}
+
+	resp := &api.PaginationResponse{
+		NextToken:  "",
+		ResultSize: safeInt32(len(result)),
+		TotalSize:  safeInt32(len(result)),
+	}
+
+	return result, resp, nil
+}
+
+// IsRepoEmpty returns true if the default branch of the repo doesn't point to any commit yet.
+func (g *githubSource) IsRepoEmpty(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ bool, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "github", "IsRepoEmpty", owner, repo)(&err)
+
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
+
+	gitRepo, err := githubClient.GetRepo(ctx, owner, repo)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get repo")
+	}
+
+	_, response, err := githubClient.GetRepoRef(ctx, owner, repo, "heads/"+gitRepo.GetDefaultBranch())
+	if err != nil {
+		// GitHub replies with 409 for refs of empty repos, and 404 when the default branch has no commits.
+		if response != nil && (response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusConflict) {
+			return true, nil
+		}
+		return false, errors.Wrapf(err, "failed to get default branch of repo '%s/%s'", owner, repo)
+	}
+
+	return false, nil
+}
+
 func (g *githubSource) waitForCommit(ctx context.Context, accessToken *AccessToken, owner, repo, sha string) (string, error) {
-	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount)
+	githubClient := g.interactionsFunc(ctx, accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.httpTimeout(), g.cfg.userAgent())
 
-	err := retry.Retry(time.Duration(g.cfg.WaitTagTimeoutSeconds)*time.Second, func(i int) error {
+	// A commit that was just created can answer 404 or 422 for a moment, so all the errors are retried.
+	err := retry.RetryContext(ctx, time.Duration(g.cfg.WaitTagTimeoutSeconds)*time.Second, func(i int) error {
 		commit, err := githubClient.GetCommit(ctx, owner, repo, sha)
 		if err != nil {
 			return err
@@ -702,3 +2055,37 @@ func encryptSecretWithPublicKey(publicKey *github.PublicKey, secretValue string)
 
 	return encoded, nil
 }
+
+// githubNotFoundError turns a 404 reply from the GitHub REST API into ErrNotFound carrying the status code,
+// and returns err unchanged otherwise.
+func githubNotFoundError(err error) error {
+	if isGithubNotFound(err) {
+		return errx.ErrNotFound.Err(err).Int(errx.StatusCodeKey, http.StatusNotFound)
+	}
+
+	return err
+}
+
+// commitExists returns ErrCommitNotFound when sha isn't a commit of the repo. GitHub answers 409 for empty repos
+// and 422 for malformed SHAs, on top of 404 for unknown ones.
+func commitExists(ctx context.Context, githubClient interactions.GithubIntr, owner, repo, sha string) error {
+	_, err := githubClient.GetCommit(ctx, owner, repo, sha)
+	if err == nil {
+		return nil
+	}
+
+	var errResp *github.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil {
+		switch errResp.Response.StatusCode {
+		case http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity:
+			return errors.Wrapf(ErrCommitNotFound, "%s/%s: %s", owner, repo, sha)
+		}
+	}
+
+	return errors.Wrapf(err, "failed to get commit %s", sha)
+}
+
+func isGithubNotFound(err error) bool {
+	var errResp *github.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusNotFound
+}
@@ -2,20 +2,28 @@ package sources_test
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	cerr "github.com/aserto-dev/errors"
 	"github.com/aserto-dev/go-grpc/aserto/api/v1"
+	"github.com/aserto-dev/scc-lib/errx"
 	"github.com/aserto-dev/scc-lib/internal/interactions"
 	"github.com/aserto-dev/scc-lib/sources"
 	"github.com/google/go-github/v66/github"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
+	"github.com/shurcooL/githubv4"
+	"github.com/shurcooL/graphql"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"k8s.io/utils/ptr"
 )
 
 const (
@@ -44,15 +52,24 @@ func setup(t *testing.T) testInteractions {
 		ctrl:        ctrl,
 		mockGithub:  mockGithubIntr,
 		mockGraphql: mockGraphqlIntr,
-		mockGithubIntrFunc: func(ctx context.Context, token, tokenType string, rateLimitTimeout, retryCount int) interactions.GithubIntr {
+		mockGithubIntrFunc: func(ctx context.Context, token, tokenType string, rateLimitTimeout, retryCount int, httpTimeout time.Duration, userAgent string) interactions.GithubIntr {
 			return mockGithubIntr
 		},
-		mockGraphqlIntrFunc: func(ctx context.Context, token, tokenType string, rateLimitTimeout, retryCount int) interactions.GraphqlIntr {
+		mockGraphqlIntrFunc: func(ctx context.Context, token, tokenType string, rateLimitTimeout, retryCount int, httpTimeout time.Duration, userAgent string) interactions.GraphqlIntr {
 			return mockGraphqlIntr
 		},
 	}
 }
 
+// setQueryField sets a nested string field on the graphql query or mutation passed to a mocked call.
+func setQueryField(target interface{}, value string, path ...string) {
+	v := reflect.ValueOf(target).Elem()
+	for _, name := range path {
+		v = v.FieldByName(name)
+	}
+	v.SetString(value)
+}
+
 func TestMockGithubConstructor(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
@@ -124,7 +141,7 @@ func TestGithubValidateConnectionErrorResponse(t *testing.T) {
 	assert.Contains(assertoErr.Data()["msg"], "unexpected reply from GitHub")
 }
 
-func TestGithubValidateConnection(t *testing.T) {
+func TestGithubValidateConnectionNilResponse(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
@@ -132,19 +149,19 @@ func TestGithubValidateConnection(t *testing.T) {
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
 	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	resp := &github.Response{Response: &http.Response{StatusCode: 200}}
 
 	// Expect
-	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(nil, resp, nil)
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(nil, nil, nil)
 
 	// Act
 	err := p.ValidateConnection(context.Background(), token, []string{})
 
 	// Assert
-	assert.NoError(err)
+	assert.Error(err)
+	assert.True(errx.ErrProviderVerification.SameAs(err))
 }
 
-func TestGithubValidateConnectionScopes(t *testing.T) {
+func TestGithubValidateConnectionNilBody(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
@@ -152,387 +169,396 @@ func TestGithubValidateConnectionScopes(t *testing.T) {
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
 	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	resp := &github.Response{Response: &http.Response{StatusCode: 200, Header: http.Header{}}}
-	resp.Response.Header.Set("X-OAuth-Scopes", "repo,user,admin:org")
+	resp := &github.Response{Response: &http.Response{StatusCode: 502, Status: "Bad Gateway"}}
 
 	// Expect
 	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(nil, resp, nil)
 
 	// Act
-	err := p.ValidateConnection(context.Background(), token, []string{"repo", "user", "(admin|read):org"})
+	err := p.ValidateConnection(context.Background(), token, []string{})
 
 	// Assert
-	assert.NoError(err)
+	assert.Error(err)
+	assertoErr := cerr.UnwrapAsertoError(err)
+	assert.Contains(assertoErr.Data()["msg"], "unexpected reply from GitHub")
+	assert.Equal("<no response body>", assertoErr.Data()["github-response"])
 }
 
-func TestGithubProfileQueryFails(t *testing.T) {
+func TestGithubValidateConnectionWithCustomClient(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
-	tstInteraction := setup(t)
-	mockintrGh := tstInteraction.mockGithubIntrFunc
-	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
-	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		assert.Equal("/user", req.URL.Path)
+		assert.Equal("Bearer sometokenvalue", req.Header.Get("Authorization"))
+		return jsonResponse(req, `{"login": "aserto"}`), nil
+	})}
+	p := sources.NewGithubWithClient(&zerolog.Logger{}, &sources.Config{}, httpClient)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
 
-	// Expect
-	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("boom"))
-
 	// Act
-	username, repos, err := p.Profile(context.Background(), token)
+	err := p.ValidateConnection(context.Background(), token, []string{})
 
 	// Assert
-	assert.Error(err)
-	assert.Contains(err.Error(), "error running query against github graphql server: boom")
-	assert.Empty(username)
-	assert.Nil(repos)
+	assert.NoError(err)
 }
 
-func TestGithubProfile(t *testing.T) {
+func TestGithubValidateConnectionCancelledDuringRateLimitWait(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
-	tstInteraction := setup(t)
-	mockintrGh := tstInteraction.mockGithubIntrFunc
-	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
-	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp := jsonResponse(req, `{"message": "You have exceeded a secondary rate limit.", "documentation_url": "https://docs.github.com/rest/overview/rate-limits-for-the-rest-api#about-secondary-rate-limits"}`)
+		resp.StatusCode = http.StatusForbidden
+		resp.Status = "403 Forbidden"
+		resp.Header.Set("Retry-After", "60")
+		return resp, nil
+	})}
+	cfg := &sources.Config{RateLimitRetryCount: 3, RateLimitTimeoutSeconds: 120}
+	p := sources.NewGithubWithClient(&zerolog.Logger{}, cfg, httpClient)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-
-	// Expect
-	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
 
 	// Act
-	username, repos, err := p.Profile(context.Background(), token)
+	err := p.ValidateConnection(ctx, token, []string{})
 
 	// Assert
-	assert.NoError(err)
-	assert.Empty(username)
-	assert.Empty(repos)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.Less(time.Since(start), 10*time.Second)
 }
 
-func TestGithubHasSecretFails(t *testing.T) {
+func TestGithubValidateConnectionRetryAfterPastTimeout(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
-	tstInteraction := setup(t)
-	mockintrGh := tstInteraction.mockGithubIntrFunc
-	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
-	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	calls := 0
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		resp := jsonResponse(req, `{"message": "You have exceeded a secondary rate limit.", "documentation_url": "https://docs.github.com/rest/overview/rate-limits-for-the-rest-api#about-secondary-rate-limits"}`)
+		resp.StatusCode = http.StatusForbidden
+		resp.Status = "403 Forbidden"
+		resp.Header.Set("Retry-After", "60")
+		return resp, nil
+	})}
+	cfg := &sources.Config{RateLimitRetryCount: 3, RateLimitTimeoutSeconds: 1}
+	p := sources.NewGithubWithClient(&zerolog.Logger{}, cfg, httpClient)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-
-	// Expect
-	tstInteraction.mockGithub.EXPECT().ListRepoSecrets(gomock.Any(), githubUsername, policyRepo, gomock.Any()).Return(nil, errors.New("Failed to get secret"))
+	start := time.Now()
 
 	// Act
-	exists, err := p.HasSecret(context.Background(), token, githubUsername, policyRepo, "ASERTO_PUSH_KEY")
+	err := p.ValidateConnection(context.Background(), token, []string{})
 
 	// Assert
 	assert.Error(err)
-	assert.Contains(err.Error(), "failed to list repo secrets: E10034 timeout after multiple retries: Failed to get secret")
-	assert.False(exists)
+	assert.True(errx.ErrRetryTimeout.SameAs(err))
+	assert.Contains(err.Error(), "secondary rate limit")
+	assert.Equal(1, calls)
+	assert.Less(time.Since(start), 10*time.Second)
 }
 
-func TestGithubHasSecretTrue(t *testing.T) {
+func TestGithubValidateConnectionSendsUserAgent(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
-	tstInteraction := setup(t)
-	mockintrGh := tstInteraction.mockGithubIntrFunc
-	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
-	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
-	secret := &github.Secret{Name: "ASERTO_PUSH_KEY"}
-	secrets := []*github.Secret{secret}
-	result := &github.Secrets{Secrets: secrets}
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		assert.Equal("policy-onboarding/1.0", req.Header.Get("User-Agent"))
+		return jsonResponse(req, `{"login": "aserto"}`), nil
+	})}
+	p := sources.NewGithubWithClient(&zerolog.Logger{}, &sources.Config{UserAgent: "policy-onboarding/1.0"}, httpClient)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
 
-	// Expect
-	tstInteraction.mockGithub.EXPECT().ListRepoSecrets(gomock.Any(), githubUsername, policyRepo, gomock.Any()).Return(result, nil)
-
 	// Act
-	exists, err := p.HasSecret(context.Background(), token, githubUsername, policyRepo, "ASERTO_PUSH_KEY")
+	err := p.ValidateConnection(context.Background(), token, []string{})
 
 	// Assert
 	assert.NoError(err)
-	assert.True(exists)
 }
 
-func TestGithubHasSecretFalse(t *testing.T) {
+func TestGithubValidateConnectionDefaultUserAgent(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
-	tstInteraction := setup(t)
-	mockintrGh := tstInteraction.mockGithubIntrFunc
-	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
-	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
-	secret := &github.Secret{Name: "other_secret"}
-	secrets := []*github.Secret{secret}
-	result := &github.Secrets{Secrets: secrets}
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		assert.Equal("aserto-scc-lib/dev", req.Header.Get("User-Agent"))
+		return jsonResponse(req, `{"login": "aserto"}`), nil
+	})}
+	p := sources.NewGithubWithClient(&zerolog.Logger{}, &sources.Config{}, httpClient)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
 
-	// Expect
-	tstInteraction.mockGithub.EXPECT().ListRepoSecrets(gomock.Any(), githubUsername, policyRepo, gomock.Any()).Return(result, nil)
-
 	// Act
-	exists, err := p.HasSecret(context.Background(), token, githubUsername, policyRepo, "ASERTO_PUSH_KEY")
+	err := p.ValidateConnection(context.Background(), token, []string{})
 
 	// Assert
 	assert.NoError(err)
-	assert.False(exists)
 }
 
-func TestAddSecretToRepoNoOrg(t *testing.T) {
+func TestGithubCloseClosesIdleConnections(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
-	tstInteraction := setup(t)
-	mockintrGh := tstInteraction.mockGithubIntrFunc
-	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
-	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
-	token := &sources.AccessToken{Token: "sometokenvalue"}
+	transport := &idleClosingTransport{}
+	p := sources.NewGithubWithClient(&zerolog.Logger{}, &sources.Config{}, &http.Client{Transport: transport})
 
 	// Act
-	err := p.AddSecretToRepo(context.Background(), token, "", policyRepo, "ASERTO_PUSH_KEY", "value", false)
+	err := p.Close()
 
 	// Assert
-	assert.Error(err)
-	assert.Equal(err.Error(), "No org name was provided")
+	assert.NoError(err)
+	assert.True(transport.closed)
 }
 
-func TestAddSecretToRepoNoRepo(t *testing.T) {
+func TestGithubPingUnauthorized(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
 	mockintrGh := tstInteraction.mockGithubIntrFunc
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
-	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
-	token := &sources.AccessToken{Token: "sometokenvalue"}
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	resp := &github.Response{Response: &http.Response{StatusCode: http.StatusUnauthorized, Status: "401 Unauthorized"}}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().Zen(gomock.Any()).Return(resp, errors.New("401 Bad credentials"))
 
 	// Act
-	err := p.AddSecretToRepo(context.Background(), token, githubUsername, "", "ASERTO_PUSH_KEY", "value", false)
+	err := p.Ping(context.Background())
 
 	// Assert
-	assert.Error(err)
-	assert.Equal(err.Error(), "No repo name was provided")
+	assert.NoError(err)
 }
 
-func TestAddSecretToRepoGetPublicKeyFails(t *testing.T) {
+func TestGithubPingUnreachable(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
 	mockintrGh := tstInteraction.mockGithubIntrFunc
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
-	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
-	token := &sources.AccessToken{Token: "sometokenvalue"}
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
 
 	// Expect
-	tstInteraction.mockGithub.EXPECT().GetRepoPublicKey(gomock.Any(), githubUsername, policyRepo).Return(nil, errors.New("failed to connect"))
+	tstInteraction.mockGithub.EXPECT().Zen(gomock.Any()).Return(nil, errors.New("dial tcp: no such host"))
 
 	// Act
-	err := p.AddSecretToRepo(context.Background(), token, githubUsername, policyRepo, "ASERTO_PUSH_KEY", "value", false)
+	err := p.Ping(context.Background())
 
 	// Assert
 	assert.Error(err)
-	assert.Equal(err.Error(), "failed to get public repo key for encryption: E10034 timeout after multiple retries: failed to connect")
+	assert.True(errx.ErrProviderUnreachable.SameAs(err))
+	assert.Contains(err.Error(), "no such host")
 }
 
-func TestAddSecretToRepoSecretExistsOverrideFalse(t *testing.T) {
+func TestGithubValidateConnection(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
 	mockintrGh := tstInteraction.mockGithubIntrFunc
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
-	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
-	secret := &github.Secret{Name: "ASERTO_PUSH_KEY"}
-	secrets := []*github.Secret{secret}
-	result := &github.Secrets{Secrets: secrets}
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &github.Response{Response: &http.Response{StatusCode: 200}}
 
 	// Expect
-	tstInteraction.mockGithub.EXPECT().GetRepoPublicKey(gomock.Any(), githubUsername, policyRepo).Return(&github.PublicKey{}, nil)
-	tstInteraction.mockGithub.EXPECT().ListRepoSecrets(gomock.Any(), githubUsername, policyRepo, gomock.Any()).Return(result, nil)
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(nil, resp, nil)
 
 	// Act
-	err := p.AddSecretToRepo(context.Background(), token, githubUsername, policyRepo, "ASERTO_PUSH_KEY", "value", false)
+	err := p.ValidateConnection(context.Background(), token, []string{})
 
 	// Assert
-	assert.Error(err)
-	assert.Equal(err.Error(), "E10022 repo has already been connected to a policy: you’re trying to link to an existing repository that already has a secret. Please consider overwriting the Aserto push secret.")
+	assert.NoError(err)
 }
 
-func TestAddSecretToRepoSecretExistsOverrideTrueCreateFails(t *testing.T) {
+func TestGithubValidateConnectionScopes(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
 	mockintrGh := tstInteraction.mockGithubIntrFunc
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
-	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
-	secret := &github.Secret{Name: "ASERTO_PUSH_KEY"}
-	secrets := []*github.Secret{secret}
-	result := &github.Secrets{Secrets: secrets}
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	body := io.NopCloser(strings.NewReader("this is the body"))
-	resp := &github.Response{Response: &http.Response{StatusCode: 404, Status: "Not Found", Body: body}}
+	resp := &github.Response{Response: &http.Response{StatusCode: 200, Header: http.Header{}}}
+	resp.Response.Header.Set("X-OAuth-Scopes", "repo,user,admin:org")
 
 	// Expect
-	tstInteraction.mockGithub.EXPECT().GetRepoPublicKey(gomock.Any(), githubUsername, policyRepo).Return(&github.PublicKey{}, nil)
-	tstInteraction.mockGithub.EXPECT().ListRepoSecrets(gomock.Any(), githubUsername, policyRepo, gomock.Any()).Return(result, nil)
-	tstInteraction.mockGithub.EXPECT().
-		CreateOrUpdateRepoSecret(gomock.Any(), githubUsername, policyRepo, gomock.Any()).
-		Return(resp, errors.New("Failed to create repo secret"))
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(nil, resp, nil)
 
 	// Act
-	err := p.AddSecretToRepo(context.Background(), token, githubUsername, policyRepo, "ASERTO_PUSH_KEY", "value", true)
+	err := p.ValidateConnection(context.Background(), token, []string{"repo", "user", "(admin|read):org"})
 
 	// Assert
-	assert.Error(err)
-	assert.Equal(err.Error(), "E10023 failed to setup repo secret: E10034 timeout after multiple retries: Failed to create repo secret")
+	assert.NoError(err)
 }
 
-func TestAddSecretToRepoSecretExistsOverrideTrueCreate(t *testing.T) {
+func TestGithubValidateConnectionMissingScopes(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
 	mockintrGh := tstInteraction.mockGithubIntrFunc
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
-	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
-	secret := &github.Secret{Name: "ASERTO_PUSH_KEY"}
-	secrets := []*github.Secret{secret}
-	result := &github.Secrets{Secrets: secrets}
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &github.Response{Response: &http.Response{StatusCode: 200, Header: http.Header{}}}
+	resp.Response.Header.Set("X-OAuth-Scopes", "repo,user")
 
 	// Expect
-	tstInteraction.mockGithub.EXPECT().GetRepoPublicKey(gomock.Any(), githubUsername, policyRepo).Return(&github.PublicKey{}, nil)
-	tstInteraction.mockGithub.EXPECT().ListRepoSecrets(gomock.Any(), githubUsername, policyRepo, gomock.Any()).Return(result, nil)
-	tstInteraction.mockGithub.EXPECT().
-		CreateOrUpdateRepoSecret(gomock.Any(), githubUsername, policyRepo, gomock.Any()).
-		Return(nil, nil)
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(nil, resp, nil)
 
 	// Act
-	err := p.AddSecretToRepo(context.Background(), token, githubUsername, policyRepo, "ASERTO_PUSH_KEY", "value", true)
+	err := p.ValidateConnection(context.Background(), token, []string{"repo", "admin:org"})
 
 	// Assert
-	assert.NoError(err)
+	assert.Error(err)
+	assert.True(errx.ErrMissingScopes.SameAs(err))
+	assert.Equal([]string{"admin:org"}, errx.MissingScopes(err))
 }
 
-func TestListOrgsPageNil(t *testing.T) {
+func TestGithubValidateConnectionFineGrainedToken(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
 	mockintrGh := tstInteraction.mockGithubIntrFunc
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
 	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
-	token := &sources.AccessToken{Token: "sometokenvalue"}
+	token := &sources.AccessToken{Token: "github_pat_sometokenvalue"}
+	resp := &github.Response{Response: &http.Response{StatusCode: 200, Header: http.Header{}}}
+	repo := &github.Repository{Name: ptr.To(policyRepo), Owner: &github.User{Login: ptr.To(githubUsername)}}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(nil, resp, nil)
+	tstInteraction.mockGithub.EXPECT().ListUserRepos(gomock.Any(), gomock.Any()).Return([]*github.Repository{repo}, resp, nil)
+	tstInteraction.mockGithub.EXPECT().GetFileContents(gomock.Any(), githubUsername, policyRepo, "", gomock.Any()).Return(nil, nil)
+	tstInteraction.mockGithub.EXPECT().GetRepoPublicKey(gomock.Any(), githubUsername, policyRepo).Return(&github.PublicKey{}, nil)
 
 	// Act
-	orgs, resp, err := p.ListOrgs(context.Background(), token, nil)
+	err := p.ValidateConnection(context.Background(), token, []string{"repo"})
 
 	// Assert
-	assert.Error(err)
-	assert.Equal(err.Error(), "page must not be empty")
-	assert.Empty(orgs)
-	assert.Nil(resp)
+	assert.NoError(err)
 }
 
-func TestListOrgsPageSizeInvalid(t *testing.T) {
+func TestGithubValidateConnectionFineGrainedTokenUnverifiableScope(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
 	mockintrGh := tstInteraction.mockGithubIntrFunc
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
 	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
-	token := &sources.AccessToken{Token: "sometokenvalue"}
-	page := &api.PaginationRequest{Size: int32(-2)}
+	token := &sources.AccessToken{Token: "github_pat_sometokenvalue"}
+	resp := &github.Response{Response: &http.Response{StatusCode: 200, Header: http.Header{}}}
+	repo := &github.Repository{Name: ptr.To(policyRepo), Owner: &github.User{Login: ptr.To(githubUsername)}}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(nil, resp, nil)
+	tstInteraction.mockGithub.EXPECT().ListUserRepos(gomock.Any(), gomock.Any()).Return([]*github.Repository{repo}, resp, nil)
+	tstInteraction.mockGithub.EXPECT().GetFileContents(gomock.Any(), githubUsername, policyRepo, "", gomock.Any()).Return(nil, nil)
+	tstInteraction.mockGithub.EXPECT().GetRepoPublicKey(gomock.Any(), githubUsername, policyRepo).Return(&github.PublicKey{}, nil)
 
 	// Act
-	orgs, resp, err := p.ListOrgs(context.Background(), token, page)
+	err := p.ValidateConnection(context.Background(), token, []string{"repo", "workflow"})
 
 	// Assert
 	assert.Error(err)
-	assert.Equal(err.Error(), "page size must be >= -1 and <= 100")
-	assert.Empty(orgs)
-	assert.Nil(resp)
+	assert.True(errx.ErrMissingScopes.SameAs(err))
+	assert.Equal([]string{"workflow"}, errx.MissingScopes(err))
 }
 
-func TestGithubListOrgsQueryFails(t *testing.T) {
+func TestGithubValidateConnectionFineGrainedTokenWithoutRepos(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
 	mockintrGh := tstInteraction.mockGithubIntrFunc
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
 	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
-	token := &sources.AccessToken{Token: "sometokenvalue"}
-	page := &api.PaginationRequest{Size: int32(-1), Token: ""}
+	token := &sources.AccessToken{Token: "github_pat_sometokenvalue"}
+	resp := &github.Response{Response: &http.Response{StatusCode: 200, Header: http.Header{}}}
 
 	// Expect
-	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("boom"))
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(nil, resp, nil)
+	tstInteraction.mockGithub.EXPECT().ListUserRepos(gomock.Any(), gomock.Any()).Return([]*github.Repository{}, resp, nil)
 
 	// Act
-	orgs, resp, err := p.ListOrgs(context.Background(), token, page)
+	err := p.ValidateConnection(context.Background(), token, []string{"repo"})
 
 	// Assert
 	assert.Error(err)
-	assert.Contains(err.Error(), "error running query against github graphql server: boom")
-	assert.Empty(orgs)
-	assert.Nil(resp)
+	assert.True(errx.ErrMissingScopes.SameAs(err))
+	assert.Equal([]string{"contents", "secrets"}, errx.MissingScopes(err))
 }
 
-func TestGithubListOrgs(t *testing.T) {
+func TestGithubValidateConnectionFineGrainedTokenProbesNextRepo(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
 	mockintrGh := tstInteraction.mockGithubIntrFunc
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
 	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
-	token := &sources.AccessToken{Token: "sometokenvalue"}
-	page := &api.PaginationRequest{Size: int32(-1), Token: ""}
+	token := &sources.AccessToken{Token: "github_pat_sometokenvalue"}
+	resp := &github.Response{Response: &http.Response{StatusCode: 200, Header: http.Header{}}}
+	emptyRepo := &github.Repository{Name: ptr.To("empty"), Owner: &github.User{Login: ptr.To(githubUsername)}}
+	repo := &github.Repository{Name: ptr.To(policyRepo), Owner: &github.User{Login: ptr.To(githubUsername)}}
+	notFound := &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound, Request: &http.Request{Method: http.MethodGet}}}
 
 	// Expect
-	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(nil, resp, nil)
+	tstInteraction.mockGithub.EXPECT().ListUserRepos(gomock.Any(), gomock.Any()).Return([]*github.Repository{emptyRepo, repo}, resp, nil)
+	tstInteraction.mockGithub.EXPECT().GetFileContents(gomock.Any(), githubUsername, "empty", "", gomock.Any()).Return(nil, notFound)
+	tstInteraction.mockGithub.EXPECT().GetRepoPublicKey(gomock.Any(), githubUsername, "empty").Return(&github.PublicKey{}, nil)
+	tstInteraction.mockGithub.EXPECT().GetFileContents(gomock.Any(), githubUsername, policyRepo, "", gomock.Any()).Return(nil, nil)
 
 	// Act
-	orgs, resp, err := p.ListOrgs(context.Background(), token, page)
+	err := p.ValidateConnection(context.Background(), token, []string{"repo"})
 
 	// Assert
 	assert.NoError(err)
-	assert.Empty(orgs)
-	assert.NotNil(resp)
-	assert.Equal(resp.TotalSize, int32(0))
 }
 
-func TestListReposPageNil(t *testing.T) {
+func TestGithubValidateConnectionFineGrainedTokenMissingPermissions(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
 	mockintrGh := tstInteraction.mockGithubIntrFunc
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
 	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
-	token := &sources.AccessToken{Token: "sometokenvalue"}
+	token := &sources.AccessToken{Token: "github_pat_sometokenvalue"}
+	resp := &github.Response{Response: &http.Response{StatusCode: 200, Header: http.Header{}}}
+	repo := &github.Repository{Name: ptr.To(policyRepo), Owner: &github.User{Login: ptr.To(githubUsername)}}
+	forbidden := &http.Response{StatusCode: http.StatusForbidden, Header: http.Header{}, Request: &http.Request{Method: http.MethodGet}}
+	forbidden.Header.Set("X-Accepted-GitHub-Permissions", "secrets=read")
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(nil, resp, nil)
+	tstInteraction.mockGithub.EXPECT().ListUserRepos(gomock.Any(), gomock.Any()).Return([]*github.Repository{repo}, resp, nil)
+	tstInteraction.mockGithub.EXPECT().GetFileContents(gomock.Any(), githubUsername, policyRepo, "", gomock.Any()).Return(nil, nil)
+	tstInteraction.mockGithub.EXPECT().GetRepoPublicKey(gomock.Any(), githubUsername, policyRepo).Return(nil, &github.ErrorResponse{Response: forbidden})
 
 	// Act
-	repos, resp, err := p.ListRepos(context.Background(), token, githubUsername, nil)
+	err := p.ValidateConnection(context.Background(), token, []string{"repo"})
 
 	// Assert
 	assert.Error(err)
-	assert.Equal(err.Error(), "page must not be empty")
-	assert.Empty(repos)
-	assert.Nil(resp)
+	assert.True(errx.ErrMissingScopes.SameAs(err))
+	assert.Equal([]string{"secrets"}, errx.MissingScopes(err))
+	assertoErr := cerr.UnwrapAsertoError(err)
+	assert.Contains(assertoErr.Data()["msg"], "github access token is missing permissions")
 }
 
-func TestListReposPageSizeInvalid(t *testing.T) {
+func TestGithubValidateConnectionClassicTokenWithoutScopesHeader(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
 	mockintrGh := tstInteraction.mockGithubIntrFunc
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
 	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
-	token := &sources.AccessToken{Token: "sometokenvalue"}
-	page := &api.PaginationRequest{Size: int32(-2)}
+	token := &sources.AccessToken{Token: "ghp_sometokenvalue"}
+	resp := &github.Response{Response: &http.Response{StatusCode: 200, Header: http.Header{}}}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(nil, resp, nil)
 
 	// Act
-	repos, resp, err := p.ListRepos(context.Background(), token, githubUsername, page)
+	err := p.ValidateConnection(context.Background(), token, []string{"repo"})
 
 	// Assert
 	assert.Error(err)
-	assert.Equal(err.Error(), "page size must be >= -1 and <= 100")
-	assert.Empty(repos)
-	assert.Nil(resp)
+	assert.True(errx.ErrMissingScopes.SameAs(err))
+	assert.Equal([]string{"repo"}, errx.MissingScopes(err))
 }
 
-func TestListReposQueryFails(t *testing.T) {
+func TestGithubProfileQueryFails(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
@@ -540,22 +566,21 @@ func TestListReposQueryFails(t *testing.T) {
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
 	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	page := &api.PaginationRequest{Size: int32(-1)}
 
 	// Expect
 	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("boom"))
 
 	// Act
-	repos, resp, err := p.ListRepos(context.Background(), token, githubUsername, page)
+	username, repos, err := p.Profile(context.Background(), token)
 
 	// Assert
 	assert.Error(err)
 	assert.Contains(err.Error(), "error running query against github graphql server: boom")
-	assert.Empty(repos)
-	assert.Nil(resp)
+	assert.Empty(username)
+	assert.Nil(repos)
 }
 
-func TestListRepos(t *testing.T) {
+func TestGithubProfile(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
@@ -563,43 +588,41 @@ func TestListRepos(t *testing.T) {
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
 	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	page := &api.PaginationRequest{Size: int32(-1)}
 
 	// Expect
 	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 
 	// Act
-	repos, resp, err := p.ListRepos(context.Background(), token, githubUsername, page)
+	username, repos, err := p.Profile(context.Background(), token)
 
 	// Assert
 	assert.NoError(err)
+	assert.Empty(username)
 	assert.Empty(repos)
-	assert.NotNil(resp)
-	assert.Equal(resp.TotalSize, int32(0))
 }
 
-func TestGetRepoFails(t *testing.T) {
+func TestGithubHasSecretFails(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
 	mockintrGh := tstInteraction.mockGithubIntrFunc
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
-	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
 
 	// Expect
-	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(nil, errors.New("no Connection"))
+	tstInteraction.mockGithub.EXPECT().ListRepoSecrets(gomock.Any(), githubUsername, policyRepo, gomock.Any()).Return(nil, nil, errors.New("Failed to get secret"))
 
 	// Act
-	repo, err := p.GetRepo(context.Background(), token, githubUsername, policyRepo)
+	exists, err := p.HasSecret(context.Background(), token, githubUsername, policyRepo, "ASERTO_PUSH_KEY", nil)
 
 	// Assert
 	assert.Error(err)
-	assert.Contains(err.Error(), "failed to get repo: no Connection")
-	assert.Nil(repo)
+	assert.Contains(err.Error(), "failed to list repo secrets: E10034 timeout after multiple retries: Failed to get secret")
+	assert.False(exists)
 }
 
-func TestGithubGetRepo(t *testing.T) {
+func TestGithubGetSecretWriteOnly(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
@@ -607,46 +630,2290 @@ func TestGithubGetRepo(t *testing.T) {
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
 	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	user := githubUsername
-	searchedRepo := policyRepo
-	URL := policyURL
-	githubUser := &github.User{Login: &user}
-	githubRepo := &github.Repository{Name: &searchedRepo, Owner: githubUser, HTMLURL: &URL}
-
-	// Expect
-	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(githubRepo, nil)
 
 	// Act
-	repo, err := p.GetRepo(context.Background(), token, githubUsername, policyRepo)
+	value, exists, err := p.GetSecret(context.Background(), token, githubUsername, policyRepo, "ASERTO_PUSH_KEY")
 
 	// Assert
-	assert.NoError(err)
-	assert.NotNil(repo)
-	assert.Equal(repo.Url, policyURL)
-	assert.Equal(repo.CiUrl, policyURL+"/actions")
+	assert.Error(err)
+	assert.True(errx.ErrSecretWriteOnly.SameAs(err))
+	assert.False(exists)
+	assert.Empty(value)
 }
 
-func TestGithubCreateRepoGetUsersFails(t *testing.T) {
+func TestGithubHasSecretTrue(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
 	mockintrGh := tstInteraction.mockGithubIntrFunc
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
-	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
-	token := &sources.AccessToken{Token: "sometokenvalue"}
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	secret := &github.Secret{Name: "ASERTO_PUSH_KEY"}
+	secrets := []*github.Secret{secret}
+	result := &github.Secrets{Secrets: secrets}
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().ListRepoSecrets(gomock.Any(), githubUsername, policyRepo, gomock.Any()).Return(result, nil, nil)
+
+	// Act
+	exists, err := p.HasSecret(context.Background(), token, githubUsername, policyRepo, "ASERTO_PUSH_KEY", nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.True(exists)
+}
+
+func TestGithubHasSecretFalse(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	secret := &github.Secret{Name: "other_secret"}
+	secrets := []*github.Secret{secret}
+	result := &github.Secrets{Secrets: secrets}
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().ListRepoSecrets(gomock.Any(), githubUsername, policyRepo, gomock.Any()).Return(result, nil, nil)
+
+	// Act
+	exists, err := p.HasSecret(context.Background(), token, githubUsername, policyRepo, "ASERTO_PUSH_KEY", nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.False(exists)
+}
+
+func TestGithubHasSecretOnSecondPage(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	// GitHub may return fewer secrets than asked for while more pages follow.
+	firstPage := &github.Secrets{TotalCount: 31}
+	for i := 0; i < 30; i++ {
+		firstPage.Secrets = append(firstPage.Secrets, &github.Secret{Name: fmt.Sprintf("SECRET_%d", i)})
+	}
+	secondPage := &github.Secrets{TotalCount: 31, Secrets: []*github.Secret{{Name: "ASERTO_PUSH_KEY"}}}
+
+	// Expect
+	gomock.InOrder(
+		tstInteraction.mockGithub.EXPECT().ListRepoSecrets(gomock.Any(), githubUsername, policyRepo, &github.ListOptions{Page: 1, PerPage: 100}).
+			Return(firstPage, &github.Response{NextPage: 2}, nil),
+		tstInteraction.mockGithub.EXPECT().ListRepoSecrets(gomock.Any(), githubUsername, policyRepo, &github.ListOptions{Page: 2, PerPage: 100}).
+			Return(secondPage, &github.Response{}, nil),
+	)
+
+	// Act
+	exists, err := p.HasSecret(context.Background(), token, githubUsername, policyRepo, "ASERTO_PUSH_KEY", nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.True(exists)
+}
+
+func TestAddSecretToRepoNoOrg(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	err := p.AddSecretToRepo(context.Background(), token, "", policyRepo, "ASERTO_PUSH_KEY", "value", false, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "No org name was provided")
+}
+
+func TestAddSecretToRepoNoRepo(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	err := p.AddSecretToRepo(context.Background(), token, githubUsername, "", "ASERTO_PUSH_KEY", "value", false, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "No repo name was provided")
+}
+
+func TestAddSecretToRepoGetPublicKeyFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepoPublicKey(gomock.Any(), githubUsername, policyRepo).Return(nil, errors.New("failed to connect"))
+
+	// Act
+	err := p.AddSecretToRepo(context.Background(), token, githubUsername, policyRepo, "ASERTO_PUSH_KEY", "value", false, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "failed to get public repo key for encryption: E10034 timeout after multiple retries: failed to connect")
+}
+
+func TestAddSecretToRepoSecretExistsOverrideFalse(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	secret := &github.Secret{Name: "ASERTO_PUSH_KEY"}
+	secrets := []*github.Secret{secret}
+	result := &github.Secrets{Secrets: secrets}
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepoPublicKey(gomock.Any(), githubUsername, policyRepo).Return(&github.PublicKey{}, nil)
+	tstInteraction.mockGithub.EXPECT().ListRepoSecrets(gomock.Any(), githubUsername, policyRepo, gomock.Any()).Return(result, nil, nil)
+
+	// Act
+	err := p.AddSecretToRepo(context.Background(), token, githubUsername, policyRepo, "ASERTO_PUSH_KEY", "value", false, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "E10022 repo has already been connected to a policy: you’re trying to link to an existing repository that already has a secret. Please consider overwriting the Aserto push secret.")
+}
+
+func TestAddSecretToRepoSecretExistsOverrideTrueCreateFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	secret := &github.Secret{Name: "ASERTO_PUSH_KEY"}
+	secrets := []*github.Secret{secret}
+	result := &github.Secrets{Secrets: secrets}
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	body := io.NopCloser(strings.NewReader("this is the body"))
+	resp := &github.Response{Response: &http.Response{StatusCode: 404, Status: "Not Found", Body: body}}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepoPublicKey(gomock.Any(), githubUsername, policyRepo).Return(&github.PublicKey{}, nil)
+	tstInteraction.mockGithub.EXPECT().ListRepoSecrets(gomock.Any(), githubUsername, policyRepo, gomock.Any()).Return(result, nil, nil)
+	tstInteraction.mockGithub.EXPECT().
+		CreateOrUpdateRepoSecret(gomock.Any(), githubUsername, policyRepo, gomock.Any()).
+		Return(resp, errors.New("Failed to create repo secret"))
+
+	// Act
+	err := p.AddSecretToRepo(context.Background(), token, githubUsername, policyRepo, "ASERTO_PUSH_KEY", "value", true, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "E10023 failed to setup repo secret: E10034 timeout after multiple retries: Failed to create repo secret")
+}
+
+func TestAddSecretToRepoCreateFailsWithoutResponse(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepoPublicKey(gomock.Any(), githubUsername, policyRepo).Return(&github.PublicKey{}, nil)
+	tstInteraction.mockGithub.EXPECT().
+		CreateOrUpdateRepoSecret(gomock.Any(), githubUsername, policyRepo, gomock.Any()).
+		Return(nil, errors.New("connection reset"))
+
+	// Act
+	err := p.AddSecretToRepo(context.Background(), token, githubUsername, policyRepo, "ASERTO_PUSH_KEY", "value", true, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrGithubSecret.SameAs(err))
+	assert.Contains(err.Error(), "connection reset")
+	assertoErr := cerr.UnwrapAsertoError(err)
+	assert.Equal("<no response body>", assertoErr.Data()["github-response"])
+}
+
+func TestAddSecretToRepoSecretExistsOverrideTrueCreate(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	secret := &github.Secret{Name: "ASERTO_PUSH_KEY"}
+	secrets := []*github.Secret{secret}
+	result := &github.Secrets{Secrets: secrets}
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepoPublicKey(gomock.Any(), githubUsername, policyRepo).Return(&github.PublicKey{}, nil)
+	tstInteraction.mockGithub.EXPECT().ListRepoSecrets(gomock.Any(), githubUsername, policyRepo, gomock.Any()).Return(result, nil, nil)
+	tstInteraction.mockGithub.EXPECT().
+		CreateOrUpdateRepoSecret(gomock.Any(), githubUsername, policyRepo, gomock.Any()).
+		Return(nil, nil)
+
+	// Act
+	err := p.AddSecretToRepo(context.Background(), token, githubUsername, policyRepo, "ASERTO_PUSH_KEY", "value", true, nil)
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestGithubAddSecretToReposReportsEachRepo(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepoPublicKey(gomock.Any(), githubUsername, "policy-a").Return(nil, errors.New("failed to connect"))
+	tstInteraction.mockGithub.EXPECT().GetRepoPublicKey(gomock.Any(), githubUsername, "policy-b").Return(&github.PublicKey{}, nil)
+	tstInteraction.mockGithub.EXPECT().
+		CreateOrUpdateRepoSecret(gomock.Any(), githubUsername, "policy-b", gomock.Any()).
+		Return(nil, nil)
+
+	// Act
+	results, err := p.(sources.BulkSecretSource).AddSecretToRepos(context.Background(), token, githubUsername, []string{"policy-a", "policy-b"}, "ASERTO_PUSH_KEY", "value", true)
+
+	// Assert
+	assert.NoError(err)
+	assert.Len(results, 2)
+	assert.Error(results["policy-a"])
+	assert.NoError(results["policy-b"])
+}
+
+func TestListOrgsPageNil(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	orgs, resp, err := p.ListOrgs(context.Background(), token, nil, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "page must not be empty")
+	assert.Empty(orgs)
+	assert.Nil(resp)
+}
+
+func TestListOrgsPageSizeInvalid(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: int32(-2)}
+
+	// Act
+	orgs, resp, err := p.ListOrgs(context.Background(), token, page, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "page size must be >= -1 and <= 100")
+	assert.Empty(orgs)
+	assert.Nil(resp)
+}
+
+func TestGithubListOrgsDefaultPageSize(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: 0}
+
+	// Expect
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ interface{}, vars map[string]interface{}) error {
+			assert.Equal(graphql.Int(30), vars["first"])
+			return nil
+		})
+
+	// Act
+	_, _, err := p.ListOrgs(context.Background(), token, page, nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(int32(0), page.Size)
+}
+
+func TestGithubListOrgsConfiguredDefaultPageSize(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{DefaultPageSize: 500}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: 0}
+
+	// Expect
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ interface{}, vars map[string]interface{}) error {
+			assert.Equal(graphql.Int(100), vars["first"])
+			return nil
+		})
+
+	// Act
+	_, _, err := p.ListOrgs(context.Background(), token, page, nil)
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestGithubListOrgsQueryFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: int32(-1), Token: ""}
+
+	// Expect
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("boom"))
+
+	// Act
+	orgs, resp, err := p.ListOrgs(context.Background(), token, page, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "error running query against github graphql server: boom")
+	assert.Empty(orgs)
+	assert.Nil(resp)
+}
+
+func TestGithubListOrgs(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: int32(-1), Token: ""}
+
+	// Expect
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	// Act
+	orgs, resp, err := p.ListOrgs(context.Background(), token, page, nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.Empty(orgs)
+	assert.NotNil(resp)
+	assert.Equal(resp.TotalSize, int32(0))
+}
+
+func TestListReposPageNil(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	repos, resp, err := p.ListRepos(context.Background(), token, githubUsername, nil, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "page must not be empty")
+	assert.Empty(repos)
+	assert.Nil(resp)
+}
+
+func TestListReposPageSizeInvalid(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: int32(-2)}
+
+	// Act
+	repos, resp, err := p.ListRepos(context.Background(), token, githubUsername, page, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "page size must be >= -1 and <= 100")
+	assert.Empty(repos)
+	assert.Nil(resp)
+}
+
+func TestListReposQueryFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: int32(-1)}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), "").Return(&github.User{Login: ptr.To("someone")}, nil, nil)
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("boom"))
+
+	// Act
+	repos, resp, err := p.ListRepos(context.Background(), token, githubUsername, page, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "error running query against github graphql server: boom")
+	assert.Empty(repos)
+	assert.Nil(resp)
+}
+
+func TestListRepos(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: int32(-1)}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), "").Return(&github.User{Login: ptr.To("someone")}, nil, nil)
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	// Act
+	repos, resp, err := p.ListRepos(context.Background(), token, githubUsername, page, nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.Empty(repos)
+	assert.NotNil(resp)
+	assert.Equal(resp.TotalSize, int32(0))
+}
+
+func TestGetRepoFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(nil, errors.New("no Connection"))
+
+	// Act
+	repo, err := p.GetRepo(context.Background(), token, githubUsername, policyRepo)
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "failed to get repo: no Connection")
+	assert.Nil(repo)
+}
+
+func TestGithubGetRepoNotFound(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	notFound := &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusNotFound, Request: &http.Request{Method: http.MethodGet}},
+		Message:  "Not Found",
+	}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(nil, notFound)
+
+	// Act
+	repo, err := p.GetRepo(context.Background(), token, githubUsername, policyRepo)
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrNotFound.SameAs(err))
+	assert.Equal("404", cerr.UnwrapAsertoError(err).Data()[errx.StatusCodeKey])
+	assert.Nil(repo)
+}
+
+func TestGithubGetRepo(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	user := githubUsername
+	searchedRepo := policyRepo
+	URL := policyURL
+	githubUser := &github.User{Login: &user}
+	githubRepo := &github.Repository{Name: &searchedRepo, Owner: githubUser, HTMLURL: &URL}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(githubRepo, nil)
+
+	// Act
+	repo, err := p.GetRepo(context.Background(), token, githubUsername, policyRepo)
+
+	// Assert
+	assert.NoError(err)
+	assert.NotNil(repo)
+	assert.Equal(repo.Url, policyURL)
+	assert.Equal(repo.CiUrl, policyURL+"/actions")
+}
+
+func TestGithubGetRepoWithoutOwner(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	githubRepo := &github.Repository{HTMLURL: ptr.To(policyURL)}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(githubRepo, nil)
+
+	// Act
+	repo, err := p.GetRepo(context.Background(), token, githubUsername, policyRepo)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(policyRepo, repo.Name)
+	assert.Equal(githubUsername, repo.Org)
+	assert.Equal(policyURL, repo.Url)
+}
+
+func TestGithubGetRepoWithoutURL(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(&github.Repository{}, nil)
+
+	// Act
+	repo, err := p.GetRepo(context.Background(), token, githubUsername, policyRepo)
+
+	// Assert
+	assert.Error(err)
+	assert.Nil(repo)
+	assert.Contains(err.Error(), "without a URL")
+}
+
+func TestGithubGetRepoID(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	githubRepo := &github.Repository{Name: ptr.To(policyRepo), NodeID: ptr.To("R_kgDOabc")}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(githubRepo, nil)
+
+	// Act
+	id, err := p.GetRepoID(context.Background(), token, githubUsername, policyRepo)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("R_kgDOabc", id)
+}
+
+func TestGithubGetCloneURLs(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	githubRepo := &github.Repository{
+		Name:     ptr.To(policyRepo),
+		HTMLURL:  ptr.To(policyURL),
+		CloneURL: ptr.To(policyURL + ".git"),
+		SSHURL:   ptr.To("git@github.com:aserto-dev/policy.git"),
+	}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(githubRepo, nil)
+
+	// Act
+	httpsURL, sshURL, err := p.(sources.CloneURLSource).GetCloneURLs(context.Background(), token, githubUsername, policyRepo)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(policyURL+".git", httpsURL)
+	assert.Equal("git@github.com:aserto-dev/policy.git", sshURL)
+}
+
+func TestGithubGetCloneURLsNotFound(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	notFound := &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusNotFound, Request: &http.Request{Method: http.MethodGet}},
+		Message:  "Not Found",
+	}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(nil, notFound)
+
+	// Act
+	httpsURL, sshURL, err := p.(sources.CloneURLSource).GetCloneURLs(context.Background(), token, githubUsername, policyRepo)
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrNotFound.SameAs(err))
+	assert.Empty(httpsURL)
+	assert.Empty(sshURL)
+}
+
+func TestGithubRenameRepo(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().EditRepo(gomock.Any(), githubUsername, policyRepo, &github.Repository{Name: ptr.To("renamed")}).
+		Return(&github.Repository{Name: ptr.To("renamed")}, nil, nil)
+
+	// Act
+	err := p.RenameRepo(context.Background(), token, githubUsername, policyRepo, "renamed")
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestGithubRenameRepoNameTaken(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	httpResp := &http.Response{StatusCode: http.StatusUnprocessableEntity, Request: &http.Request{Method: http.MethodPatch}}
+	taken := &github.ErrorResponse{
+		Response: httpResp,
+		Message:  "Repository creation failed.",
+		Errors:   []github.Error{{Resource: "Repository", Field: "name", Code: "custom", Message: "name already exists on this account"}},
+	}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().EditRepo(gomock.Any(), githubUsername, policyRepo, gomock.Any()).
+		Return(nil, &github.Response{Response: httpResp}, taken)
+
+	// Act
+	err := p.RenameRepo(context.Background(), token, githubUsername, policyRepo, "taken")
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrRepoNameTaken.SameAs(err))
+}
+
+func TestGithubCreateRepoGetUsersFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(nil, nil, errors.New("boom"))
+
+	// Act
+	_, err := p.CreateRepo(context.Background(), token, githubUsername, policyRepo, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "failed to read user from github: boom")
+}
+
+func TestGithubCreateFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	username := githubUsername
+	user := &github.User{Login: &username}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(user, nil, nil)
+	tstInteraction.mockGithub.EXPECT().CreateRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("boom"))
+
+	// Act
+	_, err := p.CreateRepo(context.Background(), token, githubUsername, policyRepo, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "failed to create repo: boom")
+}
+
+func TestGithubCreate(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	username := githubUsername
+	user := &github.User{Login: &username}
+	name := policyRepo
+	URL := policyURL
+	githubRepo := &github.Repository{Name: &name, Owner: user, HTMLURL: &URL}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(user, nil, nil)
+	tstInteraction.mockGithub.EXPECT().CreateRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(githubRepo, nil)
+
+	// Act
+	repo, err := p.CreateRepo(context.Background(), token, githubUsername, policyRepo, nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(policyRepo, repo.Name)
+	assert.Equal(githubUsername, repo.Org)
+	assert.Equal(policyURL+"/actions", repo.CiUrl)
+}
+
+func TestGetDefultRepoFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(nil, errors.New("no Connection"))
+
+	// Act
+	repo, err := p.GetDefaultBranch(context.Background(), token, githubUsername, policyRepo)
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "failed to get repo: no Connection")
+	assert.Empty(repo)
+}
+
+func TestGithubGetDefaultRepo(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	defaultBr := defaultBranch
+	githubRepo := &github.Repository{DefaultBranch: &defaultBr}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(githubRepo, nil)
+
+	// Act
+	repo, err := p.GetDefaultBranch(context.Background(), token, githubUsername, policyRepo)
+
+	// Assert
+	assert.NoError(err)
+	assert.NotEmpty(repo)
+	assert.Equal(repo, defaultBranch)
+}
+
+func TestGithubInitialTagWithInvalidRepoPath(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	_, err := p.InitialTag(context.Background(), token, "policy", "build-workflow.yaml", "", "")
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "invalid full github repo name 'policy', should be in the form owner/repo")
+}
+
+func TestGithubInitialTagAndGetRepoFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("not found"))
+
+	// Act
+	_, err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "build-workflow.yaml", "", "")
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "failed to get repo: not found")
+}
+
+func TestGithubInitialTagAndListRepoTagsFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	tstInteraction.mockGithub.EXPECT().
+		ListRepoTags(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("tags not found"))
+
+	// Act
+	_, err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "build-workflow.yaml", "", "")
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "failed to list tags for repo 'aserto-dev/policy': tags not found")
+}
+
+func TestGithubInitialTagAndRepoHasTag(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	repoTag := &github.RepositoryTag{}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	tstInteraction.mockGithub.EXPECT().
+		ListRepoTags(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]*github.RepositoryTag{repoTag}, nil)
+
+	// Act
+	_, err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "build-workflow.yaml", "", "")
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestGithubInitialTagAndGetRepoRefFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0, WaitTagTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	defaultBr := defaultBranch
+	githubRepo := &github.Repository{DefaultBranch: &defaultBr}
+	resp := &github.Response{Response: &http.Response{StatusCode: 404}}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(githubRepo, nil)
+	tstInteraction.mockGithub.EXPECT().
+		ListRepoTags(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, nil)
+	tstInteraction.mockGithub.EXPECT().
+		GetRepoRef(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, resp, errors.New("ref not found"))
+
+	// Act
+	_, err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "build-workflow.yaml", "", "")
+
+	// Assert
+	assert.Error(err)
+	assert.ErrorIs(err, sources.ErrEmptyRepo)
+}
+
+func TestGithubInitialTagOnRepoWithoutAutoInit(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	githubRepo := &github.Repository{DefaultBranch: ptr.To(defaultBranch)}
+	resp := &github.Response{Response: &http.Response{StatusCode: http.StatusConflict}}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(githubRepo, nil)
+	tstInteraction.mockGithub.EXPECT().ListRepoTags(gomock.Any(), githubUsername, policyRepo, gomock.Any()).Return(nil, nil)
+	tstInteraction.mockGithub.EXPECT().
+		GetRepoRef(gomock.Any(), githubUsername, policyRepo, "heads/"+defaultBranch).
+		Return(nil, resp, errors.New("Git Repository is empty."))
+
+	// Act
+	_, err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "build-workflow.yaml", "", "")
+
+	// Assert
+	assert.Error(err)
+	assert.ErrorIs(err, sources.ErrEmptyRepo)
+}
+
+func TestGithubInitialTag(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0, WaitTagTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	defaultBr := defaultBranch
+	githubRepo := &github.Repository{DefaultBranch: &defaultBr}
+	resp := &github.Response{Response: &http.Response{StatusCode: 200}}
+	sha := "somesha"
+	obj := &github.GitObject{SHA: &sha}
+	ref := &github.Reference{Object: obj, Ref: &sha}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(githubRepo, nil)
+	tstInteraction.mockGithub.EXPECT().
+		ListRepoTags(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, nil)
+	tstInteraction.mockGithub.EXPECT().
+		GetRepoRef(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(ref, resp, nil)
+
+	dispatchedRuns := &github.WorkflowRuns{WorkflowRuns: []*github.WorkflowRun{{ID: ptr.To(int64(346))}}}
+
+	tstInteraction.mockGraphql.EXPECT().Mutate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	gomock.InOrder(
+		tstInteraction.mockGithub.EXPECT().ListRepositoryWorkflowRuns(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil),
+		tstInteraction.mockGithub.EXPECT().CreateWorkflowDispatchEventByFileName(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil),
+		tstInteraction.mockGithub.EXPECT().
+			ListRepositoryWorkflowRuns(gomock.Any(), gomock.Any(), gomock.Any(), &github.ListWorkflowRunsOptions{Event: "workflow_dispatch"}).
+			Return(dispatchedRuns, nil),
+	)
+
+	// Act
+	runID, err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "build-workflow.yaml", "", "")
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(int64(346), runID)
+}
+
+func TestGithubInitialTagRetriggerDoesNotWork(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0, WaitTagTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	defaultBr := defaultBranch
+	githubRepo := &github.Repository{DefaultBranch: &defaultBr}
+	resp := &github.Response{Response: &http.Response{StatusCode: 200}}
+	sha := "somesh"
+	obj := &github.GitObject{SHA: &sha}
+	ref := &github.Reference{Object: obj, Ref: &sha}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(githubRepo, nil)
+	tstInteraction.mockGithub.EXPECT().
+		ListRepoTags(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, nil)
+	tstInteraction.mockGithub.EXPECT().
+		GetRepoRef(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(ref, resp, nil)
+
+	tstInteraction.mockGraphql.EXPECT().Mutate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	tstInteraction.mockGithub.EXPECT().ListRepositoryWorkflowRuns(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	tstInteraction.mockGithub.EXPECT().CreateWorkflowDispatchEventByFileName(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("boom"))
+	tstInteraction.mockGithub.EXPECT().GetWorkflowByFileName(gomock.Any(), gomock.Any(), gomock.Any(), "build-workflow.yaml").Return(&github.Workflow{}, nil)
+
+	// Act
+	_, err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "build-workflow.yaml", "", "")
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "boom")
+}
+
+func TestGithubInitialTagWorkflowNotFound(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	githubRepo := &github.Repository{}
+	notFound := &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusNotFound, Request: &http.Request{Method: http.MethodGet}},
+		Message:  "Not Found",
+	}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(githubRepo, nil)
+	tstInteraction.mockGithub.EXPECT().GetCommit(gomock.Any(), githubUsername, policyRepo, "somesha").Return(&github.Commit{SHA: ptr.To("somesha")}, nil)
+	tstInteraction.mockGraphql.EXPECT().Mutate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	tstInteraction.mockGithub.EXPECT().ListRepositoryWorkflowRuns(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+	tstInteraction.mockGithub.EXPECT().CreateWorkflowDispatchEventByFileName(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("422 Unexpected inputs provided"))
+	tstInteraction.mockGithub.EXPECT().GetWorkflowByFileName(gomock.Any(), githubUsername, policyRepo, "build-workflow.yaml").Return(nil, notFound)
+
+	// Act
+	_, err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "build-workflow.yaml", "somesha", "")
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrNotFound.SameAs(err))
+	assert.Contains(err.Error(), "workflow file not found in repo: build-workflow.yaml")
+}
+
+func TestGithubHasWorkflow(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	notFound := &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusNotFound, Request: &http.Request{Method: http.MethodGet}},
+		Message:  "Not Found",
+	}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetWorkflowByFileName(gomock.Any(), githubUsername, policyRepo, "build.yaml").Return(&github.Workflow{}, nil)
+	tstInteraction.mockGithub.EXPECT().GetWorkflowByFileName(gomock.Any(), githubUsername, policyRepo, "missing.yaml").Return(nil, notFound)
+
+	// Act
+	workflowSource, ok := p.(sources.WorkflowSource)
+	assert.True(ok)
+	found, err := workflowSource.HasWorkflow(context.Background(), token, githubUsername, policyRepo, "build.yaml")
+	assert.NoError(err)
+	missing, err := workflowSource.HasWorkflow(context.Background(), token, githubUsername, policyRepo, "missing.yaml")
+
+	// Assert
+	assert.NoError(err)
+	assert.True(found)
+	assert.False(missing)
+}
+
+func TestGithubTriggerBuild(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().CreateWorkflowDispatchEventByFileName(gomock.Any(), githubUsername, policyRepo, "build.yaml",
+		github.CreateWorkflowDispatchEventRequest{Ref: "v1.2.3"}).Return(nil)
+
+	// Act
+	err := p.(sources.BuildTrigger).TriggerBuild(context.Background(), token, githubUsername, policyRepo, "v1.2.3", "build.yaml")
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestGithubTriggerBuildWorkflowNotFound(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	notFound := &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusNotFound, Request: &http.Request{Method: http.MethodGet}},
+		Message:  "Not Found",
+	}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().CreateWorkflowDispatchEventByFileName(gomock.Any(), githubUsername, policyRepo, "missing.yaml", gomock.Any()).
+		Return(errors.New("404 Not Found"))
+	tstInteraction.mockGithub.EXPECT().GetWorkflowByFileName(gomock.Any(), githubUsername, policyRepo, "missing.yaml").Return(nil, notFound)
+
+	// Act
+	err := p.(sources.BuildTrigger).TriggerBuild(context.Background(), token, githubUsername, policyRepo, "main", "missing.yaml")
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrNotFound.SameAs(err))
+}
+
+func TestGithubCreateWebhook(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	hook := &sources.Webhook{URL: "https://example.com/hooks", Secret: "somehooksecret", Events: []string{"push", "release"}}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().
+		CreateHook(gomock.Any(), githubUsername, policyRepo, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, created *github.Hook) (*github.Hook, *github.Response, error) {
+			assert.Equal("https://example.com/hooks", created.GetConfig().GetURL())
+			assert.Equal("somehooksecret", created.GetConfig().GetSecret())
+			assert.Equal("json", created.GetConfig().GetContentType())
+			assert.Equal([]string{"push", "release"}, created.Events)
+			return &github.Hook{ID: ptr.To(int64(42))}, nil, nil
+		})
+
+	// Act
+	id, err := p.(sources.WebhookSource).CreateWebhook(context.Background(), token, githubUsername, policyRepo, hook)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("42", id)
+}
+
+func TestGithubListWebhooks(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	hook := &github.Hook{
+		ID:     ptr.To(int64(42)),
+		Config: &github.HookConfig{URL: ptr.To("https://example.com/hooks"), ContentType: ptr.To("json")},
+		Events: []string{"push"},
+	}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().
+		ListHooks(gomock.Any(), githubUsername, policyRepo, gomock.Any()).
+		Return([]*github.Hook{hook}, &github.Response{}, nil)
+
+	// Act
+	hooks, err := p.(sources.WebhookSource).ListWebhooks(context.Background(), token, githubUsername, policyRepo)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal([]*sources.Webhook{{ID: "42", URL: "https://example.com/hooks", Events: []string{"push"}, ContentType: "json"}}, hooks)
+}
+
+func TestGithubDeleteWebhookNotFound(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	notFound := &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusNotFound},
+		Message:  "Not Found",
+	}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().DeleteHook(gomock.Any(), githubUsername, policyRepo, int64(42)).Return(nil, notFound)
+
+	// Act
+	err := p.(sources.WebhookSource).DeleteWebhook(context.Background(), token, githubUsername, policyRepo, "42")
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrNotFound.SameAs(err))
+}
+
+func TestGithubGetOrg(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	org := &github.Organization{
+		Login:        ptr.To("aserto-dev"),
+		Description:  ptr.To("Authorization for everyone"),
+		AvatarURL:    ptr.To("https://avatars.example.com/aserto-dev"),
+		HTMLURL:      ptr.To("https://github.com/aserto-dev"),
+		BillingEmail: ptr.To("billing@aserto.com"),
+	}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetOrg(gomock.Any(), "aserto-dev").Return(org, nil)
+
+	// Act
+	detail, err := p.(sources.OrgSource).GetOrg(context.Background(), token, "aserto-dev")
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(&sources.SccOrgDetail{
+		ID:           "aserto-dev",
+		Name:         "aserto-dev",
+		Description:  "Authorization for everyone",
+		AvatarURL:    "https://avatars.example.com/aserto-dev",
+		URL:          "https://github.com/aserto-dev",
+		BillingEmail: "billing@aserto.com",
+	}, detail)
+}
+
+func TestGithubGetOrgNotFound(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	notFound := &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusNotFound},
+		Message:  "Not Found",
+	}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetOrg(gomock.Any(), "missing").Return(nil, notFound)
+
+	// Act
+	_, err := p.(sources.OrgSource).GetOrg(context.Background(), token, "missing")
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrNotFound.SameAs(err))
+}
+
+func TestGithubGetBranchHead(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	ref := &github.Reference{Object: &github.GitObject{SHA: ptr.To("abc123")}}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepoRef(gomock.Any(), githubUsername, policyRepo, "heads/main").Return(ref, nil, nil)
+
+	// Act
+	sha, err := p.(sources.BranchSource).GetBranchHead(context.Background(), token, githubUsername, policyRepo, "main")
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("abc123", sha)
+}
+
+func TestGithubGetBranchHeadNotFound(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	notFound := &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusNotFound},
+		Message:  "Not Found",
+	}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepoRef(gomock.Any(), githubUsername, policyRepo, "heads/missing").Return(nil, nil, notFound)
+
+	// Act
+	_, err := p.(sources.BranchSource).GetBranchHead(context.Background(), token, githubUsername, policyRepo, "missing")
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrNotFound.SameAs(err))
+}
+
+func TestGithubListPullRequests(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	pull := &github.PullRequest{
+		Number:  ptr.To(7),
+		Title:   ptr.To("Update policy"),
+		HTMLURL: ptr.To("https://github.com/aserto-dev/policy/pull/7"),
+		State:   ptr.To("open"),
+		Head:    &github.PullRequestBranch{Ref: ptr.To("update")},
+		Base:    &github.PullRequestBranch{Ref: ptr.To(defaultBranch)},
+	}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().ListPullRequests(gomock.Any(), githubUsername, policyRepo, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, opts *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error) {
+			assert.Equal("open", opts.State)
+			return []*github.PullRequest{pull}, &github.Response{}, nil
+		})
+
+	// Act
+	pulls, err := p.(sources.PullRequestSource).ListPullRequests(context.Background(), token, githubUsername, policyRepo, "")
+
+	// Assert
+	assert.NoError(err)
+	assert.Len(pulls, 1)
+	assert.Equal(&sources.PullRequest{
+		Number: 7,
+		Title:  "Update policy",
+		URL:    "https://github.com/aserto-dev/policy/pull/7",
+		Head:   "update",
+		Base:   defaultBranch,
+		State:  "open",
+	}, pulls[0])
+}
+
+func TestGithubListPullRequestsUnknownState(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	_, err := p.(sources.PullRequestSource).ListPullRequests(context.Background(), token, githubUsername, policyRepo, "merged")
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "unknown pull request state 'merged'")
+}
+
+func TestGithubAddDeployKey(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	key := &github.Key{Title: ptr.To("aserto"), Key: ptr.To("ssh-ed25519 AAAA"), ReadOnly: ptr.To(false)}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().
+		CreateKey(gomock.Any(), githubUsername, policyRepo, key).
+		Return(&github.Key{ID: ptr.To(int64(7))}, nil, nil)
+
+	// Act
+	id, err := p.(sources.DeployKeySource).AddDeployKey(context.Background(), token, githubUsername, policyRepo, "aserto", "ssh-ed25519 AAAA", false)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("7", id)
+}
+
+func TestGithubHasDeployKeyOnSecondPage(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	gomock.InOrder(
+		tstInteraction.mockGithub.EXPECT().
+			ListKeys(gomock.Any(), githubUsername, policyRepo, &github.ListOptions{PerPage: 100}).
+			Return([]*github.Key{{Title: ptr.To("other")}}, &github.Response{NextPage: 2}, nil),
+		tstInteraction.mockGithub.EXPECT().
+			ListKeys(gomock.Any(), githubUsername, policyRepo, &github.ListOptions{Page: 2, PerPage: 100}).
+			Return([]*github.Key{{Title: ptr.To("aserto")}}, &github.Response{}, nil),
+	)
+
+	// Act
+	exists, err := p.(sources.DeployKeySource).HasDeployKey(context.Background(), token, githubUsername, policyRepo, "aserto")
+
+	// Assert
+	assert.NoError(err)
+	assert.True(exists)
+}
+
+func TestGithubInitialTagWorkflowRunsInstantly(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0, WaitTagTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	defaultBr := defaultBranch
+	githubRepo := &github.Repository{DefaultBranch: &defaultBr}
+	resp := &github.Response{Response: &http.Response{StatusCode: 200}}
+	sha := "someshh"
+	obj := &github.GitObject{SHA: &sha}
+	ref := &github.Reference{Object: obj, Ref: &sha}
+	id := int64(345)
+	run := &github.WorkflowRun{ID: &id}
+	runs := &github.WorkflowRuns{
+		WorkflowRuns: []*github.WorkflowRun{run},
+	}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(githubRepo, nil)
+	tstInteraction.mockGithub.EXPECT().
+		ListRepoTags(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, nil)
+	tstInteraction.mockGithub.EXPECT().
+		GetRepoRef(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(ref, resp, nil)
+	tstInteraction.mockGraphql.EXPECT().Mutate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	tstInteraction.mockGithub.EXPECT().ListRepositoryWorkflowRuns(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(runs, nil)
+
+	// Act
+	runID, err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "build-workflow.yaml", "", "")
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(id, runID)
+}
+
+func TestGithubCreateCommitOnBranchRebasesOnConflict(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	commit := &sources.Commit{
+		Branch:           defaultBranch,
+		Message:          "update policy",
+		Owner:            githubUsername,
+		Repo:             policyRepo,
+		Content:          map[string]string{".manifest": "content"},
+		ConflictStrategy: sources.ConflictRebase,
+	}
+	sha := "newsha"
+	ghCommit := &github.Commit{SHA: &sha}
+
+	// Expect
+	gomock.InOrder(
+		tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				setQueryField(q, "oldhead", "Repository", "Ref", "Target", "Oid")
+				return nil
+			}),
+		tstInteraction.mockGraphql.EXPECT().Mutate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(errors.New("Expected branch to point to \"oldhead\" but it did not. Pull and try again.")),
+		tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+				setQueryField(q, "newhead", "Repository", "Ref", "Target", "Oid")
+				return nil
+			}),
+		tstInteraction.mockGraphql.EXPECT().Mutate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, m interface{}, _ interface{}, _ map[string]interface{}) error {
+				setQueryField(m, sha, "CreateCommitOnBranch", "Commit", "OID")
+				return nil
+			}),
+	)
+	tstInteraction.mockGithub.EXPECT().GetCommit(gomock.Any(), githubUsername, policyRepo, sha).Return(ghCommit, nil)
+
+	// Act
+	commitSha, err := p.CreateCommitOnBranch(context.Background(), token, commit)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(sha, commitSha)
+}
+
+func TestGithubCreateCommitOnBranchConflictFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	commit := &sources.Commit{
+		Branch:  defaultBranch,
+		Message: "update policy",
+		Owner:   githubUsername,
+		Repo:    policyRepo,
+		Content: map[string]string{".manifest": "content"},
+	}
+
+	// Expect
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+			setQueryField(q, "oldhead", "Repository", "Ref", "Target", "Oid")
+			return nil
+		})
+	tstInteraction.mockGraphql.EXPECT().Mutate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(errors.New("Expected branch to point to \"oldhead\" but it did not. Pull and try again."))
+
+	// Act
+	commitSha, err := p.CreateCommitOnBranch(context.Background(), token, commit)
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrConcurrentUpdate.SameAs(err))
+	assert.Contains(err.Error(), "failed to create commit")
+	assert.Empty(commitSha)
+}
+
+func TestGithubCreateCommitOnBranchWithoutContent(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	commit := &sources.Commit{
+		Branch:  defaultBranch,
+		Message: "update policy",
+		Owner:   githubUsername,
+		Repo:    policyRepo,
+	}
+
+	// Act
+	_, err := p.CreateCommitOnBranch(context.Background(), token, commit)
+
+	// Assert
+	assert.Error(err)
+	assert.Equal("commit must contain at least one file", err.Error())
+}
+
+func TestGithubCreateCommitOnBranchOfEmptyRepo(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	commit := &sources.Commit{
+		Branch:  defaultBranch,
+		Message: "update policy",
+		Owner:   githubUsername,
+		Repo:    policyRepo,
+		Content: map[string]string{".manifest": "content"},
+	}
+
+	// Expect
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	// Act
+	_, err := p.CreateCommitOnBranch(context.Background(), token, commit)
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrEmptyRepo.SameAs(err))
+	assert.ErrorIs(err, sources.ErrEmptyRepo)
+}
+
+func TestGithubCreateCommitOnBranchCreatesBranch(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	commit := &sources.Commit{
+		Branch:      "feature",
+		StartBranch: defaultBranch,
+		Message:     "update policy",
+		Owner:       githubUsername,
+		Repo:        policyRepo,
+		Content:     map[string]string{".manifest": "content"},
+	}
+	notFound := &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusNotFound},
+		Message:  "Not Found",
+	}
+	start := &github.Reference{Object: &github.GitObject{SHA: ptr.To("basesha")}}
+	sha := "newsha"
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepoRef(gomock.Any(), githubUsername, policyRepo, "heads/feature").Return(nil, nil, notFound)
+	tstInteraction.mockGithub.EXPECT().GetRepoRef(gomock.Any(), githubUsername, policyRepo, "heads/"+defaultBranch).Return(start, nil, nil)
+	tstInteraction.mockGithub.EXPECT().CreateRepoRef(gomock.Any(), githubUsername, policyRepo, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, ref *github.Reference) error {
+			assert.Equal("refs/heads/feature", ref.GetRef())
+			assert.Equal("basesha", ref.GetObject().GetSHA())
+			return nil
+		})
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+			setQueryField(q, "basesha", "Repository", "Ref", "Target", "Oid")
+			return nil
+		})
+	tstInteraction.mockGraphql.EXPECT().Mutate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, m interface{}, _ interface{}, _ map[string]interface{}) error {
+			setQueryField(m, sha, "CreateCommitOnBranch", "Commit", "OID")
+			return nil
+		})
+	tstInteraction.mockGithub.EXPECT().GetCommit(gomock.Any(), githubUsername, policyRepo, sha).Return(&github.Commit{SHA: &sha}, nil)
+
+	// Act
+	commitSha, err := p.CreateCommitOnBranch(context.Background(), token, commit)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(sha, commitSha)
+}
+
+func TestGithubCreateCommitOnBranchConflictIsNotRetried(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 5}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	commit := &sources.Commit{
+		Branch:  defaultBranch,
+		Message: "update policy",
+		Owner:   githubUsername,
+		Repo:    policyRepo,
+		Content: map[string]string{".manifest": "content"},
+	}
+
+	// Expect
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+			setQueryField(q, "oldhead", "Repository", "Ref", "Target", "Oid")
+			return nil
+		}).Times(1)
+	tstInteraction.mockGraphql.EXPECT().Mutate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(errors.New("STALE_DATA: the branch has moved")).Times(1)
+
+	// Act
+	_, err := p.CreateCommitOnBranch(context.Background(), token, commit)
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrConcurrentUpdate.SameAs(err))
+}
+
+func TestGithubCreateCommitOnBranchWithREST(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{GithubRESTCommits: true}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	commit := &sources.Commit{
+		Branch:  defaultBranch,
+		Message: "update policy",
+		Owner:   githubUsername,
+		Repo:    policyRepo,
+		Content: map[string]string{".manifest": "content", "policy.rego": "package policy"},
+		Author:  &sources.CommitAuthor{Name: "Aserto Bot", Email: "bot@aserto.com"},
+	}
+	head := &github.Reference{Object: &github.GitObject{SHA: ptr.To("headsha")}}
+	headCommit := &github.Commit{SHA: ptr.To("headsha"), Tree: &github.Tree{SHA: ptr.To("basetree")}}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepoRef(gomock.Any(), githubUsername, policyRepo, "heads/"+defaultBranch).Return(head, nil, nil)
+	tstInteraction.mockGithub.EXPECT().GetCommit(gomock.Any(), githubUsername, policyRepo, "headsha").Return(headCommit, nil)
+	tstInteraction.mockGithub.EXPECT().CreateTree(gomock.Any(), githubUsername, policyRepo, "basetree", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _, _ string, entries []*github.TreeEntry) (*github.Tree, error) {
+			assert.Len(entries, 2)
+			assert.Equal(".manifest", entries[0].GetPath())
+			assert.Equal("content", entries[0].GetContent())
+			assert.Equal("policy.rego", entries[1].GetPath())
+			assert.Equal("package policy", entries[1].GetContent())
+			return &github.Tree{SHA: ptr.To("newtree")}, nil
+		})
+	tstInteraction.mockGithub.EXPECT().CreateCommit(gomock.Any(), githubUsername, policyRepo, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, c *github.Commit) (*github.Commit, error) {
+			assert.Equal("update policy", c.GetMessage())
+			assert.Equal("newtree", c.GetTree().GetSHA())
+			assert.Len(c.Parents, 1)
+			assert.Equal("headsha", c.Parents[0].GetSHA())
+			assert.Equal("Aserto Bot", c.GetAuthor().GetName())
+			return &github.Commit{SHA: ptr.To("newsha")}, nil
+		})
+	tstInteraction.mockGithub.EXPECT().UpdateRef(gomock.Any(), githubUsername, policyRepo, gomock.Any(), false).
+		DoAndReturn(func(_ context.Context, _, _ string, ref *github.Reference, _ bool) (*github.Reference, error) {
+			assert.Equal("refs/heads/"+defaultBranch, ref.GetRef())
+			assert.Equal("newsha", ref.GetObject().GetSHA())
+			return ref, nil
+		})
+	tstInteraction.mockGithub.EXPECT().GetCommit(gomock.Any(), githubUsername, policyRepo, "newsha").Return(&github.Commit{SHA: ptr.To("newsha")}, nil)
+
+	// Act
+	commitSha, err := p.CreateCommitOnBranch(context.Background(), token, commit)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("newsha", commitSha)
+}
+
+func TestGithubCreateCommitOnBranchWithRESTUnchanged(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{GithubRESTCommits: true}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	commit := &sources.Commit{
+		Branch:  defaultBranch,
+		Message: "update policy",
+		Owner:   githubUsername,
+		Repo:    policyRepo,
+		Content: map[string]string{".manifest": "content"},
+	}
+	head := &github.Reference{Object: &github.GitObject{SHA: ptr.To("headsha")}}
+	headCommit := &github.Commit{SHA: ptr.To("headsha"), Tree: &github.Tree{SHA: ptr.To("basetree")}}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepoRef(gomock.Any(), githubUsername, policyRepo, "heads/"+defaultBranch).Return(head, nil, nil)
+	tstInteraction.mockGithub.EXPECT().GetCommit(gomock.Any(), githubUsername, policyRepo, "headsha").Return(headCommit, nil)
+	tstInteraction.mockGithub.EXPECT().CreateTree(gomock.Any(), githubUsername, policyRepo, "basetree", gomock.Any()).
+		Return(&github.Tree{SHA: ptr.To("basetree")}, nil)
+
+	// Act
+	commitSha, err := p.CreateCommitOnBranch(context.Background(), token, commit)
+
+	// Assert
+	assert.NoError(err)
+	assert.Empty(commitSha)
+}
+
+func TestGithubCreateCommitOnBranchWithRESTConflict(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{GithubRESTCommits: true}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	commit := &sources.Commit{
+		Branch:  defaultBranch,
+		Message: "update policy",
+		Owner:   githubUsername,
+		Repo:    policyRepo,
+		Content: map[string]string{".manifest": "content"},
+	}
+	head := &github.Reference{Object: &github.GitObject{SHA: ptr.To("headsha")}}
+	headCommit := &github.Commit{SHA: ptr.To("headsha"), Tree: &github.Tree{SHA: ptr.To("basetree")}}
+	notFastForward := &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusUnprocessableEntity, Request: &http.Request{Method: http.MethodPatch}},
+		Message:  "Update is not a fast forward",
+	}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepoRef(gomock.Any(), githubUsername, policyRepo, "heads/"+defaultBranch).Return(head, nil, nil)
+	tstInteraction.mockGithub.EXPECT().GetCommit(gomock.Any(), githubUsername, policyRepo, "headsha").Return(headCommit, nil)
+	tstInteraction.mockGithub.EXPECT().CreateTree(gomock.Any(), githubUsername, policyRepo, "basetree", gomock.Any()).
+		Return(&github.Tree{SHA: ptr.To("newtree")}, nil)
+	tstInteraction.mockGithub.EXPECT().CreateCommit(gomock.Any(), githubUsername, policyRepo, gomock.Any()).
+		Return(&github.Commit{SHA: ptr.To("newsha")}, nil)
+	tstInteraction.mockGithub.EXPECT().UpdateRef(gomock.Any(), githubUsername, policyRepo, gomock.Any(), false).Return(nil, notFastForward)
+
+	// Act
+	_, err := p.CreateCommitOnBranch(context.Background(), token, commit)
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrConcurrentUpdate.SameAs(err))
+}
+
+func TestGithubCreateCommitOnBranchWithRESTRebasesOnConflict(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{GithubRESTCommits: true}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	commit := &sources.Commit{
+		Branch:           defaultBranch,
+		Message:          "update policy",
+		Owner:            githubUsername,
+		Repo:             policyRepo,
+		Content:          map[string]string{".manifest": "content"},
+		ConflictStrategy: sources.ConflictRebase,
+	}
+	notFastForward := &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusUnprocessableEntity, Request: &http.Request{Method: http.MethodPatch}},
+		Message:  "Update is not a fast forward",
+	}
+
+	// Expect
+	gomock.InOrder(
+		tstInteraction.mockGithub.EXPECT().GetRepoRef(gomock.Any(), githubUsername, policyRepo, "heads/"+defaultBranch).
+			Return(&github.Reference{Object: &github.GitObject{SHA: ptr.To("oldhead")}}, nil, nil),
+		tstInteraction.mockGithub.EXPECT().GetCommit(gomock.Any(), githubUsername, policyRepo, "oldhead").
+			Return(&github.Commit{SHA: ptr.To("oldhead"), Tree: &github.Tree{SHA: ptr.To("oldtree")}}, nil),
+		tstInteraction.mockGithub.EXPECT().CreateTree(gomock.Any(), githubUsername, policyRepo, "oldtree", gomock.Any()).
+			Return(&github.Tree{SHA: ptr.To("staletree")}, nil),
+		tstInteraction.mockGithub.EXPECT().CreateCommit(gomock.Any(), githubUsername, policyRepo, gomock.Any()).
+			Return(&github.Commit{SHA: ptr.To("stalesha")}, nil),
+		tstInteraction.mockGithub.EXPECT().UpdateRef(gomock.Any(), githubUsername, policyRepo, gomock.Any(), false).Return(nil, notFastForward),
+		tstInteraction.mockGithub.EXPECT().GetRepoRef(gomock.Any(), githubUsername, policyRepo, "heads/"+defaultBranch).
+			Return(&github.Reference{Object: &github.GitObject{SHA: ptr.To("newhead")}}, nil, nil),
+		tstInteraction.mockGithub.EXPECT().GetCommit(gomock.Any(), githubUsername, policyRepo, "newhead").
+			Return(&github.Commit{SHA: ptr.To("newhead"), Tree: &github.Tree{SHA: ptr.To("newtree")}}, nil),
+		tstInteraction.mockGithub.EXPECT().CreateTree(gomock.Any(), githubUsername, policyRepo, "newtree", gomock.Any()).
+			Return(&github.Tree{SHA: ptr.To("rebasedtree")}, nil),
+		tstInteraction.mockGithub.EXPECT().CreateCommit(gomock.Any(), githubUsername, policyRepo, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ string, c *github.Commit) (*github.Commit, error) {
+				assert.Equal("newhead", c.Parents[0].GetSHA())
+				return &github.Commit{SHA: ptr.To("rebasedsha")}, nil
+			}),
+		tstInteraction.mockGithub.EXPECT().UpdateRef(gomock.Any(), githubUsername, policyRepo, gomock.Any(), false).Return(nil, nil),
+		tstInteraction.mockGithub.EXPECT().GetCommit(gomock.Any(), githubUsername, policyRepo, "rebasedsha").Return(&github.Commit{SHA: ptr.To("rebasedsha")}, nil),
+	)
+
+	// Act
+	commitSha, err := p.CreateCommitOnBranch(context.Background(), token, commit)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("rebasedsha", commitSha)
+}
+
+func TestGithubCreateFile(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().CreateFile(gomock.Any(), githubUsername, policyRepo, ".manifest", &github.RepositoryContentFileOptions{
+		Message: ptr.To("add manifest"),
+		Content: []byte("content"),
+		Branch:  ptr.To(defaultBranch),
+	}).Return(&github.RepositoryContentResponse{}, nil)
+
+	// Act
+	err := p.CreateFile(context.Background(), token, githubUsername, policyRepo, ".manifest", "content", defaultBranch, "add manifest")
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestGithubCreateFileFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().CreateFile(gomock.Any(), githubUsername, policyRepo, ".manifest", gomock.Any()).
+		Return(nil, errors.New("422 Invalid request"))
+
+	// Act
+	err := p.CreateFile(context.Background(), token, githubUsername, policyRepo, ".manifest", "content", "", "add manifest")
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "failed to create file: .manifest")
+}
+
+func TestGithubListTags(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: int32(2)}
+	first, second := "v0.0.2", "v0.0.1"
+	tags := []*github.RepositoryTag{{Name: &first}, {Name: &second}}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().ListRepoTags(gomock.Any(), githubUsername, policyRepo, gomock.Any()).Return(tags, nil)
+
+	// Act
+	result, resp, err := p.ListTags(context.Background(), token, githubUsername, policyRepo, page)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal([]string{first, second}, result)
+	assert.Equal("2", resp.NextToken)
+}
+
+func TestGithubListOrgMembers(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: int32(2)}
+	users := []*github.User{{Login: ptr.To("alice")}, {Login: ptr.To("bob"), Name: ptr.To("Bob")}}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().ListOrgMembers(gomock.Any(), githubUsername, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, opts *github.ListMembersOptions) ([]*github.User, *github.Response, error) {
+			assert.Equal(1, opts.Page)
+			assert.Equal(2, opts.PerPage)
+			return users, &github.Response{NextPage: 2}, nil
+		})
+
+	// Act
+	members, resp, err := p.ListOrgMembers(context.Background(), token, githubUsername, page)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal([]*sources.SccUser{{Login: "alice"}, {Login: "bob", Name: "Bob"}}, members)
+	assert.Equal("2", resp.NextToken)
+}
+
+func TestGithubListOrgMembersInvalidPageSize(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	_, _, err := p.ListOrgMembers(context.Background(), token, githubUsername, &api.PaginationRequest{Size: 101})
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "page size must be >= -1 and <= 100")
+}
+
+func TestGithubProfilePage(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: int32(10)}
+
+	// Expect
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+			setQueryField(q, githubUsername, "Viewer", "Login")
+			setQueryField(q, "cursor", "Viewer", "Repositories", "PageInfo", "EndCursor")
+			return nil
+		})
+
+	// Act
+	username, repos, resp, err := p.ProfilePage(context.Background(), token, page)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(githubUsername, username)
+	assert.Empty(repos)
+	assert.Equal("cursor", resp.NextToken)
+}
+
+func TestGithubPreflight(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	username := githubUsername
+	user := &github.User{Login: &username}
+	resp := &github.Response{Response: &http.Response{StatusCode: 200, Header: http.Header{}}}
+	resp.Response.Header.Set("X-OAuth-Scopes", "repo,user")
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(user, resp, nil)
+
+	// Act
+	result, err := p.Preflight(context.Background(), token, githubUsername, []string{"repo"})
+
+	// Assert
+	assert.NoError(err)
+	assert.True(result.Passed())
+	assert.Equal(sources.PreflightPassed, result.Connection.Status)
+	assert.Equal(sources.PreflightPassed, result.Scopes.Status)
+	assert.Equal(sources.PreflightPassed, result.CreateRepo.Status)
+	assert.Equal(sources.PreflightPassed, result.Visibility.Status)
+}
+
+func TestGithubPreflightNoResponse(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(nil, nil, nil)
+
+	// Act
+	result, err := p.Preflight(context.Background(), token, githubUsername, []string{"repo"})
+
+	// Assert
+	assert.NoError(err)
+	assert.False(result.Passed())
+	assert.Equal(sources.PreflightFailed, result.Connection.Status)
+}
+
+func TestGithubPreflightOrgDisallowsPublicRepos(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	username := "someone"
+	user := &github.User{Login: &username}
+	resp := &github.Response{Response: &http.Response{StatusCode: 200, Header: http.Header{}}}
+	resp.Response.Header.Set("X-OAuth-Scopes", "user")
+	role := "member"
+	canCreate, canCreatePublic := true, false
+	org := &github.Organization{MembersCanCreateRepos: &canCreate, MembersCanCreatePublicRepos: &canCreatePublic}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(user, resp, nil)
+	tstInteraction.mockGithub.EXPECT().GetOrgMembership(gomock.Any(), githubUsername).Return(&github.Membership{Role: &role}, nil)
+	tstInteraction.mockGithub.EXPECT().GetOrg(gomock.Any(), githubUsername).Return(org, nil)
+
+	// Act
+	result, err := p.Preflight(context.Background(), token, githubUsername, []string{"repo"})
+
+	// Assert
+	assert.NoError(err)
+	assert.False(result.Passed())
+	assert.Equal(sources.PreflightPassed, result.Connection.Status)
+	assert.Equal(sources.PreflightFailed, result.Scopes.Status)
+	assert.Equal(sources.PreflightPassed, result.CreateRepo.Status)
+	assert.Equal(sources.PreflightFailed, result.Visibility.Status)
+}
+
+func TestGithubListReposWithFilter(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: int32(-1)}
+	var searchQuery interface{}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), "").Return(&github.User{Login: ptr.To("someone")}, nil, nil)
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ interface{}, vars map[string]interface{}) error {
+			searchQuery = vars["query"]
+			return nil
+		})
+
+	// Act
+	_, _, err := p.ListRepos(context.Background(), token, githubUsername, page, &sources.ListReposOptions{Filter: "pol"})
+
+	// Assert
+	assert.NoError(err)
+	assert.EqualValues("org:aserto-dev pol in:name", searchQuery)
+}
+
+func TestGithubListReposOfAuthenticatedUser(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: int32(-1)}
+	var searchQuery interface{}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), "").Return(&github.User{Login: ptr.To(githubUsername)}, nil, nil)
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ interface{}, vars map[string]interface{}) error {
+			searchQuery = vars["query"]
+			return nil
+		})
+
+	// Act
+	_, _, err := p.ListRepos(context.Background(), token, githubUsername, page, nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.EqualValues("user:"+githubUsername, searchQuery)
+}
+
+func TestGithubListReposConsistent(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: int32(10)}
+	var login interface{}
+
+	// Expect
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, q interface{}, vars map[string]interface{}) error {
+			login = vars["login"]
+			setQueryField(q, githubUsername, "RepositoryOwner", "Login")
+			setQueryField(q, "cursor", "RepositoryOwner", "Repositories", "PageInfo", "EndCursor")
+			return nil
+		})
+
+	// Act
+	repos, resp, err := p.ListRepos(context.Background(), token, githubUsername, page, &sources.ListReposOptions{Consistent: true})
+
+	// Assert
+	assert.NoError(err)
+	assert.Empty(repos)
+	assert.EqualValues(githubUsername, login)
+	assert.Equal("cursor", resp.NextToken)
+}
+
+func TestGithubListReposConsistentOwnerNotFound(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: int32(-1)}
+
+	// Expect
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	// Act
+	_, _, err := p.ListRepos(context.Background(), token, "unknown", page, &sources.ListReposOptions{Consistent: true})
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrNotFound.SameAs(err))
+}
+
+func TestGithubListReposConsistentWithFilter(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: int32(-1)}
+
+	// Act
+	_, _, err := p.ListRepos(context.Background(), token, githubUsername, page, &sources.ListReposOptions{Consistent: true, Filter: "pol"})
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "filter isn't supported")
+}
+
+func TestGithubListReposKeepsPagesReadBeforeFailure(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: int32(-1)}
+	firstPage := `{"Search": {
+		"PageInfo": {"HasNextPage": true, "EndCursor": "cursor"},
+		"Edges": [{"Node": {"Repository": {"Name": "policy", "Owner": {"Login": "aserto-dev"}, "URL": "https://github.com/aserto-dev/policy"}}}]
+	}}`
 
 	// Expect
-	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(nil, nil, errors.New("boom"))
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), "").Return(&github.User{Login: ptr.To("aserto-demo")}, nil, nil)
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+			return json.Unmarshal([]byte(firstPage), q)
+		})
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("boom"))
 
 	// Act
-	err := p.CreateRepo(context.Background(), token, githubUsername, policyRepo)
+	repos, resp, err := p.ListRepos(context.Background(), token, githubUsername, page, nil)
 
 	// Assert
 	assert.Error(err)
-	assert.Equal(err.Error(), "failed to read user from github: boom")
+	assert.Contains(err.Error(), "boom")
+	assert.Nil(resp)
+	assert.Len(repos, 1)
+	assert.Equal("policy", repos[0].Name)
 }
 
-func TestGithubCreateFails(t *testing.T) {
+func TestGithubListOrgsKeepsPagesReadBeforeFailure(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
@@ -654,22 +2921,31 @@ func TestGithubCreateFails(t *testing.T) {
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
 	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	username := githubUsername
-	user := &github.User{Login: &username}
+	page := &api.PaginationRequest{Size: int32(-1)}
+	firstPage := `{"Viewer": {"Organizations": {
+		"Nodes": [{"Login": "aserto-dev"}, {"Login": "aserto-demo"}],
+		"PageInfo": {"HasNextPage": true, "EndCursor": "cursor"}
+	}}}`
 
 	// Expect
-	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(user, nil, nil)
-	tstInteraction.mockGithub.EXPECT().CreateRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("boom"))
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, q interface{}, _ map[string]interface{}) error {
+			return json.Unmarshal([]byte(firstPage), q)
+		})
+	tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("boom"))
 
 	// Act
-	err := p.CreateRepo(context.Background(), token, githubUsername, policyRepo)
+	orgs, resp, err := p.ListOrgs(context.Background(), token, page, nil)
 
 	// Assert
 	assert.Error(err)
-	assert.Equal(err.Error(), "failed to create repo: boom")
+	assert.Contains(err.Error(), "boom")
+	assert.Nil(resp)
+	assert.Len(orgs, 2)
+	assert.Equal("aserto-demo", orgs[1].Id)
 }
 
-func TestGithubCreate(t *testing.T) {
+func TestGithubListReposGetUserFails(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
@@ -677,21 +2953,22 @@ func TestGithubCreate(t *testing.T) {
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
 	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	username := githubUsername
-	user := &github.User{Login: &username}
+	page := &api.PaginationRequest{Size: int32(-1)}
 
 	// Expect
-	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(user, nil, nil)
-	tstInteraction.mockGithub.EXPECT().CreateRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), "").Return(nil, nil, errors.New("boom"))
 
 	// Act
-	err := p.CreateRepo(context.Background(), token, githubUsername, policyRepo)
+	repos, resp, err := p.ListRepos(context.Background(), token, githubUsername, page, nil)
 
 	// Assert
-	assert.NoError(err)
+	assert.Error(err)
+	assert.Contains(err.Error(), "failed to get the authenticated user: boom")
+	assert.Empty(repos)
+	assert.Nil(resp)
 }
 
-func TestGetDefultRepoFails(t *testing.T) {
+func TestGithubIsRepoEmpty(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
@@ -699,20 +2976,25 @@ func TestGetDefultRepoFails(t *testing.T) {
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
 	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
+	defaultBr := defaultBranch
+	githubRepo := &github.Repository{DefaultBranch: &defaultBr}
+	resp := &github.Response{Response: &http.Response{StatusCode: 409}}
 
 	// Expect
-	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(nil, errors.New("no Connection"))
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(githubRepo, nil)
+	tstInteraction.mockGithub.EXPECT().
+		GetRepoRef(gomock.Any(), githubUsername, policyRepo, "heads/"+defaultBranch).
+		Return(nil, resp, errors.New("Git Repository is empty."))
 
 	// Act
-	repo, err := p.GetDefaultBranch(context.Background(), token, githubUsername, policyRepo)
+	empty, err := p.IsRepoEmpty(context.Background(), token, githubUsername, policyRepo)
 
 	// Assert
-	assert.Error(err)
-	assert.Contains(err.Error(), "failed to get repo: no Connection")
-	assert.Empty(repo)
+	assert.NoError(err)
+	assert.True(empty)
 }
 
-func TestGithubGetDefaultRepo(t *testing.T) {
+func TestGithubIsRepoEmptyInitialized(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
@@ -722,20 +3004,21 @@ func TestGithubGetDefaultRepo(t *testing.T) {
 	token := &sources.AccessToken{Token: "sometokenvalue"}
 	defaultBr := defaultBranch
 	githubRepo := &github.Repository{DefaultBranch: &defaultBr}
+	resp := &github.Response{Response: &http.Response{StatusCode: 200}}
 
 	// Expect
 	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(githubRepo, nil)
+	tstInteraction.mockGithub.EXPECT().GetRepoRef(gomock.Any(), githubUsername, policyRepo, gomock.Any()).Return(&github.Reference{}, resp, nil)
 
 	// Act
-	repo, err := p.GetDefaultBranch(context.Background(), token, githubUsername, policyRepo)
+	empty, err := p.IsRepoEmpty(context.Background(), token, githubUsername, policyRepo)
 
 	// Assert
 	assert.NoError(err)
-	assert.NotEmpty(repo)
-	assert.Equal(repo, defaultBranch)
+	assert.False(empty)
 }
 
-func TestGithubInitialTagWithInvalidRepoPath(t *testing.T) {
+func TestGithubCreatePrivate(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
@@ -743,16 +3026,29 @@ func TestGithubInitialTagWithInvalidRepoPath(t *testing.T) {
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
 	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
+	username := githubUsername
+	user := &github.User{Login: &username}
+	var created *github.Repository
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(user, nil, nil)
+	tstInteraction.mockGithub.EXPECT().CreateRepo(gomock.Any(), "", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, repo *github.Repository) (*github.Repository, error) {
+			created = repo
+			return repo, nil
+		})
 
 	// Act
-	err := p.InitialTag(context.Background(), token, "policy", "build-workflow.yaml", "")
+	_, err := p.CreateRepo(context.Background(), token, githubUsername, policyRepo, &sources.CreateRepoOptions{Private: true, Description: "policies"})
 
 	// Assert
-	assert.Error(err)
-	assert.Equal(err.Error(), "invalid full github repo name 'policy', should be in the form owner/repo")
+	assert.NoError(err)
+	assert.True(created.GetPrivate())
+	assert.True(created.GetAutoInit())
+	assert.Equal("policies", created.GetDescription())
 }
 
-func TestGithubInitialTagAndGetRepoFails(t *testing.T) {
+func TestGithubCreateRepoWithoutAutoInit(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
@@ -760,19 +3056,26 @@ func TestGithubInitialTagAndGetRepoFails(t *testing.T) {
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
 	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
+	user := &github.User{Login: ptr.To(githubUsername)}
+	var created *github.Repository
 
 	// Expect
-	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("not found"))
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(user, nil, nil)
+	tstInteraction.mockGithub.EXPECT().CreateRepo(gomock.Any(), "", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, repo *github.Repository) (*github.Repository, error) {
+			created = repo
+			return repo, nil
+		})
 
 	// Act
-	err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "build-workflow.yaml", "")
+	_, err := p.CreateRepo(context.Background(), token, githubUsername, policyRepo, &sources.CreateRepoOptions{AutoInit: ptr.To(false)})
 
 	// Assert
-	assert.Error(err)
-	assert.Equal(err.Error(), "failed to get repo: not found")
+	assert.NoError(err)
+	assert.False(created.GetAutoInit())
 }
 
-func TestGithubInitialTagAndListRepoTagsFails(t *testing.T) {
+func TestGithubCreateRepoInOrgMissingScopes(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
@@ -780,22 +3083,23 @@ func TestGithubInitialTagAndListRepoTagsFails(t *testing.T) {
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
 	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
+	user := &github.User{Login: ptr.To("someone")}
+	resp := &github.Response{Response: &http.Response{StatusCode: 200, Header: http.Header{}}}
+	resp.Response.Header.Set("X-OAuth-Scopes", "repo, user")
 
 	// Expect
-	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
-	tstInteraction.mockGithub.EXPECT().
-		ListRepoTags(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-		Return(nil, errors.New("tags not found"))
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(user, resp, nil)
 
 	// Act
-	err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "build-workflow.yaml", "")
+	_, err := p.CreateRepo(context.Background(), token, githubUsername, policyRepo, nil)
 
 	// Assert
 	assert.Error(err)
-	assert.Equal(err.Error(), "failed to list tags for repo 'aserto-dev/policy': tags not found")
+	assert.True(errx.ErrMissingScopes.SameAs(err))
+	assert.Equal([]string{"read:org"}, errx.MissingScopes(err))
 }
 
-func TestGithubInitialTagAndRepoHasTag(t *testing.T) {
+func TestGithubCreateRepoInOrgWithAdminOrg(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
@@ -803,155 +3107,333 @@ func TestGithubInitialTagAndRepoHasTag(t *testing.T) {
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
 	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	repoTag := &github.RepositoryTag{}
+	user := &github.User{Login: ptr.To("someone")}
+	resp := &github.Response{Response: &http.Response{StatusCode: 200, Header: http.Header{}}}
+	resp.Response.Header.Set("X-OAuth-Scopes", "repo, admin:org")
 
 	// Expect
-	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
-	tstInteraction.mockGithub.EXPECT().
-		ListRepoTags(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-		Return([]*github.RepositoryTag{repoTag}, nil)
+	tstInteraction.mockGithub.EXPECT().GetUsers(gomock.Any(), gomock.Any()).Return(user, resp, nil)
+	tstInteraction.mockGithub.EXPECT().CreateRepo(gomock.Any(), githubUsername, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, repo *github.Repository) (*github.Repository, error) {
+			return repo, nil
+		})
 
 	// Act
-	err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "build-workflow.yaml", "")
+	_, err := p.CreateRepo(context.Background(), token, githubUsername, policyRepo, nil)
 
 	// Assert
 	assert.NoError(err)
 }
 
-func TestGithubInitialTagAndGetRepoRefFails(t *testing.T) {
+func TestGithubInitialTagWithConfiguredTag(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
 	mockintrGh := tstInteraction.mockGithubIntrFunc
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
-	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0, WaitTagTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{DefaultTag: "2024.1.0"}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	defaultBr := defaultBranch
-	githubRepo := &github.Repository{DefaultBranch: &defaultBr}
-	resp := &github.Response{Response: &http.Response{StatusCode: 404}}
+	githubRepo := &github.Repository{}
+	var event github.CreateWorkflowDispatchEventRequest
 
 	// Expect
 	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(githubRepo, nil)
-	tstInteraction.mockGithub.EXPECT().
-		ListRepoTags(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-		Return(nil, nil)
-	tstInteraction.mockGithub.EXPECT().
-		GetRepoRef(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-		Return(nil, resp, errors.New("ref not found"))
+	tstInteraction.mockGithub.EXPECT().GetCommit(gomock.Any(), githubUsername, policyRepo, "somesha").Return(&github.Commit{SHA: ptr.To("somesha")}, nil)
+	tstInteraction.mockGraphql.EXPECT().Mutate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	tstInteraction.mockGithub.EXPECT().ListRepositoryWorkflowRuns(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).Times(2)
+	tstInteraction.mockGithub.EXPECT().CreateWorkflowDispatchEventByFileName(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _, _ string, e github.CreateWorkflowDispatchEventRequest) error {
+			event = e
+			return nil
+		})
+
+	// Act
+	_, err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "build-workflow.yaml", "somesha", "")
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("2024.1.0", event.Ref)
+}
+
+func TestGithubInitialTagAnnotated(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	githubRepo := &github.Repository{NodeID: ptr.To("repo-node")}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(githubRepo, nil)
+	tstInteraction.mockGithub.EXPECT().GetCommit(gomock.Any(), githubUsername, policyRepo, "somesha").Return(&github.Commit{SHA: ptr.To("somesha")}, nil)
+	tstInteraction.mockGithub.EXPECT().CreateRepoTag(gomock.Any(), githubUsername, policyRepo, &github.Tag{
+		Tag:     sources.DefaultTag(),
+		Message: ptr.To("first release"),
+		Object:  &github.GitObject{Type: ptr.To("commit"), SHA: ptr.To("somesha")},
+	}).Return(&github.Tag{SHA: ptr.To("tagsha")}, nil)
+	tstInteraction.mockGraphql.EXPECT().Mutate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ interface{}, input githubv4.Input, _ map[string]interface{}) error {
+			refInput := input.(githubv4.CreateRefInput)
+			assert.Equal(githubv4.String("refs/tags/"+*sources.DefaultTag()), refInput.Name)
+			assert.Equal(githubv4.GitObjectID("tagsha"), refInput.Oid)
+			return nil
+		})
+
+	// Act
+	_, err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "", "somesha", "first release")
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestGithubInitialTagCommitNotFound(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	emptyRepo := &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusConflict, Request: &http.Request{Method: http.MethodGet}},
+		Message:  "Git Repository is empty.",
+	}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(&github.Repository{}, nil)
+	tstInteraction.mockGithub.EXPECT().GetCommit(gomock.Any(), githubUsername, policyRepo, "somesha").Return(nil, emptyRepo)
 
 	// Act
-	err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "build-workflow.yaml", "")
+	_, err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "build-workflow.yaml", "somesha", "")
 
 	// Assert
 	assert.Error(err)
-	assert.Contains("E10034 timeout after multiple retries: repo seems to be empty; response code from github [404]: ref not found", err.Error())
+	assert.ErrorIs(err, sources.ErrCommitNotFound)
 }
 
-func TestGithubInitialTag(t *testing.T) {
+func TestGithubGetRepoWithCIPathSuffix(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
 	mockintrGh := tstInteraction.mockGithubIntrFunc
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
-	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0, WaitTagTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CIPathSuffix: "/ci"}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	defaultBr := defaultBranch
-	githubRepo := &github.Repository{DefaultBranch: &defaultBr}
-	resp := &github.Response{Response: &http.Response{StatusCode: 200}}
-	sha := "somesha"
-	obj := &github.GitObject{SHA: &sha}
-	ref := &github.Reference{Object: obj, Ref: &sha}
+	user := githubUsername
+	searchedRepo := policyRepo
+	URL := policyURL
+	githubRepo := &github.Repository{Name: &searchedRepo, Owner: &github.User{Login: &user}, HTMLURL: &URL}
 
 	// Expect
-	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(githubRepo, nil)
-	tstInteraction.mockGithub.EXPECT().
-		ListRepoTags(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-		Return(nil, nil)
-	tstInteraction.mockGithub.EXPECT().
-		GetRepoRef(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-		Return(ref, resp, nil)
+	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), githubUsername, policyRepo).Return(githubRepo, nil)
 
-	tstInteraction.mockGraphql.EXPECT().Mutate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-	tstInteraction.mockGithub.EXPECT().ListRepositoryWorkflowRuns(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
-	tstInteraction.mockGithub.EXPECT().CreateWorkflowDispatchEventByFileName(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	// Act
+	repo, err := p.GetRepo(context.Background(), token, githubUsername, policyRepo)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(policyURL+"/ci", repo.CiUrl)
+}
+
+func TestGithubCreateCommitOnBranchMultipleFiles(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	commit := &sources.Commit{
+		Branch:  defaultBranch,
+		Message: "update policy",
+		Owner:   githubUsername,
+		Repo:    policyRepo,
+		Content: map[string]string{".manifest": "unchanged", "policy.rego": "changed"},
+	}
+	sha := "newsha"
+	ghCommit := &github.Commit{SHA: &sha}
+
+	// Expect
+	gomock.InOrder(
+		tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, q interface{}, vars map[string]interface{}) error {
+				assert.EqualValues(defaultBranch+":.manifest", vars["expression"])
+				setQueryField(q, "head", "Repository", "Ref", "Target", "Oid")
+				setQueryField(q, "unchanged", "Repository", "Object", "Blob", "Text")
+				return nil
+			}),
+		tstInteraction.mockGraphql.EXPECT().Query(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, q interface{}, vars map[string]interface{}) error {
+				assert.EqualValues(defaultBranch+":policy.rego", vars["expression"])
+				setQueryField(q, "previous", "Repository", "Object", "Blob", "Text")
+				return nil
+			}),
+		tstInteraction.mockGraphql.EXPECT().Mutate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, m interface{}, _ interface{}, _ map[string]interface{}) error {
+				setQueryField(m, sha, "CreateCommitOnBranch", "Commit", "OID")
+				return nil
+			}),
+	)
+	tstInteraction.mockGithub.EXPECT().GetCommit(gomock.Any(), githubUsername, policyRepo, sha).Return(ghCommit, nil)
 
 	// Act
-	err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "build-workflow.yaml", "")
+	commitSha, err := p.CreateCommitOnBranch(context.Background(), token, commit)
 
 	// Assert
 	assert.NoError(err)
+	assert.Equal(sha, commitSha)
 }
 
-func TestGithubInitialTagRetriggerDoesNotWork(t *testing.T) {
+func TestGithubGetFileContent(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
 	mockintrGh := tstInteraction.mockGithubIntrFunc
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
-	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0, WaitTagTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	defaultBr := defaultBranch
-	githubRepo := &github.Repository{DefaultBranch: &defaultBr}
-	resp := &github.Response{Response: &http.Response{StatusCode: 200}}
-	sha := "somesh"
-	obj := &github.GitObject{SHA: &sha}
-	ref := &github.Reference{Object: obj, Ref: &sha}
+	fileContents := &github.RepositoryContent{Content: ptr.To(fileContent)}
 
 	// Expect
-	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(githubRepo, nil)
-	tstInteraction.mockGithub.EXPECT().
-		ListRepoTags(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-		Return(nil, nil)
 	tstInteraction.mockGithub.EXPECT().
-		GetRepoRef(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-		Return(ref, resp, nil)
+		GetFileContents(gomock.Any(), githubUsername, policyRepo, file, &github.RepositoryContentGetOptions{Ref: defaultBranch}).
+		Return(fileContents, nil)
 
-	tstInteraction.mockGraphql.EXPECT().Mutate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-	tstInteraction.mockGithub.EXPECT().ListRepositoryWorkflowRuns(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
-	tstInteraction.mockGithub.EXPECT().CreateWorkflowDispatchEventByFileName(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("boom"))
+	// Act
+	content, exists, err := p.GetFileContent(context.Background(), token, githubUsername, policyRepo, file, defaultBranch)
+
+	// Assert
+	assert.NoError(err)
+	assert.True(exists)
+	assert.Equal(fileContent, content)
+}
+
+func TestGithubGetFileContentMissing(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	notFound := &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusNotFound, Request: &http.Request{Method: http.MethodGet}},
+		Message:  "Not Found",
+	}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetFileContents(gomock.Any(), githubUsername, policyRepo, file, gomock.Any()).Return(nil, notFound)
+
+	// Act
+	content, exists, err := p.GetFileContent(context.Background(), token, githubUsername, policyRepo, file, "")
+
+	// Assert
+	assert.NoError(err)
+	assert.False(exists)
+	assert.Empty(content)
+}
+
+func TestGithubWaitForWorkflowRun(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{WaitTagTimeoutSeconds: 5}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	gomock.InOrder(
+		tstInteraction.mockGithub.EXPECT().GetWorkflowRunByID(gomock.Any(), githubUsername, policyRepo, int64(42)).
+			Return(&github.WorkflowRun{Status: ptr.To("in_progress")}, nil),
+		tstInteraction.mockGithub.EXPECT().GetWorkflowRunByID(gomock.Any(), githubUsername, policyRepo, int64(42)).
+			Return(&github.WorkflowRun{Status: ptr.To("completed"), Conclusion: ptr.To("success")}, nil),
+	)
+
+	// Act
+	conclusion, err := p.(sources.WorkflowRunWaiter).WaitForWorkflowRun(context.Background(), token, githubUsername, policyRepo, 42)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("success", conclusion)
+}
+
+func TestGithubWaitForWorkflowRunNotFoundIsNotRetried(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{WaitTagTimeoutSeconds: 5}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	notFound := &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusNotFound, Request: &http.Request{Method: http.MethodGet}},
+		Message:  "Not Found",
+	}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetWorkflowRunByID(gomock.Any(), githubUsername, policyRepo, int64(42)).
+		Return(nil, notFound).Times(1)
 
 	// Act
-	err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "build-workflow.yaml", "")
+	_, err := p.(sources.WorkflowRunWaiter).WaitForWorkflowRun(context.Background(), token, githubUsername, policyRepo, 42)
 
 	// Assert
 	assert.Error(err)
-	assert.Equal(err.Error(), "boom")
+	assert.False(errx.ErrRetryTimeout.SameAs(err))
+	assert.ErrorIs(err, notFound)
 }
 
-func TestGithubInitialTagWorkflowRunsInstantly(t *testing.T) {
+func TestGithubWaitForWorkflowRunRetriesServerErrors(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	tstInteraction := setup(t)
 	mockintrGh := tstInteraction.mockGithubIntrFunc
 	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
-	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{CreateRepoTimeoutSeconds: 0, WaitTagTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{WaitTagTimeoutSeconds: 5}, mockintrGh, mockintrGQL)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	defaultBr := defaultBranch
-	githubRepo := &github.Repository{DefaultBranch: &defaultBr}
-	resp := &github.Response{Response: &http.Response{StatusCode: 200}}
-	sha := "someshh"
-	obj := &github.GitObject{SHA: &sha}
-	ref := &github.Reference{Object: obj, Ref: &sha}
-	id := int64(345)
-	run := &github.WorkflowRun{ID: &id}
-	runs := &github.WorkflowRuns{
-		WorkflowRuns: []*github.WorkflowRun{run},
+	badGateway := &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusBadGateway, Request: &http.Request{Method: http.MethodGet}},
+		Message:  "Bad Gateway",
 	}
 
 	// Expect
-	tstInteraction.mockGithub.EXPECT().GetRepo(gomock.Any(), gomock.Any(), gomock.Any()).Return(githubRepo, nil)
-	tstInteraction.mockGithub.EXPECT().
-		ListRepoTags(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-		Return(nil, nil)
-	tstInteraction.mockGithub.EXPECT().
-		GetRepoRef(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-		Return(ref, resp, nil)
-	tstInteraction.mockGraphql.EXPECT().Mutate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-	tstInteraction.mockGithub.EXPECT().ListRepositoryWorkflowRuns(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(runs, nil)
+	gomock.InOrder(
+		tstInteraction.mockGithub.EXPECT().GetWorkflowRunByID(gomock.Any(), githubUsername, policyRepo, int64(42)).
+			Return(nil, badGateway),
+		tstInteraction.mockGithub.EXPECT().GetWorkflowRunByID(gomock.Any(), githubUsername, policyRepo, int64(42)).
+			Return(&github.WorkflowRun{Status: ptr.To("completed"), Conclusion: ptr.To("success")}, nil),
+	)
 
 	// Act
-	err := p.InitialTag(context.Background(), token, githubUsername+"/"+policyRepo, "build-workflow.yaml", "")
+	conclusion, err := p.(sources.WorkflowRunWaiter).WaitForWorkflowRun(context.Background(), token, githubUsername, policyRepo, 42)
 
 	// Assert
 	assert.NoError(err)
+	assert.Equal("success", conclusion)
+}
+
+func TestGithubWaitForWorkflowRunTimeout(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	tstInteraction := setup(t)
+	mockintrGh := tstInteraction.mockGithubIntrFunc
+	mockintrGQL := tstInteraction.mockGraphqlIntrFunc
+	p := sources.NewTestGithub(tstInteraction.ctrl, &zerolog.Logger{}, &sources.Config{WaitTagTimeoutSeconds: 0}, mockintrGh, mockintrGQL)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	tstInteraction.mockGithub.EXPECT().GetWorkflowRunByID(gomock.Any(), githubUsername, policyRepo, int64(42)).
+		Return(&github.WorkflowRun{Status: ptr.To("queued")}, nil)
+
+	// Act
+	conclusion, err := p.(sources.WorkflowRunWaiter).WaitForWorkflowRun(context.Background(), token, githubUsername, policyRepo, 42)
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrRetryTimeout.SameAs(err))
+	assert.ErrorIs(err, sources.ErrWorkflowRunNotComplete)
+	assert.Empty(conclusion)
 }
@@ -2,23 +2,37 @@ package sources
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aserto-dev/go-grpc/aserto/api/v1"
 	scc "github.com/aserto-dev/go-grpc/aserto/tenant/scc/v1"
 	"github.com/aserto-dev/scc-lib/errx"
 	"github.com/aserto-dev/scc-lib/internal/interactions"
+	"github.com/aserto-dev/scc-lib/retry"
 	"github.com/friendsofgo/errors"
 	"github.com/rs/zerolog"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
+	"k8s.io/utils/ptr"
 )
 
 var (
-	_        Source = &gitlabSource{}
-	gitlabCI        = "/-/pipelines"
+	_        Source                   = &gitlabSource{}
+	_        BuildTrigger             = &gitlabSource{}
+	_        WebhookSource            = &gitlabSource{}
+	_        DeployKeySource          = &gitlabSource{}
+	_        BranchSource             = &gitlabSource{}
+	_        CloneURLSource           = &gitlabSource{}
+	_        PullRequestSource        = &gitlabSource{}
+	_        MergeRequestCommitSource = &gitlabSource{}
+	_        OrgSource                = &gitlabSource{}
+	_        BulkSecretSource         = &gitlabSource{}
+	gitlabCI                          = "/-/pipelines"
 )
 
 // gitlabSource deals with source management on gitlab.com.
@@ -26,10 +40,39 @@ type gitlabSource struct {
 	logger           *zerolog.Logger
 	cfg              *Config
 	interactionsFunc interactions.GlIntr
+	// clients are the HTTP clients the source sends its requests through.
+	clients *httpClients
 }
 
-func (g *gitlabSource) ValidateConnection(ctx context.Context, accessToken *AccessToken, requiredScopes []string) error {
-	client, err := g.interactionsFunc(accessToken.Token)
+// Ping checks that the Gitlab API answers.
+func (g *gitlabSource) Ping(ctx context.Context) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "Ping", "", "")(&err)
+
+	client, err := g.interactionsFunc("", "", g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+	if err != nil {
+		return errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	response, err := client.GetVersion()
+
+	statusCode := 0
+	if response != nil && response.Response != nil {
+		statusCode = response.StatusCode
+	}
+
+	return pingError("gitlab", statusCode, err)
+}
+
+// Close closes the idle connections of the HTTP clients the source sends its requests through.
+func (g *gitlabSource) Close() error {
+	g.clients.closeIdleConnections()
+	return nil
+}
+
+func (g *gitlabSource) ValidateConnection(ctx context.Context, accessToken *AccessToken, requiredScopes []string) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "ValidateConnection", "", "")(&err)
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
 	if err != nil {
 		return errors.Wrap(err, "failed to create Gitlab client")
 	}
@@ -39,55 +82,230 @@ func (g *gitlabSource) ValidateConnection(ctx context.Context, accessToken *Acce
 		return errors.Wrapf(err, "failed to connect to Gitlab")
 	}
 
+	if response == nil || response.Response == nil {
+		return errx.ErrProviderVerification.
+			FromReader("gitlab-response", responseBody(nil)).
+			Msg("no reply from Gitlab")
+	}
+
 	if response.StatusCode != http.StatusOK {
 		return errx.ErrProviderVerification.
 			Str("status", response.Status).
 			Int("status-code", response.StatusCode).
-			FromReader("gitlab-response", response.Body).
+			FromReader("gitlab-response", responseBody(response.Response)).
 			Msg("unexpected reply from Gitlab")
 	}
 
+	return g.validateScopes(client, accessToken, requiredScopes)
+}
+
+// validateScopes checks that the token has the required scopes. Gitlab only reports the scopes of personal,
+// project and group access tokens; OAuth tokens get the scopes of their application and aren't checked.
+func (g *gitlabSource) validateScopes(client interactions.GitlabIntr, accessToken *AccessToken, requiredScopes []string) error {
+	if len(requiredScopes) == 0 || isGitlabOAuthToken(accessToken) {
+		return nil
+	}
+
+	token, response, err := client.GetCurrentAccessToken()
+	if err != nil {
+		return errors.Wrap(gitlabStatusError(err, response), "failed to get the scopes of the Gitlab token")
+	}
+
+	missing, err := missingScopes(token.Scopes, requiredScopes)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return errx.ErrMissingScopes.
+			Err(&errx.MissingScopesError{Scopes: missing}).
+			Interface("provided-scopes", token.Scopes).
+			Interface("required-scopes", requiredScopes).
+			Msg("gitlab access token is missing scopes")
+	}
+
 	return nil
 }
 
-func (g *gitlabSource) Profile(ctx context.Context, accessToken *AccessToken) (string, []*scc.Repo, error) {
+func isGitlabOAuthToken(accessToken *AccessToken) bool {
+	return strings.EqualFold(accessToken.Type, "oauth") || strings.EqualFold(accessToken.Type, "bearer")
+}
+
+// Preflight checks that the token is valid, has the required scopes, and can create projects for the owner.
+// The scopes check is skipped for OAuth tokens and when no scopes are required, and the visibility check for groups.
+func (g *gitlabSource) Preflight(ctx context.Context, accessToken *AccessToken, owner string, requiredScopes []string) (_ *PreflightResult, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "Preflight", owner, "")(&err)
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	result := &PreflightResult{}
+
+	user, response, err := client.CurrentUser()
+	if err != nil {
+		result.Connection = checkFromError(errors.Wrap(err, "failed to connect to Gitlab"))
+		return result, nil
+	}
+
+	if response == nil || response.Response == nil {
+		result.Connection = checkFromError(errx.ErrProviderVerification.Msg("no reply from Gitlab"))
+		return result, nil
+	}
+
+	if response.StatusCode != http.StatusOK {
+		result.Connection = checkFromError(errx.ErrProviderVerification.
+			Str("status", response.Status).
+			Int("status-code", response.StatusCode).
+			Msg("unexpected reply from Gitlab"))
+		return result, nil
+	}
+
+	result.Connection = checkFromError(nil)
+	if len(requiredScopes) > 0 && !isGitlabOAuthToken(accessToken) {
+		result.Scopes = checkFromError(g.validateScopes(client, accessToken, requiredScopes))
+	}
+
+	if owner == user.Username {
+		result.CreateRepo = checkFromError(nil)
+		if !user.CanCreateProject {
+			result.CreateRepo = checkFromError(errx.ErrProviderVerification.Str("user", owner).Msg("user is not allowed to create projects"))
+		}
+		result.Visibility = checkFromError(nil)
+		return result, nil
+	}
+
+	group, err := client.GetGroup(owner)
+	if err != nil {
+		result.CreateRepo = checkFromError(errors.Wrapf(err, "failed to get group '%s'", owner))
+		return result, nil
+	}
+
+	member, err := client.GetInheritedGroupMember(owner, user.ID)
+	if err != nil {
+		result.CreateRepo = checkFromError(errors.Wrapf(err, "failed to get membership of group '%s'", owner))
+		return result, nil
+	}
+
+	result.CreateRepo = checkFromError(nil)
+	if !canCreateProject(group.ProjectCreationLevel, member.AccessLevel) {
+		result.CreateRepo = checkFromError(errx.ErrProviderVerification.Str("group", owner).Msg("user is not allowed to create projects in group"))
+	}
+
+	// A group only holds projects as visible as itself or less, so whether it can hold the project depends on the
+	// CreateRepoOptions.Private passed to CreateRepo, which Preflight doesn't know. The visibility is left unchecked.
+	return result, nil
+}
+
+func canCreateProject(level gitlab.ProjectCreationLevelValue, access gitlab.AccessLevelValue) bool {
+	switch level {
+	case gitlab.NoOneProjectCreation:
+		return false
+	case gitlab.OwnerProjectCreation:
+		return access >= gitlab.OwnerPermissions
+	case gitlab.DeveloperProjectCreation:
+		return access >= gitlab.DeveloperPermissions
+	case gitlab.MaintainerProjectCreation:
+		return access >= gitlab.MaintainerPermissions
+	default:
+		return access >= gitlab.MaintainerPermissions
+	}
+}
+
+func (g *gitlabSource) Profile(ctx context.Context, accessToken *AccessToken) (_ string, _ []*scc.Repo, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "Profile", "", "")(&err)
+
+	username, repos, _, err := g.profilePage(ctx, accessToken, &api.PaginationRequest{Size: -1})
+	return username, repos, err
+}
+
+// ProfilePage returns the username of the user that owns the token, and a page of the projects it can push to:
+// the projects of its personal namespace and the group projects where it is at least a maintainer.
+// A page size of -1 reads all the pages.
+func (g *gitlabSource) ProfilePage(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest) (_ string, _ []*scc.Repo, _ *api.PaginationResponse, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "ProfilePage", "", "")(&err)
+
+	return g.profilePage(ctx, accessToken, page)
+}
+
+// profilePage reads the profile page for Profile and ProfilePage, which each track the operation themselves.
+func (g *gitlabSource) profilePage(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest) (_ string, _ []*scc.Repo, _ *api.PaginationResponse, err error) {
+	if page == nil {
+		return "", nil, nil, errors.New("page must not be empty")
+	}
+	page = g.cfg.pageRequest(page)
+	if page.Size < -1 || page.Size > 100 {
+		return "", nil, nil, errors.New("page size must be >= -1 and <= 100")
+	}
+
 	repos := []*scc.Repo{}
-	client, err := g.interactionsFunc(accessToken.Token)
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
 
 	if err != nil {
-		return "", repos, errors.Wrap(err, "failed to create Gitlab client")
+		return "", repos, nil, errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	pageToRead := 0
+	if strings.TrimSpace(page.Token) != "" {
+		pageToRead, err = strconv.Atoi(page.Token)
+		if err != nil {
+			return "", repos, nil, errors.Wrap(err, "page token must be int")
+		}
 	}
 
-	user, _, err := client.CurrentUser()
+	user, err := client.CachedCurrentUser()
 	if err != nil {
-		return "", repos, err
+		return "", repos, nil, err
 	}
 
 	username := user.Username
 
 	opt := &gitlab.ListProjectsOptions{
-		ListOptions: gitlab.ListOptions{},
+		ListOptions:    gitlab.ListOptions{Page: pageToRead, PerPage: int(page.Size)},
+		Membership:     ptr.To(true),
+		MinAccessLevel: ptr.To(gitlab.MaintainerPermissions),
+	}
+
+	if page.Size == -1 {
+		opt.ListOptions.PerPage = 100
 	}
 
+	seen := map[int]bool{}
+
 	for {
-		projects, resp, err := client.ListUserProjects(username, opt)
+		if err := ctx.Err(); err != nil {
+			return "", repos, nil, err
+		}
+
+		projects, resp, err := client.ListProjects(opt)
 		if err != nil {
-			return "", repos, err
+			return "", repos, nil, err
 		}
 
 		for _, proj := range projects {
-			// Only add the projects that are owned by the current user.go tool cover -html=coverage.out -o coverage.html
-			if proj.Owner == nil || proj.Owner.Username != username {
+			// Projects can move between pages while they are read.
+			if seen[proj.ID] {
 				continue
 			}
+			seen[proj.ID] = true
+
 			repos = append(repos, &scc.Repo{
 				Name:  proj.Name,
-				Org:   proj.Owner.Username,
+				Org:   gitlabProjectOrg(proj, username),
 				Url:   proj.WebURL,
-				CiUrl: proj.WebURL + gitlabCI,
+				CiUrl: g.cfg.ciURL(proj.WebURL, gitlabCI),
 			})
 		}
 
+		if page.Size != -1 {
+			response := &api.PaginationResponse{
+				NextToken:  fmt.Sprintf("%d", resp.NextPage),
+				ResultSize: safeInt32(len(repos)),
+				TotalSize:  safeInt32(resp.TotalItems),
+			}
+			return username, repos, response, nil
+		}
+
 		if resp.NextPage == 0 {
 			break
 		}
@@ -95,19 +313,27 @@ func (g *gitlabSource) Profile(ctx context.Context, accessToken *AccessToken) (s
 		opt.Page = resp.NextPage
 	}
 
-	return username, repos, nil
+	response := &api.PaginationResponse{
+		NextToken:  "",
+		ResultSize: safeInt32(len(repos)),
+		TotalSize:  safeInt32(len(repos)),
+	}
+	return username, repos, response, nil
 }
 
-func (g *gitlabSource) ListOrgs(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest) ([]*api.SccOrg, *api.PaginationResponse, error) {
+func (g *gitlabSource) ListOrgs(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest, opts *ListOrgsOptions) (_ []*api.SccOrg, _ *api.PaginationResponse, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "ListOrgs", "", "")(&err)
+
 	if page == nil {
 		return nil, nil, errors.New("page must not be empty")
 	}
+	page = g.cfg.pageRequest(page)
 	if page.Size < -1 || page.Size > 100 {
 		return nil, nil, errors.New("page size must be >= -1 and <= 100")
 	}
 
 	var orgs []*api.SccOrg
-	client, err := g.interactionsFunc(accessToken.Token)
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
 
 	if err != nil {
 		return orgs, nil, errors.Wrap(err, "failed to create Gitlab client")
@@ -123,6 +349,13 @@ func (g *gitlabSource) ListOrgs(ctx context.Context, accessToken *AccessToken, p
 
 	accessLevel := gitlab.DeveloperPermissions
 	top := false
+	if opts != nil {
+		if opts.MinAccessLevel != 0 {
+			accessLevel = gitlab.AccessLevelValue(opts.MinAccessLevel)
+		}
+		top = opts.TopLevelOnly
+	}
+
 	opt := &gitlab.ListGroupsOptions{
 		ListOptions:    gitlab.ListOptions{Page: pageToRead, PerPage: int(page.Size)},
 		TopLevelOnly:   &top,
@@ -148,14 +381,82 @@ func (g *gitlabSource) ListOrgs(ctx context.Context, accessToken *AccessToken, p
 			orgs = append(orgs, org)
 		}
 
-		response := &api.PaginationResponse{
-			NextToken:  fmt.Sprintf("%d", resp.NextPage),
-			ResultSize: int32(len(orgs)),       // nolint: gosec
-			TotalSize:  int32(resp.TotalItems), // nolint: gosec
+		if page.Size != -1 {
+			return orgs, &api.PaginationResponse{
+				NextToken:  fmt.Sprintf("%d", resp.NextPage),
+				ResultSize: safeInt32(len(orgs)),
+				TotalSize:  safeInt32(resp.TotalItems),
+			}, nil
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.ListOptions.Page = resp.NextPage
+	}
+
+	// The full list is the total, whatever the provider reported while paging.
+	response := &api.PaginationResponse{
+		NextToken:  "",
+		ResultSize: safeInt32(len(orgs)),
+		TotalSize:  safeInt32(len(orgs)),
+	}
+	return orgs, response, nil
+}
+
+func (g *gitlabSource) ListOrgMembers(ctx context.Context, accessToken *AccessToken, org string, page *api.PaginationRequest) (_ []*SccUser, _ *api.PaginationResponse, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "ListOrgMembers", org, "")(&err)
+
+	if page == nil {
+		return nil, nil, errors.New("page must not be empty")
+	}
+	page = g.cfg.pageRequest(page)
+	if page.Size < -1 || page.Size > 100 {
+		return nil, nil, errors.New("page size must be >= -1 and <= 100")
+	}
+
+	var members []*SccUser
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+	if err != nil {
+		return members, nil, errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	pageToRead := 0
+	if strings.TrimSpace(page.Token) != "" {
+		pageToRead, err = strconv.Atoi(page.Token)
+		if err != nil {
+			return members, nil, errors.Wrap(err, "page token must be int")
+		}
+	}
+
+	opt := &gitlab.ListGroupMembersOptions{
+		ListOptions: gitlab.ListOptions{Page: pageToRead, PerPage: int(page.Size)},
+	}
+
+	if page.Size == -1 {
+		opt.ListOptions.PerPage = 100
+	}
+
+	for {
+		groupMembers, resp, err := client.ListGroupMembers(org, opt)
+		if err != nil {
+			return members, nil, errors.Wrapf(gitlabStatusError(err, resp), "failed to list members of group '%s'", org)
+		}
+
+		for _, member := range groupMembers {
+			members = append(members, &SccUser{
+				Login: member.Username,
+				Name:  member.Name,
+				Email: member.Email,
+			})
 		}
 
 		if page.Size != -1 {
-			return orgs, response, nil
+			return members, &api.PaginationResponse{
+				NextToken:  fmt.Sprintf("%d", resp.NextPage),
+				ResultSize: safeInt32(len(members)),
+				TotalSize:  safeInt32(resp.TotalItems),
+			}, nil
 		}
 		if resp.NextPage == 0 {
 			break
@@ -166,21 +467,52 @@ func (g *gitlabSource) ListOrgs(ctx context.Context, accessToken *AccessToken, p
 
 	response := &api.PaginationResponse{
 		NextToken:  "",
-		ResultSize: int32(len(orgs)), // nolint: gosec
-		TotalSize:  int32(len(orgs)), // nolint: gosec
+		ResultSize: safeInt32(len(members)),
+		TotalSize:  safeInt32(len(members)),
 	}
-	return orgs, response, nil
+	return members, response, nil
+}
+
+func (g *gitlabSource) GetOrg(ctx context.Context, accessToken *AccessToken, org string) (_ *SccOrgDetail, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "GetOrg", org, "")(&err)
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	group, err := client.GetGroup(org)
+	if err != nil {
+		return nil, errors.Wrapf(gitlabStatusError(err, gitlabErrorResponse(err)), "failed to get group %s", org)
+	}
+
+	return &SccOrgDetail{
+		ID:          group.FullPath,
+		Name:        group.Name,
+		Description: group.Description,
+		AvatarURL:   group.AvatarURL,
+		URL:         group.WebURL,
+	}, nil
 }
 
-func (g *gitlabSource) ListRepos(ctx context.Context, accessToken *AccessToken, org string, page *api.PaginationRequest) ([]*scc.Repo, *api.PaginationResponse, error) {
+func (g *gitlabSource) ListRepos(
+	ctx context.Context,
+	accessToken *AccessToken,
+	org string,
+	page *api.PaginationRequest,
+	opts *ListReposOptions,
+) (_ []*scc.Repo, _ *api.PaginationResponse, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "ListRepos", org, "")(&err)
+
 	if page == nil {
 		return nil, nil, errors.New("page must not be empty")
 	}
+	page = g.cfg.pageRequest(page)
 	if page.Size < -1 || page.Size > 100 {
 		return nil, nil, errors.New("page size must be >= -1 and <= 100")
 	}
 	repos := []*scc.Repo{}
-	client, err := g.interactionsFunc(accessToken.Token)
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
 
 	if err != nil {
 		return repos, nil, errors.Wrap(err, "failed to create Gitlab client")
@@ -195,7 +527,7 @@ func (g *gitlabSource) ListRepos(ctx context.Context, accessToken *AccessToken,
 		}
 	}
 
-	user, _, err := client.CurrentUser()
+	user, err := client.CachedCurrentUser()
 	if err != nil {
 		return repos, nil, err
 	}
@@ -208,77 +540,204 @@ func (g *gitlabSource) ListRepos(ctx context.Context, accessToken *AccessToken,
 		listOpt.PerPage = 100
 	}
 
+	var search *string
+	if opts != nil && strings.TrimSpace(opts.Filter) != "" {
+		filter := strings.TrimSpace(opts.Filter)
+		search = &filter
+	}
+
+	includeSubgroups := true
+	if opts != nil && opts.IncludeSubgroups != nil {
+		includeSubgroups = *opts.IncludeSubgroups
+	}
+
 	if org == user.Username {
-		opt := &gitlab.ListProjectsOptions{ListOptions: listOpt}
+		opt := gitlab.ListProjectsOptions{ListOptions: listOpt, Search: search}
 		return g.listPagedRepos(
-			org, pageSize,
-			func() ([]*gitlab.Project, *gitlab.Response, error) {
-				return client.ListUserProjects(org, opt)
-			}, &listOpt)
+			org, pageSize, pageToRead,
+			func(page int) ([]*gitlab.Project, *gitlab.Response, error) {
+				pageOpt := opt
+				pageOpt.Page = page
+				return client.ListUserProjects(org, &pageOpt)
+			})
 	}
-	opt := &gitlab.ListGroupProjectsOptions{ListOptions: listOpt}
+	opt := gitlab.ListGroupProjectsOptions{ListOptions: listOpt, Search: search, IncludeSubGroups: &includeSubgroups}
 	return g.listPagedRepos(
-		org, pageSize, func() ([]*gitlab.Project, *gitlab.Response, error) {
-			return client.ListGroupProjects(org, opt)
-		}, &listOpt)
+		org, pageSize, pageToRead,
+		func(page int) ([]*gitlab.Project, *gitlab.Response, error) {
+			pageOpt := opt
+			pageOpt.Page = page
+			return client.ListGroupProjects(org, &pageOpt)
+		})
 }
 
+// listPagedRepos lists a page of repos with listPage, or all of them from firstPage on when pageSize is -1.
+// The pages after the first are fetched by up to Config.ListConcurrency workers when Gitlab reports the total
+// number of pages, and one after the other otherwise.
 func (g *gitlabSource) listPagedRepos(
 	user string,
-	pageSize int,
-	lpFunc func() ([]*gitlab.Project, *gitlab.Response, error),
-	opt *gitlab.ListOptions,
+	pageSize, firstPage int,
+	listPage func(page int) ([]*gitlab.Project, *gitlab.Response, error),
 ) ([]*scc.Repo, *api.PaginationResponse, error) {
-	repos := []*scc.Repo{}
-
-	for {
-		projects, resp, err := lpFunc()
-		if err != nil {
-			return repos, nil, err
-		}
+	projects, resp, err := listPage(firstPage)
+	if err != nil {
+		return []*scc.Repo{}, nil, err
+	}
 
-		for _, proj := range projects {
-			repos = append(repos, &scc.Repo{
-				Name:  proj.Name,
-				Org:   user,
-				Url:   proj.WebURL,
-				CiUrl: proj.WebURL + gitlabCI,
-			})
-		}
+	repos := g.gitlabRepos(projects, user)
 
+	if pageSize != -1 {
 		response := &api.PaginationResponse{
 			NextToken:  fmt.Sprintf("%d", resp.NextPage),
-			ResultSize: int32(len(repos)),      // nolint: gosec
-			TotalSize:  int32(resp.TotalItems), // nolint: gosec
+			ResultSize: safeInt32(len(repos)),
+			TotalSize:  safeInt32(resp.TotalItems),
 		}
+		return repos, response, nil
+	}
 
-		if pageSize != -1 {
-			return repos, response, nil
-		}
-		if resp.NextPage == 0 {
-			break
+	if resp.NextPage != 0 && resp.TotalPages >= resp.NextPage && g.cfg.ListConcurrency > 1 {
+		pageRepos, err := g.listPagesConcurrently(user, resp.NextPage, resp.TotalPages, listPage)
+		repos = append(repos, pageRepos...)
+		if err != nil {
+			return repos, nil, err
 		}
+	} else {
+		for resp.NextPage != 0 {
+			projects, resp, err = listPage(resp.NextPage)
+			if err != nil {
+				return repos, nil, err
+			}
 
-		opt.Page = resp.NextPage
+			repos = append(repos, g.gitlabRepos(projects, user)...)
+		}
 	}
 
 	response := &api.PaginationResponse{
 		NextToken:  "",
-		ResultSize: int32(len(repos)), // nolint: gosec
-		TotalSize:  int32(len(repos)), // nolint: gosec
+		ResultSize: safeInt32(len(repos)),
+		TotalSize:  safeInt32(len(repos)),
 	}
 	return repos, response, nil
 }
 
-func (g *gitlabSource) GetRepo(ctx context.Context, accessToken *AccessToken, owner, repo string) (*scc.Repo, error) {
+// listPagesConcurrently lists the pages from firstPage to lastPage with up to Config.ListConcurrency workers.
+// The repos keep the order of the pages. On failure, it returns the repos of the pages before the first one that failed.
+func (g *gitlabSource) listPagesConcurrently(
+	user string,
+	firstPage, lastPage int,
+	listPage func(page int) ([]*gitlab.Project, *gitlab.Response, error),
+) ([]*scc.Repo, error) {
+	pages := make([][]*scc.Repo, lastPage-firstPage+1)
+	errs := make([]error, len(pages))
+	workers := make(chan struct{}, g.cfg.ListConcurrency)
+
+	var wg sync.WaitGroup
+	for i := range pages {
+		wg.Add(1)
+		workers <- struct{}{}
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			projects, _, err := listPage(firstPage + i)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+
+			pages[i] = g.gitlabRepos(projects, user)
+		}()
+	}
+	wg.Wait()
+
+	repos := []*scc.Repo{}
+	for i, pageRepos := range pages {
+		if errs[i] != nil {
+			return repos, errs[i]
+		}
+
+		repos = append(repos, pageRepos...)
+	}
+
+	return repos, nil
+}
+
+// gitlabRepos converts the projects of a page to repos.
+func (g *gitlabSource) gitlabRepos(projects []*gitlab.Project, user string) []*scc.Repo {
+	repos := make([]*scc.Repo, 0, len(projects))
+	for _, proj := range projects {
+		repos = append(repos, &scc.Repo{
+			Name:  proj.Name,
+			Org:   gitlabProjectOrg(proj, user),
+			Url:   proj.WebURL,
+			CiUrl: g.cfg.ciURL(proj.WebURL, gitlabCI),
+		})
+	}
+
+	return repos
+}
+
+func (g *gitlabSource) GetRepo(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ *scc.Repo, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "GetRepo", owner, repo)(&err)
 
 	resultRepo, _, err := g.getSccRepoWithGitlabProj(accessToken, owner, repo)
 
 	return resultRepo, err
 }
 
+func (g *gitlabSource) RenameRepo(ctx context.Context, accessToken *AccessToken, owner, repo, newName string) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "RenameRepo", owner, repo)(&err)
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+	if err != nil {
+		return errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	// The path is what the URL of the project is made of, rename it along with the display name.
+	_, resp, err := client.EditProject(owner+"/"+repo, &gitlab.EditProjectOptions{
+		Name: &newName,
+		Path: &newName,
+	})
+	if err != nil {
+		statusCode := 0
+		if resp != nil && resp.Response != nil {
+			statusCode = resp.StatusCode
+		}
+		return errors.Wrapf(renameRepoError(err, statusCode, owner, newName), "failed to rename repo %s/%s", owner, repo)
+	}
+
+	return nil
+}
+
+func (g *gitlabSource) GetRepoID(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "GetRepoID", owner, repo)(&err)
+
+	_, proj, err := g.getSccRepoWithGitlabProj(accessToken, owner, repo)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(proj.ID), nil
+}
+
+func (g *gitlabSource) GetCloneURLs(ctx context.Context, accessToken *AccessToken, owner, repo string) (_, _ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "GetCloneURLs", owner, repo)(&err)
+
+	_, proj, err := g.getSccRepoWithGitlabProj(accessToken, owner, repo)
+	if err != nil {
+		return "", "", err
+	}
+
+	if proj.HTTPURLToRepo == "" && proj.SSHURLToRepo == "" {
+		return "", "", errors.Errorf("gitlab returned project '%s/%s' without clone URLs", owner, repo)
+	}
+
+	return proj.HTTPURLToRepo, proj.SSHURLToRepo, nil
+}
+
 func (g *gitlabSource) getSccRepoWithGitlabProj(accessToken *AccessToken, owner, repo string) (*scc.Repo, *gitlab.Project, error) {
-	client, err := g.interactionsFunc(accessToken.Token)
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
 
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "failed to create Gitlab client")
@@ -288,33 +747,35 @@ func (g *gitlabSource) getSccRepoWithGitlabProj(accessToken *AccessToken, owner,
 
 	repoName := owner + "/" + repo
 
-	proj, _, err := client.GetProject(repoName)
+	proj, resp, err := client.GetProject(repoName)
 	if err != nil {
-		return resultRepo, nil, errors.Wrapf(err, "failed to get project: %s", repoName)
+		return resultRepo, nil, errors.Wrapf(gitlabStatusError(err, resp), "failed to get project: %s", repoName)
 	}
 
 	resultRepo = &scc.Repo{
 		Name:  proj.Name,
 		Org:   owner,
 		Url:   proj.WebURL,
-		CiUrl: proj.WebURL + gitlabCI,
+		CiUrl: g.cfg.ciURL(proj.WebURL, gitlabCI),
 	}
 
 	return resultRepo, proj, nil
 }
 
-func (g *gitlabSource) CreateRepo(ctx context.Context, accessToken *AccessToken, owner, name string) error {
-	client, err := g.interactionsFunc(accessToken.Token)
+func (g *gitlabSource) CreateRepo(ctx context.Context, accessToken *AccessToken, owner, name string, opts *CreateRepoOptions) (_ *scc.Repo, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "CreateRepo", owner, name)(&err)
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
 
 	if err != nil {
-		return errors.Wrap(err, "failed to create Gitlab client")
+		return nil, errors.Wrap(err, "failed to create Gitlab client")
 	}
 
 	visibility := gitlab.PublicVisibility
 
-	namespace, err := client.GetNamespace(owner)
+	namespace, resp, err := client.GetNamespace(owner)
 	if err != nil {
-		return err
+		return nil, gitlabStatusError(err, resp)
 	}
 
 	opt := &gitlab.CreateProjectOptions{
@@ -323,34 +784,74 @@ func (g *gitlabSource) CreateRepo(ctx context.Context, accessToken *AccessToken,
 		Name:        &name,
 	}
 
-	proj, err := client.CreateProject(opt)
-
-	if err != nil {
-		return err
+	if opts != nil {
+		if opts.Private {
+			visibility = gitlab.PrivateVisibility
+		}
+		if opts.Description != "" {
+			opt.Description = &opts.Description
+		}
+		opt.InitializeWithReadme = opts.AutoInit
 	}
 
-	permission := gitlab.MaintainerPermissions
+	proj, resp, err := client.CreateProject(opt)
 
-	protectedTags := "v*"
-	protectedTagOpt := &gitlab.ProtectRepositoryTagsOptions{
-		Name:              &protectedTags,
-		CreateAccessLevel: &permission,
+	if err != nil {
+		return nil, gitlabStatusError(err, resp)
 	}
 
-	err = client.ProtectRepositoryTags(proj.ID, protectedTagOpt)
+	if protectedTagOpt := gitlabProtectedTagOptions(opts); protectedTagOpt != nil {
+		err = client.ProtectRepositoryTags(proj.ID, protectedTagOpt)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	return err
+	return &scc.Repo{
+		Name:  proj.Name,
+		Org:   owner,
+		Url:   proj.WebURL,
+		CiUrl: g.cfg.ciURL(proj.WebURL, gitlabCI),
+	}, nil
 }
 
-func (g *gitlabSource) InitialTag(ctx context.Context, accessToken *AccessToken, fullName, workflowFileName, commitSha string) error {
-	client, err := g.interactionsFunc(accessToken.Token)
+// gitlabProtectedTagOptions returns the tag protection of a new project, nil when it is disabled.
+func gitlabProtectedTagOptions(opts *CreateRepoOptions) *gitlab.ProtectRepositoryTagsOptions {
+	protectedTags := "v*"
+	permission := gitlab.MaintainerPermissions
+
+	if opts != nil && opts.TagProtection != nil {
+		if opts.TagProtection.Disabled {
+			return nil
+		}
+		if opts.TagProtection.Pattern != "" {
+			protectedTags = opts.TagProtection.Pattern
+		}
+		if opts.TagProtection.CreateAccessLevel != nil {
+			permission = gitlab.AccessLevelValue(*opts.TagProtection.CreateAccessLevel)
+		}
+	}
+
+	return &gitlab.ProtectRepositoryTagsOptions{
+		Name:              &protectedTags,
+		CreateAccessLevel: &permission,
+	}
+}
+
+// InitialTag creates a tag for a repo, if it has no tags yet, and returns the ID of the pipeline the tag
+// triggered. When none was and GitlabTriggerTagPipeline is set, it creates a pipeline for the tag instead.
+// The ID is 0 when the repo was already tagged or no pipeline was found.
+func (g *gitlabSource) InitialTag(ctx context.Context, accessToken *AccessToken, fullName, workflowFileName, commitSha, tagMessage string) (_ int64, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "InitialTag", "", fullName)(&err)
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
 
 	if err != nil {
-		return errors.Wrap(err, "failed to create Gitlab client")
+		return 0, errors.Wrap(err, "failed to create Gitlab client")
 	}
 
 	if strings.Count(fullName, "/") == 0 {
-		return errors.Errorf("invalid full gitlab repo name '%s', should be in the form owner/repo", fullName)
+		return 0, errors.Errorf("invalid full gitlab repo name '%s', should be in the form owner/repo", fullName)
 	}
 
 	owner := fullName[:strings.LastIndex(fullName, "/")]
@@ -359,64 +860,465 @@ func (g *gitlabSource) InitialTag(ctx context.Context, accessToken *AccessToken,
 	_, proj, err := g.getSccRepoWithGitlabProj(accessToken, owner, name)
 
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if len(proj.TagList) > 0 {
-		return nil
+		return 0, nil
+	}
+
+	if proj.EmptyRepo {
+		return 0, errors.Wrapf(ErrEmptyRepo, "%s", fullName)
 	}
 
 	if commitSha == "" {
 		commitSha = proj.DefaultBranch
 	}
 
+	tag := g.cfg.initialTag()
 	opt := &gitlab.CreateTagOptions{
 		Ref:     &commitSha,
-		TagName: &defaultTag,
-		Message: &defaultTag,
+		TagName: &tag,
+		Message: ptr.To(tagMessageOrName(tag, tagMessage)),
 	}
 
 	err = client.CreateTag(proj.ID, opt)
+	if err != nil {
+		return 0, err
+	}
 
-	return err
+	pipelineID := g.tagPipeline(ctx, client, proj.ID, tag)
+	if pipelineID != 0 || !g.cfg.GitlabTriggerTagPipeline {
+		return pipelineID, nil
+	}
+
+	g.logger.Warn().Msgf("no pipeline was triggered for tag [%s], creating one", tag)
+	pipeline, resp, err := client.CreatePipeline(proj.ID, &gitlab.CreatePipelineOptions{Ref: &tag})
+	if err != nil {
+		return 0, errors.Wrapf(gitlabStatusError(err, resp), "failed to create pipeline for tag %s", tag)
+	}
+
+	return int64(pipeline.ID), nil
 }
 
-func (g *gitlabSource) hasSecret(client interactions.GitlabIntr, orgName, repoName, secretName string) (bool, error) {
-	variable, resp, err := client.GetProjectVariable(orgName+"/"+repoName, secretName)
+// TriggerBuild runs the pipeline of the project on ref. Gitlab pipelines aren't split in workflow files, so
+// workflowFileName is ignored.
+func (g *gitlabSource) TriggerBuild(ctx context.Context, accessToken *AccessToken, owner, repo, ref, workflowFileName string) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "TriggerBuild", owner, repo)(&err)
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+	if err != nil {
+		return errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	_, resp, err := client.CreatePipeline(owner+"/"+repo, &gitlab.CreatePipelineOptions{Ref: &ref})
 	if err != nil {
-		if resp != nil && resp.StatusCode == 404 {
+		return errors.Wrapf(gitlabStatusError(err, resp), "failed to create pipeline for %s/%s", owner, repo)
+	}
+
+	return nil
+}
+
+func (g *gitlabSource) CreateWebhook(ctx context.Context, accessToken *AccessToken, owner, repo string, hook *Webhook) (_ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "CreateWebhook", owner, repo)(&err)
+
+	opt, err := gitlabProjectHookOptions(hook)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	created, resp, err := client.AddProjectHook(owner+"/"+repo, opt)
+	if err != nil {
+		return "", errors.Wrap(gitlabStatusError(err, resp), "failed to create webhook")
+	}
+
+	return strconv.Itoa(created.ID), nil
+}
+
+func (g *gitlabSource) DeleteWebhook(ctx context.Context, accessToken *AccessToken, owner, repo, hookID string) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "DeleteWebhook", owner, repo)(&err)
+
+	id, err := strconv.Atoi(hookID)
+	if err != nil {
+		return errors.Wrapf(err, "invalid webhook ID %s", hookID)
+	}
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+	if err != nil {
+		return errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	if resp, err := client.DeleteProjectHook(owner+"/"+repo, id); err != nil {
+		return errors.Wrap(gitlabStatusError(err, resp), "failed to delete webhook")
+	}
+
+	return nil
+}
+
+func (g *gitlabSource) ListWebhooks(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ []*Webhook, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "ListWebhooks", owner, repo)(&err)
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	webhooks := []*Webhook{}
+	opt := &gitlab.ListProjectHooksOptions{PerPage: 100}
+	for {
+		hooks, resp, err := client.ListProjectHooks(owner+"/"+repo, opt)
+		if err != nil {
+			return nil, errors.Wrap(gitlabStatusError(err, resp), "failed to list webhooks")
+		}
+
+		for _, hook := range hooks {
+			webhooks = append(webhooks, gitlabWebhook(hook))
+		}
+
+		if resp == nil || resp.NextPage == 0 {
+			return webhooks, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+func (g *gitlabSource) ListPullRequests(ctx context.Context, accessToken *AccessToken, owner, repo, state string) (_ []*PullRequest, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "ListPullRequests", owner, repo)(&err)
+
+	state, err = pullRequestState(state)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	// Gitlab tells closed and merged requests apart, and has no state matching both: closed ones are filtered
+	// out of all of them.
+	opt := &gitlab.ListProjectMergeRequestsOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+	if state == "open" {
+		opt.State = ptr.To("opened")
+	}
+
+	pullRequests := []*PullRequest{}
+	for {
+		mergeRequests, resp, err := client.ListProjectMergeRequests(owner+"/"+repo, opt)
+		if err != nil {
+			return nil, errors.Wrap(gitlabStatusError(err, resp), "failed to list merge requests")
+		}
+
+		for _, mergeRequest := range mergeRequests {
+			pullRequest := gitlabPullRequest(mergeRequest)
+			if state == "closed" && pullRequest.State != "closed" {
+				continue
+			}
+			pullRequests = append(pullRequests, pullRequest)
+		}
+
+		if resp == nil || resp.NextPage == 0 {
+			return pullRequests, nil
+		}
+		opt.Page = resp.NextPage
+	}
+}
+
+// gitlabPullRequest maps a merge request to a pull request. Opened and locked requests are open, closed and
+// merged ones are closed.
+func gitlabPullRequest(mergeRequest *gitlab.MergeRequest) *PullRequest {
+	state := "open"
+	if mergeRequest.State == "closed" || mergeRequest.State == "merged" {
+		state = "closed"
+	}
+
+	return &PullRequest{
+		Number: mergeRequest.IID,
+		Title:  mergeRequest.Title,
+		URL:    mergeRequest.WebURL,
+		Head:   mergeRequest.SourceBranch,
+		Base:   mergeRequest.TargetBranch,
+		State:  state,
+	}
+}
+
+func (g *gitlabSource) AddDeployKey(ctx context.Context, accessToken *AccessToken, owner, repo, title, publicKey string, readOnly bool) (_ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "AddDeployKey", owner, repo)(&err)
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	created, resp, err := client.AddDeployKey(owner+"/"+repo, &gitlab.AddDeployKeyOptions{
+		Title:   &title,
+		Key:     &publicKey,
+		CanPush: ptr.To(!readOnly),
+	})
+	if err != nil {
+		return "", errors.Wrap(gitlabStatusError(err, resp), "failed to add deploy key")
+	}
+
+	return strconv.Itoa(created.ID), nil
+}
+
+func (g *gitlabSource) HasDeployKey(ctx context.Context, accessToken *AccessToken, owner, repo, title string) (_ bool, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "HasDeployKey", owner, repo)(&err)
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+	if err != nil {
+		return false, errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	opt := &gitlab.ListProjectDeployKeysOptions{PerPage: 100}
+	for {
+		keys, resp, err := client.ListProjectDeployKeys(owner+"/"+repo, opt)
+		if err != nil {
+			return false, errors.Wrap(gitlabStatusError(err, resp), "failed to list deploy keys")
+		}
+
+		for _, key := range keys {
+			if key.Title == title {
+				return true, nil
+			}
+		}
+
+		if resp == nil || resp.NextPage == 0 {
 			return false, nil
 		}
-		return false, err
+		opt.Page = resp.NextPage
+	}
+}
+
+func (g *gitlabSource) DeleteDeployKey(ctx context.Context, accessToken *AccessToken, owner, repo, keyID string) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "DeleteDeployKey", owner, repo)(&err)
+
+	id, err := strconv.Atoi(keyID)
+	if err != nil {
+		return errors.Wrapf(err, "invalid deploy key ID %s", keyID)
+	}
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+	if err != nil {
+		return errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	if resp, err := client.DeleteDeployKey(owner+"/"+repo, id); err != nil {
+		return errors.Wrap(gitlabStatusError(err, resp), "failed to delete deploy key")
 	}
 
-	if variable != nil {
-		return true, nil
+	return nil
+}
+
+func (g *gitlabSource) GetBranchHead(ctx context.Context, accessToken *AccessToken, owner, repo, branch string) (_ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "GetBranchHead", owner, repo)(&err)
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	return gitlabBranchHead(client, owner+"/"+repo, branch)
+}
+
+// gitlabBranchHead returns the SHA of the latest commit of the branch of the project pid.
+func gitlabBranchHead(client interactions.GitlabIntr, pid, branch string) (string, error) {
+	gitlabBranch, resp, err := client.GetBranch(pid, branch)
+	if err != nil {
+		return "", errors.Wrapf(gitlabStatusError(err, resp), "failed to get branch %s", branch)
+	}
+	if gitlabBranch.Commit == nil {
+		return "", errors.Errorf("gitlab returned branch %s without a commit", branch)
+	}
+
+	return gitlabBranch.Commit.ID, nil
+}
+
+// gitlabProjectHookOptions maps hook to the options of a Gitlab project hook. Only push events are enabled when
+// hook has no events.
+func gitlabProjectHookOptions(hook *Webhook) (*gitlab.AddProjectHookOptions, error) {
+	events := hook.Events
+	if len(events) == 0 {
+		events = []string{"push"}
+	}
+
+	// Gitlab enables push events unless told otherwise.
+	opt := &gitlab.AddProjectHookOptions{
+		URL:                   ptr.To(hook.URL),
+		PushEvents:            ptr.To(false),
+		EnableSSLVerification: ptr.To(true),
+	}
+	if hook.Secret != "" {
+		opt.Token = ptr.To(hook.Secret)
+	}
+
+	for _, event := range events {
+		switch event {
+		case "push":
+			opt.PushEvents = ptr.To(true)
+		case "tag_push":
+			opt.TagPushEvents = ptr.To(true)
+		case "merge_request":
+			opt.MergeRequestsEvents = ptr.To(true)
+		case "pipeline":
+			opt.PipelineEvents = ptr.To(true)
+		case "release":
+			opt.ReleasesEvents = ptr.To(true)
+		default:
+			return nil, errors.Errorf("unsupported Gitlab webhook event: %s", event)
+		}
+	}
+
+	return opt, nil
+}
+
+// gitlabWebhook returns the Webhook of a Gitlab project hook, with the events gitlabProjectHookOptions can set.
+func gitlabWebhook(hook *gitlab.ProjectHook) *Webhook {
+	events := []string{}
+	for _, event := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"push", hook.PushEvents},
+		{"tag_push", hook.TagPushEvents},
+		{"merge_request", hook.MergeRequestsEvents},
+		{"pipeline", hook.PipelineEvents},
+		{"release", hook.ReleasesEvents},
+	} {
+		if event.enabled {
+			events = append(events, event.name)
+		}
+	}
+
+	return &Webhook{
+		ID:          strconv.Itoa(hook.ID),
+		URL:         hook.URL,
+		Events:      events,
+		ContentType: "json",
+	}
+}
+
+// tagPipeline waits for the pipeline of a tag to be created and returns its ID, or 0 if none shows up.
+func (g *gitlabSource) tagPipeline(ctx context.Context, client interactions.GitlabIntr, pid int, tag string) int64 {
+	var pipelineID int64
+	err := retry.RetryIfContext(ctx, time.Duration(g.cfg.WaitTagTimeoutSeconds)*time.Second, isRetryable, func(i int) error {
+		pipelines, _, err := client.ListProjectPipelines(pid, &gitlab.ListProjectPipelinesOptions{Ref: &tag})
+		if err != nil {
+			return err
+		}
+		if len(pipelines) == 0 {
+			return errors.Errorf("no pipeline was triggered for tag %s", tag)
+		}
+		pipelineID = int64(pipelines[0].ID)
+		return nil
+	})
+	if err != nil {
+		g.logger.Debug().Err(err).Msgf("failed to find the pipeline of tag [%s]", tag)
+		return 0
+	}
+
+	return pipelineID
+}
+
+func (g *gitlabSource) hasSecret(client interactions.GitlabIntr, orgName, repoName, secretName, environmentScope string) (bool, error) {
+	variable, err := g.getSecret(client, orgName, repoName, secretName, environmentScope)
+
+	return variable != nil, err
+}
+
+// getSecret returns the project variable, or nil when it doesn't exist.
+func (g *gitlabSource) getSecret(client interactions.GitlabIntr, orgName, repoName, secretName, environmentScope string) (*gitlab.ProjectVariable, error) {
+	variable, resp, err := client.GetProjectVariable(orgName+"/"+repoName, secretName, environmentScope)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, gitlabStatusError(err, resp)
 	}
 
-	return false, nil
+	return variable, nil
 }
 
-func (g *gitlabSource) HasSecret(ctx context.Context, token *AccessToken, owner, repo, secretName string) (bool, error) {
-	client, err := g.interactionsFunc(token.Token)
+func (g *gitlabSource) HasSecret(ctx context.Context, token *AccessToken, owner, repo, secretName string, opts *SecretOptions) (_ bool, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "HasSecret", owner, repo)(&err)
+
+	client, err := g.interactionsFunc(token.Token, token.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
 
 	if err != nil {
 		return false, errors.Wrap(err, "failed to create Gitlab client")
 	}
 
-	return g.hasSecret(client, owner, repo, secretName)
+	return g.hasSecret(client, owner, repo, secretName, opts.environmentScope())
+}
+
+// GetSecret returns the value of the project CI/CD variable available to all environments, as set by
+// AddSecretToRepo without an EnvironmentScope.
+func (g *gitlabSource) GetSecret(ctx context.Context, token *AccessToken, owner, repo, secretName string) (_ string, _ bool, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "GetSecret", owner, repo)(&err)
+
+	client, err := g.interactionsFunc(token.Token, token.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	variable, err := g.getSecret(client, owner, repo, secretName, "*")
+	if err != nil || variable == nil {
+		return "", false, err
+	}
+
+	return variable.Value, true, nil
 }
 
-func (g *gitlabSource) AddSecretToRepo(ctx context.Context, token *AccessToken, orgName, repoName, secretName, value string, overrideSecret bool) error {
-	client, err := g.interactionsFunc(token.Token)
+// AddSecretToRepo stores the secret as a project CI/CD variable. A nil opts creates a masked and protected variable.
+func (g *gitlabSource) AddSecretToRepo(ctx context.Context, token *AccessToken, orgName, repoName, secretName, value string, overrideSecret bool, opts *SecretOptions) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "AddSecretToRepo", orgName, repoName)(&err)
+
+	client, err := g.interactionsFunc(token.Token, token.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
 
 	if err != nil {
 		return errors.Wrap(err, "failed to create Gitlab client")
 	}
 
-	masked := true
+	return g.addSecretToRepo(client, orgName, repoName, secretName, value, overrideSecret, opts)
+}
+
+// AddSecretToRepos adds the secret to each of the projects of owner through a single client, as a masked and
+// protected variable, like AddSecretToRepo with nil opts.
+func (g *gitlabSource) AddSecretToRepos(ctx context.Context, token *AccessToken, owner string, repos []string, secretName, value string, overrideSecret bool) (_ map[string]error, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "AddSecretToRepos", owner, "")(&err)
+
+	client, err := g.interactionsFunc(token.Token, token.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
 
-	hasSecret, err := g.hasSecret(client, orgName, repoName, secretName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	results := make(map[string]error, len(repos))
+	for _, repo := range repos {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return results, ctxErr
+		}
+
+		results[repo] = g.addSecretToRepo(client, owner, repo, secretName, value, overrideSecret, nil)
+	}
+
+	return results, nil
+}
+
+func (g *gitlabSource) addSecretToRepo(client interactions.GitlabIntr, orgName, repoName, secretName, value string, overrideSecret bool, opts *SecretOptions) error {
+	if opts == nil {
+		opts = &SecretOptions{Masked: true, Protected: true}
+	}
+
+	environmentScope := opts.environmentScope()
+
+	hasSecret, err := g.hasSecret(client, orgName, repoName, secretName, environmentScope)
 	if err != nil {
 		return err
 	}
@@ -427,28 +1329,46 @@ func (g *gitlabSource) AddSecretToRepo(ctx context.Context, token *AccessToken,
 
 	repo := orgName + "/" + repoName
 
+	var resp *gitlab.Response
 	if hasSecret {
 		opt := &gitlab.UpdateProjectVariableOptions{
-			Value:     &value,
-			Masked:    &masked,
-			Protected: &masked,
+			Value:            &value,
+			Masked:           &opts.Masked,
+			Protected:        &opts.Protected,
+			EnvironmentScope: &environmentScope,
 		}
-		err = client.UpdateProjectVariable(repo, secretName, opt)
+		resp, err = client.UpdateProjectVariable(repo, secretName, environmentScope, opt)
 	} else {
 		opt := &gitlab.CreateProjectVariableOptions{
-			Key:       &secretName,
-			Value:     &value,
-			Masked:    &masked,
-			Protected: &masked,
+			Key:              &secretName,
+			Value:            &value,
+			Masked:           &opts.Masked,
+			Protected:        &opts.Protected,
+			EnvironmentScope: &environmentScope,
 		}
-		err = client.CreateProjectVariable(repo, opt)
+		resp, err = client.CreateProjectVariable(repo, opt)
+	}
+
+	if err != nil && opts.Masked && resp != nil && resp.StatusCode == http.StatusBadRequest {
+		return errors.Wrap(err, "gitlab can't mask the secret value: masked values must be on a single line, "+
+			"at least 8 characters long and only use the Base64 alphabet, '@', ':', '.' or '~'; "+
+			"store it unmasked instead")
 	}
 
 	return err
 }
 
-func (g *gitlabSource) CreateCommitOnBranch(ctx context.Context, accessToken *AccessToken, commit *Commit) (string, error) {
-	client, err := g.interactionsFunc(accessToken.Token)
+func (g *gitlabSource) CreateCommitOnBranch(ctx context.Context, accessToken *AccessToken, commit *Commit) (_ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "CreateCommitOnBranch", commit.Owner, commit.Repo)(&err)
+
+	if len(commit.Content) == 0 {
+		return "", errors.New("commit must contain at least one file")
+	}
+	if err = checkConflictStrategy("gitlab", commit); err != nil {
+		return "", err
+	}
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
 
 	if err != nil {
 		return "", errors.Wrap(err, "failed to create Gitlab client")
@@ -458,13 +1378,33 @@ func (g *gitlabSource) CreateCommitOnBranch(ctx context.Context, accessToken *Ac
 
 	repo := commit.Owner + "/" + commit.Repo
 
+	// The files of a branch that doesn't exist yet are those of the branch it starts from.
+	startBranch, err := gitlabStartBranch(client, repo, commit)
+	if err != nil {
+		return "", err
+	}
+	ref := commit.Branch
+	if startBranch != nil {
+		ref = *startBranch
+	}
+
 	for filePath, content := range commit.Content {
 		act := gitlab.FileUpdate
 
-		err := client.GetProjectFile(repo, filePath, &gitlab.GetFileOptions{Ref: &commit.Branch})
-
+		file, resp, err := client.GetProjectFile(repo, filePath, &gitlab.GetFileOptions{Ref: &ref})
 		if err != nil {
+			if resp == nil || resp.StatusCode != http.StatusNotFound {
+				return "", errors.Wrapf(gitlabStatusError(err, resp), "failed to get file: %s", filePath)
+			}
 			act = gitlab.FileCreate
+		} else if startBranch == nil {
+			current, err := gitlabFileContent(file)
+			if err != nil {
+				return "", errors.Wrapf(err, "failed to decode file: %s", filePath)
+			}
+			if current == content {
+				continue
+			}
 		}
 		c := content
 		f := filePath
@@ -476,18 +1416,175 @@ func (g *gitlabSource) CreateCommitOnBranch(ctx context.Context, accessToken *Ac
 		actions = append(actions, action)
 	}
 
+	if len(actions) == 0 {
+		// All the files are unchanged, the head of the branch already has the content of the commit.
+		return gitlabBranchHead(client, repo, commit.Branch)
+	}
+
 	opt := &gitlab.CreateCommitOptions{
 		Branch:        &commit.Branch,
+		StartBranch:   startBranch,
 		CommitMessage: &commit.Message,
 		Actions:       actions,
 	}
+	if commit.Author != nil {
+		opt.AuthorName = &commit.Author.Name
+		opt.AuthorEmail = &commit.Author.Email
+	}
 
 	commitSha, err := client.CreateCommit(repo, opt)
 
 	return commitSha, err
 }
 
-func (g *gitlabSource) GetDefaultBranch(ctx context.Context, accessToken *AccessToken, owner, repo string) (string, error) {
+func (g *gitlabSource) CreateCommitViaMergeRequest(ctx context.Context, accessToken *AccessToken, commit *Commit) (_ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "CreateCommitViaMergeRequest", commit.Owner, commit.Repo)(&err)
+
+	target := commit.StartBranch
+	if target == "" {
+		target, err = g.GetDefaultBranch(ctx, accessToken, commit.Owner, commit.Repo)
+		if err != nil {
+			return "", err
+		}
+	}
+	if commit.Branch == "" || commit.Branch == target {
+		return "", errors.Errorf("commit branch must differ from %s, the target of the merge request", target)
+	}
+
+	branchCommit := *commit
+	branchCommit.StartBranch = target
+	if _, err := g.CreateCommitOnBranch(ctx, accessToken, &branchCommit); err != nil {
+		return "", err
+	}
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	repo := commit.Owner + "/" + commit.Repo
+	title, _, _ := strings.Cut(commit.Message, "\n")
+
+	mergeRequest, resp, err := client.CreateMergeRequest(repo, &gitlab.CreateMergeRequestOptions{
+		Title:        &title,
+		SourceBranch: &commit.Branch,
+		TargetBranch: &target,
+	})
+	if err == nil {
+		return mergeRequest.WebURL, nil
+	}
+	if resp == nil || resp.StatusCode != http.StatusConflict {
+		return "", errors.Wrapf(gitlabStatusError(err, resp), "failed to create merge request of %s into %s", commit.Branch, target)
+	}
+
+	// Gitlab answers 409 when the branch already has an open merge request, which now holds the commit too.
+	mergeRequests, listResp, listErr := client.ListProjectMergeRequests(repo, &gitlab.ListProjectMergeRequestsOptions{
+		State:        ptr.To("opened"),
+		SourceBranch: &commit.Branch,
+		TargetBranch: &target,
+	})
+	if listErr != nil {
+		return "", errors.Wrap(gitlabStatusError(listErr, listResp), "failed to list merge requests")
+	}
+	if len(mergeRequests) == 0 {
+		return "", errors.Wrapf(err, "failed to create merge request of %s into %s, and the branch has no open one", commit.Branch, target)
+	}
+
+	return mergeRequests[0].WebURL, nil
+}
+
+// gitlabStartBranch returns the branch the commit creates its branch from, or nil when the commit has no
+// StartBranch or its branch already exists.
+func gitlabStartBranch(client interactions.GitlabIntr, repo string, commit *Commit) (*string, error) {
+	if commit.StartBranch == "" {
+		return nil, nil
+	}
+
+	_, resp, err := client.GetBranch(repo, commit.Branch)
+	if err == nil {
+		return nil, nil
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		return nil, errors.Wrapf(gitlabStatusError(err, resp), "failed to get branch %s", commit.Branch)
+	}
+
+	return &commit.StartBranch, nil
+}
+
+func (g *gitlabSource) CreateFile(ctx context.Context, accessToken *AccessToken, owner, repo, path, content, branch, message string) (err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "CreateFile", owner, repo)(&err)
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+	if err != nil {
+		return errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	_, err = client.CreateCommit(owner+"/"+repo, &gitlab.CreateCommitOptions{
+		Branch:        &branch,
+		CommitMessage: &message,
+		Actions: []*gitlab.CommitActionOptions{{
+			Action:   ptr.To(gitlab.FileCreate),
+			FilePath: &path,
+			Content:  &content,
+		}},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create file: %s", path)
+	}
+
+	return nil
+}
+
+// GetFileContent returns the content of a file at the given ref, or on the default branch when ref is empty.
+// The bool is false when the file doesn't exist.
+func (g *gitlabSource) GetFileContent(ctx context.Context, accessToken *AccessToken, owner, repo, path, ref string) (_ string, _ bool, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "GetFileContent", owner, repo)(&err)
+
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	if ref == "" {
+		ref, err = g.GetDefaultBranch(ctx, accessToken, owner, repo)
+		if err != nil {
+			return "", false, err
+		}
+	}
+
+	file, resp, err := client.GetProjectFile(owner+"/"+repo, path, &gitlab.GetFileOptions{Ref: &ref})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", false, nil
+		}
+		return "", false, errors.Wrapf(gitlabStatusError(err, resp), "failed to get file: %s", path)
+	}
+
+	content, err := gitlabFileContent(file)
+	if err != nil {
+		return "", false, errors.Wrapf(err, "failed to decode file: %s", path)
+	}
+
+	return content, true, nil
+}
+
+// gitlabFileContent returns the content of a file read from Gitlab, which is usually base64 encoded.
+func gitlabFileContent(file *gitlab.File) (string, error) {
+	if file.Encoding != "base64" {
+		return file.Content, nil
+	}
+
+	content, err := base64.StdEncoding.DecodeString(file.Content)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+func (g *gitlabSource) GetDefaultBranch(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ string, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "GetDefaultBranch", owner, repo)(&err)
+
 	_, proj, err := g.getSccRepoWithGitlabProj(accessToken, owner, repo)
 	if err != nil {
 		return "", err
@@ -495,3 +1592,126 @@ func (g *gitlabSource) GetDefaultBranch(ctx context.Context, accessToken *Access
 
 	return proj.DefaultBranch, nil
 }
+
+// IsRepoEmpty returns true if the project has no commits yet.
+func (g *gitlabSource) IsRepoEmpty(ctx context.Context, accessToken *AccessToken, owner, repo string) (_ bool, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "IsRepoEmpty", owner, repo)(&err)
+
+	_, proj, err := g.getSccRepoWithGitlabProj(accessToken, owner, repo)
+	if err != nil {
+		return false, err
+	}
+
+	return proj.EmptyRepo, nil
+}
+
+// ListTags lists the tags of a project, most recently updated first.
+func (g *gitlabSource) ListTags(ctx context.Context, accessToken *AccessToken, owner, repo string, page *api.PaginationRequest) (_ []string, _ *api.PaginationResponse, err error) {
+	defer trackOperation(g.logger, g.cfg.Observer, "gitlab", "ListTags", owner, repo)(&err)
+
+	if page == nil {
+		return nil, nil, errors.New("page must not be empty")
+	}
+	page = g.cfg.pageRequest(page)
+	if page.Size < -1 || page.Size > 100 {
+		return nil, nil, errors.New("page size must be >= -1 and <= 100")
+	}
+
+	tags := []string{}
+	client, err := g.interactionsFunc(accessToken.Token, accessToken.Type, g.cfg.RateLimitTimeoutSeconds, g.cfg.RateLimitRetryCount, g.cfg.RetryStatusCodes, g.cfg.httpTimeout(), g.cfg.userAgent(), g.cfg.userCacheTTL())
+	if err != nil {
+		return tags, nil, errors.Wrap(err, "failed to create Gitlab client")
+	}
+
+	pageToRead := 0
+	if strings.TrimSpace(page.Token) != "" {
+		pageToRead, err = strconv.Atoi(page.Token)
+		if err != nil {
+			return tags, nil, errors.Wrap(err, "page token must be int")
+		}
+	}
+
+	orderBy := "updated"
+	sort := "desc"
+	opt := &gitlab.ListTagsOptions{
+		ListOptions: gitlab.ListOptions{Page: pageToRead, PerPage: int(page.Size)},
+		OrderBy:     &orderBy,
+		Sort:        &sort,
+	}
+
+	if page.Size == -1 {
+		opt.ListOptions.PerPage = 100
+	}
+
+	for {
+		glTags, resp, err := client.ListTags(owner+"/"+repo, opt)
+		if err != nil {
+			return tags, nil, err
+		}
+
+		for _, tag := range glTags {
+			tags = append(tags, tag.Name)
+		}
+
+		response := &api.PaginationResponse{
+			NextToken:  fmt.Sprintf("%d", resp.NextPage),
+			ResultSize: safeInt32(len(tags)),
+			TotalSize:  safeInt32(resp.TotalItems),
+		}
+
+		if page.Size != -1 {
+			return tags, response, nil
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.ListOptions.Page = resp.NextPage
+	}
+
+	response := &api.PaginationResponse{
+		NextToken:  "",
+		ResultSize: safeInt32(len(tags)),
+		TotalSize:  safeInt32(len(tags)),
+	}
+	return tags, response, nil
+}
+
+// gitlabStatusError turns 404 and 403 replies into ErrNotFound and ErrForbidden carrying the status code,
+// and returns err unchanged otherwise.
+func gitlabStatusError(err error, resp *gitlab.Response) error {
+	if resp == nil {
+		return err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return errx.ErrNotFound.Err(err).Int(errx.StatusCodeKey, resp.StatusCode)
+	case http.StatusForbidden:
+		return errx.ErrForbidden.Err(err).Int(errx.StatusCodeKey, resp.StatusCode)
+	default:
+		return err
+	}
+}
+
+// gitlabErrorResponse returns the response of a failed Gitlab request, for the interactions that don't return it.
+func gitlabErrorResponse(err error) *gitlab.Response {
+	var errResp *gitlab.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil {
+		return &gitlab.Response{Response: errResp.Response}
+	}
+
+	return nil
+}
+
+// gitlabProjectOrg returns the namespace a project lives in, which is what GetRepo expects as owner.
+func gitlabProjectOrg(proj *gitlab.Project, fallback string) string {
+	if proj.Namespace != nil && proj.Namespace.FullPath != "" {
+		return proj.Namespace.FullPath
+	}
+	if proj.Owner != nil {
+		return proj.Owner.Username
+	}
+
+	return fallback
+}
@@ -1,14 +1,24 @@
 package sources_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	cerr "github.com/aserto-dev/errors"
 	"github.com/aserto-dev/go-grpc/aserto/api/v1"
+	"github.com/aserto-dev/scc-lib/errx"
 	"github.com/aserto-dev/scc-lib/internal/interactions"
 	"github.com/aserto-dev/scc-lib/sources"
 	"github.com/pkg/errors"
@@ -16,6 +26,7 @@ import (
 	"github.com/stretchr/testify/require"
 	gitlab "gitlab.com/gitlab-org/api/client-go"
 	"go.uber.org/mock/gomock"
+	"k8s.io/utils/ptr"
 )
 
 var mockIntr *interactions.MockGitlabIntr
@@ -34,7 +45,7 @@ func init() {
 }
 
 func newMockIntrFunc(ctrl *gomock.Controller) interactions.GlIntr {
-	return func(token string) (interactions.GitlabIntr, error) {
+	return func(token, tokenType string, _, _ int, _ []int, httpTimeout time.Duration, userAgent string, userCacheTTL time.Duration) (interactions.GitlabIntr, error) {
 		if token == "" {
 			return nil, errors.New("Kaboom")
 		}
@@ -69,458 +80,2828 @@ func TestConstructor(t *testing.T) {
 	assert.Contains(err.Error(), "GET https://gitlab.com/api/v4/user: 401 {message: 401 Unauthorized}")
 }
 
+func TestValidateConnectionWithOAuthToken(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, func(token, tokenType string, _, _ int, _ []int, httpTimeout time.Duration, userAgent string, userCacheTTL time.Duration) (interactions.GitlabIntr, error) {
+		assert.Equal("oauth", tokenType)
+		return mockintrFunc(token, tokenType, 0, 0, nil, httpTimeout, userAgent, userCacheTTL)
+	})
+	token := &sources.AccessToken{Token: "sometokenvalue", Type: "oauth"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: 200}}
+
+	// Expect
+	mockIntr.EXPECT().CurrentUser().Return(&gitlab.User{}, resp, nil)
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestValidateConnectionDefaultHTTPTimeout(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, func(token, tokenType string, _, _ int, _ []int, httpTimeout time.Duration, userAgent string, userCacheTTL time.Duration) (interactions.GitlabIntr, error) {
+		assert.Equal(30*time.Second, httpTimeout)
+		return mockintrFunc(token, tokenType, 0, 0, nil, httpTimeout, userAgent, userCacheTTL)
+	})
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: 200}}
+
+	// Expect
+	mockIntr.EXPECT().CurrentUser().Return(&gitlab.User{}, resp, nil)
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestValidateConnectionConfiguredHTTPTimeout(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{HTTPTimeoutSeconds: 5}, func(token, tokenType string, _, _ int, _ []int, httpTimeout time.Duration, userAgent string, userCacheTTL time.Duration) (interactions.GitlabIntr, error) {
+		assert.Equal(5*time.Second, httpTimeout)
+		return mockintrFunc(token, tokenType, 0, 0, nil, httpTimeout, userAgent, userCacheTTL)
+	})
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: 200}}
+
+	// Expect
+	mockIntr.EXPECT().CurrentUser().Return(&gitlab.User{}, resp, nil)
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestValidateConnectionWithCustomClient(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		assert.Equal("/api/v4/user", req.URL.Path)
+		assert.Equal("sometokenvalue", req.Header.Get("Private-Token"))
+		return jsonResponse(req, `{"id": 1, "username": "aserto"}`), nil
+	})}
+	p := sources.NewGitlabWithClient(&zerolog.Logger{}, &sources.Config{}, httpClient)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestValidateConnectionSendsUserAgent(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		assert.Equal("policy-onboarding/1.0", req.Header.Get("User-Agent"))
+		return jsonResponse(req, `{"id": 1, "username": "aserto"}`), nil
+	})}
+	p := sources.NewGitlabWithClient(&zerolog.Logger{}, &sources.Config{UserAgent: "policy-onboarding/1.0"}, httpClient)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.NoError(err)
+}
+
+// tlsGitlabServer starts a TLS server answering the Gitlab user endpoint, and returns the PEM encoded certificate of
+// its CA along with a client that dials it for any host and checks its certificate against example.com.
+func tlsGitlabServer(t *testing.T) ([]byte, *http.Client) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": 1, "username": "aserto"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		},
+		TLSClientConfig: &tls.Config{ServerName: "example.com", MinVersion: tls.VersionTLS12},
+	}
+
+	return caCert, &http.Client{Transport: transport}
+}
+
+func TestValidateConnectionTrustsTLSCACert(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	caCert, httpClient := tlsGitlabServer(t)
+	p := sources.NewGitlabWithClient(&zerolog.Logger{}, &sources.Config{TLSCACert: caCert}, httpClient)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestValidateConnectionVerifiesServerCertificate(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	_, httpClient := tlsGitlabServer(t)
+	p := sources.NewGitlabWithClient(&zerolog.Logger{}, &sources.Config{}, httpClient)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "certificate")
+}
+
+func TestValidateConnectionWithTLSInsecureSkipVerify(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	_, httpClient := tlsGitlabServer(t)
+	p := sources.NewGitlabWithClient(&zerolog.Logger{}, &sources.Config{TLSInsecureSkipVerify: true}, httpClient)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestValidateConnectionWithMissingTLSCACertFile(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	_, httpClient := tlsGitlabServer(t)
+	cfg := &sources.Config{TLSCACertFile: "/nonexistent/ca.pem"}
+	p := sources.NewGitlabWithClient(&zerolog.Logger{}, cfg, httpClient)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "failed to read CA certificate file /nonexistent/ca.pem")
+}
+
+func TestCloseClosesIdleConnections(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	transport := &idleClosingTransport{}
+	p := sources.NewGitlabWithClient(&zerolog.Logger{}, &sources.Config{}, &http.Client{Transport: transport})
+
+	// Act
+	err := p.Close()
+
+	// Assert
+	assert.NoError(err)
+	assert.True(transport.closed)
+}
+
+func TestValidateConnectionRetriesRateLimit(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	calls := 0
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			resp := jsonResponse(req, `{"message": "429 Too Many Requests"}`)
+			resp.StatusCode = http.StatusTooManyRequests
+			resp.Status = "429 Too Many Requests"
+			resp.Header.Set("Retry-After", "1")
+			return resp, nil
+		}
+		return jsonResponse(req, `{"id": 1, "username": "aserto"}`), nil
+	})}
+	observer := &recordingObserver{}
+	cfg := &sources.Config{RateLimitRetryCount: 3, RateLimitTimeoutSeconds: 10, Observer: observer}
+	p := sources.NewGitlabWithClient(&zerolog.Logger{}, cfg, httpClient)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(2, calls)
+	assert.Len(observer.calls, 3)
+	assert.Equal("api CurrentUser", observer.calls[0].operation)
+	assert.Error(observer.calls[0].err)
+	assert.Equal("api CurrentUser", observer.calls[1].operation)
+	assert.NoError(observer.calls[1].err)
+}
+
+func TestValidateConnectionObservesCalls(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(req, `{"id": 1, "username": "aserto"}`), nil
+	})}
+	observer := &recordingObserver{}
+	p := sources.NewGitlabWithClient(&zerolog.Logger{}, &sources.Config{Observer: observer}, httpClient)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal([]observedCall{
+		{provider: "gitlab", operation: "api CurrentUser"},
+		{provider: "gitlab", operation: "ValidateConnection"},
+	}, observer.calls)
+}
+
+func TestGetRepoObservesError(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	observer := &recordingObserver{}
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{Observer: observer}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(nil, nil, errors.New("boom"))
+
+	// Act
+	_, err := p.GetRepo(context.Background(), token, "aserto-dev", "policy")
+
+	// Assert
+	assert.Error(err)
+	assert.Len(observer.calls, 1)
+	assert.Equal("GetRepo", observer.calls[0].operation)
+	assert.ErrorIs(observer.calls[0].err, err)
+}
+
+func TestValidateConnectionRetryLimit(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	calls := 0
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		resp := jsonResponse(req, `{"message": "503 Service Unavailable"}`)
+		resp.StatusCode = http.StatusServiceUnavailable
+		resp.Status = "503 Service Unavailable"
+		resp.Header.Set("Retry-After", "1")
+		return resp, nil
+	})}
+	cfg := &sources.Config{RateLimitRetryCount: 2, RateLimitTimeoutSeconds: 10}
+	p := sources.NewGitlabWithClient(&zerolog.Logger{}, cfg, httpClient)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrRetryTimeout.SameAs(err))
+	assert.Equal(2, errx.RetryAttempts(err))
+	assert.Equal(2, calls)
+}
+
+func TestCreateRepoDoesntRetryServerErrors(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	posts := 0
+	httpClient := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodGet {
+			return jsonResponse(req, `{"id": 1, "path": "aserto-dev"}`), nil
+		}
+
+		posts++
+		resp := jsonResponse(req, `{"message": "502 Bad Gateway"}`)
+		resp.StatusCode = http.StatusBadGateway
+		resp.Status = "502 Bad Gateway"
+		resp.Header.Set("Retry-After", "1")
+		return resp, nil
+	})}
+	cfg := &sources.Config{RateLimitRetryCount: 3, RateLimitTimeoutSeconds: 10}
+	p := sources.NewGitlabWithClient(&zerolog.Logger{}, cfg, httpClient)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	_, err := p.CreateRepo(context.Background(), token, "aserto-dev", "policy", nil)
+
+	// Assert
+	assert.Error(err)
+	assert.False(errx.ErrRetryTimeout.SameAs(err))
+	assert.Equal(1, posts)
+}
+
+func TestPing(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, func(token, tokenType string, _, _ int, _ []int, httpTimeout time.Duration, userAgent string, userCacheTTL time.Duration) (interactions.GitlabIntr, error) {
+		assert.Empty(token)
+		return mockIntr, nil
+	})
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: http.StatusUnauthorized, Status: "401 Unauthorized"}}
+
+	// Expect
+	mockIntr.EXPECT().GetVersion().Return(resp, errors.New("401 Unauthorized"))
+
+	// Act
+	err := p.Ping(context.Background())
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestPingServerError(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, func(token, tokenType string, _, _ int, _ []int, httpTimeout time.Duration, userAgent string, userCacheTTL time.Duration) (interactions.GitlabIntr, error) {
+		return mockIntr, nil
+	})
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway"}}
+
+	// Expect
+	mockIntr.EXPECT().GetVersion().Return(resp, errors.New("502 Bad Gateway"))
+
+	// Act
+	err := p.Ping(context.Background())
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrProviderUnreachable.SameAs(err))
+	assertoErr := cerr.UnwrapAsertoError(err)
+	assert.Equal("502", assertoErr.Data()[errx.StatusCodeKey])
+}
+
 func TestValidateConnectionWithEmptyToken(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
-	mockintr := newMockIntrFunc(ctrl)
-	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintr)
-	token := &sources.AccessToken{Token: ""}
+	mockintr := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintr)
+	token := &sources.AccessToken{Token: ""}
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "failed to create Gitlab client: Kaboom")
+}
+
+func TestValidateConnectionDoesntConnect(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockIntr.EXPECT().CurrentUser().Return(nil, nil, errors.New("no Connection"))
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "failed to connect to Gitlab: no Connection")
+}
+
+func TestValidateConnection(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: 200}}
+
+	// Expect
+	mockIntr.EXPECT().CurrentUser().Return(nil, resp, nil)
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestValidateConnectionMissingScopes(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: 200}}
+
+	// Expect
+	mockIntr.EXPECT().CurrentUser().Return(&gitlab.User{}, resp, nil)
+	mockIntr.EXPECT().GetCurrentAccessToken().Return(&gitlab.PersonalAccessToken{Scopes: []string{"api", "read_repository"}}, resp, nil)
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{"api", "write_repository"})
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrMissingScopes.SameAs(err))
+	assert.Equal([]string{"write_repository"}, errx.MissingScopes(err))
+}
+
+func TestValidateConnectionWithScopes(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: 200}}
+
+	// Expect
+	mockIntr.EXPECT().CurrentUser().Return(&gitlab.User{}, resp, nil)
+	mockIntr.EXPECT().GetCurrentAccessToken().Return(&gitlab.PersonalAccessToken{Scopes: []string{"api", "write_repository"}}, resp, nil)
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{"api", "write_repository"})
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestValidateConnectionSkipsScopesOfOAuthToken(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue", Type: "oauth"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: 200}}
+
+	// Expect
+	mockIntr.EXPECT().CurrentUser().Return(&gitlab.User{}, resp, nil)
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{"api"})
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestValidateConnectionErrorResponse(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	body := io.NopCloser(strings.NewReader("this is the body"))
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: 404, Status: "Not Found", Body: body}}
+
+	// Expect
+	mockIntr.EXPECT().CurrentUser().Return(nil, resp, nil)
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.Error(err)
+	assertoErr := cerr.UnwrapAsertoError(err)
+	assert.Contains(assertoErr.Data()["msg"], "unexpected reply from Gitlab")
+}
+
+func TestValidateConnectionNilResponse(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockIntr.EXPECT().CurrentUser().Return(nil, nil, nil)
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrProviderVerification.SameAs(err))
+}
+
+func TestValidateConnectionNilBody(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: 502, Status: "Bad Gateway"}}
+
+	// Expect
+	mockIntr.EXPECT().CurrentUser().Return(nil, resp, nil)
+
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+
+	// Assert
+	assert.Error(err)
+	assertoErr := cerr.UnwrapAsertoError(err)
+	assert.Contains(assertoErr.Data()["msg"], "unexpected reply from Gitlab")
+	assert.Equal("<no response body>", assertoErr.Data()["gitlab-response"])
+}
+
+func TestProfileConnectionWithEmptyToken(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintr := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintr)
+	token := &sources.AccessToken{Token: ""}
+
+	// Act
+	_, _, err := p.Profile(context.Background(), token)
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "failed to create Gitlab client: Kaboom")
+}
+
+func TestProfileDoesntConnect(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockIntr.EXPECT().CachedCurrentUser().Return(nil, errors.New("no Connection"))
+
+	// Act
+	_, _, err := p.Profile(context.Background(), token)
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "no Connection")
+}
+
+func TestProfileReadOnePage(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	var projects []*gitlab.Project
+	gitlabUser := &gitlab.User{Username: "aserto-tests"}
+	projects = append(projects, &gitlab.Project{Name: "template-policy", Owner: gitlabUser, WebURL: "gitlab.com/template-policy"})
+	resp := &gitlab.Response{NextPage: 0}
+
+	// Expect
+	mockIntr.EXPECT().CachedCurrentUser().Return(gitlabUser, nil)
+	mockIntr.EXPECT().ListProjects(gomock.Any()).
+		DoAndReturn(func(opt *gitlab.ListProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error) {
+			assert.True(*opt.Membership)
+			assert.Equal(gitlab.MaintainerPermissions, *opt.MinAccessLevel)
+			return projects, resp, nil
+		})
+
+	// Act
+	username, repos, err := p.Profile(context.Background(), token)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(username, gitlabUser.Username)
+	assert.Equal(len(repos), 1)
+	assert.Equal(repos[0].Name, "template-policy")
+	assert.Equal(repos[0].CiUrl, "gitlab.com/template-policy/-/pipelines")
+}
+
+func TestProfileReadTwoPages(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	var projects []*gitlab.Project
+	var projectsSecondPage []*gitlab.Project
+	gitlabUser := &gitlab.User{Username: "aserto-tests"}
+	projects = append(projects, &gitlab.Project{ID: 1, Name: "template-policy", Owner: gitlabUser, WebURL: "gitlab.com/template-policy"})
+	projectsSecondPage = append(projectsSecondPage,
+		&gitlab.Project{ID: 1, Name: "template-policy", Owner: gitlabUser, WebURL: "gitlab.com/template-policy"},
+		&gitlab.Project{
+			ID:        2,
+			Name:      "template-policy2",
+			Owner:     nil,
+			Namespace: &gitlab.ProjectNamespace{FullPath: "aserto-dev"},
+			WebURL:    "gitlab.com/template-policy2",
+		},
+	)
+	resp := &gitlab.Response{NextPage: 1}
+	resp2 := &gitlab.Response{NextPage: 0}
+
+	// Expect
+	mockIntr.EXPECT().CachedCurrentUser().Return(gitlabUser, nil)
+	mockIntr.EXPECT().ListProjects(gomock.Any()).Return(projects, resp, nil).Times(1)
+	mockIntr.EXPECT().ListProjects(gomock.Any()).Return(projectsSecondPage, resp2, nil).Times(1)
+
+	// Act
+	username, repos, err := p.Profile(context.Background(), token)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(username, gitlabUser.Username)
+	assert.Equal(2, len(repos))
+	assert.Equal("template-policy", repos[0].Name)
+	assert.Equal("aserto-tests", repos[0].Org)
+	assert.Equal("template-policy2", repos[1].Name)
+	assert.Equal("aserto-dev", repos[1].Org)
+}
+
+func TestListOrgsWithNilPage(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	// Act
+	_, _, err := p.ListOrgs(context.Background(), token, nil, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "page must not be empty")
+}
+
+func TestListOrgsWithInvalidPageSize(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: -2}
+	// Act
+	_, _, err := p.ListOrgs(context.Background(), token, page, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "page size must be >= -1 and <= 100")
+}
+
+func TestListOrgsWithStringPageToken(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: -1, Token: "next_token"}
+	// Act
+	_, _, err := p.ListOrgs(context.Background(), token, page, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "page token must be int: strconv.Atoi: parsing \"next_token\":")
+}
+
+func TestListOrgsWithDefaultPageSize(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: 0}
+
+	// Expect
+	mockIntr.EXPECT().ListGroups(gomock.Any()).
+		DoAndReturn(func(opt *gitlab.ListGroupsOptions) ([]*gitlab.Group, *gitlab.Response, error) {
+			assert.Equal(30, opt.PerPage)
+			return nil, &gitlab.Response{}, nil
+		})
+
+	// Act
+	_, _, err := p.ListOrgs(context.Background(), token, page, nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(int32(0), page.Size)
+}
+
+func TestListOrgsWithConfiguredDefaultPageSize(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{DefaultPageSize: 50}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: 0}
+
+	// Expect
+	mockIntr.EXPECT().ListGroups(gomock.Any()).
+		DoAndReturn(func(opt *gitlab.ListGroupsOptions) ([]*gitlab.Group, *gitlab.Response, error) {
+			assert.Equal(50, opt.PerPage)
+			return nil, &gitlab.Response{}, nil
+		})
+
+	// Act
+	_, _, err := p.ListOrgs(context.Background(), token, page, nil)
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestListOrgsAllInOnePage(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: -1, Token: ""}
+	var groups []*gitlab.Group
+	groups = append(groups, &gitlab.Group{Name: "tests", FullPath: "test7929"})
+	resp := &gitlab.Response{NextPage: 0, TotalItems: 1}
+
+	// Expect
+	mockIntr.EXPECT().ListGroups(gomock.Any()).Return(groups, resp, nil)
+
+	// Act
+	orgs, pageResp, err := p.ListOrgs(context.Background(), token, page, nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(pageResp.ResultSize, pageResp.TotalSize)
+	assert.Equal(1, len(orgs))
+	assert.Equal("test7929", orgs[0].Id)
+}
+
+func TestListOrgsDefaultOptions(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: 10}
+
+	// Expect
+	mockIntr.EXPECT().ListGroups(gomock.Any()).
+		DoAndReturn(func(opt *gitlab.ListGroupsOptions) ([]*gitlab.Group, *gitlab.Response, error) {
+			assert.Equal(gitlab.DeveloperPermissions, *opt.MinAccessLevel)
+			assert.False(*opt.TopLevelOnly)
+			return nil, &gitlab.Response{}, nil
+		})
+
+	// Act
+	_, _, err := p.ListOrgs(context.Background(), token, page, nil)
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestListOrgsWithOptions(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: 10}
+	opts := &sources.ListOrgsOptions{MinAccessLevel: int(gitlab.MaintainerPermissions), TopLevelOnly: true}
+
+	// Expect
+	mockIntr.EXPECT().ListGroups(gomock.Any()).
+		DoAndReturn(func(opt *gitlab.ListGroupsOptions) ([]*gitlab.Group, *gitlab.Response, error) {
+			assert.Equal(gitlab.MaintainerPermissions, *opt.MinAccessLevel)
+			assert.True(*opt.TopLevelOnly)
+			return nil, &gitlab.Response{}, nil
+		})
+
+	// Act
+	_, _, err := p.ListOrgs(context.Background(), token, page, opts)
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestListOrgsWithTwoPages(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: -1, Token: ""}
+	var groups []*gitlab.Group
+	var groupsSecondPage []*gitlab.Group
+	groups = append(groups, &gitlab.Group{Name: "tests", Path: "test7929"})
+	groupsSecondPage = append(groupsSecondPage, &gitlab.Group{Name: "aserto-demo", Path: "aserto-demmo"})
+	resp := &gitlab.Response{NextPage: 1, ItemsPerPage: 1, TotalItems: 2}
+	resp2 := &gitlab.Response{NextPage: 0, ItemsPerPage: 1, TotalItems: 2}
+
+	// Expect
+	mockIntr.EXPECT().ListGroups(gomock.Any()).Return(groups, resp, nil)
+	mockIntr.EXPECT().ListGroups(gomock.Any()).Return(groupsSecondPage, resp2, nil)
+
+	// Act
+	orgs, pageResp, err := p.ListOrgs(context.Background(), token, page, nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(pageResp.ResultSize, pageResp.TotalSize)
+	assert.Equal(2, len(orgs))
+}
+
+func TestListOrgsAllPagesIgnoresReportedTotal(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: -1, Token: ""}
+
+	// Expect
+	gomock.InOrder(
+		mockIntr.EXPECT().ListGroups(gomock.Any()).
+			Return([]*gitlab.Group{{Name: "one", FullPath: "one"}, {Name: "two", FullPath: "two"}}, &gitlab.Response{NextPage: 2, TotalItems: 10}, nil),
+		mockIntr.EXPECT().ListGroups(gomock.Any()).
+			Return([]*gitlab.Group{{Name: "three", FullPath: "three"}}, &gitlab.Response{NextPage: 3, TotalItems: 10}, nil),
+		mockIntr.EXPECT().ListGroups(gomock.Any()).
+			Return([]*gitlab.Group{{Name: "four", FullPath: "four"}}, &gitlab.Response{NextPage: 0, TotalItems: 10}, nil),
+	)
+
+	// Act
+	orgs, pageResp, err := p.ListOrgs(context.Background(), token, page, nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.Len(orgs, 4)
+	assert.Equal(int32(4), pageResp.TotalSize)
+	assert.Equal(pageResp.ResultSize, pageResp.TotalSize)
+	assert.Empty(pageResp.NextToken)
+}
+
+func TestListReposWithNilPage(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	// Act
+	_, _, err := p.ListRepos(context.Background(), token, "aserto-demo", nil, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "page must not be empty")
+}
+
+func TestListReposWithInvalidPageSize(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: -2}
+	// Act
+	_, _, err := p.ListRepos(context.Background(), token, "aserto-demo", page, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "page size must be >= -1 and <= 100")
+}
+
+func TestListReposWithStringPageToken(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: -1, Token: "next_token"}
+	// Act
+	_, _, err := p.ListRepos(context.Background(), token, "aserto-demo", page, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "page token must be int: strconv.Atoi: parsing \"next_token\":")
+}
+
+func TestListReposAllInOnePageWithUser(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: -1, Token: ""}
+	var projects []*gitlab.Project
+	gitlabUser := &gitlab.User{Username: "aserto-demo"}
+	projects = append(projects, &gitlab.Project{Name: "template-policy", Owner: gitlabUser, WebURL: "gitlab.com/template-policy"})
+	resp := &gitlab.Response{NextPage: 0, TotalItems: 1}
+
+	// Expect
+	mockIntr.EXPECT().CachedCurrentUser().Return(gitlabUser, nil)
+	mockIntr.EXPECT().ListUserProjects("aserto-demo", gomock.Any()).Return(projects, resp, nil)
+
+	// Act
+	repos, pageResp, err := p.ListRepos(context.Background(), token, "aserto-demo", page, nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(pageResp.ResultSize, pageResp.TotalSize)
+	assert.Equal(1, len(repos))
+	assert.Equal("template-policy", repos[0].Name)
+}
+
+func TestListReposAllInOnePageWithOrg(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: -1, Token: ""}
+	var projects []*gitlab.Project
+	gitlabUser := &gitlab.User{Username: "aserto-demo"}
+	projects = append(projects, &gitlab.Project{Name: "template-policy", Owner: gitlabUser, WebURL: "gitlab.com/template-policy"})
+	resp := &gitlab.Response{NextPage: 0, TotalItems: 1}
+
+	// Expect
+	mockIntr.EXPECT().CachedCurrentUser().Return(gitlabUser, nil)
+	mockIntr.EXPECT().ListGroupProjects("aserto-dev", gomock.Any()).Return(projects, resp, nil)
+
+	// Act
+	repos, pageResp, err := p.ListRepos(context.Background(), token, "aserto-dev", page, nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(pageResp.ResultSize, pageResp.TotalSize)
+	assert.Equal(1, len(repos))
+	assert.Equal("template-policy", repos[0].Name)
+}
+
+func TestGetRepoFail(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(nil, nil, errors.New("repo not found"))
+
+	// Act
+	repo, err := p.GetRepo(context.Background(), token, "aserto-dev", "policy")
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "failed to get project: aserto-dev/policy: repo not found")
+	assert.Nil(repo)
+}
+
+func TestGetRepoNotFound(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
+
+	// Expect
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(nil, resp, errors.New("404 Project Not Found"))
+
+	// Act
+	repo, err := p.GetRepo(context.Background(), token, "aserto-dev", "policy")
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrNotFound.SameAs(err))
+	assert.Equal("404", cerr.UnwrapAsertoError(err).Data()[errx.StatusCodeKey])
+	assert.Nil(repo)
+}
+
+func TestGetRepo(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	proj := &gitlab.Project{Name: "policy", WebURL: "gitlab.com/policy"}
+
+	// Expect
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
+
+	// Act
+	repo, err := p.GetRepo(context.Background(), token, "aserto-dev", "policy")
+
+	// Assert
+	assert.NoError(err)
+	assert.NotNil(repo)
+	assert.Equal(repo.Org, "aserto-dev")
+}
+
+func TestGetRepoLogsOperation(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	var logs bytes.Buffer
+	logger := zerolog.New(&logs).Level(zerolog.DebugLevel)
+	p := sources.NewTestGitlab(ctrl, &logger, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	proj := &gitlab.Project{Name: "policy", WebURL: "gitlab.com/policy"}
+
+	// Expect
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
+
+	// Act
+	_, err := p.GetRepo(context.Background(), token, "aserto-dev", "policy")
+
+	// Assert
+	assert.NoError(err)
+	assert.Contains(logs.String(), `"provider":"gitlab","operation":"GetRepo","owner":"aserto-dev","repo":"policy","message":"operation started"`)
+	assert.Contains(logs.String(), `"duration_ms":`)
+	assert.NotContains(logs.String(), "sometokenvalue")
+}
+
+func TestGetRepoID(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	proj := &gitlab.Project{ID: 4242, Name: "policy", WebURL: "gitlab.com/policy"}
+
+	// Expect
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
+
+	// Act
+	id, err := p.GetRepoID(context.Background(), token, "aserto-dev", "policy")
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("4242", id)
+}
+
+func TestGetCloneURLs(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	proj := &gitlab.Project{
+		Name:          "policy",
+		WebURL:        "https://gitlab.com/aserto-dev/policy",
+		HTTPURLToRepo: "https://gitlab.com/aserto-dev/policy.git",
+		SSHURLToRepo:  "git@gitlab.com:aserto-dev/policy.git",
+	}
+
+	// Expect
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
+
+	// Act
+	httpsURL, sshURL, err := p.(sources.CloneURLSource).GetCloneURLs(context.Background(), token, "aserto-dev", "policy")
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("https://gitlab.com/aserto-dev/policy.git", httpsURL)
+	assert.Equal("git@gitlab.com:aserto-dev/policy.git", sshURL)
+}
+
+func TestRenameRepo(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockIntr.EXPECT().EditProject("aserto-dev/policy", gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.EditProjectOptions) (*gitlab.Project, *gitlab.Response, error) {
+			assert.Equal("renamed", *opt.Name)
+			assert.Equal("renamed", *opt.Path)
+
+			return &gitlab.Project{Name: "renamed"}, nil, nil
+		})
+
+	// Act
+	err := p.RenameRepo(context.Background(), token, "aserto-dev", "policy", "renamed")
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestRenameRepoNotFound(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
+
+	// Expect
+	mockIntr.EXPECT().EditProject("aserto-dev/policy", gomock.Any()).Return(nil, resp, errors.New("404 Project Not Found"))
+
+	// Act
+	err := p.RenameRepo(context.Background(), token, "aserto-dev", "policy", "renamed")
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrNotFound.SameAs(err))
+}
+
+func TestGetDefaultBranchFail(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(nil, nil, errors.New("repo not found"))
+
+	// Act
+	defaultBr, err := p.GetDefaultBranch(context.Background(), token, "aserto-dev", "policy")
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "failed to get project: aserto-dev/policy: repo not found")
+	assert.Equal(defaultBr, "")
+}
+
+func TestGetDefaultBranch(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	proj := &gitlab.Project{Name: "policy", WebURL: "gitlab.com/policy", DefaultBranch: "main"}
+
+	// Expect
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
+
+	// Act
+	defaultBr, err := p.GetDefaultBranch(context.Background(), token, "aserto-dev", "policy")
+
+	// Assert
+	assert.NoError(err)
+	assert.NotEmpty(defaultBr)
+	assert.Equal(defaultBr, "main")
+}
+
+func TestCreateRepoAndGetNamespaceFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockIntr.EXPECT().GetNamespace("aserto-dev").Return(nil, nil, errors.New("namespace not found"))
+
+	// Act
+	_, err := p.CreateRepo(context.Background(), token, "aserto-dev", "policy", nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "namespace not found")
+}
+
+func TestCreateRepoForbidden(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	namespace := &gitlab.Namespace{ID: 1001}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: http.StatusForbidden}}
+
+	// Expect
+	mockIntr.EXPECT().GetNamespace("aserto-dev").Return(namespace, nil, nil)
+	mockIntr.EXPECT().CreateProject(gomock.Any()).Return(nil, resp, errors.New("403 Forbidden"))
+
+	// Act
+	_, err := p.CreateRepo(context.Background(), token, "aserto-dev", "policy", nil)
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrForbidden.SameAs(err))
+	assert.Equal("403", cerr.UnwrapAsertoError(err).Data()[errx.StatusCodeKey])
+}
+
+func TestCreateRepoFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	namespace := &gitlab.Namespace{ID: 1001}
+
+	// Expect
+	mockIntr.EXPECT().GetNamespace("aserto-dev").Return(namespace, nil, nil)
+	mockIntr.EXPECT().CreateProject(gomock.Any()).Return(nil, nil, errors.New("failed to create repo"))
+
+	// Act
+	_, err := p.CreateRepo(context.Background(), token, "aserto-dev", "policy", nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "failed to create repo")
+}
+
+func TestCreateRepoProtectTagsFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	namespace := &gitlab.Namespace{ID: 1001}
+	createdGitlabProj := &gitlab.Project{ID: 654}
+
+	// Expect
+	mockIntr.EXPECT().GetNamespace("aserto-dev").Return(namespace, nil, nil)
+	mockIntr.EXPECT().CreateProject(gomock.Any()).Return(createdGitlabProj, nil, nil)
+	mockIntr.EXPECT().ProtectRepositoryTags(gomock.Any(), gomock.Any()).Return(errors.New("failed to protct tags"))
+
+	// Act
+	_, err := p.CreateRepo(context.Background(), token, "aserto-dev", "policy", nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "failed to protct tags")
+}
+
+func TestCreateRepo(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	namespace := &gitlab.Namespace{ID: 1001}
+	createdGitlabProj := &gitlab.Project{ID: 654, Name: "policy", WebURL: "gitlab.com/policy"}
+
+	// Expect
+	mockIntr.EXPECT().GetNamespace("aserto-dev").Return(namespace, nil, nil)
+	mockIntr.EXPECT().CreateProject(gomock.Any()).Return(createdGitlabProj, nil, nil)
+	mockIntr.EXPECT().ProtectRepositoryTags(gomock.Any(), gomock.Any()).Return(nil)
+
+	// Act
+	repo, err := p.CreateRepo(context.Background(), token, "aserto-dev", "policy", nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("policy", repo.Name)
+	assert.Equal("aserto-dev", repo.Org)
+	assert.Equal("gitlab.com/policy/-/pipelines", repo.CiUrl)
+}
+
+func TestCreateRepoDefaultTagProtection(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	namespace := &gitlab.Namespace{ID: 1001}
+	createdGitlabProj := &gitlab.Project{ID: 654, Name: "policy", WebURL: "gitlab.com/policy"}
+
+	// Expect
+	mockIntr.EXPECT().GetNamespace("aserto-dev").Return(namespace, nil, nil)
+	mockIntr.EXPECT().CreateProject(gomock.Any()).Return(createdGitlabProj, nil, nil)
+	mockIntr.EXPECT().ProtectRepositoryTags(654, &gitlab.ProtectRepositoryTagsOptions{
+		Name:              ptr.To("v*"),
+		CreateAccessLevel: ptr.To(gitlab.MaintainerPermissions),
+	}).Return(nil)
+
+	// Act
+	_, err := p.CreateRepo(context.Background(), token, "aserto-dev", "policy", &sources.CreateRepoOptions{})
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestCreateRepoCustomTagProtection(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	namespace := &gitlab.Namespace{ID: 1001}
+	createdGitlabProj := &gitlab.Project{ID: 654, Name: "policy", WebURL: "gitlab.com/policy"}
+
+	// Expect
+	mockIntr.EXPECT().GetNamespace("aserto-dev").Return(namespace, nil, nil)
+	mockIntr.EXPECT().CreateProject(gomock.Any()).Return(createdGitlabProj, nil, nil)
+	mockIntr.EXPECT().ProtectRepositoryTags(654, &gitlab.ProtectRepositoryTagsOptions{
+		Name:              ptr.To("release-*"),
+		CreateAccessLevel: ptr.To(gitlab.NoPermissions),
+	}).Return(nil)
+
+	// Act
+	_, err := p.CreateRepo(context.Background(), token, "aserto-dev", "policy", &sources.CreateRepoOptions{
+		TagProtection: &sources.TagProtectionOptions{Pattern: "release-*", CreateAccessLevel: ptr.To(0)},
+	})
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestCreateRepoTagProtectionDisabled(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	namespace := &gitlab.Namespace{ID: 1001}
+	createdGitlabProj := &gitlab.Project{ID: 654, Name: "policy", WebURL: "gitlab.com/policy"}
+
+	// Expect
+	mockIntr.EXPECT().GetNamespace("aserto-dev").Return(namespace, nil, nil)
+	mockIntr.EXPECT().CreateProject(gomock.Any()).Return(createdGitlabProj, nil, nil)
+
+	// Act
+	_, err := p.CreateRepo(context.Background(), token, "aserto-dev", "policy", &sources.CreateRepoOptions{
+		TagProtection: &sources.TagProtectionOptions{Disabled: true},
+	})
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestInitialTagWithWrongFullName(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Act
+	_, err := p.InitialTag(context.Background(), token, "aserto-dev", "", "", "")
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "invalid full gitlab repo name 'aserto-dev', should be in the form owner/repo")
+}
+
+func TestInitialTagWithRepoAlreadyTagged(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "dsfcds"}
+	tags := []string{"v0.0.0"}
+	proj := &gitlab.Project{Name: "policy", WebURL: "gitlab.com/policy", TagList: tags}
+
+	// Expect
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
+
+	// Act
+	_, err := p.InitialTag(context.Background(), token, "aserto-dev/policy", "", "", "")
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestInitialTagOnEmptyRepo(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "dsfcds"}
+	proj := &gitlab.Project{ID: 1001, Name: "policy", WebURL: "gitlab.com/policy", EmptyRepo: true}
+
+	// Expect
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
+
+	// Act
+	_, err := p.InitialTag(context.Background(), token, "aserto-dev/policy", "", "", "")
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrEmptyRepo.SameAs(err))
+}
+
+func TestInitialTagFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "dsfcds"}
+	tags := []string{}
+	proj := &gitlab.Project{ID: 1001, Name: "policy", WebURL: "gitlab.com/policy", TagList: tags}
+
+	// Expect
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
+	mockIntr.EXPECT().CreateTag(gomock.Any(), gomock.Any()).Return(errors.New("failed to create tag"))
+
+	// Act
+	_, err := p.InitialTag(context.Background(), token, "aserto-dev/policy", "", "", "")
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "failed to create tag")
+}
+
+func TestInitialTag(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "dsfcds"}
+	tags := []string{}
+	proj := &gitlab.Project{ID: 1001, Name: "policy", WebURL: "gitlab.com/policy", TagList: tags}
+
+	// Expect
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
+	mockIntr.EXPECT().CreateTag(gomock.Any(), gomock.Any()).Return(nil)
+	mockIntr.EXPECT().ListProjectPipelines(1001, gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.ListProjectPipelinesOptions) ([]*gitlab.PipelineInfo, *gitlab.Response, error) {
+			assert.Equal(*sources.DefaultTag(), *opt.Ref)
+			return []*gitlab.PipelineInfo{{ID: 77}}, nil, nil
+		})
+
+	// Act
+	pipelineID, err := p.InitialTag(context.Background(), token, "aserto-dev/policy", "", "", "")
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(int64(77), pipelineID)
+}
+
+func TestInitialTagWithMessage(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "dsfcds"}
+	proj := &gitlab.Project{ID: 1001, Name: "policy", WebURL: "gitlab.com/policy"}
+
+	// Expect
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
+	mockIntr.EXPECT().CreateTag(1001, gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.CreateTagOptions) error {
+			assert.Equal(*sources.DefaultTag(), *opt.TagName)
+			assert.Equal("first release", *opt.Message)
+			return nil
+		})
+	mockIntr.EXPECT().ListProjectPipelines(1001, gomock.Any()).Return([]*gitlab.PipelineInfo{{ID: 77}}, nil, nil)
+
+	// Act
+	_, err := p.InitialTag(context.Background(), token, "aserto-dev/policy", "", "", "first release")
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestInitialTagTriggersPipeline(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{GitlabTriggerTagPipeline: true}, mockintrFunc)
+	token := &sources.AccessToken{Token: "dsfcds"}
+	proj := &gitlab.Project{ID: 1001, Name: "policy", WebURL: "gitlab.com/policy"}
+	tag := *sources.DefaultTag()
+
+	// Expect
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
+	mockIntr.EXPECT().CreateTag(1001, gomock.Any()).Return(nil)
+	mockIntr.EXPECT().ListProjectPipelines(1001, gomock.Any()).Return(nil, nil, nil)
+	mockIntr.EXPECT().CreatePipeline(1001, &gitlab.CreatePipelineOptions{Ref: &tag}).Return(&gitlab.Pipeline{ID: 88}, nil, nil)
+
+	// Act
+	pipelineID, err := p.InitialTag(context.Background(), token, "aserto-dev/policy", "", "", "")
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(int64(88), pipelineID)
+}
+
+func TestInitialTagDoesNotTriggerPipelineByDefault(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "dsfcds"}
+	proj := &gitlab.Project{ID: 1001, Name: "policy", WebURL: "gitlab.com/policy"}
+
+	// Expect
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
+	mockIntr.EXPECT().CreateTag(1001, gomock.Any()).Return(nil)
+	mockIntr.EXPECT().ListProjectPipelines(1001, gomock.Any()).Return(nil, nil, nil)
+
+	// Act
+	pipelineID, err := p.InitialTag(context.Background(), token, "aserto-dev/policy", "", "", "")
+
+	// Assert
+	assert.NoError(err)
+	assert.Zero(pipelineID)
+}
+
+func TestTriggerBuild(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockIntr.EXPECT().CreatePipeline("aserto-dev/policy", &gitlab.CreatePipelineOptions{Ref: ptr.To("v1.2.3")}).
+		Return(&gitlab.Pipeline{ID: 42}, nil, nil)
+
+	// Act
+	err := p.(sources.BuildTrigger).TriggerBuild(context.Background(), token, "aserto-dev", "policy", "v1.2.3", "")
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestCreateWebhook(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	hook := &sources.Webhook{URL: "https://example.com/hooks", Secret: "somehooksecret", Events: []string{"tag_push"}}
+
+	// Expect
+	mockIntr.EXPECT().
+		AddProjectHook("aserto-dev/policy", &gitlab.AddProjectHookOptions{
+			URL:                   ptr.To("https://example.com/hooks"),
+			Token:                 ptr.To("somehooksecret"),
+			PushEvents:            ptr.To(false),
+			TagPushEvents:         ptr.To(true),
+			EnableSSLVerification: ptr.To(true),
+		}).
+		Return(&gitlab.ProjectHook{ID: 42}, nil, nil)
+
+	// Act
+	id, err := p.(sources.WebhookSource).CreateWebhook(context.Background(), token, "aserto-dev", "policy", hook)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("42", id)
+}
+
+func TestCreateWebhookUnsupportedEvent(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	hook := &sources.Webhook{URL: "https://example.com/hooks", Events: []string{"pull_request"}}
+
+	// Act
+	_, err := p.(sources.WebhookSource).CreateWebhook(context.Background(), token, "aserto-dev", "policy", hook)
+
+	// Assert
+	assert.Error(err)
+	assert.Contains(err.Error(), "unsupported Gitlab webhook event: pull_request")
+}
+
+func TestListWebhooks(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	hook := &gitlab.ProjectHook{ID: 42, URL: "https://example.com/hooks", PushEvents: true, PipelineEvents: true}
+
+	// Expect
+	mockIntr.EXPECT().
+		ListProjectHooks("aserto-dev/policy", gomock.Any()).
+		Return([]*gitlab.ProjectHook{hook}, &gitlab.Response{}, nil)
+
+	// Act
+	hooks, err := p.(sources.WebhookSource).ListWebhooks(context.Background(), token, "aserto-dev", "policy")
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal([]*sources.Webhook{{ID: "42", URL: "https://example.com/hooks", Events: []string{"push", "pipeline"}, ContentType: "json"}}, hooks)
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockIntr.EXPECT().DeleteProjectHook("aserto-dev/policy", 42).Return(nil, nil)
+
+	// Act
+	err := p.(sources.WebhookSource).DeleteWebhook(context.Background(), token, "aserto-dev", "policy", "42")
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestAddDeployKey(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockIntr.EXPECT().
+		AddDeployKey("aserto-dev/policy", &gitlab.AddDeployKeyOptions{
+			Title:   ptr.To("aserto"),
+			Key:     ptr.To("ssh-ed25519 AAAA"),
+			CanPush: ptr.To(false),
+		}).
+		Return(&gitlab.ProjectDeployKey{ID: 7}, nil, nil)
+
+	// Act
+	id, err := p.(sources.DeployKeySource).AddDeployKey(context.Background(), token, "aserto-dev", "policy", "aserto", "ssh-ed25519 AAAA", true)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("7", id)
+}
+
+func TestHasDeployKeyFalse(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockIntr.EXPECT().
+		ListProjectDeployKeys("aserto-dev/policy", gomock.Any()).
+		Return([]*gitlab.ProjectDeployKey{{ID: 7, Title: "other"}}, &gitlab.Response{}, nil)
+
+	// Act
+	exists, err := p.(sources.DeployKeySource).HasDeployKey(context.Background(), token, "aserto-dev", "policy", "aserto")
+
+	// Assert
+	assert.NoError(err)
+	assert.False(exists)
+}
+
+func TestDeleteDeployKeyNotFound(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
+
+	// Expect
+	mockIntr.EXPECT().DeleteDeployKey("aserto-dev/policy", 7).Return(resp, errors.New("404 Not Found"))
+
+	// Act
+	err := p.(sources.DeployKeySource).DeleteDeployKey(context.Background(), token, "aserto-dev", "policy", "7")
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrNotFound.SameAs(err))
+}
+
+func TestGetBranchHead(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	branch := &gitlab.Branch{Name: "main", Commit: &gitlab.Commit{ID: "abc123"}}
+
+	// Expect
+	mockIntr.EXPECT().GetBranch("aserto-dev/policy", "main").Return(branch, nil, nil)
+
+	// Act
+	sha, err := p.(sources.BranchSource).GetBranchHead(context.Background(), token, "aserto-dev", "policy", "main")
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal("abc123", sha)
+}
+
+func TestGetBranchHeadNotFound(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
+
+	// Expect
+	mockIntr.EXPECT().GetBranch("aserto-dev/policy", "missing").Return(nil, resp, errors.New("404 Branch Not Found"))
+
+	// Act
+	_, err := p.(sources.BranchSource).GetBranchHead(context.Background(), token, "aserto-dev", "policy", "missing")
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrNotFound.SameAs(err))
+}
+
+func TestListPullRequestsOpen(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	mergeRequest := &gitlab.MergeRequest{
+		IID:          3,
+		Title:        "Update policy",
+		WebURL:       "https://gitlab.com/aserto-dev/policy/-/merge_requests/3",
+		SourceBranch: "update",
+		TargetBranch: "main",
+		State:        "opened",
+	}
+
+	// Expect
+	mockIntr.EXPECT().ListProjectMergeRequests("aserto-dev/policy", gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.ListProjectMergeRequestsOptions) ([]*gitlab.MergeRequest, *gitlab.Response, error) {
+			assert.NotNil(opt.State)
+			assert.Equal("opened", *opt.State)
+			return []*gitlab.MergeRequest{mergeRequest}, &gitlab.Response{}, nil
+		})
+
+	// Act
+	pulls, err := p.(sources.PullRequestSource).ListPullRequests(context.Background(), token, "aserto-dev", "policy", "open")
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal([]*sources.PullRequest{{
+		Number: 3,
+		Title:  "Update policy",
+		URL:    "https://gitlab.com/aserto-dev/policy/-/merge_requests/3",
+		Head:   "update",
+		Base:   "main",
+		State:  "open",
+	}}, pulls)
+}
+
+func TestListPullRequestsClosedIncludesMerged(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	mergeRequests := []*gitlab.MergeRequest{
+		{IID: 1, State: "opened"},
+		{IID: 2, State: "closed"},
+		{IID: 3, State: "merged"},
+	}
+
+	// Expect
+	mockIntr.EXPECT().ListProjectMergeRequests("aserto-dev/policy", gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.ListProjectMergeRequestsOptions) ([]*gitlab.MergeRequest, *gitlab.Response, error) {
+			assert.Nil(opt.State)
+			return mergeRequests, &gitlab.Response{}, nil
+		})
+
+	// Act
+	pulls, err := p.(sources.PullRequestSource).ListPullRequests(context.Background(), token, "aserto-dev", "policy", "closed")
+
+	// Assert
+	assert.NoError(err)
+	assert.Len(pulls, 2)
+	assert.Equal(2, pulls[0].Number)
+	assert.Equal(3, pulls[1].Number)
+}
+
+func TestGetOrg(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	group := &gitlab.Group{
+		Name:        "Aserto",
+		FullPath:    "aserto-dev",
+		Description: "Authorization for everyone",
+		AvatarURL:   "https://gitlab.com/uploads/aserto.png",
+		WebURL:      "https://gitlab.com/groups/aserto-dev",
+	}
+
+	// Expect
+	mockIntr.EXPECT().GetGroup("aserto-dev").Return(group, nil)
+
+	// Act
+	detail, err := p.(sources.OrgSource).GetOrg(context.Background(), token, "aserto-dev")
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(&sources.SccOrgDetail{
+		ID:          "aserto-dev",
+		Name:        "Aserto",
+		Description: "Authorization for everyone",
+		AvatarURL:   "https://gitlab.com/uploads/aserto.png",
+		URL:         "https://gitlab.com/groups/aserto-dev",
+	}, detail)
+}
+
+func TestGetOrgNotFound(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	notFound := &gitlab.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusNotFound, Request: &http.Request{Method: http.MethodGet, URL: &url.URL{}}},
+		Message:  "404 Group Not Found",
+	}
+
+	// Expect
+	mockIntr.EXPECT().GetGroup("missing").Return(nil, notFound)
+
+	// Act
+	_, err := p.(sources.OrgSource).GetOrg(context.Background(), token, "missing")
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrNotFound.SameAs(err))
+}
+
+func TestHasSecretFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockIntr.EXPECT().
+		GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY", "*").
+		Return(nil, nil, errors.New("failed to connect to gitlab"))
+
+	// Act
+	secretExists, err := p.HasSecret(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY", nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "failed to connect to gitlab")
+	assert.False(secretExists)
+}
+
+func TestHasSecretNotFound(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: 404}}
+
+	// Expect
+	mockIntr.EXPECT().
+		GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY", "*").
+		Return(nil, resp, errors.New("failed to connect to gitlab"))
+
+	// Act
+	secretExists, err := p.HasSecret(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY", nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.False(secretExists)
+}
+
+func TestHasSecretForbidden(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: http.StatusForbidden}}
+
+	// Expect
+	mockIntr.EXPECT().
+		GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY", "*").
+		Return(nil, resp, errors.New("403 Forbidden"))
+
+	// Act
+	secretExists, err := p.HasSecret(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY", nil)
+
+	// Assert
+	assert.Error(err)
+	assert.True(errx.ErrForbidden.SameAs(err))
+	assert.False(secretExists)
+}
+
+func TestHasSecret(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	variable := &gitlab.ProjectVariable{}
+
+	// Expect
+	mockIntr.EXPECT().
+		GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY", "*").
+		Return(variable, nil, nil)
+
+	// Act
+	secretExists, err := p.HasSecret(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY", nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.True(secretExists)
+}
+
+func TestGetSecret(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockIntr.EXPECT().
+		GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY", "*").
+		Return(&gitlab.ProjectVariable{Key: "ASERTO_PUSH_KEY", Value: "somesecretvalue"}, nil, nil)
+
+	// Act
+	value, exists, err := p.GetSecret(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY")
+
+	// Assert
+	assert.NoError(err)
+	assert.True(exists)
+	assert.Equal("somesecretvalue", value)
+}
+
+func TestGetSecretNotFound(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
+
+	// Expect
+	mockIntr.EXPECT().
+		GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY", "*").
+		Return(nil, resp, errors.New("404 Not Found"))
+
+	// Act
+	value, exists, err := p.GetSecret(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY")
+
+	// Assert
+	assert.NoError(err)
+	assert.False(exists)
+	assert.Empty(value)
+}
+
+func TestHasSecretInEnvironment(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: 404}}
+
+	// Expect
+	mockIntr.EXPECT().
+		GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY", "staging").
+		Return(nil, resp, errors.New("404 Variable Not Found"))
+
+	// Act
+	secretExists, err := p.HasSecret(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY", &sources.SecretOptions{EnvironmentScope: "staging"})
+
+	// Assert
+	assert.NoError(err)
+	assert.False(secretExists)
+}
+
+func TestAddSecretToRepoFails(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+
+	// Expect
+	mockIntr.EXPECT().
+		GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY", "*").
+		Return(nil, nil, errors.New("failed to connect to gitlab"))
+
+	// Act
+	err := p.AddSecretToRepo(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY", "value", false, nil)
+
+	// Assert
+	assert.Error(err)
+	assert.Equal(err.Error(), "failed to connect to gitlab")
+}
+
+func TestAddSecretToRepoOverwriteSecretFalse(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	variable := &gitlab.ProjectVariable{}
+
+	// Expect
+	mockIntr.EXPECT().GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY", "*").Return(variable, nil, nil)
 
 	// Act
-	err := p.ValidateConnection(context.Background(), token, []string{})
+	err := p.AddSecretToRepo(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY", "value", false, nil)
 
 	// Assert
 	assert.Error(err)
-	assert.Contains(err.Error(), "failed to create Gitlab client: Kaboom")
+	assert.Equal(err.Error(), "E10022 repo has already been connected to a policy: you're trying to link to an existing repository that already has a secret. Please consider overwriting the Aserto push secret.")
 }
 
-func TestValidateConnectionDoesntConnect(t *testing.T) {
+func TestAddSecretToRepoOverwriteSecretTrue(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
+	variable := &gitlab.ProjectVariable{}
 
 	// Expect
-	mockIntr.EXPECT().CurrentUser().Return(nil, nil, errors.New("no Connection"))
+	mockIntr.EXPECT().GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY", "*").Return(variable, nil, nil)
+	mockIntr.EXPECT().UpdateProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY", "*", gomock.Any()).Return(nil, nil)
 
 	// Act
-	err := p.ValidateConnection(context.Background(), token, []string{})
+	err := p.AddSecretToRepo(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY", "value", true, nil)
 
 	// Assert
-	assert.Error(err)
-	assert.Contains(err.Error(), "failed to connect to Gitlab: no Connection")
+	assert.NoError(err)
 }
 
-func TestValidateConnection(t *testing.T) {
+func TestAddSecretToRepoOverwriteSecretInEnvironment(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	resp := &gitlab.Response{Response: &http.Response{StatusCode: 200}}
+	variable := &gitlab.ProjectVariable{EnvironmentScope: "staging"}
+	opts := &sources.SecretOptions{Masked: true, Protected: true, EnvironmentScope: "staging"}
 
 	// Expect
-	mockIntr.EXPECT().CurrentUser().Return(nil, resp, nil)
+	mockIntr.EXPECT().GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY", "staging").Return(variable, nil, nil)
+	mockIntr.EXPECT().UpdateProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY", "staging", gomock.Any()).
+		DoAndReturn(func(_ interface{}, _, _ string, opt *gitlab.UpdateProjectVariableOptions) (*gitlab.Response, error) {
+			assert.Equal("staging", *opt.EnvironmentScope)
+
+			return nil, nil
+		})
 
 	// Act
-	err := p.ValidateConnection(context.Background(), token, []string{})
+	err := p.AddSecretToRepo(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY", "value", true, opts)
 
 	// Assert
 	assert.NoError(err)
 }
 
-func TestValidateConnectionErrorResponse(t *testing.T) {
+func TestAddSecretToRepoNewVariable(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	body := io.NopCloser(strings.NewReader("this is the body"))
-	resp := &gitlab.Response{Response: &http.Response{StatusCode: 404, Status: "Not Found", Body: body}}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: 404}}
 
 	// Expect
-	mockIntr.EXPECT().CurrentUser().Return(nil, resp, nil)
+	mockIntr.EXPECT().GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY", "*").Return(nil, resp, nil)
+	mockIntr.EXPECT().CreateProjectVariable("aserto-dev/policy", gomock.Any()).Return(nil, nil)
 
 	// Act
-	err := p.ValidateConnection(context.Background(), token, []string{})
+	err := p.AddSecretToRepo(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY", "value", false, nil)
 
 	// Assert
-	assert.Error(err)
-	assertoErr := cerr.UnwrapAsertoError(err)
-	assert.Contains(assertoErr.Data()["msg"], "unexpected reply from Gitlab")
+	assert.NoError(err)
 }
 
-func TestProfileConnectionWithEmptyToken(t *testing.T) {
+func TestAddSecretToReposReportsEachRepo(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
-	mockintr := newMockIntrFunc(ctrl)
-	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintr)
-	token := &sources.AccessToken{Token: ""}
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: 404}}
+
+	// Expect
+	mockIntr.EXPECT().
+		GetProjectVariable("aserto-dev/policy-a", "ASERTO_PUSH_KEY", "*").
+		Return(&gitlab.ProjectVariable{Key: "ASERTO_PUSH_KEY"}, nil, nil)
+	mockIntr.EXPECT().GetProjectVariable("aserto-dev/policy-b", "ASERTO_PUSH_KEY", "*").Return(nil, resp, nil)
+	mockIntr.EXPECT().CreateProjectVariable("aserto-dev/policy-b", gomock.Any()).Return(nil, nil)
 
 	// Act
-	_, _, err := p.Profile(context.Background(), token)
+	results, err := p.(sources.BulkSecretSource).AddSecretToRepos(context.Background(), token, "aserto-dev", []string{"policy-a", "policy-b"}, "ASERTO_PUSH_KEY", "value", false)
 
 	// Assert
-	assert.Error(err)
-	assert.Contains(err.Error(), "failed to create Gitlab client: Kaboom")
+	assert.NoError(err)
+	assert.Len(results, 2)
+	assert.True(errx.ErrRepoAlreadyConnected.SameAs(results["policy-a"]))
+	assert.NoError(results["policy-b"])
 }
 
-func TestProfileDoesntConnect(t *testing.T) {
+func TestAddSecretToRepoWithOptions(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: 404}}
+	opts := &sources.SecretOptions{EnvironmentScope: "production"}
 
 	// Expect
-	mockIntr.EXPECT().CurrentUser().Return(nil, nil, errors.New("no Connection"))
+	mockIntr.EXPECT().GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY", "production").Return(nil, resp, nil)
+	mockIntr.EXPECT().CreateProjectVariable("aserto-dev/policy", gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.CreateProjectVariableOptions) (*gitlab.Response, error) {
+			assert.False(*opt.Masked)
+			assert.False(*opt.Protected)
+			assert.Equal("production", *opt.EnvironmentScope)
+
+			return nil, nil
+		})
 
 	// Act
-	_, _, err := p.Profile(context.Background(), token)
+	err := p.AddSecretToRepo(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY", "value", false, opts)
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestAddSecretToRepoMaskingRejected(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	notFound := &gitlab.Response{Response: &http.Response{StatusCode: 404}}
+	badRequest := &gitlab.Response{Response: &http.Response{StatusCode: 400}}
+
+	// Expect
+	mockIntr.EXPECT().GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY", "*").Return(nil, notFound, nil)
+	mockIntr.EXPECT().CreateProjectVariable("aserto-dev/policy", gomock.Any()).
+		Return(badRequest, errors.New("{value: [is invalid]}"))
+
+	// Act
+	err := p.AddSecretToRepo(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY", "short", false, nil)
 
 	// Assert
 	assert.Error(err)
-	assert.Contains(err.Error(), "no Connection")
+	assert.Contains(err.Error(), "gitlab can't mask the secret value")
 }
 
-func TestProfileReadOnePage(t *testing.T) {
+func TestCommitOnBranchFails(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	var projects []*gitlab.Project
-	gitlabUser := &gitlab.User{Username: "aserto-tests"}
-	projects = append(projects, &gitlab.Project{Name: "template-policy", Owner: gitlabUser, WebURL: "gitlab.com/template-policy"})
-	resp := &gitlab.Response{NextPage: 0}
+	content := make(map[string]string)
+	content[file] = fileContent
+	commit := sources.Commit{
+		Branch:  "main",
+		Message: "Some commit",
+		Owner:   "aserto-dev",
+		Repo:    repo,
+		Content: content,
+	}
+
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
 
 	// Expect
-	mockIntr.EXPECT().CurrentUser().Return(gitlabUser, nil, nil)
-	mockIntr.EXPECT().ListUserProjects("aserto-tests", gomock.Any()).Return(projects, resp, nil)
+	mockIntr.EXPECT().GetProjectFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, resp, errors.New("404 File Not Found"))
+	mockIntr.EXPECT().CreateCommit(gomock.Any(), gomock.Any()).Return("", errors.New("failed to create commit"))
 
 	// Act
-	username, repos, err := p.Profile(context.Background(), token)
+	_, err := p.CreateCommitOnBranch(context.Background(), token, &commit)
 
 	// Assert
-	assert.NoError(err)
-	assert.Equal(username, gitlabUser.Username)
-	assert.Equal(len(repos), 1)
-	assert.Equal(repos[0].Name, "template-policy")
-	assert.Equal(repos[0].CiUrl, "gitlab.com/template-policy/-/pipelines")
+	assert.Error(err)
+	assert.Equal(err.Error(), "failed to create commit")
 }
 
-func TestProfileReadTwoPages(t *testing.T) {
+func TestCommitOnBranch(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	var projects []*gitlab.Project
-	var projectsSecondPage []*gitlab.Project
-	gitlabUser := &gitlab.User{Username: "aserto-tests"}
-	projects = append(projects, &gitlab.Project{Name: "template-policy", Owner: gitlabUser, WebURL: "gitlab.com/template-policy"})
-	projectsSecondPage = append(projectsSecondPage, &gitlab.Project{Name: "template-policy2", Owner: nil, WebURL: "gitlab.com/template-policy2"})
-	resp := &gitlab.Response{NextPage: 1}
-	resp2 := &gitlab.Response{NextPage: 0}
+	content := make(map[string]string)
+	content[file] = fileContent
+	commit := sources.Commit{
+		Branch:  "main",
+		Message: "Some commit",
+		Owner:   "aserto-dev",
+		Repo:    repo,
+		Content: content,
+	}
+	returnedSha := "sha256"
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
 
 	// Expect
-	mockIntr.EXPECT().CurrentUser().Return(gitlabUser, nil, nil)
-	mockIntr.EXPECT().ListUserProjects("aserto-tests", gomock.Any()).Return(projects, resp, nil).Times(1)
-	mockIntr.EXPECT().ListUserProjects("aserto-tests", gomock.Any()).Return(projectsSecondPage, resp2, nil).Times(1)
+	mockIntr.EXPECT().GetProjectFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, resp, errors.New("404 File Not Found"))
+	mockIntr.EXPECT().CreateCommit(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.CreateCommitOptions) (string, error) {
+			assert.Equal(gitlab.FileCreate, *opt.Actions[0].Action)
+			return returnedSha, nil
+		})
 
 	// Act
-	username, repos, err := p.Profile(context.Background(), token)
+	commitSha, err := p.CreateCommitOnBranch(context.Background(), token, &commit)
 
 	// Assert
 	assert.NoError(err)
-	assert.Equal(username, gitlabUser.Username)
-	assert.Equal(1, len(repos))
-	assert.Equal(repos[0].Name, "template-policy")
+	assert.Equal(returnedSha, commitSha)
 }
 
-func TestListOrgsWithNilPage(t *testing.T) {
+func TestCommitOnBranchWithoutContent(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
+	commit := sources.Commit{
+		Branch:  "main",
+		Message: "Some commit",
+		Owner:   "aserto-dev",
+		Repo:    repo,
+		Content: map[string]string{},
+	}
+
 	// Act
-	_, _, err := p.ListOrgs(context.Background(), token, nil)
+	_, err := p.CreateCommitOnBranch(context.Background(), token, &commit)
 
 	// Assert
 	assert.Error(err)
-	assert.Contains(err.Error(), "page must not be empty")
+	assert.Equal("commit must contain at least one file", err.Error())
 }
 
-func TestListOrgsWithInvalidPageSize(t *testing.T) {
+func TestCommitOnBranchRefusesRebase(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	page := &api.PaginationRequest{Size: -2}
+	commit := sources.Commit{
+		Branch:           "main",
+		Message:          "Some commit",
+		Owner:            "aserto-dev",
+		Repo:             repo,
+		Content:          map[string]string{".manifest": "content"},
+		ConflictStrategy: sources.ConflictRebase,
+	}
+
 	// Act
-	_, _, err := p.ListOrgs(context.Background(), token, page)
+	_, err := p.CreateCommitOnBranch(context.Background(), token, &commit)
 
 	// Assert
 	assert.Error(err)
-	assert.Contains(err.Error(), "page size must be >= -1 and <= 100")
+	assert.Equal("gitlab doesn't support the rebase conflict strategy", err.Error())
 }
 
-func TestListOrgsWithStringPageToken(t *testing.T) {
+func TestCommitOnBranchSkipsUnchangedFiles(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	page := &api.PaginationRequest{Size: -1, Token: "next_token"}
+	commit := sources.Commit{
+		Branch:  "main",
+		Message: "Some commit",
+		Owner:   "aserto-dev",
+		Repo:    repo,
+		Content: map[string]string{file: fileContent, "README.md": "new readme"},
+	}
+	unchanged := &gitlab.File{Encoding: "base64", Content: base64.StdEncoding.EncodeToString([]byte(fileContent))}
+	changed := &gitlab.File{Encoding: "base64", Content: base64.StdEncoding.EncodeToString([]byte("old readme"))}
+
+	// Expect
+	mockIntr.EXPECT().GetProjectFile("aserto-dev/"+repo, file, gomock.Any()).Return(unchanged, nil, nil)
+	mockIntr.EXPECT().GetProjectFile("aserto-dev/"+repo, "README.md", gomock.Any()).Return(changed, nil, nil)
+	mockIntr.EXPECT().CreateCommit(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.CreateCommitOptions) (string, error) {
+			assert.Len(opt.Actions, 1)
+			assert.Equal("README.md", *opt.Actions[0].FilePath)
+			return "sha256", nil
+		})
+
 	// Act
-	_, _, err := p.ListOrgs(context.Background(), token, page)
+	commitSha, err := p.CreateCommitOnBranch(context.Background(), token, &commit)
 
 	// Assert
-	assert.Error(err)
-	assert.Contains(err.Error(), "page token must be int: strconv.Atoi: parsing \"next_token\":")
+	assert.NoError(err)
+	assert.Equal("sha256", commitSha)
 }
 
-func TestListOrgsAllInOnePage(t *testing.T) {
+func TestCommitOnBranchUnchangedReturnsHead(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	page := &api.PaginationRequest{Size: -1, Token: ""}
-	var groups []*gitlab.Group
-	groups = append(groups, &gitlab.Group{Name: "tests", FullPath: "test7929"})
-	resp := &gitlab.Response{NextPage: 0, TotalItems: 1}
+	commit := sources.Commit{
+		Branch:  "main",
+		Message: "Some commit",
+		Owner:   "aserto-dev",
+		Repo:    repo,
+		Content: map[string]string{file: fileContent},
+	}
+	unchanged := &gitlab.File{Encoding: "base64", Content: base64.StdEncoding.EncodeToString([]byte(fileContent))}
+	branch := &gitlab.Branch{Name: "main", Commit: &gitlab.Commit{ID: "headsha"}}
 
 	// Expect
-	mockIntr.EXPECT().ListGroups(gomock.Any()).Return(groups, resp, nil)
+	mockIntr.EXPECT().GetProjectFile("aserto-dev/"+repo, file, gomock.Any()).Return(unchanged, nil, nil)
+	mockIntr.EXPECT().GetBranch("aserto-dev/"+repo, "main").Return(branch, nil, nil)
 
 	// Act
-	orgs, pageResp, err := p.ListOrgs(context.Background(), token, page)
+	commitSha, err := p.CreateCommitOnBranch(context.Background(), token, &commit)
 
 	// Assert
 	assert.NoError(err)
-	assert.Equal(pageResp.ResultSize, pageResp.TotalSize)
-	assert.Equal(1, len(orgs))
-	assert.Equal("test7929", orgs[0].Id)
+	assert.Equal("headsha", commitSha)
 }
 
-func TestListOrgsWithTwoPages(t *testing.T) {
+func TestCommitOnNewBranch(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	page := &api.PaginationRequest{Size: -1, Token: ""}
-	var groups []*gitlab.Group
-	var groupsSecondPage []*gitlab.Group
-	groups = append(groups, &gitlab.Group{Name: "tests", Path: "test7929"})
-	groupsSecondPage = append(groupsSecondPage, &gitlab.Group{Name: "aserto-demo", Path: "aserto-demmo"})
-	resp := &gitlab.Response{NextPage: 1, ItemsPerPage: 1, TotalItems: 2}
-	resp2 := &gitlab.Response{NextPage: 0, ItemsPerPage: 1, TotalItems: 2}
+	commit := sources.Commit{
+		Branch:      "feature",
+		StartBranch: "main",
+		Message:     "Some commit",
+		Owner:       "aserto-dev",
+		Repo:        repo,
+		Content:     map[string]string{file: fileContent},
+	}
+	notFound := &gitlab.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
 
 	// Expect
-	mockIntr.EXPECT().ListGroups(gomock.Any()).Return(groups, resp, nil)
-	mockIntr.EXPECT().ListGroups(gomock.Any()).Return(groupsSecondPage, resp2, nil)
+	mockIntr.EXPECT().GetBranch("aserto-dev/"+repo, "feature").Return(nil, notFound, errors.New("404 Branch Not Found"))
+	mockIntr.EXPECT().GetProjectFile("aserto-dev/"+repo, file, gomock.Any()).
+		DoAndReturn(func(_ interface{}, _ string, opt *gitlab.GetFileOptions) (*gitlab.File, *gitlab.Response, error) {
+			assert.Equal("main", *opt.Ref)
+			return &gitlab.File{}, nil, nil
+		})
+	mockIntr.EXPECT().CreateCommit(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.CreateCommitOptions) (string, error) {
+			assert.Equal("feature", *opt.Branch)
+			assert.NotNil(opt.StartBranch)
+			assert.Equal("main", *opt.StartBranch)
+			assert.Equal(gitlab.FileUpdate, *opt.Actions[0].Action)
+			return "sha256", nil
+		})
 
 	// Act
-	orgs, pageResp, err := p.ListOrgs(context.Background(), token, page)
+	_, err := p.CreateCommitOnBranch(context.Background(), token, &commit)
 
 	// Assert
 	assert.NoError(err)
-	assert.Equal(pageResp.ResultSize, pageResp.TotalSize)
-	assert.Equal(2, len(orgs))
 }
 
-func TestListReposWithNilPage(t *testing.T) {
+func TestCommitViaMergeRequest(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	commit := sources.Commit{
+		Branch:  "feature",
+		Message: "Update the policy\n\nWith more rules.",
+		Owner:   "aserto-dev",
+		Repo:    repo,
+		Content: map[string]string{file: fileContent},
+	}
+	notFound := &gitlab.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
+	proj := &gitlab.Project{Name: repo, WebURL: "https://gitlab.com/aserto-dev/" + repo, DefaultBranch: "main"}
+	mrURL := "https://gitlab.com/aserto-dev/" + repo + "/-/merge_requests/7"
+
+	// Expect
+	mockIntr.EXPECT().GetProject("aserto-dev/"+repo).Return(proj, nil, nil)
+	mockIntr.EXPECT().GetBranch("aserto-dev/"+repo, "feature").Return(nil, notFound, errors.New("404 Branch Not Found"))
+	mockIntr.EXPECT().GetProjectFile("aserto-dev/"+repo, file, gomock.Any()).Return(nil, notFound, errors.New("404 File Not Found"))
+	mockIntr.EXPECT().CreateCommit(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.CreateCommitOptions) (string, error) {
+			assert.Equal("feature", *opt.Branch)
+			assert.Equal("main", *opt.StartBranch)
+			return "sha256", nil
+		})
+	mockIntr.EXPECT().CreateMergeRequest("aserto-dev/"+repo, gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.CreateMergeRequestOptions) (*gitlab.MergeRequest, *gitlab.Response, error) {
+			assert.Equal("Update the policy", *opt.Title)
+			assert.Equal("feature", *opt.SourceBranch)
+			assert.Equal("main", *opt.TargetBranch)
+			return &gitlab.MergeRequest{WebURL: mrURL}, nil, nil
+		})
+
+	// Act
+	mergeRequestURL, err := p.(sources.MergeRequestCommitSource).CreateCommitViaMergeRequest(context.Background(), token, &commit)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(mrURL, mergeRequestURL)
+	assert.Empty(commit.StartBranch)
+}
+
+func TestCommitViaMergeRequestReturnsOpenMergeRequest(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	commit := sources.Commit{
+		Branch:      "feature",
+		StartBranch: "release",
+		Message:     "Update the policy",
+		Owner:       "aserto-dev",
+		Repo:        repo,
+		Content:     map[string]string{file: fileContent},
+	}
+	conflict := &gitlab.Response{Response: &http.Response{StatusCode: http.StatusConflict}}
+	mrURL := "https://gitlab.com/aserto-dev/" + repo + "/-/merge_requests/7"
+
+	// Expect
+	mockIntr.EXPECT().GetBranch("aserto-dev/"+repo, "feature").Return(&gitlab.Branch{Name: "feature"}, nil, nil)
+	mockIntr.EXPECT().GetProjectFile("aserto-dev/"+repo, file, gomock.Any()).Return(&gitlab.File{Content: "old"}, nil, nil)
+	mockIntr.EXPECT().CreateCommit(gomock.Any(), gomock.Any()).Return("sha256", nil)
+	mockIntr.EXPECT().CreateMergeRequest("aserto-dev/"+repo, gomock.Any()).
+		Return(nil, conflict, errors.New("409 Another open merge request already exists for this source branch"))
+	mockIntr.EXPECT().ListProjectMergeRequests("aserto-dev/"+repo, gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.ListProjectMergeRequestsOptions) ([]*gitlab.MergeRequest, *gitlab.Response, error) {
+			assert.Equal("opened", *opt.State)
+			assert.Equal("feature", *opt.SourceBranch)
+			assert.Equal("release", *opt.TargetBranch)
+			return []*gitlab.MergeRequest{{WebURL: mrURL}}, &gitlab.Response{}, nil
+		})
+
+	// Act
+	mergeRequestURL, err := p.(sources.MergeRequestCommitSource).CreateCommitViaMergeRequest(context.Background(), token, &commit)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(mrURL, mergeRequestURL)
+}
+
+func TestCommitViaMergeRequestOnTargetBranch(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
+	commit := sources.Commit{
+		Branch:      "main",
+		StartBranch: "main",
+		Message:     "Update the policy",
+		Owner:       "aserto-dev",
+		Repo:        repo,
+		Content:     map[string]string{file: fileContent},
+	}
+
 	// Act
-	_, _, err := p.ListRepos(context.Background(), token, "aserto-demo", nil)
+	_, err := p.(sources.MergeRequestCommitSource).CreateCommitViaMergeRequest(context.Background(), token, &commit)
 
 	// Assert
 	assert.Error(err)
-	assert.Contains(err.Error(), "page must not be empty")
+	assert.Contains(err.Error(), "commit branch must differ from main")
 }
 
-func TestListReposWithInvalidPageSize(t *testing.T) {
+func TestCommitOnExistingBranchIgnoresStartBranch(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	page := &api.PaginationRequest{Size: -2}
+	commit := sources.Commit{
+		Branch:      "feature",
+		StartBranch: "main",
+		Message:     "Some commit",
+		Owner:       "aserto-dev",
+		Repo:        repo,
+		Content:     map[string]string{file: fileContent},
+	}
+
+	// Expect
+	mockIntr.EXPECT().GetBranch("aserto-dev/"+repo, "feature").Return(&gitlab.Branch{Name: "feature"}, nil, nil)
+	mockIntr.EXPECT().GetProjectFile("aserto-dev/"+repo, file, gomock.Any()).Return(&gitlab.File{}, nil, nil)
+	mockIntr.EXPECT().CreateCommit(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.CreateCommitOptions) (string, error) {
+			assert.Nil(opt.StartBranch)
+			return "sha256", nil
+		})
+
+	// Act
+	_, err := p.CreateCommitOnBranch(context.Background(), token, &commit)
+
+	// Assert
+	assert.NoError(err)
+}
+
+func TestCommitOnBranchWithAuthor(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	ctrl := gomock.NewController(t)
+	mockintrFunc := newMockIntrFunc(ctrl)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	commit := sources.Commit{
+		Branch:  "main",
+		Message: "Some commit",
+		Owner:   "aserto-dev",
+		Repo:    repo,
+		Content: map[string]string{file: fileContent},
+		Author:  &sources.CommitAuthor{Name: "Aserto Bot", Email: "bot@aserto.com"},
+	}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
+
+	// Expect
+	mockIntr.EXPECT().GetProjectFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, resp, errors.New("404 File Not Found"))
+	mockIntr.EXPECT().CreateCommit(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.CreateCommitOptions) (string, error) {
+			assert.Equal("Aserto Bot", *opt.AuthorName)
+			assert.Equal("bot@aserto.com", *opt.AuthorEmail)
+			return "sha256", nil
+		})
+
 	// Act
-	_, _, err := p.ListRepos(context.Background(), token, "aserto-demo", page)
+	_, err := p.CreateCommitOnBranch(context.Background(), token, &commit)
 
 	// Assert
-	assert.Error(err)
-	assert.Contains(err.Error(), "page size must be >= -1 and <= 100")
+	assert.NoError(err)
 }
 
-func TestListReposWithStringPageToken(t *testing.T) {
+func TestCommitOnBranchUpdatesExistingFile(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	page := &api.PaginationRequest{Size: -1, Token: "next_token"}
+	commit := sources.Commit{
+		Branch:  "main",
+		Message: "Some commit",
+		Owner:   "aserto-dev",
+		Repo:    repo,
+		Content: map[string]string{file: fileContent},
+	}
+
+	// Expect
+	mockIntr.EXPECT().GetProjectFile("aserto-dev/policy", file, gomock.Any()).Return(&gitlab.File{FilePath: file}, nil, nil)
+	mockIntr.EXPECT().CreateCommit(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.CreateCommitOptions) (string, error) {
+			assert.Equal(gitlab.FileUpdate, *opt.Actions[0].Action)
+			return "sha256", nil
+		})
+
 	// Act
-	_, _, err := p.ListRepos(context.Background(), token, "aserto-demo", page)
+	_, err := p.CreateCommitOnBranch(context.Background(), token, &commit)
 
 	// Assert
-	assert.Error(err)
-	assert.Contains(err.Error(), "page token must be int: strconv.Atoi: parsing \"next_token\":")
+	assert.NoError(err)
 }
 
-func TestListReposAllInOnePageWithUser(t *testing.T) {
+func TestCreateFile(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	page := &api.PaginationRequest{Size: -1, Token: ""}
-	var projects []*gitlab.Project
-	gitlabUser := &gitlab.User{Username: "aserto-demo"}
-	projects = append(projects, &gitlab.Project{Name: "template-policy", Owner: gitlabUser, WebURL: "gitlab.com/template-policy"})
-	resp := &gitlab.Response{NextPage: 0, TotalItems: 1}
 
 	// Expect
-	mockIntr.EXPECT().CurrentUser().Return(gitlabUser, nil, nil)
-	mockIntr.EXPECT().ListUserProjects("aserto-demo", gomock.Any()).Return(projects, resp, nil)
+	mockIntr.EXPECT().CreateCommit("aserto-dev/policy", gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.CreateCommitOptions) (string, error) {
+			assert.Equal("main", *opt.Branch)
+			assert.Equal("Add manifest", *opt.CommitMessage)
+			assert.Len(opt.Actions, 1)
+			assert.Equal(gitlab.FileCreate, *opt.Actions[0].Action)
+			assert.Equal(file, *opt.Actions[0].FilePath)
+			assert.Equal(fileContent, *opt.Actions[0].Content)
+			return "sha256", nil
+		})
 
 	// Act
-	repos, pageResp, err := p.ListRepos(context.Background(), token, "aserto-demo", page)
+	err := p.CreateFile(context.Background(), token, "aserto-dev", repo, file, fileContent, "main", "Add manifest")
 
 	// Assert
 	assert.NoError(err)
-	assert.Equal(pageResp.ResultSize, pageResp.TotalSize)
-	assert.Equal(1, len(repos))
-	assert.Equal("template-policy", repos[0].Name)
 }
 
-func TestListReposAllInOnePageWithOrg(t *testing.T) {
+func TestCommitOnBranchGetFileFails(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	page := &api.PaginationRequest{Size: -1, Token: ""}
-	var projects []*gitlab.Project
-	gitlabUser := &gitlab.User{Username: "aserto-demo"}
-	projects = append(projects, &gitlab.Project{Name: "template-policy", Owner: gitlabUser, WebURL: "gitlab.com/template-policy"})
-	resp := &gitlab.Response{NextPage: 0, TotalItems: 1}
+	commit := sources.Commit{
+		Branch:  "main",
+		Message: "Some commit",
+		Owner:   "aserto-dev",
+		Repo:    repo,
+		Content: map[string]string{file: fileContent},
+	}
 
 	// Expect
-	mockIntr.EXPECT().CurrentUser().Return(gitlabUser, nil, nil)
-	mockIntr.EXPECT().ListGroupProjects("aserto-dev", gomock.Any()).Return(projects, resp, nil)
+	mockIntr.EXPECT().GetProjectFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil, errors.New("failed to connect to server"))
 
 	// Act
-	repos, pageResp, err := p.ListRepos(context.Background(), token, "aserto-dev", page)
+	_, err := p.CreateCommitOnBranch(context.Background(), token, &commit)
 
 	// Assert
-	assert.NoError(err)
-	assert.Equal(pageResp.ResultSize, pageResp.TotalSize)
-	assert.Equal(1, len(repos))
-	assert.Equal("template-policy", repos[0].Name)
+	assert.Error(err)
+	assert.Contains(err.Error(), "failed to connect to server")
 }
 
-func TestGetRepoFail(t *testing.T) {
+func TestCommitOnBranchServerErrorOnExistenceCheck(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
+	commit := sources.Commit{
+		Branch:  "main",
+		Message: "Some commit",
+		Owner:   "aserto-dev",
+		Repo:    repo,
+		Content: map[string]string{file: fileContent},
+	}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: http.StatusInternalServerError}}
 
 	// Expect
-	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(nil, nil, errors.New("repo not found"))
+	mockIntr.EXPECT().GetProjectFile("aserto-dev/policy", file, gomock.Any()).Return(nil, resp, errors.New("500 Internal Server Error"))
 
 	// Act
-	repo, err := p.GetRepo(context.Background(), token, "aserto-dev", "policy")
+	_, err := p.CreateCommitOnBranch(context.Background(), token, &commit)
 
 	// Assert
 	assert.Error(err)
-	assert.Contains(err.Error(), "failed to get project: aserto-dev/policy: repo not found")
-	assert.Nil(repo)
+	assert.Contains(err.Error(), "failed to get file: .gitignore: 500 Internal Server Error")
 }
 
-func TestGetRepo(t *testing.T) {
+func TestListTags(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	proj := &gitlab.Project{Name: "policy", WebURL: "gitlab.com/policy"}
+	page := &api.PaginationRequest{Size: -1}
+	tags := []*gitlab.Tag{{Name: "v0.0.2"}, {Name: "v0.0.1"}}
+	resp := &gitlab.Response{NextPage: 0, TotalItems: 2}
 
 	// Expect
-	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
+	mockIntr.EXPECT().ListTags("aserto-dev/policy", gomock.Any()).Return(tags, resp, nil)
 
 	// Act
-	repo, err := p.GetRepo(context.Background(), token, "aserto-dev", "policy")
+	result, pageResp, err := p.ListTags(context.Background(), token, "aserto-dev", "policy", page)
 
 	// Assert
 	assert.NoError(err)
-	assert.NotNil(repo)
-	assert.Equal(repo.Org, "aserto-dev")
+	assert.Equal([]string{"v0.0.2", "v0.0.1"}, result)
+	assert.Equal(int32(2), pageResp.TotalSize)
 }
 
-func TestGetDefaultBranchFail(t *testing.T) {
+func TestListOrgMembers(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: -1}
+	firstPage := []*gitlab.GroupMember{{Username: "alice", Name: "Alice", Email: "alice@example.com"}}
+	secondPage := []*gitlab.GroupMember{{Username: "bob", Name: "Bob"}}
 
 	// Expect
-	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(nil, nil, errors.New("repo not found"))
+	mockIntr.EXPECT().ListGroupMembers("aserto-dev", gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.ListGroupMembersOptions) ([]*gitlab.GroupMember, *gitlab.Response, error) {
+			assert.Equal(100, opt.PerPage)
+			if opt.Page == 2 {
+				return secondPage, &gitlab.Response{TotalItems: 2}, nil
+			}
+			return firstPage, &gitlab.Response{NextPage: 2, TotalItems: 2}, nil
+		}).Times(2)
 
 	// Act
-	defaultBr, err := p.GetDefaultBranch(context.Background(), token, "aserto-dev", "policy")
+	members, pageResp, err := p.ListOrgMembers(context.Background(), token, "aserto-dev", page)
 
 	// Assert
-	assert.Error(err)
-	assert.Contains(err.Error(), "failed to get project: aserto-dev/policy: repo not found")
-	assert.Equal(defaultBr, "")
+	assert.NoError(err)
+	assert.Equal([]*sources.SccUser{
+		{Login: "alice", Name: "Alice", Email: "alice@example.com"},
+		{Login: "bob", Name: "Bob"},
+	}, members)
+	assert.Equal(int32(2), pageResp.TotalSize)
+	assert.Empty(pageResp.NextToken)
 }
 
-func TestGetDefaultBranch(t *testing.T) {
+func TestProfilePage(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	proj := &gitlab.Project{Name: "policy", WebURL: "gitlab.com/policy", DefaultBranch: "main"}
+	page := &api.PaginationRequest{Size: 1}
+	gitlabUser := &gitlab.User{Username: "aserto-tests"}
+	projects := []*gitlab.Project{{Name: "template-policy", Owner: gitlabUser, WebURL: "gitlab.com/template-policy"}}
+	resp := &gitlab.Response{NextPage: 2, TotalItems: 2}
 
 	// Expect
-	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
+	mockIntr.EXPECT().CachedCurrentUser().Return(gitlabUser, nil)
+	mockIntr.EXPECT().ListProjects(gomock.Any()).Return(projects, resp, nil)
 
 	// Act
-	defaultBr, err := p.GetDefaultBranch(context.Background(), token, "aserto-dev", "policy")
+	username, repos, pageResp, err := p.ProfilePage(context.Background(), token, page)
 
 	// Assert
 	assert.NoError(err)
-	assert.NotEmpty(defaultBr)
-	assert.Equal(defaultBr, "main")
+	assert.Equal(gitlabUser.Username, username)
+	assert.Equal(1, len(repos))
+	assert.Equal("2", pageResp.NextToken)
+	assert.Equal(int32(2), pageResp.TotalSize)
 }
 
-func TestCreateRepoAndGetNamespaceFails(t *testing.T) {
+func TestPreflightNoResponse(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
@@ -529,363 +2910,465 @@ func TestCreateRepoAndGetNamespaceFails(t *testing.T) {
 	token := &sources.AccessToken{Token: "sometokenvalue"}
 
 	// Expect
-	mockIntr.EXPECT().GetNamespace("aserto-dev").Return(nil, errors.New("namespace not found"))
+	mockIntr.EXPECT().CurrentUser().Return(nil, nil, nil)
 
 	// Act
-	err := p.CreateRepo(context.Background(), token, "aserto-dev", "policy")
+	result, err := p.Preflight(context.Background(), token, "aserto-dev", nil)
 
 	// Assert
-	assert.Error(err)
-	assert.Equal(err.Error(), "namespace not found")
+	assert.NoError(err)
+	assert.False(result.Passed())
+	assert.Equal(sources.PreflightFailed, result.Connection.Status)
 }
 
-func TestCreateRepoFails(t *testing.T) {
+func TestPreflightPrivateGroup(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	namespace := &gitlab.Namespace{ID: 1001}
+	gitlabUser := &gitlab.User{ID: 7, Username: "aserto-tests"}
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: 200}}
+	group := &gitlab.Group{Visibility: gitlab.PrivateVisibility, ProjectCreationLevel: gitlab.DeveloperProjectCreation}
+	member := &gitlab.GroupMember{AccessLevel: gitlab.DeveloperPermissions}
 
 	// Expect
-	mockIntr.EXPECT().GetNamespace("aserto-dev").Return(namespace, nil)
-	mockIntr.EXPECT().CreateProject(gomock.Any()).Return(nil, errors.New("failed to create repo"))
+	mockIntr.EXPECT().CurrentUser().Return(gitlabUser, resp, nil)
+	mockIntr.EXPECT().GetGroup("aserto-dev").Return(group, nil)
+	mockIntr.EXPECT().GetInheritedGroupMember("aserto-dev", 7).Return(member, nil)
 
 	// Act
-	err := p.CreateRepo(context.Background(), token, "aserto-dev", "policy")
+	result, err := p.Preflight(context.Background(), token, "aserto-dev", nil)
 
 	// Assert
-	assert.Error(err)
-	assert.Equal(err.Error(), "failed to create repo")
+	assert.NoError(err)
+	assert.True(result.Passed())
+	assert.Equal(sources.PreflightSkipped, result.Scopes.Status)
+	assert.Equal(sources.PreflightPassed, result.CreateRepo.Status)
+	assert.Equal(sources.PreflightSkipped, result.Visibility.Status)
 }
 
-func TestCreateRepoProtectTagsFails(t *testing.T) {
+func TestListReposWithFilter(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	namespace := &gitlab.Namespace{ID: 1001}
-	createdGitlabProj := &gitlab.Project{ID: 654}
+	page := &api.PaginationRequest{Size: -1, Token: ""}
+	gitlabUser := &gitlab.User{Username: "aserto-demo"}
+	resp := &gitlab.Response{NextPage: 0, TotalItems: 0}
+	var search *string
 
 	// Expect
-	mockIntr.EXPECT().GetNamespace("aserto-dev").Return(namespace, nil)
-	mockIntr.EXPECT().CreateProject(gomock.Any()).Return(createdGitlabProj, nil)
-	mockIntr.EXPECT().ProtectRepositoryTags(gomock.Any(), gomock.Any()).Return(errors.New("failed to protct tags"))
+	mockIntr.EXPECT().CachedCurrentUser().Return(gitlabUser, nil)
+	mockIntr.EXPECT().ListGroupProjects("aserto-dev", gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.ListGroupProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error) {
+			search = opt.Search
+			return nil, resp, nil
+		})
 
 	// Act
-	err := p.CreateRepo(context.Background(), token, "aserto-dev", "policy")
+	_, _, err := p.ListRepos(context.Background(), token, "aserto-dev", page, &sources.ListReposOptions{Filter: "pol"})
 
 	// Assert
-	assert.Error(err)
-	assert.Equal(err.Error(), "failed to protct tags")
+	assert.NoError(err)
+	assert.NotNil(search)
+	assert.Equal("pol", *search)
 }
 
-func TestCreateRepo(t *testing.T) {
+func TestListReposIncludesSubgroups(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	namespace := &gitlab.Namespace{ID: 1001}
-	createdGitlabProj := &gitlab.Project{ID: 654}
+	page := &api.PaginationRequest{Size: -1, Token: ""}
+	gitlabUser := &gitlab.User{Username: "aserto-demo"}
+	resp := &gitlab.Response{NextPage: 0, TotalItems: 1}
+	projects := []*gitlab.Project{{Name: "policies", Namespace: &gitlab.ProjectNamespace{FullPath: "aserto-dev/platform"}}}
 
 	// Expect
-	mockIntr.EXPECT().GetNamespace("aserto-dev").Return(namespace, nil)
-	mockIntr.EXPECT().CreateProject(gomock.Any()).Return(createdGitlabProj, nil)
-	mockIntr.EXPECT().ProtectRepositoryTags(gomock.Any(), gomock.Any()).Return(nil)
+	mockIntr.EXPECT().CachedCurrentUser().Return(gitlabUser, nil)
+	mockIntr.EXPECT().ListGroupProjects("aserto-dev", gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.ListGroupProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error) {
+			assert.NotNil(opt.IncludeSubGroups)
+			assert.True(*opt.IncludeSubGroups)
+			return projects, resp, nil
+		})
 
 	// Act
-	err := p.CreateRepo(context.Background(), token, "aserto-dev", "policy")
+	repos, _, err := p.ListRepos(context.Background(), token, "aserto-dev", page, nil)
 
 	// Assert
 	assert.NoError(err)
+	assert.Len(repos, 1)
+	assert.Equal("aserto-dev/platform", repos[0].Org)
 }
 
-func TestInitialTagWithWrongFullName(t *testing.T) {
+func TestListReposWithoutSubgroups(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: -1, Token: ""}
+	gitlabUser := &gitlab.User{Username: "aserto-demo"}
+	resp := &gitlab.Response{NextPage: 0, TotalItems: 0}
+	includeSubgroups := false
+
+	// Expect
+	mockIntr.EXPECT().CachedCurrentUser().Return(gitlabUser, nil)
+	mockIntr.EXPECT().ListGroupProjects("aserto-dev", gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.ListGroupProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error) {
+			assert.NotNil(opt.IncludeSubGroups)
+			assert.False(*opt.IncludeSubGroups)
+			return nil, resp, nil
+		})
 
 	// Act
-	err := p.InitialTag(context.Background(), token, "aserto-dev", "", "")
+	_, _, err := p.ListRepos(context.Background(), token, "aserto-dev", page, &sources.ListReposOptions{IncludeSubgroups: &includeSubgroups})
 
 	// Assert
-	assert.Error(err)
-	assert.Equal(err.Error(), "invalid full gitlab repo name 'aserto-dev', should be in the form owner/repo")
+	assert.NoError(err)
 }
 
-func TestInitialTagWithRepoAlreadyTagged(t *testing.T) {
+func TestListReposConcurrentlyKeepsPageOrder(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
-	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
-	token := &sources.AccessToken{Token: "dsfcds"}
-	tags := []string{"v0.0.0"}
-	proj := &gitlab.Project{Name: "policy", WebURL: "gitlab.com/policy", TagList: tags}
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{ListConcurrency: 2}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: -1, Token: ""}
+	gitlabUser := &gitlab.User{Username: "aserto-demo"}
 
 	// Expect
-	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
+	mockIntr.EXPECT().CachedCurrentUser().Return(gitlabUser, nil)
+	mockIntr.EXPECT().ListGroupProjects("aserto-dev", gomock.Any()).Times(3).
+		DoAndReturn(func(_ interface{}, opt *gitlab.ListGroupProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error) {
+			pageNum := max(opt.Page, 1)
+			projects := []*gitlab.Project{
+				{Name: fmt.Sprintf("repo-%d-a", pageNum)},
+				{Name: fmt.Sprintf("repo-%d-b", pageNum)},
+			}
+			resp := &gitlab.Response{TotalItems: 6, TotalPages: 3}
+			if pageNum < 3 {
+				resp.NextPage = pageNum + 1
+			}
+			return projects, resp, nil
+		})
 
 	// Act
-	err := p.InitialTag(context.Background(), token, "aserto-dev/policy", "", "")
+	repos, pageResp, err := p.ListRepos(context.Background(), token, "aserto-dev", page, nil)
 
 	// Assert
 	assert.NoError(err)
+	assert.Equal(int32(6), pageResp.TotalSize)
+	assert.Equal(int32(6), pageResp.ResultSize)
+	names := []string{}
+	for _, repo := range repos {
+		names = append(names, repo.Name)
+	}
+	assert.Equal([]string{"repo-1-a", "repo-1-b", "repo-2-a", "repo-2-b", "repo-3-a", "repo-3-b"}, names)
 }
 
-func TestInitialTagFails(t *testing.T) {
+func TestListReposConcurrentlyFails(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
-	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
-	token := &sources.AccessToken{Token: "dsfcds"}
-	tags := []string{}
-	proj := &gitlab.Project{ID: 1001, Name: "policy", WebURL: "gitlab.com/policy", TagList: tags}
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{ListConcurrency: 4}, mockintrFunc)
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: -1, Token: ""}
+	gitlabUser := &gitlab.User{Username: "aserto-demo"}
 
 	// Expect
-	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
-	mockIntr.EXPECT().CreateTag(gomock.Any(), gomock.Any()).Return(errors.New("failed to create tag"))
+	mockIntr.EXPECT().CachedCurrentUser().Return(gitlabUser, nil)
+	mockIntr.EXPECT().ListGroupProjects("aserto-dev", gomock.Any()).Times(3).
+		DoAndReturn(func(_ interface{}, opt *gitlab.ListGroupProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error) {
+			pageNum := max(opt.Page, 1)
+			if pageNum == 2 {
+				return nil, nil, errors.New("boom")
+			}
+			resp := &gitlab.Response{TotalItems: 3, TotalPages: 3}
+			if pageNum < 3 {
+				resp.NextPage = pageNum + 1
+			}
+			return []*gitlab.Project{{Name: fmt.Sprintf("repo-%d", pageNum)}}, resp, nil
+		})
 
 	// Act
-	err := p.InitialTag(context.Background(), token, "aserto-dev/policy", "", "")
+	repos, _, err := p.ListRepos(context.Background(), token, "aserto-dev", page, nil)
 
 	// Assert
 	assert.Error(err)
-	assert.Equal(err.Error(), "failed to create tag")
+	assert.Contains(err.Error(), "boom")
+	assert.Len(repos, 1)
+	assert.Equal("repo-1", repos[0].Name)
 }
 
-func TestInitialTag(t *testing.T) {
+func TestListReposKeepsPagesReadBeforeFailure(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
-	token := &sources.AccessToken{Token: "dsfcds"}
-	tags := []string{}
-	proj := &gitlab.Project{ID: 1001, Name: "policy", WebURL: "gitlab.com/policy", TagList: tags}
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: -1, Token: ""}
+	gitlabUser := &gitlab.User{Username: "aserto-demo"}
 
 	// Expect
-	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
-	mockIntr.EXPECT().CreateTag(gomock.Any(), gomock.Any()).Return(nil)
+	mockIntr.EXPECT().CachedCurrentUser().Return(gitlabUser, nil)
+	mockIntr.EXPECT().ListGroupProjects("aserto-dev", gomock.Any()).
+		Return([]*gitlab.Project{{Name: "repo-1"}}, &gitlab.Response{NextPage: 2}, nil)
+	mockIntr.EXPECT().ListGroupProjects("aserto-dev", gomock.Any()).Return(nil, nil, errors.New("boom"))
 
 	// Act
-	err := p.InitialTag(context.Background(), token, "aserto-dev/policy", "", "")
+	repos, resp, err := p.ListRepos(context.Background(), token, "aserto-dev", page, nil)
 
 	// Assert
-	assert.NoError(err)
+	assert.Error(err)
+	assert.Contains(err.Error(), "boom")
+	assert.Nil(resp)
+	assert.Len(repos, 1)
+	assert.Equal("repo-1", repos[0].Name)
 }
 
-func TestHasSecretFails(t *testing.T) {
+func TestListOrgsKeepsPagesReadBeforeFailure(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: -1, Token: ""}
 
 	// Expect
-	mockIntr.EXPECT().
-		GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY").
-		Return(nil, nil, errors.New("failed to connect to gitlab"))
+	mockIntr.EXPECT().ListGroups(gomock.Any()).
+		Return([]*gitlab.Group{{Name: "tests", FullPath: "test7929"}}, &gitlab.Response{NextPage: 2}, nil)
+	mockIntr.EXPECT().ListGroups(gomock.Any()).Return(nil, nil, errors.New("boom"))
 
 	// Act
-	secretExists, err := p.HasSecret(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY")
+	orgs, resp, err := p.ListOrgs(context.Background(), token, page, nil)
 
 	// Assert
 	assert.Error(err)
-	assert.Equal(err.Error(), "failed to connect to gitlab")
-	assert.False(secretExists)
+	assert.Contains(err.Error(), "boom")
+	assert.Nil(resp)
+	assert.Len(orgs, 1)
+	assert.Equal("test7929", orgs[0].Id)
 }
 
-func TestHasSecretNotFound(t *testing.T) {
+// userCountingClient answers the Gitlab requests with the aserto-demo user and no projects, and counts the requests
+// for the user.
+func userCountingClient(userCalls *int) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/api/v4/user" {
+			*userCalls++
+			return jsonResponse(req, `{"id": 1, "username": "aserto-demo"}`), nil
+		}
+		return jsonResponse(req, `[]`), nil
+	})}
+}
+
+func TestListReposRemembersUser(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
-	ctrl := gomock.NewController(t)
-	mockintrFunc := newMockIntrFunc(ctrl)
-	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	userCalls := 0
+	p := sources.NewGitlabWithClient(&zerolog.Logger{}, &sources.Config{}, userCountingClient(&userCalls))
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	resp := &gitlab.Response{Response: &http.Response{StatusCode: 404}}
-
-	// Expect
-	mockIntr.EXPECT().
-		GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY").
-		Return(nil, resp, errors.New("failed to connect to gitlab"))
+	page := &api.PaginationRequest{Size: -1, Token: ""}
 
 	// Act
-	secretExists, err := p.HasSecret(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY")
+	_, _, err := p.ListRepos(context.Background(), token, "aserto-demo", page, nil)
+	assert.NoError(err)
+	_, _, err = p.ListRepos(context.Background(), token, "aserto-demo", page, nil)
 
 	// Assert
 	assert.NoError(err)
-	assert.False(secretExists)
+	assert.Equal(1, userCalls)
 }
 
-func TestHasSecret(t *testing.T) {
+func TestListReposRemembersValidatedUser(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
-	ctrl := gomock.NewController(t)
-	mockintrFunc := newMockIntrFunc(ctrl)
-	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	userCalls := 0
+	p := sources.NewGitlabWithClient(&zerolog.Logger{}, &sources.Config{}, userCountingClient(&userCalls))
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	variable := &gitlab.ProjectVariable{}
+	page := &api.PaginationRequest{Size: -1, Token: ""}
 
-	// Expect
-	mockIntr.EXPECT().
-		GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY").
-		Return(variable, nil, nil)
+	// Act
+	err := p.ValidateConnection(context.Background(), token, []string{})
+	assert.NoError(err)
+	_, _, err = p.ListRepos(context.Background(), token, "aserto-demo", page, nil)
+
+	// Assert
+	assert.NoError(err)
+	assert.Equal(1, userCalls)
+}
+
+func TestListReposWithoutUserCache(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	userCalls := 0
+	p := sources.NewGitlabWithClient(&zerolog.Logger{}, &sources.Config{UserCacheTTLSeconds: -1}, userCountingClient(&userCalls))
+	token := &sources.AccessToken{Token: "sometokenvalue"}
+	page := &api.PaginationRequest{Size: -1, Token: ""}
 
 	// Act
-	secretExists, err := p.HasSecret(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY")
+	_, _, err := p.ListRepos(context.Background(), token, "aserto-demo", page, nil)
+	assert.NoError(err)
+	_, _, err = p.ListRepos(context.Background(), token, "aserto-demo", page, nil)
 
 	// Assert
 	assert.NoError(err)
-	assert.True(secretExists)
+	assert.Equal(2, userCalls)
 }
 
-func TestAddSecretToRepoFails(t *testing.T) {
+func TestIsRepoEmpty(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
+	proj := &gitlab.Project{Name: "policy", WebURL: "gitlab.com/policy", EmptyRepo: true}
 
 	// Expect
-	mockIntr.EXPECT().
-		GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY").
-		Return(nil, nil, errors.New("failed to connect to gitlab"))
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
 
 	// Act
-	err := p.AddSecretToRepo(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY", "value", false)
+	empty, err := p.IsRepoEmpty(context.Background(), token, "aserto-dev", "policy")
 
 	// Assert
-	assert.Error(err)
-	assert.Equal(err.Error(), "failed to connect to gitlab")
+	assert.NoError(err)
+	assert.True(empty)
 }
 
-func TestAddSecretToRepoOverwriteSecretFalse(t *testing.T) {
+func TestCreatePrivateRepo(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	variable := &gitlab.ProjectVariable{}
+	namespace := &gitlab.Namespace{ID: 1001}
+	createdGitlabProj := &gitlab.Project{ID: 654}
+	var visibility gitlab.VisibilityValue
 
 	// Expect
-	mockIntr.EXPECT().GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY").Return(variable, nil, nil)
+	mockIntr.EXPECT().GetNamespace("aserto-dev").Return(namespace, nil, nil)
+	mockIntr.EXPECT().CreateProject(gomock.Any()).
+		DoAndReturn(func(opt *gitlab.CreateProjectOptions) (*gitlab.Project, *gitlab.Response, error) {
+			visibility = *opt.Visibility
+			return createdGitlabProj, nil, nil
+		})
+	mockIntr.EXPECT().ProtectRepositoryTags(gomock.Any(), gomock.Any()).Return(nil)
 
 	// Act
-	err := p.AddSecretToRepo(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY", "value", false)
+	_, err := p.CreateRepo(context.Background(), token, "aserto-dev", "policy", &sources.CreateRepoOptions{Private: true})
 
 	// Assert
-	assert.Error(err)
-	assert.Equal(err.Error(), "E10022 repo has already been connected to a policy: you're trying to link to an existing repository that already has a secret. Please consider overwriting the Aserto push secret.")
+	assert.NoError(err)
+	assert.Equal(gitlab.PrivateVisibility, visibility)
 }
 
-func TestAddSecretToRepoOverwriteSecretTrue(t *testing.T) {
+func TestInitialTagWithConfiguredTag(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
-	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
-	token := &sources.AccessToken{Token: "sometokenvalue"}
-	variable := &gitlab.ProjectVariable{}
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{DefaultTag: " 2024.1.0 "}, mockintrFunc)
+	token := &sources.AccessToken{Token: "dsfcds"}
+	proj := &gitlab.Project{ID: 1001, Name: "policy", WebURL: "gitlab.com/policy", TagList: []string{}}
+	var tagName string
 
 	// Expect
-	mockIntr.EXPECT().GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY").Return(variable, nil, nil)
-	mockIntr.EXPECT().UpdateProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY", gomock.Any()).Return(nil)
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
+	mockIntr.EXPECT().CreateTag(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, opt *gitlab.CreateTagOptions) error {
+			tagName = *opt.TagName
+			return nil
+		})
+	mockIntr.EXPECT().ListProjectPipelines(1001, gomock.Any()).Return(nil, nil, nil)
 
 	// Act
-	err := p.AddSecretToRepo(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY", "value", true)
+	pipelineID, err := p.InitialTag(context.Background(), token, "aserto-dev/policy", "", "", "")
 
 	// Assert
 	assert.NoError(err)
+	assert.Equal("2024.1.0", tagName)
+	assert.Zero(pipelineID)
 }
 
-func TestAddSecretToRepoNewVariable(t *testing.T) {
+func TestGetRepoWithCIPathSuffix(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
-	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
+	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{CIPathSuffix: "/ci"}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	resp := &gitlab.Response{Response: &http.Response{StatusCode: 404}}
+	proj := &gitlab.Project{Name: "policy", WebURL: "gitlab.com/policy"}
 
 	// Expect
-	mockIntr.EXPECT().GetProjectVariable("aserto-dev/policy", "ASERTO_PUSH_KEY").Return(nil, resp, nil)
-	mockIntr.EXPECT().CreateProjectVariable("aserto-dev/policy", gomock.Any()).Return(nil)
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
 
 	// Act
-	err := p.AddSecretToRepo(context.Background(), token, "aserto-dev", "policy", "ASERTO_PUSH_KEY", "value", false)
+	repo, err := p.GetRepo(context.Background(), token, "aserto-dev", "policy")
 
 	// Assert
 	assert.NoError(err)
+	assert.Equal("gitlab.com/policy/ci", repo.CiUrl)
 }
 
-func TestCommitOnBranchFails(t *testing.T) {
+func TestGetFileContentOnDefaultBranch(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	content := make(map[string]string)
-	content[file] = fileContent
-	commit := sources.Commit{
-		Branch:  "main",
-		Message: "Some commit",
-		Owner:   "aserto-dev",
-		Repo:    repo,
-		Content: content,
-	}
+	proj := &gitlab.Project{Name: "policy", WebURL: "gitlab.com/policy", DefaultBranch: "main"}
+	gitlabFile := &gitlab.File{Encoding: "base64", Content: base64.StdEncoding.EncodeToString([]byte(fileContent))}
 
 	// Expect
-	mockIntr.EXPECT().GetProjectFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("failed to connect to server"))
-	mockIntr.EXPECT().CreateCommit(gomock.Any(), gomock.Any()).Return("", errors.New("failed to create commit"))
+	mockIntr.EXPECT().GetProject("aserto-dev/policy").Return(proj, nil, nil)
+	mockIntr.EXPECT().GetProjectFile("aserto-dev/policy", file, gomock.Any()).
+		DoAndReturn(func(_ interface{}, _ string, opt *gitlab.GetFileOptions) (*gitlab.File, *gitlab.Response, error) {
+			assert.Equal("main", *opt.Ref)
+			return gitlabFile, nil, nil
+		})
 
 	// Act
-	_, err := p.CreateCommitOnBranch(context.Background(), token, &commit)
+	content, exists, err := p.GetFileContent(context.Background(), token, "aserto-dev", "policy", file, "")
 
 	// Assert
-	assert.Error(err)
-	assert.Equal(err.Error(), "failed to create commit")
+	assert.NoError(err)
+	assert.True(exists)
+	assert.Equal(fileContent, content)
 }
 
-func TestCommitOnBranch(t *testing.T) {
+func TestGetFileContentMissing(t *testing.T) {
 	// Arrange
 	assert := require.New(t)
 	ctrl := gomock.NewController(t)
 	mockintrFunc := newMockIntrFunc(ctrl)
 	p := sources.NewTestGitlab(ctrl, &zerolog.Logger{}, &sources.Config{}, mockintrFunc)
 	token := &sources.AccessToken{Token: "sometokenvalue"}
-	content := make(map[string]string)
-	content[file] = fileContent
-	commit := sources.Commit{
-		Branch:  "main",
-		Message: "Some commit",
-		Owner:   "aserto-dev",
-		Repo:    repo,
-		Content: content,
-	}
-	returnedSha := "sha256"
+	resp := &gitlab.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
 
 	// Expect
-	mockIntr.EXPECT().GetProjectFile(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("failed to connect to server"))
-	mockIntr.EXPECT().CreateCommit(gomock.Any(), gomock.Any()).Return(returnedSha, nil)
+	mockIntr.EXPECT().GetProjectFile("aserto-dev/policy", file, gomock.Any()).Return(nil, resp, errors.New("404 File Not Found"))
 
 	// Act
-	commitSha, err := p.CreateCommitOnBranch(context.Background(), token, &commit)
+	content, exists, err := p.GetFileContent(context.Background(), token, "aserto-dev", "policy", file, "main")
 
 	// Assert
 	assert.NoError(err)
-	assert.Equal(returnedSha, commitSha)
+	assert.False(exists)
+	assert.Empty(content)
 }
@@ -0,0 +1,58 @@
+package sources
+
+import (
+	"net/http"
+	"sync"
+)
+
+// httpClients are the HTTP clients a source builds to send its requests through, whose idle connections Close closes.
+type httpClients struct {
+	mu      sync.Mutex
+	clients []*http.Client
+}
+
+func newHTTPClients() *httpClients {
+	return &httpClients{}
+}
+
+// add remembers client to close it with the others, and returns it.
+func (c *httpClients) add(client *http.Client) *http.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.clients = append(c.clients, client)
+
+	return client
+}
+
+// closeIdleConnections closes the idle connections of the clients. The sources built for tests have none.
+func (c *httpClients) closeIdleConnections() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, client := range c.clients {
+		client.CloseIdleConnections()
+	}
+}
+
+// ownHTTPClient returns a copy of base, or a new client when base is nil. A client without a transport gets a clone
+// of http.DefaultTransport, so that closing its idle connections leaves those of the rest of the process alone.
+func ownHTTPClient(base *http.Client) *http.Client {
+	client := &http.Client{}
+	if base != nil {
+		copied := *base
+		client = &copied
+	}
+
+	if client.Transport == nil {
+		if transport, ok := http.DefaultTransport.(*http.Transport); ok {
+			client.Transport = transport.Clone()
+		}
+	}
+
+	return client
+}
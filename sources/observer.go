@@ -0,0 +1,109 @@
+package sources
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aserto-dev/scc-lib/internal/interactions"
+	"github.com/rs/zerolog"
+)
+
+// Observer is told about the calls made by the sources, to record metrics without tying this library to a metrics
+// library. The operation is the name of the Source method, like GetRepo, or "api <method>" for the provider API calls
+// the method makes, named after the method of the provider client, like "api GetProject". err is the error of the
+// call, which carries the HTTP status of the calls the provider rejected, so that rate limits can be counted. Retried
+// API calls are observed once per attempt.
+type Observer interface {
+	ObserveCall(provider, operation string, duration time.Duration, err error)
+}
+
+// trackOperation logs the start of a source operation at debug level, and returns a func that logs its end with its
+// duration and hands it to observer, when not nil, with the error err points to. Empty owners and repos are left out.
+// Only these fields are logged, never the access token.
+func trackOperation(logger *zerolog.Logger, observer Observer, provider, operation, owner, repo string) func(err *error) {
+	start := time.Now()
+	operationEvent(logger, provider, operation, owner, repo).Msg("operation started")
+
+	return func(err *error) {
+		duration := time.Since(start)
+		operationEvent(logger, provider, operation, owner, repo).
+			Int64("duration_ms", duration.Milliseconds()).
+			Msg("operation finished")
+
+		if observer != nil {
+			observer.ObserveCall(provider, operation, duration, *err)
+		}
+	}
+}
+
+func operationEvent(logger *zerolog.Logger, provider, operation, owner, repo string) *zerolog.Event {
+	event := logger.Debug().Str("provider", provider).Str("operation", operation)
+	if owner != "" {
+		event = event.Str("owner", owner)
+	}
+	if repo != "" {
+		event = event.Str("repo", repo)
+	}
+
+	return event
+}
+
+func newGithubInteraction(cfg *Config, clients *httpClients) interactions.GhIntr {
+	return newGithubInteractionWithClient(cfg, nil, clients)
+}
+
+func newGithubInteractionWithClient(cfg *Config, httpClient *http.Client, clients *httpClients) interactions.GhIntr {
+	return interactions.NewGithubInteractionWithClient(sourceHTTPClient(cfg, httpClient, clients), observeFunc(cfg, "github"))
+}
+
+func newGraphqlInteraction(cfg *Config, clients *httpClients) interactions.GqlIntr {
+	return newGraphqlInteractionWithClient(cfg, nil, clients)
+}
+
+func newGraphqlInteractionWithClient(cfg *Config, httpClient *http.Client, clients *httpClients) interactions.GqlIntr {
+	return interactions.NewGraphqlInteractionWithClient(sourceHTTPClient(cfg, httpClient, clients), observeFunc(cfg, "github"))
+}
+
+func newGitlabInteraction(cfg *Config, clients *httpClients) interactions.GlIntr {
+	return newGitlabInteractionWithClient(cfg, nil, clients)
+}
+
+func newGitlabInteractionWithClient(cfg *Config, httpClient *http.Client, clients *httpClients) interactions.GlIntr {
+	return interactions.NewGitlabInteractionWithClient(sourceHTTPClient(cfg, httpClient, clients), observeFunc(cfg, "gitlab"))
+}
+
+func newBitbucketInteraction(cfg *Config, clients *httpClients) interactions.BbIntr {
+	return interactions.NewBitbucketInteractionWithClient(clients.add(timeoutHTTPClient(cfg, ownHTTPClient(nil))), observeFunc(cfg, "bitbucket"))
+}
+
+func newGiteaInteraction(cfg *Config, clients *httpClients) interactions.GtIntr {
+	return interactions.NewGiteaInteractionWithClient(clients.add(timeoutHTTPClient(cfg, tlsHTTPClient(cfg, ownHTTPClient(nil)))), observeFunc(cfg, "gitea"))
+}
+
+func newAzureDevOpsInteraction(cfg *Config) interactions.AzIntr {
+	return interactions.NewAzureDevOpsInteractionWithObserver(observeFunc(cfg, "azure-devops"))
+}
+
+// timeoutHTTPClient bounds the requests of client by the HTTP timeout of cfg, for the providers whose interactions
+// take no timeout of their own, and returns it.
+func timeoutHTTPClient(cfg *Config, client *http.Client) *http.Client {
+	client.Timeout = cfg.httpTimeout()
+
+	return client
+}
+
+// sourceHTTPClient returns the client a source sends its requests through: a copy of base with its own transport and
+// the TLS options of cfg, which clients closes with the others of the source.
+func sourceHTTPClient(cfg *Config, base *http.Client, clients *httpClients) *http.Client {
+	return clients.add(tlsHTTPClient(cfg, ownHTTPClient(base)))
+}
+
+// observeFunc hands the calls the interactions of provider make to the observer of cfg, as "api <method>". The
+// observer is read on each call, so that it can be set after the source is created.
+func observeFunc(cfg *Config, provider string) interactions.ObserveFunc {
+	return func(method string, duration time.Duration, err error) {
+		if cfg.Observer != nil {
+			cfg.Observer.ObserveCall(provider, "api "+method, duration, err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package sources
+
+// PreflightStatus is the outcome of a single preflight check.
+type PreflightStatus int
+
+const (
+	// PreflightSkipped means the check didn't run, because a previous check failed or the provider doesn't support it.
+	PreflightSkipped PreflightStatus = iota
+	PreflightPassed
+	PreflightFailed
+)
+
+// PreflightCheck holds the status of a single preflight check, and the reason it failed.
+type PreflightCheck struct {
+	Status PreflightStatus
+	Err    error
+}
+
+// PreflightResult aggregates the checks that must pass before a repository can be created or connected.
+type PreflightResult struct {
+	Connection PreflightCheck
+	Scopes     PreflightCheck
+	CreateRepo PreflightCheck
+	Visibility PreflightCheck
+}
+
+// Passed returns true if none of the checks failed.
+func (r *PreflightResult) Passed() bool {
+	for _, check := range []PreflightCheck{r.Connection, r.Scopes, r.CreateRepo, r.Visibility} {
+		if check.Status == PreflightFailed {
+			return false
+		}
+	}
+
+	return true
+}
+
+func checkFromError(err error) PreflightCheck {
+	if err != nil {
+		return PreflightCheck{Status: PreflightFailed, Err: err}
+	}
+
+	return PreflightCheck{Status: PreflightPassed}
+}
@@ -0,0 +1,39 @@
+package sources
+
+import (
+	"net/http"
+
+	"github.com/google/go-github/v66/github"
+	"github.com/pkg/errors"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+// isRetryable is the retry predicate of the source methods: a provider that answered with a 4xx status other than
+// 429 will answer the same to the same request, so only the other errors are worth retrying. GitHub rate limits
+// answer 403, and are retried too.
+func isRetryable(err error) bool {
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &rateLimitErr) || errors.As(err, &abuseErr) {
+		return true
+	}
+
+	var response *http.Response
+
+	var githubErr *github.ErrorResponse
+	var gitlabErr *gitlab.ErrorResponse
+	switch {
+	case errors.As(err, &githubErr):
+		response = githubErr.Response
+	case errors.As(err, &gitlabErr):
+		response = gitlabErr.Response
+	}
+
+	if response == nil {
+		return true
+	}
+
+	return response.StatusCode < http.StatusBadRequest ||
+		response.StatusCode >= http.StatusInternalServerError ||
+		response.StatusCode == http.StatusTooManyRequests
+}
@@ -0,0 +1,52 @@
+package sources
+
+// The operations RequiredScopes knows the scopes of.
+const (
+	OperationCreateRepo = "create-repo"
+	OperationAddSecret  = "add-secret"
+	OperationCommit     = "commit"
+	OperationTag        = "tag"
+)
+
+// operationScopes maps the providers that check the scopes of their tokens to the scopes each operation needs.
+var operationScopes = map[string]map[string][]string{
+	"github": {
+		// Creating a repo in an org reads the org membership first.
+		OperationCreateRepo: {"repo", "read:org"},
+		OperationAddSecret:  {"repo"},
+		// Commits can touch the files under .github/workflows, which needs the workflow scope.
+		OperationCommit: {"repo", "workflow"},
+		OperationTag:    {"repo"},
+	},
+	"gitlab": {
+		OperationCreateRepo: {"api"},
+		OperationAddSecret:  {"api"},
+		OperationCommit:     {"api"},
+		OperationTag:        {"api"},
+	},
+	"bitbucket": {
+		OperationCreateRepo: {"repository:admin"},
+		OperationAddSecret:  {"pipeline:variable"},
+		OperationCommit:     {"repository:write"},
+		OperationTag:        {"repository:write"},
+	},
+}
+
+// RequiredScopes returns the OAuth scopes a token of provider ("github", "gitlab" or "bitbucket") needs for the
+// given operations, without duplicates. UIs can request them upfront, and they can be passed as the requiredScopes
+// of ValidateConnection and Preflight. Unknown providers and operations need no scopes.
+func RequiredScopes(provider string, operations ...string) []string {
+	scopes := []string{}
+	seen := map[string]bool{}
+
+	for _, operation := range operations {
+		for _, scope := range operationScopes[provider][operation] {
+			if !seen[scope] {
+				seen[scope] = true
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	return scopes
+}
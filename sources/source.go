@@ -2,43 +2,561 @@ package sources
 
 import (
 	"context"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"time"
 
 	"github.com/aserto-dev/go-grpc/aserto/api/v1"
 	scc "github.com/aserto-dev/go-grpc/aserto/tenant/scc/v1"
+	"github.com/aserto-dev/scc-lib/errx"
+	"github.com/pkg/errors"
 )
 
 var defaultTag = "v0.0.0"
 
+const defaultHTTPTimeout = 30 * time.Second
+
+const defaultUserCacheTTL = 30 * time.Second
+
+const defaultPageSize = 30
+
+const modulePath = "github.com/aserto-dev/scc-lib"
+
+var defaultUserAgent = "aserto-scc-lib/" + libVersion()
+
 type AccessToken struct {
 	Token string
 	Type  string
 }
 
+// WorkflowSource is implemented by the sources whose InitialTag dispatches a workflow, i.e. GitHub.
+type WorkflowSource interface {
+	// HasWorkflow checks that the repo has the workflow fileName, e.g. before relying on InitialTag to dispatch it.
+	HasWorkflow(ctx context.Context, accessToken *AccessToken, owner, repo, fileName string) (bool, error)
+}
+
+// SccUser is a member of an organization. Name and Email are empty when the provider doesn't expose them.
+type SccUser struct {
+	Login string
+	Name  string
+	Email string
+}
+
+// SccOrgDetail describes an organization, e.g. for an org picker. Fields the provider doesn't expose are empty.
+type SccOrgDetail struct {
+	// ID is the Id ListOrgs returns for the org: the login on GitHub and the full path on Gitlab.
+	ID          string
+	Name        string
+	Description string
+	AvatarURL   string
+	URL         string
+	// BillingEmail is only returned by GitHub, to the org owners.
+	BillingEmail string
+}
+
 type Config struct {
 	CreateRepoTimeoutSeconds int
 	WaitTagTimeoutSeconds    int
 	RateLimitRetryCount      int
 	RateLimitTimeoutSeconds  int
+	// RetryStatusCodes are the 5xx statuses Gitlab GET and HEAD requests are retried on, on top of 429. Other
+	// requests are only retried on 429, since Gitlab may have applied them before failing.
+	// Defaults to 502, 503 and 504 when empty.
+	RetryStatusCodes []int
+	// UserAgent is sent with the requests to GitHub and Gitlab, so that their audit logs can tell this traffic apart.
+	// Defaults to aserto-scc-lib/<version> when empty.
+	UserAgent string
+	// HTTPTimeoutSeconds bounds each request to the provider. Defaults to 30 seconds when not positive.
+	HTTPTimeoutSeconds int
+	// GitlabTriggerTagPipeline makes the Gitlab InitialTag create a pipeline for the tag when none starts
+	// within WaitTagTimeoutSeconds, for projects whose CI doesn't run on tags.
+	GitlabTriggerTagPipeline bool
+	// DefaultTag is the tag created by InitialTag. Defaults to v0.0.0 when empty.
+	DefaultTag string
+	// CIPathSuffix is appended to repo URLs to build their CI URL. When empty, the provider
+	// default is used (/actions on GitHub, /-/pipelines on Gitlab, /pipelines on Bitbucket,
+	// /actions on Gitea, the project's /_build on Azure DevOps).
+	CIPathSuffix string
+	// BaseURL is the URL of self-hosted providers. Required by Gitea, which has no central host,
+	// and by Azure DevOps, where it is the organization URL (https://dev.azure.com/{organization}).
+	BaseURL string
+	// Observer is told about each source operation and each of the provider API calls they make. Nil observes nothing.
+	Observer Observer
+	// ListConcurrency is the number of pages the Gitlab ListRepos fetches at once when it lists all the repos
+	// (a page size of -1). Values below 2 fetch them one after the other. GitHub pages through GraphQL cursors,
+	// which can only be followed in order, so its ListRepos ignores it.
+	ListConcurrency int
+	// UserCacheTTLSeconds is how long a Gitlab source remembers the user that owns a token, so that ListRepos and
+	// ProfilePage don't fetch it on every call. Defaults to 30 seconds when zero; negative values turn the cache off.
+	UserCacheTTLSeconds int
+	// DefaultPageSize is the size of the GitHub and Gitlab pages requested with a size of 0. Defaults to 30 when
+	// not positive, and is capped at 100.
+	DefaultPageSize int
+	// GithubRESTCommits makes the GitHub CreateCommitOnBranch write the files through the REST git data API rather
+	// than the GraphQL createCommitOnBranch mutation, for GitHub Enterprise servers where the mutation is disabled.
+	// The files are still written as a single commit, which is authored as Commit.Author.
+	GithubRESTCommits bool
+	// TLSCACert holds PEM encoded CA certificates that the GitHub, Gitlab and Gitea servers are also trusted with, on top of
+	// the system roots, e.g. the internal CA of a self-hosted instance. The TLS options are read when the source is
+	// created, and apply when its client has no transport or an *http.Transport.
+	TLSCACert []byte
+	// TLSCACertFile is the path of a PEM file of CA certificates, trusted like TLSCACert. Requests fail when it
+	// can't be read.
+	TLSCACertFile string
+	// TLSInsecureSkipVerify turns off the verification of the GitHub, Gitlab and Gitea server certificates. It is
+	// meant for lab environments only and is never on by default.
+	TLSInsecureSkipVerify bool
+}
+
+// initialTag returns the tag InitialTag creates.
+func (c *Config) initialTag() string {
+	if tag := strings.TrimSpace(c.DefaultTag); tag != "" {
+		return tag
+	}
+
+	return defaultTag
+}
+
+// tagMessageOrName returns the message of the initial tag: tagMessage, or the tag name when it's empty.
+func tagMessageOrName(tag, tagMessage string) string {
+	if tagMessage != "" {
+		return tagMessage
+	}
+
+	return tag
+}
+
+// userAgent returns the User-Agent sent to GitHub and Gitlab.
+func (c *Config) userAgent() string {
+	if userAgent := strings.TrimSpace(c.UserAgent); userAgent != "" {
+		return userAgent
+	}
+
+	return defaultUserAgent
+}
+
+// libVersion returns the version of this module in the build info of the binary, or "dev" when it's unknown,
+// e.g. in its own tests.
+func libVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath && dep.Version != "" {
+			return dep.Version
+		}
+	}
+
+	return "dev"
+}
+
+// httpTimeout returns the timeout of a single request to the provider.
+func (c *Config) httpTimeout() time.Duration {
+	if c.HTTPTimeoutSeconds <= 0 {
+		return defaultHTTPTimeout
+	}
+
+	return time.Duration(c.HTTPTimeoutSeconds) * time.Second
+}
+
+// userCacheTTL returns how long the user that owns a token is remembered.
+func (c *Config) userCacheTTL() time.Duration {
+	if c.UserCacheTTLSeconds == 0 {
+		return defaultUserCacheTTL
+	}
+
+	return time.Duration(c.UserCacheTTLSeconds) * time.Second
+}
+
+// pageRequest returns page, or a copy of it with the default page size when its size is 0.
+func (c *Config) pageRequest(page *api.PaginationRequest) *api.PaginationRequest {
+	if page == nil || page.Size != 0 {
+		return page
+	}
+
+	size := c.DefaultPageSize
+	if size <= 0 {
+		size = defaultPageSize
+	}
+
+	return &api.PaginationRequest{Size: safeInt32(min(size, 100)), Token: page.Token}
+}
+
+// ciURL returns the CI URL of a repo, using the configured suffix over the provider default.
+func (c *Config) ciURL(repoURL, providerSuffix string) string {
+	if c.CIPathSuffix != "" {
+		return repoURL + c.CIPathSuffix
+	}
+
+	return repoURL + providerSuffix
+}
+
+// missingScopes returns the required scopes, compiled as regexps, that none of the provided scopes match.
+func missingScopes(providedScopes, requiredScopes []string) ([]string, error) {
+	foundScopes := map[string]bool{}
+	for _, es := range providedScopes {
+		for _, rs := range requiredScopes {
+			r, err := regexp.Compile(rs)
+			if err != nil {
+				return nil, errx.ErrProviderVerification.Err(err).Msgf("failed to compile regexp: %s", err.Error())
+			}
+			if r.MatchString(strings.TrimSpace(es)) {
+				foundScopes[rs] = true
+				break
+			}
+		}
+	}
+
+	missing := []string{}
+	for _, rs := range requiredScopes {
+		if !foundScopes[rs] {
+			missing = append(missing, rs)
+		}
+	}
+
+	return missing, nil
+}
+
+// safeInt32 converts n for the pagination responses, clamping it to the int32 range.
+func safeInt32(n int) int32 {
+	switch {
+	case n > math.MaxInt32:
+		return math.MaxInt32
+	case n < math.MinInt32:
+		return math.MinInt32
+	default:
+		return int32(n)
+	}
+}
+
+// pingError reports the result of a Ping from the status the provider replied with, 0 when there was no reply.
+// Any status below 500 means the host is up, even when it rejects the missing credentials.
+func pingError(provider string, statusCode int, err error) error {
+	if statusCode > 0 && statusCode < http.StatusInternalServerError {
+		return nil
+	}
+
+	unreachable := errx.ErrProviderUnreachable.Str("provider", provider)
+	if statusCode > 0 {
+		unreachable = unreachable.Int(errx.StatusCodeKey, statusCode)
+	}
+
+	if err != nil {
+		return unreachable.Err(err)
+	}
+
+	return unreachable.Msgf("no reply from %s", provider)
+}
+
+// renameRepoError maps a failed rename to errx.ErrNotFound when the repo doesn't exist, and to errx.ErrRepoNameTaken
+// when the provider reports that the new name is taken, with a 409 or a validation error mentioning it.
+func renameRepoError(err error, statusCode int, owner, newName string) error {
+	switch statusCode {
+	case http.StatusNotFound:
+		return errx.ErrNotFound.Err(err).Int(errx.StatusCodeKey, statusCode)
+	case http.StatusConflict:
+		return errx.ErrRepoNameTaken.Err(err).Str("repo", owner+"/"+newName)
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		msg := strings.ToLower(err.Error())
+		if strings.Contains(msg, "already exists") || strings.Contains(msg, "already taken") || strings.Contains(msg, "already been taken") {
+			return errx.ErrRepoNameTaken.Err(err).Str("repo", owner+"/"+newName)
+		}
+	}
+
+	return err
+}
+
+// responseBody returns the body of resp, or a placeholder when there is no response or body to read.
+func responseBody(resp *http.Response) io.Reader {
+	if resp == nil || resp.Body == nil {
+		return strings.NewReader("<no response body>")
+	}
+
+	return resp.Body
+}
+
+// ConflictStrategy controls what happens when the branch head moves while a commit is being created.
+type ConflictStrategy int
+
+const (
+	// ConflictFail returns errx.ErrConcurrentUpdate to the caller, without retrying.
+	ConflictFail ConflictStrategy = iota
+	// ConflictRebase re-reads the branch head and re-applies the commit content on top of it. It returns
+	// errx.ErrConcurrentUpdate when the branch keeps moving. Only GitHub supports it, the other sources refuse
+	// commits that ask for it.
+	ConflictRebase
+)
+
+// checkConflictStrategy refuses commits asking for a conflict strategy the provider doesn't implement.
+func checkConflictStrategy(provider string, commit *Commit) error {
+	if commit.ConflictStrategy == ConflictRebase {
+		return errors.Errorf("%s doesn't support the rebase conflict strategy", provider)
+	}
+
+	return nil
 }
 
 type Commit struct {
-	Branch  string
-	Message string
-	Owner   string
-	Repo    string
-	Content map[string]string
+	Branch           string
+	Message          string
+	Owner            string
+	Repo             string
+	Content          map[string]string
+	ConflictStrategy ConflictStrategy
+	// Author is who the commit is authored as, the token owner when nil. GitHub ignores it unless
+	// Config.GithubRESTCommits is set: the GraphQL createCommitOnBranch mutation has no author input and always
+	// attributes the commit to the token owner, so use the token of a GitHub App to commit as a bot there.
+	Author *CommitAuthor
+	// StartBranch is the branch Branch is created from when it doesn't exist yet. When empty, Branch must exist.
+	// Only GitHub and Gitlab support it.
+	StartBranch string
+}
+
+// CommitAuthor is the identity of a commit author, e.g. "Aserto Bot <bot@aserto.com>".
+type CommitAuthor struct {
+	Name  string
+	Email string
+}
+
+// ListOrgsOptions narrows down the orgs returned by ListOrgs. Only Gitlab applies them; a nil value lists
+// all the groups, nested ones included, the user is at least a developer of.
+type ListOrgsOptions struct {
+	// MinAccessLevel is the Gitlab access level the user needs on a group: 10 guest, 20 reporter, 30 developer,
+	// 40 maintainer, 50 owner. Defaults to developer when 0.
+	MinAccessLevel int
+	// TopLevelOnly skips the groups nested in other groups.
+	TopLevelOnly bool
 }
 
+// ListReposOptions narrows down the repos returned by ListRepos. A nil value lists all the repos of the owner.
+type ListReposOptions struct {
+	// Filter only returns repos whose name contains the given term.
+	Filter string
+	// IncludeSubgroups also lists the repos of the groups nested under the owner. Only Gitlab has subgroups;
+	// when nil, they are included.
+	IncludeSubgroups *bool
+	// Consistent lists the GitHub repos through the repositories connection of the owner rather than the search API.
+	// Search indexes new repos with a delay and stops at 1000 results, but it can filter by name; the connection is
+	// complete and up to date, including private repos, but can't filter, so Filter must be empty. Ignored by the
+	// other providers, whose listings are consistent.
+	Consistent bool
+}
+
+// CreateRepoOptions configures the repos created by CreateRepo. A nil value creates a public repo
+// with the provider defaults.
+type CreateRepoOptions struct {
+	Private     bool
+	Description string
+	// AutoInit creates the repo with an initial commit. When nil, GitHub repos are initialized and Gitlab and
+	// Bitbucket ones aren't.
+	AutoInit *bool
+	// TagProtection configures the tags Gitlab protects in the new repo. When nil, only maintainers can create
+	// tags matching "v*". The other providers ignore it.
+	TagProtection *TagProtectionOptions
+}
+
+// TagProtectionOptions configures the protected tags of a new Gitlab repo.
+type TagProtectionOptions struct {
+	// Disabled leaves the tags of the repo unprotected, for users who manage the protection themselves.
+	Disabled bool
+	// Pattern is the wildcard the protected tags match, e.g. "release-*". Defaults to "v*" when empty.
+	Pattern string
+	// CreateAccessLevel is the Gitlab access level needed to create the protected tags: 0 no one, 30 developer,
+	// 40 maintainer. Defaults to maintainer when nil.
+	CreateAccessLevel *int
+}
+
+// SecretOptions configures the secrets set by AddSecretToRepo and looked up by HasSecret. Only Gitlab uses them;
+// a nil value creates a masked and protected variable available to all environments.
+type SecretOptions struct {
+	// Masked hides the value in job logs. Gitlab can only mask single-line values of at least 8 characters
+	// from the Base64 alphabet, plus '@', ':', '.' and '~'.
+	Masked bool
+	// Protected only exposes the variable to pipelines running on protected branches and tags.
+	Protected bool
+	// EnvironmentScope limits the variable to the matching environments. All environments ("*") when empty.
+	// It is the only option HasSecret uses.
+	EnvironmentScope string
+}
+
+func (o *SecretOptions) environmentScope() string {
+	if o == nil || o.EnvironmentScope == "" {
+		return "*"
+	}
+
+	return o.EnvironmentScope
+}
+
+// Source is a source code provider. Its methods stop retrying as soon as their ctx is done, so callers running a
+// flow of calls, like an onboarding that creates a repo, adds its secret, tags it and waits for its build, should
+// give them all a single ctx whose deadline caps the whole flow, rather than rely on the per-call timeouts of Config.
+// Only the rate limit retries of single Gitlab requests ignore ctx; RateLimitTimeoutSeconds bounds them.
+//
+// The paginated listings take a page size from 1 to 100, or -1 to read all the pages. On GitHub and Gitlab, a size
+// of 0 reads a page of Config.DefaultPageSize. When reading all the pages fails partway, the GitHub and Gitlab
+// ListOrgs and ListRepos return the results of the pages read so far along with the error, and a nil response.
 type Source interface {
+	// Ping checks that the provider is reachable, without credentials. Unlike ValidateConnection, it doesn't
+	// validate a token.
+	Ping(ctx context.Context) error
 	ValidateConnection(ctx context.Context, accessToken *AccessToken, requiredScopes []string) error
+	Preflight(ctx context.Context, accessToken *AccessToken, owner string, requiredScopes []string) (*PreflightResult, error)
 	Profile(ctx context.Context, accessToken *AccessToken) (string, []*scc.Repo, error)
-	ListOrgs(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest) ([]*api.SccOrg, *api.PaginationResponse, error)
-	ListRepos(ctx context.Context, accessToken *AccessToken, owner string, page *api.PaginationRequest) ([]*scc.Repo, *api.PaginationResponse, error)
-	CreateRepo(ctx context.Context, accessToken *AccessToken, owner, name string) error
+	ProfilePage(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest) (string, []*scc.Repo, *api.PaginationResponse, error)
+	ListOrgs(ctx context.Context, accessToken *AccessToken, page *api.PaginationRequest, opts *ListOrgsOptions) ([]*api.SccOrg, *api.PaginationResponse, error)
+	// ListOrgMembers lists the members of an organization: a GitHub organization, a Gitlab group, a Bitbucket
+	// workspace, a Gitea organization, or the default team of an Azure DevOps project.
+	ListOrgMembers(ctx context.Context, accessToken *AccessToken, org string, page *api.PaginationRequest) ([]*SccUser, *api.PaginationResponse, error)
+	ListRepos(ctx context.Context, accessToken *AccessToken, owner string, page *api.PaginationRequest, opts *ListReposOptions) ([]*scc.Repo, *api.PaginationResponse, error)
+	CreateRepo(ctx context.Context, accessToken *AccessToken, owner, name string, opts *CreateRepoOptions) (*scc.Repo, error)
 	GetRepo(ctx context.Context, accessToken *AccessToken, owner, repo string) (*scc.Repo, error)
-	HasSecret(ctx context.Context, token *AccessToken, owner, repo, secretName string) (bool, error)
-	AddSecretToRepo(ctx context.Context, token *AccessToken, orgName, repoName, secretName, value string, overrideSecret bool) error
-	InitialTag(ctx context.Context, accessToken *AccessToken, fullName, workflowFileName, commitSHA string) error
+	// RenameRepo renames a repo, keeping its history. It returns errx.ErrNotFound when the repo doesn't exist and
+	// errx.ErrRepoNameTaken when another repo of the owner already has newName.
+	RenameRepo(ctx context.Context, accessToken *AccessToken, owner, repo, newName string) error
+	// GetRepoID returns the provider's own identifier of the repo, for follow-up calls that need it: the numeric
+	// project ID on Gitlab and Gitea, the node ID on GitHub, and the UUID on Bitbucket and Azure DevOps.
+	GetRepoID(ctx context.Context, accessToken *AccessToken, owner, repo string) (string, error)
+	HasSecret(ctx context.Context, token *AccessToken, owner, repo, secretName string, opts *SecretOptions) (bool, error)
+	// GetSecret reads back the value of a secret, and whether it exists, to detect drift. Only Gitlab, whose CI/CD
+	// variables are readable, supports it; the other providers return errx.ErrSecretWriteOnly.
+	GetSecret(ctx context.Context, token *AccessToken, owner, repo, secretName string) (string, bool, error)
+	AddSecretToRepo(ctx context.Context, token *AccessToken, orgName, repoName, secretName, value string, overrideSecret bool, opts *SecretOptions) error
+	// InitialTag tags the repo with the configured default tag, unless it already has tags. A non-empty tagMessage
+	// makes it an annotated tag carrying that message; otherwise the tag name is used as the message, and GitHub
+	// creates a lightweight tag. It returns errx.ErrEmptyRepo when the repo has no commit to tag yet.
+	InitialTag(ctx context.Context, accessToken *AccessToken, fullName, workflowFileName, commitSHA, tagMessage string) (int64, error)
+	// CreateCommitOnBranch commits the content of commit. GitHub returns errx.ErrEmptyRepo for repos without commits,
+	// which Gitlab commits to as their first commit.
 	CreateCommitOnBranch(ctx context.Context, accessToken *AccessToken, commit *Commit) (string, error)
+	// CreateFile commits a single new file to branch, e.g. to seed a freshly created repo.
+	CreateFile(ctx context.Context, accessToken *AccessToken, owner, repo, path, content, branch, message string) error
+	GetFileContent(ctx context.Context, accessToken *AccessToken, owner, repo, path, ref string) (string, bool, error)
 	GetDefaultBranch(ctx context.Context, accessToken *AccessToken, owner, repo string) (string, error)
+	IsRepoEmpty(ctx context.Context, accessToken *AccessToken, owner, repo string) (bool, error)
+	ListTags(ctx context.Context, accessToken *AccessToken, owner, repo string, page *api.PaginationRequest) ([]string, *api.PaginationResponse, error)
+	// Close releases the idle HTTP connections of the source, for services that keep a source around. Each source
+	// has connections of its own, so it leaves those of the rest of the process alone. It is a no-op for Azure DevOps,
+	// whose SDK builds its own HTTP clients.
+	Close() error
+}
+
+// BulkSecretSource is implemented by the sources that can add a secret to many repos at once, i.e. GitHub and Gitlab.
+type BulkSecretSource interface {
+	// AddSecretToRepos adds the secret to each of the repos of owner, like AddSecretToRepo, reusing one client. It
+	// returns the error of each repo, nil on success, so that one failure doesn't stop the batch. The error it returns
+	// is for the whole batch, e.g. when ctx is done, in which case the remaining repos are left out of the results.
+	AddSecretToRepos(ctx context.Context, token *AccessToken, owner string, repos []string, secretName, value string, overrideSecret bool) (map[string]error, error)
+}
+
+// OrgSource is implemented by the sources that can describe an organization, i.e. GitHub and Gitlab.
+type OrgSource interface {
+	// GetOrg describes the GitHub organization or Gitlab group org. It returns errx.ErrNotFound when it doesn't exist.
+	GetOrg(ctx context.Context, accessToken *AccessToken, org string) (*SccOrgDetail, error)
+}
+
+// WorkflowRunWaiter is implemented by the sources that can wait for a CI run to complete.
+type WorkflowRunWaiter interface {
+	// WaitForWorkflowRun blocks until the run completes and returns its conclusion.
+	WaitForWorkflowRun(ctx context.Context, accessToken *AccessToken, owner, repo string, runID int64) (string, error)
+}
+
+// BuildTrigger is implemented by the sources that can start a CI build on demand, i.e. GitHub and Gitlab.
+type BuildTrigger interface {
+	// TriggerBuild builds ref, a branch or a tag, e.g. to rebuild a repo that is already tagged. GitHub dispatches
+	// the workflow workflowFileName; Gitlab runs the pipeline of the project and ignores it.
+	TriggerBuild(ctx context.Context, accessToken *AccessToken, owner, repo, ref, workflowFileName string) error
+}
+
+// Webhook is a repo webhook. Events are the provider's own event names: e.g. "push" and "pull_request" on GitHub,
+// and "push", "tag_push", "merge_request", "pipeline" or "release" on Gitlab. No events subscribes to pushes.
+type Webhook struct {
+	// ID is the provider's identifier of the hook. It is set by ListWebhooks and ignored by CreateWebhook.
+	ID  string
+	URL string
+	// Secret signs the deliveries on GitHub, and is sent as the X-Gitlab-Token header by Gitlab. ListWebhooks
+	// leaves it empty, since the providers don't return it.
+	Secret string
+	Events []string
+	// ContentType is the format of the GitHub payloads, "json" or "form". Defaults to json. Gitlab always sends JSON.
+	ContentType string
+}
+
+// PullRequest is a GitHub pull request or a Gitlab merge request. State is "open" or "closed"; merged requests
+// are closed.
+type PullRequest struct {
+	Number int
+	Title  string
+	URL    string
+	// Head is the branch the changes come from, and Base the branch they are merged into.
+	Head  string
+	Base  string
+	State string
+}
+
+// PullRequestSource is implemented by the sources that can list pull requests, i.e. GitHub and Gitlab.
+type PullRequestSource interface {
+	// ListPullRequests lists the pull requests of the repo in state: "open", "closed" or "all". An empty state
+	// lists the open ones.
+	ListPullRequests(ctx context.Context, accessToken *AccessToken, owner, repo, state string) ([]*PullRequest, error)
+}
+
+// MergeRequestCommitSource is implemented by the sources that can commit through a merge request, i.e. Gitlab,
+// for projects whose default branch is protected against direct commits.
+type MergeRequestCommitSource interface {
+	// CreateCommitViaMergeRequest commits to commit.Branch, created from commit.StartBranch, or from the default
+	// branch when it's empty, and opens a merge request of it into that branch, titled after the first line of the
+	// commit message. It returns the URL of the merge request, or of the open one the branch already had.
+	CreateCommitViaMergeRequest(ctx context.Context, accessToken *AccessToken, commit *Commit) (string, error)
+}
+
+// pullRequestState checks state, one of "open", "closed" and "all", defaulting to "open" when empty.
+func pullRequestState(state string) (string, error) {
+	switch state {
+	case "":
+		return "open", nil
+	case "open", "closed", "all":
+		return state, nil
+	default:
+		return "", errors.Errorf("unknown pull request state '%s', should be open, closed or all", state)
+	}
+}
+
+// CloneURLSource is implemented by the sources that can tell the clone URLs of a repo, i.e. GitHub and Gitlab.
+type CloneURLSource interface {
+	// GetCloneURLs returns the HTTPS and SSH clone URLs of the repo, unlike scc.Repo.Url which is its web page.
+	// It returns errx.ErrNotFound when the repo doesn't exist.
+	GetCloneURLs(ctx context.Context, accessToken *AccessToken, owner, repo string) (httpsURL, sshURL string, err error)
+}
+
+// BranchSource is implemented by the sources that can read the head of a branch, i.e. GitHub and Gitlab.
+type BranchSource interface {
+	// GetBranchHead returns the SHA of the latest commit of branch, e.g. to pass as the expected head of a commit.
+	// It returns errx.ErrNotFound when the repo or the branch doesn't exist.
+	GetBranchHead(ctx context.Context, accessToken *AccessToken, owner, repo, branch string) (string, error)
+}
+
+// WebhookSource is implemented by the sources that can register repo webhooks, i.e. GitHub and Gitlab.
+type WebhookSource interface {
+	// CreateWebhook registers hook on the repo and returns its ID.
+	CreateWebhook(ctx context.Context, accessToken *AccessToken, owner, repo string, hook *Webhook) (string, error)
+	// DeleteWebhook removes the hook hookID from the repo. It returns errx.ErrNotFound when the hook doesn't exist.
+	DeleteWebhook(ctx context.Context, accessToken *AccessToken, owner, repo, hookID string) error
+	ListWebhooks(ctx context.Context, accessToken *AccessToken, owner, repo string) ([]*Webhook, error)
+}
+
+// DeployKeySource is implemented by the sources that can add SSH deploy keys to repos, i.e. GitHub and Gitlab, as an
+// alternative to pushing with an access token.
+type DeployKeySource interface {
+	// AddDeployKey adds the SSH publicKey to the repo under title and returns its ID. A read-only key can't push.
+	AddDeployKey(ctx context.Context, accessToken *AccessToken, owner, repo, title, publicKey string, readOnly bool) (string, error)
+	// HasDeployKey reports whether the repo has a deploy key titled title.
+	HasDeployKey(ctx context.Context, accessToken *AccessToken, owner, repo, title string) (bool, error)
+	// DeleteDeployKey removes the key keyID from the repo. It returns errx.ErrNotFound when the key doesn't exist.
+	DeleteDeployKey(ctx context.Context, accessToken *AccessToken, owner, repo, keyID string) error
 }
@@ -0,0 +1,115 @@
+package sources_test
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aserto-dev/scc-lib/sources"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTripFunc answers the requests of a custom http.Client without a network.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// idleClosingTransport records that the idle connections were closed.
+type idleClosingTransport struct {
+	http.RoundTripper
+	closed bool
+}
+
+func (t *idleClosingTransport) CloseIdleConnections() {
+	t.closed = true
+}
+
+// observedCall is a call handed to a recordingObserver.
+type observedCall struct {
+	provider  string
+	operation string
+	err       error
+}
+
+// recordingObserver records the calls it observes.
+type recordingObserver struct {
+	calls []observedCall
+}
+
+func (o *recordingObserver) ObserveCall(provider, operation string, _ time.Duration, err error) {
+	o.calls = append(o.calls, observedCall{provider: provider, operation: operation, err: err})
+}
+
+func jsonResponse(req *http.Request, body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+}
+
+func TestOwnHTTPClientDoesntShareDefaultTransport(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+
+	// Act
+	client := sources.OwnHTTPClient(nil)
+
+	// Assert
+	assert.NotNil(client.Transport)
+	assert.NotSame(http.DefaultTransport, client.Transport)
+}
+
+func TestOwnHTTPClientKeepsTransport(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+	transport := &idleClosingTransport{}
+	base := &http.Client{Transport: transport}
+
+	// Act
+	client := sources.OwnHTTPClient(base)
+
+	// Assert
+	assert.NotSame(base, client)
+	assert.Same(transport, client.Transport)
+}
+
+func TestSafeInt32(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+
+	// Act & Assert
+	assert.Equal(int32(42), sources.SafeInt32(42))
+	assert.Equal(int32(math.MaxInt32), sources.SafeInt32(math.MaxInt32))
+	assert.Equal(int32(math.MaxInt32), sources.SafeInt32(math.MaxInt32+1))
+	assert.Equal(int32(math.MinInt32), sources.SafeInt32(math.MinInt32-1))
+}
+
+func TestRequiredScopes(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+
+	// Act
+	scopes := sources.RequiredScopes("github", sources.OperationCreateRepo, sources.OperationAddSecret, sources.OperationCommit)
+
+	// Assert
+	assert.Equal([]string{"repo", "read:org", "workflow"}, scopes)
+}
+
+func TestRequiredScopesUnknownProvider(t *testing.T) {
+	// Arrange
+	assert := require.New(t)
+
+	// Act
+	scopes := sources.RequiredScopes("svn", sources.OperationCommit)
+
+	// Assert
+	assert.Empty(scopes)
+}
@@ -0,0 +1,105 @@
+package sources
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// hasTLSOptions reports whether c changes how the servers of GitHub and Gitlab are verified.
+func (c *Config) hasTLSOptions() bool {
+	return len(c.TLSCACert) > 0 || c.TLSCACertFile != "" || c.TLSInsecureSkipVerify
+}
+
+// tlsConfig returns a copy of base, or a new config when base is nil, with the TLS options of c applied.
+func (c *Config) tlsConfig(base *tls.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if base != nil {
+		tlsConfig = base.Clone()
+	}
+
+	if len(c.TLSCACert) > 0 || c.TLSCACertFile != "" {
+		// The CAs are added to the system roots, so that public servers are still trusted.
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if len(c.TLSCACert) > 0 && !pool.AppendCertsFromPEM(c.TLSCACert) {
+			return nil, errors.New("TLSCACert has no PEM encoded certificate")
+		}
+
+		if c.TLSCACertFile != "" {
+			caCert, err := os.ReadFile(c.TLSCACertFile)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read CA certificate file %s", c.TLSCACertFile)
+			}
+			if !pool.AppendCertsFromPEM(caCert) {
+				return nil, errors.Errorf("CA certificate file %s has no PEM encoded certificate", c.TLSCACertFile)
+			}
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.TLSInsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true //nolint:gosec // opted into for lab environments only
+	}
+
+	return tlsConfig, nil
+}
+
+// tlsHTTPClient returns a copy of base, or a new client when base is nil, whose transport verifies servers with the
+// TLS options of cfg. base is returned unchanged when cfg has none, and so is a transport other than *http.Transport,
+// which has no TLS config to set. Since the source constructors can't fail, invalid options fail every request instead.
+func tlsHTTPClient(cfg *Config, base *http.Client) *http.Client {
+	if !cfg.hasTLSOptions() {
+		return base
+	}
+
+	client := &http.Client{}
+	if base != nil {
+		copied := *base
+		client = &copied
+	}
+
+	roundTripper := client.Transport
+	if roundTripper == nil {
+		roundTripper = http.DefaultTransport
+	}
+
+	transport, ok := roundTripper.(*http.Transport)
+	if !ok {
+		return client
+	}
+
+	transport = transport.Clone()
+
+	tlsConfig, err := cfg.tlsConfig(transport.TLSClientConfig)
+	if err != nil {
+		client.Transport = &tlsErrorTransport{err: err}
+		return client
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	client.Transport = transport
+
+	return client
+}
+
+// tlsErrorTransport fails every request with the error of invalid TLS options.
+type tlsErrorTransport struct {
+	err error
+}
+
+func (t *tlsErrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must close the body of the request, even when it fails.
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	return nil, errors.Wrap(t.err, "invalid TLS options")
+}
@@ -4,6 +4,8 @@
 package sources
 
 import (
+	"net/http"
+
 	"github.com/aserto-dev/scc-lib/internal/interactions"
 	"github.com/google/wire"
 	"github.com/rs/zerolog"
@@ -11,29 +13,88 @@ import (
 )
 
 func NewGitlab(log *zerolog.Logger, cfg *Config) Source {
+	wire.Build(
+		wire.Struct(new(gitlabSource), "logger", "cfg", "interactionsFunc", "clients"),
+		wire.Bind(new(Source), new(*gitlabSource)),
+		newGitlabInteraction,
+		newHTTPClients,
+	)
+
+	return &gitlabSource{}
+}
+
+// NewGitlabWithClient creates a Gitlab source that sends its requests through httpClient.
+func NewGitlabWithClient(log *zerolog.Logger, cfg *Config, httpClient *http.Client) Source {
 	wire.Build(
 		wire.Struct(new(gitlabSource), "*"),
 		wire.Bind(new(Source), new(*gitlabSource)),
-		interactions.NewGitlabInteraction,
+		newGitlabInteractionWithClient,
+		newHTTPClients,
 	)
 
 	return &gitlabSource{}
 }
 
 func NewGithub(log *zerolog.Logger, cfg *Config) Source {
+	wire.Build(
+		wire.Struct(new(githubSource), "logger", "cfg", "interactionsFunc", "graphqlFunc", "clients"),
+		wire.Bind(new(Source), new(*githubSource)),
+		newGithubInteraction,
+		newGraphqlInteraction,
+		newHTTPClients,
+	)
+
+	return &githubSource{}
+}
+
+// NewGithubWithClient creates a GitHub source that sends its requests through httpClient.
+func NewGithubWithClient(log *zerolog.Logger, cfg *Config, httpClient *http.Client) Source {
 	wire.Build(
 		wire.Struct(new(githubSource), "*"),
 		wire.Bind(new(Source), new(*githubSource)),
-		interactions.NewGithubInteraction,
-		interactions.NewGraphqlInteraction,
+		newGithubInteractionWithClient,
+		newGraphqlInteractionWithClient,
+		newHTTPClients,
 	)
 
 	return &githubSource{}
 }
 
+func NewBitbucket(log *zerolog.Logger, cfg *Config) Source {
+	wire.Build(
+		wire.Struct(new(bitbucketSource), "*"),
+		wire.Bind(new(Source), new(*bitbucketSource)),
+		newBitbucketInteraction,
+		newHTTPClients,
+	)
+
+	return &bitbucketSource{}
+}
+
+func NewGitea(log *zerolog.Logger, cfg *Config) Source {
+	wire.Build(
+		wire.Struct(new(giteaSource), "*"),
+		wire.Bind(new(Source), new(*giteaSource)),
+		newGiteaInteraction,
+		newHTTPClients,
+	)
+
+	return &giteaSource{}
+}
+
+func NewAzureDevOps(log *zerolog.Logger, cfg *Config) Source {
+	wire.Build(
+		wire.Struct(new(azureDevOpsSource), "*"),
+		wire.Bind(new(Source), new(*azureDevOpsSource)),
+		newAzureDevOpsInteraction,
+	)
+
+	return &azureDevOpsSource{}
+}
+
 func NewTestGithub(ctrl *gomock.Controller, log *zerolog.Logger, cfg *Config, pager interactions.GhIntr, graphql interactions.GqlIntr) Source {
 	wire.Build(
-		wire.Struct(new(githubSource), "*"),
+		wire.Struct(new(githubSource), "logger", "cfg", "interactionsFunc", "graphqlFunc"),
 		wire.Bind(new(Source), new(*githubSource)),
 	)
 
@@ -42,9 +103,36 @@ func NewTestGithub(ctrl *gomock.Controller, log *zerolog.Logger, cfg *Config, pa
 
 func NewTestGitlab(ctrl *gomock.Controller, log *zerolog.Logger, cfg *Config, pager interactions.GlIntr) Source {
 	wire.Build(
-		wire.Struct(new(gitlabSource), "*"),
+		wire.Struct(new(gitlabSource), "logger", "cfg", "interactionsFunc"),
 		wire.Bind(new(Source), new(*gitlabSource)),
 	)
 
 	return &gitlabSource{}
 }
+
+func NewTestBitbucket(ctrl *gomock.Controller, log *zerolog.Logger, cfg *Config, pager interactions.BbIntr) Source {
+	wire.Build(
+		wire.Struct(new(bitbucketSource), "logger", "cfg", "interactionsFunc"),
+		wire.Bind(new(Source), new(*bitbucketSource)),
+	)
+
+	return &bitbucketSource{}
+}
+
+func NewTestGitea(ctrl *gomock.Controller, log *zerolog.Logger, cfg *Config, pager interactions.GtIntr) Source {
+	wire.Build(
+		wire.Struct(new(giteaSource), "logger", "cfg", "interactionsFunc"),
+		wire.Bind(new(Source), new(*giteaSource)),
+	)
+
+	return &giteaSource{}
+}
+
+func NewTestAzureDevOps(ctrl *gomock.Controller, log *zerolog.Logger, cfg *Config, pager interactions.AzIntr) Source {
+	wire.Build(
+		wire.Struct(new(azureDevOpsSource), "*"),
+		wire.Bind(new(Source), new(*azureDevOpsSource)),
+	)
+
+	return &azureDevOpsSource{}
+}
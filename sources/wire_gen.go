@@ -7,6 +7,8 @@
 package sources
 
 import (
+	"net/http"
+
 	"github.com/aserto-dev/scc-lib/internal/interactions"
 	"github.com/rs/zerolog"
 	"go.uber.org/mock/gomock"
@@ -15,27 +17,93 @@ import (
 // Injectors from wire.go:
 
 func NewGitlab(log *zerolog.Logger, cfg *Config) Source {
-	glIntr := interactions.NewGitlabInteraction()
+	sourcesHttpClients := newHTTPClients()
+	glIntr := newGitlabInteraction(cfg, sourcesHttpClients)
 	sourcesGitlabSource := &gitlabSource{
 		logger:           log,
 		cfg:              cfg,
 		interactionsFunc: glIntr,
+		clients:          sourcesHttpClients,
+	}
+	return sourcesGitlabSource
+}
+
+// NewGitlabWithClient creates a Gitlab source that sends its requests through httpClient.
+func NewGitlabWithClient(log *zerolog.Logger, cfg *Config, httpClient *http.Client) Source {
+	sourcesHttpClients := newHTTPClients()
+	glIntr := newGitlabInteractionWithClient(cfg, httpClient, sourcesHttpClients)
+	sourcesGitlabSource := &gitlabSource{
+		logger:           log,
+		cfg:              cfg,
+		interactionsFunc: glIntr,
+		clients:          sourcesHttpClients,
 	}
 	return sourcesGitlabSource
 }
 
 func NewGithub(log *zerolog.Logger, cfg *Config) Source {
-	ghIntr := interactions.NewGithubInteraction()
-	gqlIntr := interactions.NewGraphqlInteraction()
+	sourcesHttpClients := newHTTPClients()
+	ghIntr := newGithubInteraction(cfg, sourcesHttpClients)
+	gqlIntr := newGraphqlInteraction(cfg, sourcesHttpClients)
+	sourcesGithubSource := &githubSource{
+		logger:           log,
+		cfg:              cfg,
+		interactionsFunc: ghIntr,
+		graphqlFunc:      gqlIntr,
+		clients:          sourcesHttpClients,
+	}
+	return sourcesGithubSource
+}
+
+// NewGithubWithClient creates a GitHub source that sends its requests through httpClient.
+func NewGithubWithClient(log *zerolog.Logger, cfg *Config, httpClient *http.Client) Source {
+	sourcesHttpClients := newHTTPClients()
+	ghIntr := newGithubInteractionWithClient(cfg, httpClient, sourcesHttpClients)
+	gqlIntr := newGraphqlInteractionWithClient(cfg, httpClient, sourcesHttpClients)
 	sourcesGithubSource := &githubSource{
 		logger:           log,
 		cfg:              cfg,
 		interactionsFunc: ghIntr,
 		graphqlFunc:      gqlIntr,
+		clients:          sourcesHttpClients,
 	}
 	return sourcesGithubSource
 }
 
+func NewBitbucket(log *zerolog.Logger, cfg *Config) Source {
+	sourcesHttpClients := newHTTPClients()
+	bbIntr := newBitbucketInteraction(cfg, sourcesHttpClients)
+	sourcesBitbucketSource := &bitbucketSource{
+		logger:           log,
+		cfg:              cfg,
+		interactionsFunc: bbIntr,
+		clients:          sourcesHttpClients,
+	}
+	return sourcesBitbucketSource
+}
+
+func NewGitea(log *zerolog.Logger, cfg *Config) Source {
+	sourcesHttpClients := newHTTPClients()
+	gtIntr := newGiteaInteraction(cfg, sourcesHttpClients)
+	sourcesGiteaSource := &giteaSource{
+		logger:           log,
+		cfg:              cfg,
+		interactionsFunc: gtIntr,
+		clients:          sourcesHttpClients,
+	}
+	return sourcesGiteaSource
+}
+
+func NewAzureDevOps(log *zerolog.Logger, cfg *Config) Source {
+	azIntr := newAzureDevOpsInteraction(cfg)
+	sourcesAzureDevOpsSource := &azureDevOpsSource{
+		logger:           log,
+		cfg:              cfg,
+		interactionsFunc: azIntr,
+	}
+	return sourcesAzureDevOpsSource
+}
+
 func NewTestGithub(ctrl *gomock.Controller, log *zerolog.Logger, cfg *Config, pager interactions.GhIntr, graphql interactions.GqlIntr) Source {
 	sourcesGithubSource := &githubSource{
 		logger:           log,
@@ -54,3 +122,30 @@ func NewTestGitlab(ctrl *gomock.Controller, log *zerolog.Logger, cfg *Config, pa
 	}
 	return sourcesGitlabSource
 }
+
+func NewTestBitbucket(ctrl *gomock.Controller, log *zerolog.Logger, cfg *Config, pager interactions.BbIntr) Source {
+	sourcesBitbucketSource := &bitbucketSource{
+		logger:           log,
+		cfg:              cfg,
+		interactionsFunc: pager,
+	}
+	return sourcesBitbucketSource
+}
+
+func NewTestGitea(ctrl *gomock.Controller, log *zerolog.Logger, cfg *Config, pager interactions.GtIntr) Source {
+	sourcesGiteaSource := &giteaSource{
+		logger:           log,
+		cfg:              cfg,
+		interactionsFunc: pager,
+	}
+	return sourcesGiteaSource
+}
+
+func NewTestAzureDevOps(ctrl *gomock.Controller, log *zerolog.Logger, cfg *Config, pager interactions.AzIntr) Source {
+	sourcesAzureDevOpsSource := &azureDevOpsSource{
+		logger:           log,
+		cfg:              cfg,
+		interactionsFunc: pager,
+	}
+	return sourcesAzureDevOpsSource
+}